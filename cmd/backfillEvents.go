@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
-	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -37,11 +36,12 @@ headers checked as it queried for the previous (now incomplete) set of logs.`,
 func init() {
 	rootCmd.AddCommand(backfillEventsCmd)
 	backfillEventsCmd.Flags().Int64VarP(&endingBlockNumber, endingBlockNumberFlagName, "e", -1, "last block from which to back-fill events")
+	backfillEventsCmd.Flags().Int64Var(&logsBlockRange, "logs-block-range", 1, "maximum number of blocks to fetch logs for in a single request; 1 fetches logs for each header by block hash")
 	backfillEventsCmd.MarkFlagRequired(endingBlockNumberFlagName)
 }
 
 func backFillEvents() error {
-	ethEventInitializers, _, _, exportTransformersErr := exportTransformers()
+	ethEventInitializers, _, _, exportTransformersErr := exportTransformers(nil)
 	if exportTransformersErr != nil {
 		LogWithCommand.Fatalf("SubCommand %v: exporting transformers failed: %v", SubCommand, exportTransformersErr)
 	}
@@ -54,11 +54,8 @@ func backFillEvents() error {
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
 
-	repo, repoErr := repositories.NewCheckedHeadersRepository(&db, genConfig.Schema)
-	if repoErr != nil {
-		return fmt.Errorf("error creating checked headers repository %w for schema %s", repoErr, genConfig.Schema)
-	}
-	extractor := logs.NewLogExtractor(&db, blockChain, repo)
+	extractor := logs.NewLogExtractor(&db, blockChain)
+	extractor.BlockRangeSize = logsBlockRange
 
 	for _, initializer := range ethEventInitializers {
 		transformer := initializer(&db)
@@ -68,7 +65,13 @@ func backFillEvents() error {
 		}
 	}
 
-	err := extractor.BackFillLogs(endingBlockNumber)
+	ctx, cancel := shutdownContext()
+	defer cancel()
+	err := extractor.BackFillLogs(ctx, endingBlockNumber)
+	if isShutdown(err) {
+		LogWithCommand.Info("back-fill interrupted before completion")
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("error backfilling logs: %w", err)
 	}
@@ -66,7 +66,7 @@ func backfillStorage() error {
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
 
-	_, storageInitializers, _, exportTransformersErr := exportTransformers()
+	_, storageInitializers, _, exportTransformersErr := exportTransformers(nil)
 	if exportTransformersErr != nil {
 		return fmt.Errorf("SubCommand %v: exporting transformers failed: %v", SubCommand, exportTransformersErr)
 	}
@@ -88,7 +88,14 @@ func backfillStorage() error {
 	}
 
 	LogWithCommand.Infof("Back-filling storage for blocks %d-%d", backfillStorageStartBlockNumber, backfillStorageEndBlockNumber)
-	return loader.Run()
+	ctx, cancel := shutdownContext()
+	defer cancel()
+	err := loader.Run(ctx)
+	if isShutdown(err) {
+		LogWithCommand.Info("back-fill interrupted before completion")
+		return nil
+	}
+	return err
 }
 
 func validateBackfillStorageArgs() error {
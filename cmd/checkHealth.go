@@ -0,0 +1,60 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkHealthTimeout time.Duration
+	checkHealthURL     string
+)
+
+// checkHealthCmd represents the checkHealth command
+var checkHealthCmd = &cobra.Command{
+	Use:   "checkHealth",
+	Short: "Exits non-zero unless a health probe served by --health-address reports healthy",
+	Long: `Requests a /healthz or /readyz probe served by headerSync, execute, or extractDiffs with --health-address,
+failing unless it returns 200. Intended for container health checks, so that images don't need an http client.
+
+Use: ./vulcanizedb checkHealth --url http://localhost:8080/healthz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SubCommand = cmd.CalledAs()
+		LogWithCommand = *logrus.WithField("SubCommand", SubCommand)
+
+		ctx, cancel := context.WithTimeout(context.Background(), checkHealthTimeout)
+		defer cancel()
+		probeErr := health.Probe(ctx, checkHealthURL)
+		if probeErr != nil {
+			return fmt.Errorf("SubCommand %v: health check failed: %w", SubCommand, probeErr)
+		}
+		return nil
+	},
+}
+
+func init() {
+	checkHealthCmd.Flags().StringVar(&checkHealthURL, "url", "http://localhost:8080"+health.LivenessPath, "url of the probe to check")
+	checkHealthCmd.Flags().DurationVar(&checkHealthTimeout, "timeout", 10*time.Second, "time to wait for the probe to respond")
+	rootCmd.AddCommand(checkHealthCmd)
+}
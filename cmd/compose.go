@@ -30,6 +30,10 @@ var composeCmd = &cobra.Command{
 of transformers specified in the config file. The plugin is loaded and the set
 of transformer initializers can be executed over by the appropriate watcher.
 
+With static = true in the [exporter] config, it instead builds a vulcanizedb binary
+with the transformers compiled in, whose execute, backfillEvents and backfillStorage
+commands run them without loading a plugin.
+
 This command needs a config file location specified:
 ./vulcanizedb compose --config=./environments/config_name.toml`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -48,7 +52,14 @@ func compose() {
 
 	composeTransformers()
 
-	// TODO: Embed versioning info in the .so files so we know which version of vulcanizedb to run them with
+	if genConfig.Static {
+		binaryPath, pathErr := genConfig.GetBinaryPath()
+		if pathErr != nil {
+			LogWithCommand.Fatalf("getting binary path failed: %s", pathErr.Error())
+		}
+		LogWithCommand.Info("static binary output to ", binaryPath)
+		return
+	}
 	_, pluginPath, pathErr := genConfig.GetPluginPaths()
 	if pathErr != nil {
 		LogWithCommand.Fatalf("getting plugin path failed: %s", pathErr.Error())
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -26,8 +27,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
-	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
-	"github.com/makerdao/vulcanizedb/pkg/fs"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,8 +40,11 @@ var executeCmd = &cobra.Command{
 	Long: `Run this command to take the composed plugin and pass it to the appropriate watcher 
 to execute over. The plugin file needs to be located in the /plugins directory 
 and this command assumes the db migrations remain from when the plugin was composed.
-Additionally, the plugin must have been composed by the same version of vulcanizedb 
-or else it will not be compatible.
+Additionally, the plugin must have been composed by the same build of vulcanizedb 
+and with the same transformers configured, or else it is refused as incompatible.
+
+Events of contracts in the [contract] config are decoded with their ABIs and persisted 
+to tables in the exporter schema, without a plugin.
 
 This command needs a config file location specified: 
 ./vulcanizedb execute --config=./environments/config_name.toml`,
@@ -62,11 +65,20 @@ func init() {
 	executeCmd.Flags().DurationVarP(&retryInterval, "retry-interval", "i", 7*time.Second, "interval duration between retries on execution error")
 	executeCmd.Flags().IntVarP(&maxUnexpectedErrors, "max-unexpected-errs", "m", 5, "maximum number of unexpected errors to allow (with retries) before exiting")
 	executeCmd.Flags().Int64VarP(&newDiffBlockFromHeadOfChain, "new-diff-blocks-from-head", "d", -1, "number of blocks from head of chain to start reprocessing new diffs, defaults to -1 so all diffs are processsed")
+	executeCmd.Flags().Int64Var(&logsBlockRange, "logs-block-range", 1, "maximum number of blocks to fetch logs for in a single request; 1 fetches logs for each header by block hash")
+	executeCmd.Flags().Int64Var(&maxTransformErrors, "max-transform-errors", logs.DefaultMaxTransformErrors, "number of times a log may fail to transform before it is quarantined and skipped until retried with retryQuarantinedLogs")
+	executeCmd.Flags().DurationVar(&transformErrorBackoff, "transform-error-backoff", logs.DefaultTransformErrorBackoff, "time a log waits to be transformed again after failing to transform, doubling with each failure")
+	executeCmd.Flags().IntVar(&storageReorgWindow, "reorg-window", watcher.DefaultReorgWindow, "number of blocks from the most recent header within which storage diffs with a mismatched header hash are retried rather than marked noncanonical")
+	executeCmd.Flags().StringVar(&metricsAddress, metricsAddressFlagName, "", metricsAddressUsage)
 	executeCmd.Flags().Int64VarP(&unrecognizedDiffBlockFromHeadOfChain, "unrecognized-diff-blocks-from-head", "u", -1, "number of blocks from head of chain to start reprocessing unrecognized diffs, defaults to -1 so all diffs are processsed")
+	addHealthFlags(executeCmd)
 }
 
+// Runs the watchers until SIGINT or SIGTERM, returning once each has finished its in-flight work
 func executeTransformers() {
-	ethEventInitializers, ethStorageInitializers, _, exportTransformersErr := exportTransformers()
+	ctx, cancel := shutdownContext()
+	defer cancel()
+	ethEventInitializers, ethStorageInitializers, ethContractInitializers, exportTransformersErr := exportTransformers(nil)
 	if exportTransformersErr != nil {
 		LogWithCommand.Fatalf("SubCommand %v: exporting transformers failed: %v", SubCommand, exportTransformersErr)
 	}
@@ -74,52 +86,69 @@ func executeTransformers() {
 	// Setup bc and db objects
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
-	healthCheckFile := "/tmp/execute_health_check"
+	healthChecker := startHealthChecker(&db, blockChain)
+	startMetricsServer(metrics.NewStorageDiffsCollector(&db))
 
 	// Execute over transformer sets returned by the exporter
 	// Use WaitGroup to wait on both goroutines
 	var wg sync.WaitGroup
 	if len(ethEventInitializers) > 0 {
-		repo, repoErr := repositories.NewCheckedHeadersRepository(&db, genConfig.Schema)
-		if repoErr != nil {
-			LogWithCommand.Fatalf("failed to create checked headers repository %s for schema %s", repoErr.Error(), genConfig.Schema)
-		}
-		extractor := logs.NewLogExtractor(&db, blockChain, repo)
+		extractor := logs.NewLogExtractor(&db, blockChain)
+		extractor.BlockRangeSize = logsBlockRange
 		delegator := logs.NewLogDelegator(&db)
-		eventHealthCheckMessage := []byte("event watcher starting\n")
-		statusWriter := fs.NewStatusWriter(healthCheckFile, eventHealthCheckMessage)
-		ew := watcher.NewEventWatcher(&db, blockChain, extractor, delegator, maxUnexpectedErrors, retryInterval, statusWriter)
+		delegator.MaxTransformErrors = maxTransformErrors
+		delegator.TransformErrorBackoff = transformErrorBackoff
+		extractStatusWriter := healthChecker.NewStatusWriter("event watcher log extraction")
+		delegateStatusWriter := healthChecker.NewStatusWriter("event watcher log delegation")
+		ew := watcher.NewEventWatcher(&db, blockChain, extractor, delegator, maxUnexpectedErrors, retryInterval, extractStatusWriter, delegateStatusWriter)
 		addErr := ew.AddTransformers(ethEventInitializers)
 		if addErr != nil {
 			LogWithCommand.Fatalf("failed to add event transformer initializers to watcher: %s", addErr.Error())
 		}
 		wg.Add(1)
-		go watchEthEvents(&ew, &wg)
+		go watchEthEvents(ctx, &ew, &wg)
 	}
 
 	if len(ethStorageInitializers) > 0 {
-		newDiffStorageHealthCheckMessage := []byte("storage watcher for new diffs starting\n")
-		newDiffStatusWriter := fs.NewStatusWriter(healthCheckFile, newDiffStorageHealthCheckMessage)
+		newDiffStatusWriter := healthChecker.NewStatusWriter("storage watcher for new diffs")
 		newDiffStorageWatcher := watcher.NewStorageWatcher(&db, newDiffBlockFromHeadOfChain, newDiffStatusWriter, watcher.New)
+		newDiffStorageWatcher.ReorgWindow = storageReorgWindow
 		newDiffStorageWatcher.AddTransformers(ethStorageInitializers)
 		wg.Add(1)
-		go watchEthStorage(&newDiffStorageWatcher, &wg)
+		go watchEthStorage(ctx, &newDiffStorageWatcher, &wg)
 
-		unrecognizedDiffStorageHealthCheckMessage := []byte("storage watcher for unrecognized diffs starting\n")
-		unrecognizedDiffStatusWriter := fs.NewStatusWriter(healthCheckFile, unrecognizedDiffStorageHealthCheckMessage)
+		unrecognizedDiffStatusWriter := healthChecker.NewStatusWriter("storage watcher for unrecognized diffs")
 		unrecognizedDiffStorageWatcher := watcher.NewStorageWatcher(&db, unrecognizedDiffBlockFromHeadOfChain, unrecognizedDiffStatusWriter, watcher.Unrecognized)
+		unrecognizedDiffStorageWatcher.ReorgWindow = storageReorgWindow
 		unrecognizedDiffStorageWatcher.AddTransformers(ethStorageInitializers)
 		wg.Add(1)
-		go watchEthStorage(&unrecognizedDiffStorageWatcher, &wg)
+		go watchEthStorage(ctx, &unrecognizedDiffStorageWatcher, &wg)
+	}
+
+	if len(ethContractInitializers) > 0 {
+		contractStatusWriter := healthChecker.NewStatusWriter("contract watcher")
+		cw := watcher.NewContractWatcher(&db, blockChain, maxUnexpectedErrors, retryInterval, contractStatusWriter)
+		cw.AddTransformers(ethContractInitializers)
+		wg.Add(1)
+		go watchEthContract(ctx, &cw, &wg)
 	}
 	wg.Wait()
+	LogWithCommand.Info("all watchers stopped")
+}
+
+// Exporter compiled into a binary composed in static mode, used instead of loading a plugin
+var registeredExporter Exporter
+
+// Registers the Exporter of a binary composed in static mode, called by its generated main package at init
+func RegisterExporter(exporter Exporter) {
+	registeredExporter = exporter
 }
 
 type Exporter interface {
 	Export() ([]event.TransformerInitializer, []storage.TransformerInitializer, []transformer.ContractTransformerInitializer)
 }
 
-func watchEthEvents(w *watcher.EventWatcher, wg *sync.WaitGroup) {
+func watchEthEvents(ctx context.Context, w *watcher.EventWatcher, wg *sync.WaitGroup) {
 	defer wg.Done()
 	// Execute over the EventTransformerInitializer set using the watcher
 	LogWithCommand.Info("executing event transformers")
@@ -129,18 +158,28 @@ func watchEthEvents(w *watcher.EventWatcher, wg *sync.WaitGroup) {
 	} else {
 		recheck = constants.HeaderUnchecked
 	}
-	err := w.Execute(recheck)
-	if err != nil {
+	err := w.Execute(ctx, recheck)
+	if err != nil && !isShutdown(err) {
 		LogWithCommand.Fatalf("error executing event watcher: %s", err.Error())
 	}
 }
 
-func watchEthStorage(w watcher.IStorageWatcher, wg *sync.WaitGroup) {
+func watchEthContract(ctx context.Context, w *watcher.ContractWatcher, wg *sync.WaitGroup) {
+	defer wg.Done()
+	// Execute over the ContractTransformerInitializer set using the contract watcher
+	LogWithCommand.Info("executing contract transformers")
+	err := w.Execute(ctx)
+	if err != nil && !isShutdown(err) {
+		LogWithCommand.Fatalf("error executing contract watcher: %s", err.Error())
+	}
+}
+
+func watchEthStorage(ctx context.Context, w watcher.IStorageWatcher, wg *sync.WaitGroup) {
 	defer wg.Done()
 	// Execute over the storage.TransformerInitializer set using the storage watcher
 	LogWithCommand.Info("executing storage transformers")
-	err := w.Execute()
-	if err != nil {
+	err := w.Execute(ctx)
+	if err != nil && !isShutdown(err) {
 		LogWithCommand.Fatalf("error executing storage watcher: %s", err.Error())
 	}
 }
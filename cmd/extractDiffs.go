@@ -8,6 +8,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage/fetcher"
 	"github.com/makerdao/vulcanizedb/libraries/shared/streamer"
 	"github.com/makerdao/vulcanizedb/pkg/fs"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -37,16 +38,17 @@ func init() {
 	rootCmd.AddCommand(extractDiffsCmd)
 	extractDiffsCmd.Flags().StringVarP(&storageDiffsSource, storageDiffsSourceFlag, "s", "csv", "where to get the state diffs: csv or geth")
 	extractDiffsCmd.Flags().StringVarP(&storageDiffsPath, storageDiffsPathFlag, "p", "", "location of storage diffs csv file")
+	extractDiffsCmd.Flags().StringVar(&metricsAddress, metricsAddressFlagName, "", metricsAddressUsage)
+	addHealthFlags(extractDiffsCmd)
 }
 
 func extractDiffs() {
 	// Setup bc and db objects
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	startMetricsServer(metrics.NewStorageDiffsCollector(&db))
 
-	healthCheckFile := "/tmp/connection"
-	msg := []byte("geth storage fetcher connection established\n")
-	gethStatusWriter := fs.NewStatusWriter(healthCheckFile, msg)
+	healthChecker := startHealthChecker(&db, blockChain)
 
 	// initialize fetcher
 	var storageFetcher fetcher.IStorageFetcher
@@ -61,20 +63,19 @@ func extractDiffs() {
 		}
 		stateDiffStreamer := streamer.NewEthStateChangeStreamer(ethClient, filterQuery)
 		payloadChan := make(chan filters.Payload)
-		storageFetcher = fetcher.NewGethRpcStorageFetcher(&stateDiffStreamer, payloadChan, gethStatusWriter)
+		storageFetcher = fetcher.NewGethRpcStorageFetcher(&stateDiffStreamer, payloadChan, healthChecker.NewStatusWriter("geth storage fetcher"))
 	default:
 		logrus.Debug("fetching storage diffs from csv")
 		tailer := fs.FileTailer{Path: storageDiffsPath}
-		msg := []byte("csv tail storage fetcher connection established\n")
-		statusWriter := fs.NewStatusWriter(healthCheckFile, msg)
-
-		storageFetcher = fetcher.NewCsvTailStorageFetcher(tailer, statusWriter)
+		storageFetcher = fetcher.NewCsvTailStorageFetcher(tailer, healthChecker.NewStatusWriter("csv tail storage fetcher"))
 	}
 
 	// extract diffs
 	extractor := storage.NewDiffExtractor(storageFetcher, &db)
-	err := extractor.ExtractDiffs()
-	if err != nil {
+	ctx, cancel := shutdownContext()
+	defer cancel()
+	err := extractor.ExtractDiffs(ctx)
+	if err != nil && !isShutdown(err) {
 		LogWithCommand.Fatalf("extracting diffs failed: %s", err.Error())
 	}
 }
@@ -17,9 +17,13 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/makerdao/vulcanizedb/libraries/shared/transactions"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
@@ -31,7 +35,18 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var startingBlockFlagName = "starting-block-number"
+var (
+	startingBlockFlagName = "starting-block-number"
+	headerSyncWorkers     int
+	headerSyncBatchSize   int
+	subscribeToNewHeads   bool
+	maxReorgDepth         int64
+	syncTransactions      bool
+	transactionAddresses  []string
+)
+
+// time to wait before checking for missing headers again when none were found
+const backFillInterval = 3 * time.Second
 
 // headerSyncCmd represents the headerSync command
 var headerSyncCmd = &cobra.Command{
@@ -54,57 +69,153 @@ CLI flags, or it will attempt to run with default values.`,
 func init() {
 	rootCmd.AddCommand(headerSyncCmd)
 	headerSyncCmd.Flags().Int64VarP(&startingBlockNumber, startingBlockFlagName, "s", 0, "Block number to start syncing from")
+	headerSyncCmd.Flags().IntVarP(&headerSyncWorkers, "workers", "w", history.DefaultHeaderWorkers, "number of concurrent workers fetching missing headers")
+	headerSyncCmd.Flags().BoolVar(&subscribeToNewHeads, "subscribe", false, "ingest headers from a newHeads subscription (requires an IPC or websocket connection), polling while resubscribing if the subscription fails")
+	headerSyncCmd.Flags().IntVarP(&headerSyncBatchSize, "batch-size", "b", history.DefaultHeaderBatchSize, "number of missing headers fetched by a worker in each batch")
+	headerSyncCmd.Flags().BoolVar(&syncTransactions, "sync-transactions", false, "persist transactions from each synced header's block body")
+	headerSyncCmd.Flags().StringSliceVar(&transactionAddresses, "transaction-addresses", []string{}, "only persist block body transactions sent from or to these addresses; persists every transaction if empty")
+	headerSyncCmd.Flags().StringVar(&metricsAddress, metricsAddressFlagName, "", metricsAddressUsage)
+	headerSyncCmd.Flags().Int64Var(&maxReorgDepth, "max-reorg-depth", history.DefaultMaxReorgDepth, "maximum number of stored headers that may be replaced when a reorg is detected")
+	addHealthFlags(headerSyncCmd)
 }
 
-func backFillAllHeaders(blockchain core.BlockChain, headerRepository datastore.HeaderRepository, missingBlocksPopulated chan int, startingBlockNumber int64) {
-	populated, err := history.PopulateMissingHeaders(blockchain, headerRepository, startingBlockNumber, validationWindowSize)
-	if err != nil {
+// Back-fills missing headers after the delay, sending the number populated on missingBlocksPopulated. Sends 0 without
+// back-filling if the context is cancelled during the delay.
+func backFillAllHeaders(ctx context.Context, delay time.Duration, blockchain core.BlockChain, headerRepository datastore.HeaderRepository, missingBlocksPopulated chan int, startingBlockNumber int64, statusWriter fs.StatusWriter) {
+	select {
+	case <-ctx.Done():
+		missingBlocksPopulated <- 0
+		return
+	case <-time.After(delay):
+	}
+	// confirm health after each batch, so the status doesn't go stale while back-filling a long range
+	onBatch := func(int) { writeHealthCheck(statusWriter) }
+	populated, err := history.PopulateMissingHeaders(ctx, blockchain, headerRepository, startingBlockNumber, validationWindowSize, headerSyncWorkers, headerSyncBatchSize, onBatch)
+	if err != nil && !isShutdown(err) {
 		LogWithCommand.Errorf("backfillAllHeaders: Error populating headers: %s", err.Error())
+	} else if err == nil {
+		writeHealthCheck(statusWriter)
 	}
 	missingBlocksPopulated <- populated
 }
 
+func syncBlockTransactions(ctx context.Context, syncer transactions.IBlockTransactionsSyncer, startingBlockNumber int64, statusWriter fs.StatusWriter) {
+	for ctx.Err() == nil {
+		err := syncer.SyncBlockTransactions(ctx, startingBlockNumber)
+		if err == nil {
+			writeHealthCheck(statusWriter)
+			continue
+		}
+		// wait for new headers, or for headerSync to replace headers that no longer match the node's blocks
+		if errors.Is(err, transactions.ErrNoUnsyncedHeaders) || errors.Is(err, transactions.ErrNoHeadersSynced) {
+			writeHealthCheck(statusWriter)
+		} else if !isShutdown(err) {
+			LogWithCommand.Errorf("headerSync: error syncing block transactions: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollingInterval):
+		}
+	}
+}
+
+func writeHealthCheck(statusWriter fs.StatusWriter) {
+	writeErr := statusWriter.Write()
+	if writeErr != nil {
+		LogWithCommand.Errorf("headerSync: error confirming health check: %s", writeErr.Error())
+	}
+}
+
+// Syncs headers until SIGINT or SIGTERM, then waits for in-flight header and transaction syncing to finish
 func headerSync() error {
+	ctx, cancel := shutdownContext()
+	defer cancel()
 	ticker := time.NewTicker(pollingInterval)
 	defer ticker.Stop()
 	blockChain := getBlockChain()
-	validationErr := validateHeaderSyncArgs(blockChain)
+	validationErr := validateHeaderSyncArgs(ctx, blockChain)
 	if validationErr != nil {
 		return fmt.Errorf("error validating args: %w", validationErr)
 	}
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
 
-	headerRepository := repositories.NewHeaderRepository(&db)
-	validator := history.NewHeaderValidator(blockChain, headerRepository, validationWindowSize)
+	// headers up to the max reorg depth below the validation window may be replaced
+	headerRepository := repositories.NewHeaderRepositoryWithReorgWindow(&db, maxReorgDepth+int64(validationWindowSize))
+	reorgDetector := history.NewReorgDetector(blockChain, headerRepository, maxReorgDepth)
+	validator := history.NewHeaderValidator(blockChain, reorgDetector, validationWindowSize)
 	missingBlocksPopulated := make(chan int)
 
-	statusWriter := fs.NewStatusWriter("/tmp/header_sync_health_check", []byte("headerSync starting\n"))
-	writeErr := statusWriter.Write()
-	if writeErr != nil {
-		return fmt.Errorf("error writing health check file: %w", writeErr)
+	healthChecker := startHealthChecker(&db, blockChain)
+	validationStatusWriter := healthChecker.NewStatusWriter("header validation")
+	backFillStatusWriter := healthChecker.NewStatusWriter("header backfill")
+
+	startMetricsServer()
+
+	go backFillAllHeaders(ctx, 0, blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber, backFillStatusWriter)
+
+	var wg sync.WaitGroup
+	if syncTransactions {
+		syncer := transactions.NewBlockTransactionsSyncer(&db, blockChain, transactionAddresses)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			syncBlockTransactions(ctx, syncer, startingBlockNumber, healthChecker.NewStatusWriter("block transaction sync"))
+		}()
 	}
 
-	go backFillAllHeaders(blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber)
+	// headers are polled until subscribed, and while resubscribing
+	subscribed := false
+	subscriptionStatus := make(chan bool)
+	if subscribeToNewHeads {
+		subscriber := history.NewHeaderSubscriber(blockChain, reorgDetector)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// only returns once the context is cancelled
+			_ = subscriber.SubscribeWithRetry(ctx, subscriptionStatus)
+		}()
+	}
 
 	for {
 		select {
+		case <-ctx.Done():
+			LogWithCommand.Info("headerSync: shutting down")
+			// a back-fill is always in flight while the loop is selecting
+			<-missingBlocksPopulated
+			wg.Wait()
+			return nil
 		case <-ticker.C:
-			window, err := validator.ValidateHeaders()
+			lagErr := history.RecordHeaderSyncLag(ctx, blockChain, headerRepository)
+			if lagErr != nil {
+				LogWithCommand.Errorf("headerSync: error recording sync lag: %s", lagErr.Error())
+			}
+			if subscribed {
+				writeHealthCheck(validationStatusWriter)
+				continue
+			}
+			window, err := validator.ValidateHeaders(ctx)
 			if err != nil {
 				LogWithCommand.Errorf("headerSync: ValidateHeaders failed: %s", err.Error())
+			} else {
+				writeHealthCheck(validationStatusWriter)
 			}
 			LogWithCommand.Debug(window.GetString())
+		case subscribed = <-subscriptionStatus:
+			if !subscribed {
+				LogWithCommand.Warn("headerSync: polling for headers until resubscribed to new heads")
+			}
 		case n := <-missingBlocksPopulated:
+			var delay time.Duration
 			if n == 0 {
-				time.Sleep(3 * time.Second)
+				delay = backFillInterval
 			}
-			go backFillAllHeaders(blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber)
+			go backFillAllHeaders(ctx, delay, blockChain, headerRepository, missingBlocksPopulated, startingBlockNumber, backFillStatusWriter)
 		}
 	}
 }
 
-func validateHeaderSyncArgs(blockChain *eth.BlockChain) error {
-	chainHead, err := blockChain.ChainHead()
+func validateHeaderSyncArgs(ctx context.Context, blockChain *eth.BlockChain) error {
+	chainHead, err := blockChain.ChainHead(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting last block from chain: %w", err)
 	}
@@ -0,0 +1,77 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var listQuarantinedTransformerName string
+
+// listQuarantinedLogsCmd represents the listQuarantinedLogs command
+var listQuarantinedLogsCmd = &cobra.Command{
+	Use:   "listQuarantinedLogs",
+	Short: "Lists event logs quarantined after repeatedly failing to transform",
+	Long: `Run this command to list the event logs that execute stopped passing to a transformer
+after it failed on them --max-transform-errors times, along with its last error.
+
+Use: ./vulcanizedb listQuarantinedLogs --config=<config.toml> --transformer=<transformer name>
+
+The transformer name is the TransformerName in its event.TransformerConfig.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SubCommand = cmd.CalledAs()
+		LogWithCommand = *logrus.WithField("SubCommand", SubCommand)
+
+		listErr := listQuarantinedLogs(listQuarantinedTransformerName)
+		if listErr != nil {
+			return fmt.Errorf("SubCommand %v: failed to list logs quarantined by %s: %w", SubCommand,
+				listQuarantinedTransformerName, listErr)
+		}
+		return nil
+	},
+}
+
+func init() {
+	listQuarantinedLogsCmd.Flags().StringVar(&listQuarantinedTransformerName, "transformer", "", "name of the event transformer to list quarantined logs for")
+	listQuarantinedLogsCmd.MarkFlagRequired("transformer")
+	rootCmd.AddCommand(listQuarantinedLogsCmd)
+}
+
+func listQuarantinedLogs(transformerName string) error {
+	blockChain := getBlockChain()
+	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	repo := repositories.NewEventLogRepository(&db)
+	quarantinedLogs, getErr := repo.GetQuarantinedEventLogs(transformerName)
+	if getErr != nil {
+		return getErr
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tBLOCK\tTX HASH\tLOG INDEX\tADDRESS\tERRORS\tLAST ERROR")
+	for _, log := range quarantinedLogs {
+		fmt.Fprintf(writer, "%d\t%d\t%s\t%d\t%s\t%d\t%s\n", log.ID, log.Log.BlockNumber, log.Log.TxHash.Hex(),
+			log.Log.Index, log.Log.Address.Hex(), log.TransformErrorCount, log.LastTransformError)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,192 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/migrations"
+	"github.com/makerdao/vulcanizedb/pkg/plugin/manager"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	migrateDatabaseURL string
+	migratePlugin      bool
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate up|down|status|to-version <version>",
+	Short: "Runs database migrations",
+	Long: `Run this command to set up or change the database schema, using the core migrations
+embedded in the vulcanizedb binary, so that no separate goose binary or copy of the
+migrations is needed.
+
+Use: ./vulcanizedb migrate up --config=<config.toml>
+     ./vulcanizedb migrate down --config=<config.toml> [--plugin]
+     ./vulcanizedb migrate status --config=<config.toml> [--plugin]
+     ./vulcanizedb migrate to-version <version> --config=<config.toml> [--plugin]
+
+up applies all pending core migrations, followed by the migrations of the plugin's
+transformers if the config has an [exporter] section. down rolls back the latest
+migration, status lists which migrations have been applied, and to-version applies or
+rolls back migrations until the given version is the latest applied.
+
+Pass --plugin to act on the migrations of the plugin's transformers, recorded in the
+exporter schema, rather than the core migrations. These are embedded in the plugin (or
+in this binary, if it was composed in static mode) when it's composed.
+
+Pass --database-url to run the core migrations against a postgres connection string
+(e.g. $VDB_PG_CONNECT) instead of the configured database.`,
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: []string{"up", "down", "status", "to-version"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SubCommand = cmd.CalledAs()
+		LogWithCommand = *logrus.WithField("SubCommand", SubCommand)
+
+		migrateErr := migrate(args)
+		if migrateErr != nil {
+			return fmt.Errorf("SubCommand %v: %w", SubCommand, migrateErr)
+		}
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migratePlugin, "plugin", false, "act on the migrations of the plugin's transformers instead of the core migrations")
+	migrateCmd.Flags().StringVar(&migrateDatabaseURL, "database-url", "", "postgres connection string to run the core migrations against instead of the configured database")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func migrate(args []string) error {
+	command := args[0]
+	runPluginMigrations := migratePlugin || (command == "up" && len(viper.GetStringSlice("exporter.transformerNames")) > 0)
+	if migrateDatabaseURL != "" && runPluginMigrations {
+		return errors.New("--database-url is only supported for the core migrations")
+	}
+	if migratePlugin {
+		return migratePluginSchema(command, args[1:])
+	}
+
+	connectionString := config.DbConnectionString(databaseConfig)
+	if migrateDatabaseURL != "" {
+		connectionString = migrateDatabaseURL
+	}
+	db, openErr := sql.Open("postgres", connectionString)
+	if openErr != nil {
+		return fmt.Errorf("failed to open db: %w", openErr)
+	}
+	defer db.Close()
+
+	coreMigrator, cleanUp, migratorErr := migrations.NewCoreMigrator(db)
+	if migratorErr != nil {
+		return migratorErr
+	}
+	defer cleanUp()
+
+	runErr := runMigrationCommand(coreMigrator, command, args[1:])
+	if runErr != nil {
+		return runErr
+	}
+
+	if runPluginMigrations {
+		return migratePluginSchema(command, args[1:])
+	}
+	return nil
+}
+
+// Runs the migrations of the plugin's transformers embedded in this binary if it was composed in static mode, or else in
+// the configured plugin
+func migratePluginSchema(command string, args []string) error {
+	configErr := prepConfig()
+	if configErr != nil {
+		return fmt.Errorf("failed to prepare config: %w", configErr)
+	}
+	pluginMigrations := registeredMigrations
+	if registeredExporter == nil {
+		var loadErr error
+		pluginMigrations, loadErr = loadPluginMigrations()
+		if loadErr != nil {
+			return loadErr
+		}
+	}
+	migrationManager := manager.NewMigrationManager(genConfig, databaseConfig)
+	pluginMigrator, migratorErr := migrationManager.PluginMigrator(pluginMigrations)
+	if migratorErr != nil {
+		return migratorErr
+	}
+	defer migrationManager.CleanUp()
+
+	return runMigrationCommand(pluginMigrator, command, args)
+}
+
+// Links the configured plugin and loads the migrations of its transformers, embedded in it when it was composed
+func loadPluginMigrations() (fs.FS, error) {
+	plug, openErr := openPlugin()
+	if openErr != nil {
+		return nil, openErr
+	}
+	symMigrations, lookupErr := plug.Lookup(manager.PluginMigrationsSymbol)
+	if lookupErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: loading %s symbol failed, recompose the plugin to embed its migrations: %v",
+			SubCommand, manager.PluginMigrationsSymbol, lookupErr)
+	}
+	pluginMigrations, ok := symMigrations.(*embed.FS)
+	if !ok {
+		return nil, fmt.Errorf("SubCommand %v: plugged-in %s symbol not of type embed.FS", SubCommand, manager.PluginMigrationsSymbol)
+	}
+	return pluginMigrations, nil
+}
+
+// Migrations of the transformers compiled into a binary composed in static mode, used instead of a plugin's
+var registeredMigrations fs.FS
+
+// Registers the migrations of a binary composed in static mode, called by its generated main package at init
+func RegisterMigrations(pluginMigrations fs.FS) {
+	registeredMigrations = pluginMigrations
+}
+
+func runMigrationCommand(migrator migrations.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		return migrator.Up()
+	case "down":
+		return migrator.Down()
+	case "status":
+		return migrator.Status()
+	case "to-version":
+		if len(args) != 1 {
+			return errors.New("to-version requires a version")
+		}
+		version, parseErr := strconv.ParseInt(args[0], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("invalid migration version %s: %w", args[0], parseErr)
+		}
+		return migrator.To(version)
+	default:
+		return fmt.Errorf("unknown migrate command %s, expected up, down, status or to-version", command)
+	}
+}
@@ -65,11 +65,6 @@ func init() {
 func resetHeaderCount(blockNumber int64) error {
 	blockChain := getBlockChain()
 	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
-	repo, repoErr := repositories.NewCheckedHeadersRepository(&db, genConfig.Schema)
-
-	if repoErr != nil {
-		return fmt.Errorf("error creating checked headers repository %w", repoErr)
-	}
-
+	repo := repositories.NewCheckedLogsRepository(&db)
 	return repo.MarkSingleHeaderUnchecked(blockNumber)
 }
@@ -0,0 +1,146 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
+	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
+	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retransformEndingBlock     int64
+	retransformStartingBlock   int64
+	retransformTransformerName string
+)
+
+// retransformCmd represents the retransform command
+var retransformCmd = &cobra.Command{
+	Use:   "retransform",
+	Short: "Transforms an event transformer's logs again",
+	Long: `Run this command to forget which event logs a transformer has already transformed,
+e.g. after fixing a bug in it or adding it to an existing deployment. A running execute
+command passes the transformer all of its logs again, without affecting other transformers.
+
+Use: ./vulcanizedb retransform --config=<config.toml> --transformer=<transformer name>
+
+With --start and --end, the transformer is instead loaded from the plugin (or the binary,
+if composed in static mode) and run over its persisted logs between those blocks straight
+away, skipping logs quarantined for it. If the transformer implements event.Cleaner, the
+models it persisted for each batch of logs are deleted just before the batch is transformed
+again; otherwise existing models are updated in place. The node isn't used.
+
+Use: ./vulcanizedb retransform --config=<config.toml> --transformer=<transformer name> --start=<block> --end=<block>
+
+The transformer name is the TransformerName in its event.TransformerConfig.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SubCommand = cmd.CalledAs()
+		LogWithCommand = *logrus.WithField("SubCommand", SubCommand)
+
+		startSet, endSet := cmd.Flags().Changed("start"), cmd.Flags().Changed("end")
+		if startSet != endSet {
+			return fmt.Errorf("SubCommand %v: --start and --end must be passed together", SubCommand)
+		}
+		if startSet {
+			if retransformStartingBlock > retransformEndingBlock {
+				return fmt.Errorf("SubCommand %v: --start must not be after --end", SubCommand)
+			}
+			retransformed, retransformErr := retransformRange(retransformTransformerName, retransformStartingBlock,
+				retransformEndingBlock)
+			if retransformErr != nil {
+				return fmt.Errorf("SubCommand %v: failed to retransform logs for %s: %w", SubCommand, retransformTransformerName, retransformErr)
+			}
+			LogWithCommand.Infof("retransformed %d logs for %s between blocks %d and %d", retransformed,
+				retransformTransformerName, retransformStartingBlock, retransformEndingBlock)
+			return nil
+		}
+
+		resetErr := retransform(retransformTransformerName)
+		if resetErr != nil {
+			return fmt.Errorf("SubCommand %v: failed to reset transformer %s: %w", SubCommand, retransformTransformerName, resetErr)
+		}
+		LogWithCommand.Infof("reset %s to transform all of its logs again", retransformTransformerName)
+		return nil
+	},
+}
+
+func init() {
+	retransformCmd.Flags().StringVar(&retransformTransformerName, "transformer", "", "name of the event transformer to retransform logs for")
+	retransformCmd.Flags().Int64Var(&retransformStartingBlock, "start", 0, "first block to retransform logs from, with --end")
+	retransformCmd.Flags().Int64Var(&retransformEndingBlock, "end", 0, "last block to retransform logs from, with --start")
+	retransformCmd.MarkFlagRequired("transformer")
+	rootCmd.AddCommand(retransformCmd)
+}
+
+func retransform(transformerName string) error {
+	db, dbErr := postgres.NewDBWithoutNode(databaseConfig)
+	if dbErr != nil {
+		return dbErr
+	}
+	repo := repositories.NewEventLogRepository(db)
+	return repo.ResetTransformedEventLogs(transformerName)
+}
+
+// Runs the transformer over its logs between the given blocks again, cleaning up the models of each batch of logs first.
+// Returns the number of logs retransformed.
+func retransformRange(transformerName string, startingBlock, endingBlock int64) (int, error) {
+	db, dbErr := postgres.NewDBWithoutNode(databaseConfig)
+	if dbErr != nil {
+		return 0, dbErr
+	}
+
+	// the ABIs saved while extracting the transformer's logs are used, rather than fetching them again
+	eventTransformerInitializers, _, _, exportErr := exportTransformers(repositories.NewAbiRepository(db))
+	if exportErr != nil {
+		return 0, exportErr
+	}
+	var transformer event.ITransformer
+	for _, initializer := range eventTransformerInitializers {
+		candidate := initializer(db)
+		if candidate.GetConfig().TransformerName == transformerName {
+			transformer = candidate
+			break
+		}
+	}
+	if transformer == nil {
+		return 0, errors.New("no event transformer with that name is configured")
+	}
+
+	if preparer, ok := transformer.(event.Preparer); ok {
+		prepareErr := preparer.Prepare()
+		if prepareErr != nil {
+			return 0, prepareErr
+		}
+	}
+	if _, ok := transformer.(event.Cleaner); !ok {
+		LogWithCommand.Warnf("%s doesn't implement event.Cleaner, so its existing models are updated rather than deleted",
+			transformerName)
+	}
+
+	ctx, cancel := shutdownContext()
+	defer cancel()
+	delegator := logs.NewLogDelegator(db)
+	delegator.AddTransformer(transformer)
+	return delegator.RedelegateLogs(ctx, startingBlock, endingBlock, watcher.ResultsLimit)
+}
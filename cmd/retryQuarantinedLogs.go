@@ -0,0 +1,75 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retryLogIDs          []int
+	retryTransformerName string
+)
+
+// retryQuarantinedLogsCmd represents the retryQuarantinedLogs command
+var retryQuarantinedLogsCmd = &cobra.Command{
+	Use:   "retryQuarantinedLogs",
+	Short: "Releases quarantined event logs to be transformed again",
+	Long: `Run this command to release event logs quarantined after a transformer repeatedly
+failed on them, e.g. once the bug that caused the failures has been fixed. Their error
+counts are reset, and a running execute command passes them to the transformer again.
+
+Use: ./vulcanizedb retryQuarantinedLogs --config=<config.toml> --transformer=<transformer name> [--log-ids=<id>,<id>]
+
+All of the transformer's quarantined logs are released if no log ids are given. The
+transformer name is the TransformerName in its event.TransformerConfig.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SubCommand = cmd.CalledAs()
+		LogWithCommand = *logrus.WithField("SubCommand", SubCommand)
+
+		retried, retryErr := retryQuarantinedLogs(retryTransformerName, retryLogIDs)
+		if retryErr != nil {
+			return fmt.Errorf("SubCommand %v: failed to retry logs quarantined by %s: %w", SubCommand,
+				retryTransformerName, retryErr)
+		}
+		LogWithCommand.Infof("released %d logs quarantined by %s", retried, retryTransformerName)
+		return nil
+	},
+}
+
+func init() {
+	retryQuarantinedLogsCmd.Flags().StringVar(&retryTransformerName, "transformer", "", "name of the event transformer to retry quarantined logs for")
+	retryQuarantinedLogsCmd.Flags().IntSliceVar(&retryLogIDs, "log-ids", nil, "ids of the quarantined logs to retry; retries all of the transformer's quarantined logs if empty")
+	retryQuarantinedLogsCmd.MarkFlagRequired("transformer")
+	rootCmd.AddCommand(retryQuarantinedLogsCmd)
+}
+
+func retryQuarantinedLogs(transformerName string, logIDs []int) (int64, error) {
+	blockChain := getBlockChain()
+	db := utils.LoadPostgres(databaseConfig, blockChain.Node())
+	repo := repositories.NewEventLogRepository(&db)
+	var ids []int64
+	for _, id := range logIDs {
+		ids = append(ids, int64(id))
+	}
+	return repo.RetryQuarantinedEventLogs(transformerName, ids)
+}
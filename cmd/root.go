@@ -17,11 +17,15 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"plugin"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -30,10 +34,18 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/storage"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/eth"
 	"github.com/makerdao/vulcanizedb/pkg/eth/client"
 	"github.com/makerdao/vulcanizedb/pkg/eth/converters"
 	"github.com/makerdao/vulcanizedb/pkg/eth/node"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/makerdao/vulcanizedb/pkg/plugin/metadata"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -47,16 +59,26 @@ var (
 	newDiffBlockFromHeadOfChain          int64
 	unrecognizedDiffBlockFromHeadOfChain int64
 	genConfig                            config.Plugin
+	healthAddress                        string
+	healthMaxHeaderLag                   int64
+	healthMaxStaleness                   time.Duration
 	ipc                                  string
+	logsBlockRange                       int64
+	maxTransformErrors                   int64
 	maxUnexpectedErrors                  int
+	metricsAddress                       string
 	recheckHeadersArg                    bool
 	retryInterval                        time.Duration
 	startingBlockNumber                  int64
+	storageReorgWindow                   int
+	transformErrorBackoff                time.Duration
 )
 
 const (
-	pollingInterval      = 7 * time.Second
-	validationWindowSize = 15
+	metricsAddressFlagName = "metrics-address"
+	metricsAddressUsage    = "address (e.g. :9090) at which to serve Prometheus metrics; metrics are not served if empty"
+	pollingInterval        = 7 * time.Second
+	validationWindowSize   = 15
 )
 
 var rootCmd = &cobra.Command{
@@ -64,6 +86,21 @@ var rootCmd = &cobra.Command{
 	PersistentPreRun: initFuncs,
 }
 
+// Main sets up logging as JSON to vulcanizedb.log and runs the command line. It's the entrypoint of both the vulcanizedb
+// binary and binaries composed in static mode.
+func Main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	file, err := os.OpenFile("vulcanizedb.log",
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err == nil {
+		logrus.SetOutput(file)
+	} else {
+		logrus.Info("Failed to log to file, using default stderr")
+	}
+
+	Execute()
+}
+
 func Execute() {
 	logrus.Info("----- Starting vDB -----")
 	if err := rootCmd.Execute(); err != nil {
@@ -153,6 +190,56 @@ func getBlockChain() *eth.BlockChain {
 	return eth.NewBlockChain(vdbEthClient, rpcClient, vdbNode, transactionConverter)
 }
 
+// Serves Prometheus metrics if --metrics-address was passed, registering any command specific collectors
+func startMetricsServer(collectors ...prometheus.Collector) {
+	if metricsAddress == "" {
+		return
+	}
+	prometheus.MustRegister(collectors...)
+	metrics.StartServer(metricsAddress)
+}
+
+// Returns a context that is cancelled on SIGINT or SIGTERM, so long-running commands can finish in-flight work and exit
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case sig := <-signals:
+			LogWithCommand.Infof("received %s, shutting down", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// Reports whether err was caused by a shutdown signal rather than a failure
+func isShutdown(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
+
+func addHealthFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&healthAddress, "health-address", "", "address (e.g. :8080) at which to serve /healthz and /readyz probes; probes are not served if empty")
+	cmd.Flags().DurationVar(&healthMaxStaleness, "health-max-staleness", 5*time.Minute, "maximum time since a component's last successful loop iteration before /healthz fails; 0 disables the check")
+	cmd.Flags().Int64Var(&healthMaxHeaderLag, "health-max-header-lag", 50, "maximum number of blocks the most recent header may trail the chain head before /readyz fails")
+}
+
+// Creates a checker whose readiness requires database and node connectivity and a header sync within
+// --health-max-header-lag blocks of the chain head, serving it if --health-address was passed
+func startHealthChecker(db *postgres.DB, blockChain core.BlockChain) *health.Checker {
+	checker := health.NewChecker(healthMaxStaleness)
+	checker.AddReadinessCheck("database", health.DatabaseCheck(db))
+	checker.AddReadinessCheck("node", health.NodeCheck(blockChain))
+	checker.AddReadinessCheck("headerSync", health.HeaderSyncLagCheck(blockChain, repositories.NewHeaderRepository(db), healthMaxHeaderLag))
+	if healthAddress != "" {
+		health.StartServer(healthAddress, checker)
+	}
+	return checker
+}
+
 func getClients() (client.RpcClient, *ethclient.Client) {
 	rawRpcClient, err := rpc.Dial(ipc)
 
@@ -197,7 +284,7 @@ func prepConfig() error {
 		}
 		transformerType := config.GetTransformerType(t)
 		if transformerType == config.UnknownTransformerType {
-			return errors.New(`unknown transformer type in exporter config accepted types are "eth_event", "eth_storage"`)
+			return errors.New(`unknown transformer type in exporter config accepted types are "eth_event", "eth_storage", "eth_contract"`)
 		}
 
 		transformers[name] = config.Transformer{
@@ -206,6 +293,7 @@ func prepConfig() error {
 			RepositoryPath: r,
 			MigrationPath:  m,
 			MigrationRank:  rank,
+			Version:        transformer["version"],
 		}
 	}
 
@@ -216,46 +304,137 @@ func prepConfig() error {
 		FileName:     viper.GetString("exporter.name"),
 		Save:         viper.GetBool("exporter.save"),
 		Home:         viper.GetString("exporter.home"),
+		Static:       viper.GetBool("exporter.static"),
 	}
 	return nil
 }
 
-func exportTransformers() ([]event.TransformerInitializer, []storage.TransformerInitializer, []transformer.ContractTransformerInitializer, error) {
+// ABIs for the [contract] config are read from savedAbis if it's not nil, and otherwise fetched from Etherscan
+func exportTransformers(savedAbis datastore.AbiRepository) ([]event.TransformerInitializer, []storage.TransformerInitializer, []transformer.ContractTransformerInitializer, error) {
 	// Build plugin generator config
 	configErr := prepConfig()
 	if configErr != nil {
 		return nil, nil, nil, fmt.Errorf("SubCommand %v: failed to to prepare config: %v", SubCommand, configErr)
 	}
 
-	// Get the plugin path and load the plugin
-	_, pluginPath, pathErr := genConfig.GetPluginPaths()
-	if pathErr != nil {
-		return nil, nil, nil, fmt.Errorf("SubCommand %v: failed to get plugin paths: %v", SubCommand, pathErr)
+	// Build ABI transformers for contracts in the [contract] config, which need no plugin
+	abiTransformerInitializers, abiErr := exportAbiTransformers(savedAbis)
+	if abiErr != nil {
+		return nil, nil, nil, fmt.Errorf("SubCommand %v: failed to build ABI transformers: %w", SubCommand, abiErr)
 	}
 
-	LogWithCommand.Info("linking plugin ", pluginPath)
-	plug, openErr := plugin.Open(pluginPath)
+	// Binaries composed in static mode have their Exporter compiled in, otherwise it's loaded from the plugin
+	exporter := registeredExporter
+	if exporter != nil {
+		LogWithCommand.Info("loading transformers compiled into this binary")
+	} else {
+		if genConfig.FileName == "" && len(abiTransformerInitializers) > 0 {
+			LogWithCommand.Info("no plugin configured, executing ABI transformers only")
+			return abiTransformerInitializers, nil, nil, nil
+		}
+		var loadErr error
+		exporter, loadErr = loadPluginExporter()
+		if loadErr != nil {
+			return nil, nil, nil, loadErr
+		}
+	}
+
+	// Use the Exporters export method to load the EventTransformerInitializer, StorageTransformerInitializer, and ContractTransformerInitializer sets
+	eventTransformerInitializers, storageTransformerInitializers, contractTransformerInitializers := exporter.Export()
+	eventTransformerInitializers = append(eventTransformerInitializers, abiTransformerInitializers...)
+
+	return eventTransformerInitializers, storageTransformerInitializers, contractTransformerInitializers, nil
+}
+
+// Links the configured plugin and loads its Exporter, refusing plugins composed by a different build of vulcanizedb
+func loadPluginExporter() (Exporter, error) {
+	plug, openErr := openPlugin()
 	if openErr != nil {
-		return nil, nil, nil, fmt.Errorf("SubCommand %v: linking plugin failed: %v", SubCommand, openErr)
+		return nil, openErr
 	}
 
 	// Load the `Exporter` symbol from the plugin
 	LogWithCommand.Info("loading transformers from plugin")
 	symExporter, lookupErr := plug.Lookup("Exporter")
 	if lookupErr != nil {
-		return nil, nil, nil, fmt.Errorf("SubCommand %v: loading Exporter symbol failed: %v", SubCommand, lookupErr)
+		return nil, fmt.Errorf("SubCommand %v: loading Exporter symbol failed: %v", SubCommand, lookupErr)
 	}
 
 	// Assert that the symbol is of type Exporter
 	exporter, ok := symExporter.(Exporter)
 	if !ok {
-		return nil, nil, nil, fmt.Errorf("SubCommand %v: plugged-in symbol not of type Exporter", SubCommand)
+		return nil, fmt.Errorf("SubCommand %v: plugged-in symbol not of type Exporter", SubCommand)
 	}
+	return exporter, nil
+}
 
-	// Use the Exporters export method to load the EventTransformerInitializer, StorageTransformerInitializer, and ContractTransformerInitializer sets
-	eventTransformerInitializers, storageTransformerInitializers, contractTransformerInitializers := exporter.Export()
+// Links the configured plugin, refusing plugins composed by a different build of vulcanizedb
+func openPlugin() (*plugin.Plugin, error) {
+	// Get the plugin path and load the plugin
+	_, pluginPath, pathErr := genConfig.GetPluginPaths()
+	if pathErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: failed to get plugin paths: %v", SubCommand, pathErr)
+	}
 
-	return eventTransformerInitializers, storageTransformerInitializers, contractTransformerInitializers, nil
+	// Check the plugin was composed by this build of vulcanizedb before linking it, since linking fails obscurely if not
+	repoVersions, versionErr := genConfig.GetRepoVersions()
+	if versionErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: failed to get transformer repository versions: %w", SubCommand, versionErr)
+	}
+	expectedMetadata := metadata.New(genConfig.GetTransformerNames(), repoVersions)
+	metadataErr := checkPluginMetadataFile(pluginPath, expectedMetadata)
+	if metadataErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: plugin %s: %w", SubCommand, pluginPath, metadataErr)
+	}
+
+	LogWithCommand.Info("linking plugin ", pluginPath)
+	plug, openErr := plugin.Open(pluginPath)
+	if openErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: linking plugin failed, check it was composed by this build of vulcanizedb: %v", SubCommand, openErr)
+	}
+
+	pluginMetadata, lookupMetadataErr := metadata.Lookup(plug)
+	if lookupMetadataErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: plugin %s: %w", SubCommand, pluginPath, lookupMetadataErr)
+	}
+	compareErr := pluginMetadata.Compare(expectedMetadata)
+	if compareErr != nil {
+		return nil, fmt.Errorf("SubCommand %v: plugin %s: %w", SubCommand, pluginPath, compareErr)
+	}
+	return plug, nil
+}
+
+// Compares the metadata file written when the plugin was composed against this binary and config. Plugins composed
+// before the file existed are left for the check of their exported metadata once linked.
+func checkPluginMetadataFile(pluginPath string, expected metadata.Metadata) error {
+	metadataPath := metadata.FilePath(pluginPath)
+	if _, statErr := os.Stat(metadataPath); os.IsNotExist(statErr) {
+		LogWithCommand.Warnf("no metadata file found at %s, plugin compatibility will be checked after linking", metadataPath)
+		return nil
+	}
+	composed, readErr := metadata.Read(metadataPath)
+	if readErr != nil {
+		return readErr
+	}
+	return composed.Compare(expected)
+}
+
+// Builds an event transformer for each event watched by the [contract] config, with tables in the exporter schema.
+// ABIs missing from the config are read from savedAbis if it's not nil, falling back to fetching them from Etherscan.
+func exportAbiTransformers(savedAbis datastore.AbiRepository) ([]event.TransformerInitializer, error) {
+	if len(viper.GetStringSlice("contract.addresses")) == 0 {
+		return nil, nil
+	}
+	if genConfig.Schema == "" {
+		return nil, errors.New("exporter `schema` is required to create tables for ABI transformers")
+	}
+	var contractConfig config.ContractConfig
+	contractConfig.PrepConfig()
+	var fetcher event.AbiFetcher = eth.NewEtherScanClient(eth.GenURL(contractConfig.Network))
+	if savedAbis != nil {
+		fetcher = event.SavedAbiFetcher{AbiRepository: savedAbis, Fetcher: fetcher}
+	}
+	return event.NewAbiTransformerInitializers(contractConfig, genConfig.Schema, fetcher)
 }
 
 func validateBlockNumberArg(blockNumber int64, argName string) error {
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE public.reorgs
+(
+    id             SERIAL PRIMARY KEY,
+    block_number   BIGINT        NOT NULL,
+    depth          INTEGER       NOT NULL,
+    old_hashes     VARCHAR(66)[] NOT NULL,
+    new_hashes     VARCHAR(66)[] NOT NULL,
+    old_header_ids INTEGER[]     NOT NULL,
+    eth_node_id    INTEGER       NOT NULL REFERENCES eth_nodes (id) ON DELETE CASCADE,
+    created        TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN public.reorgs.block_number
+    IS 'Block number of the common ancestor of the orphaned and canonical headers';
+
+CREATE INDEX reorgs_block_number
+    ON public.reorgs (block_number);
+
+
+-- +goose Down
+DROP TABLE public.reorgs;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE public.headers
+    ADD COLUMN transactions_synced BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN public.headers.transactions_synced
+    IS 'Whether every transaction in the block body has been checked against the block transaction sync address filter';
+
+CREATE INDEX headers_transactions_not_synced
+    ON public.headers (block_number)
+    WHERE transactions_synced = FALSE;
+
+
+-- +goose Down
+DROP INDEX public.headers_transactions_not_synced;
+
+ALTER TABLE public.headers
+    DROP COLUMN transactions_synced;
@@ -0,0 +1,141 @@
+-- +goose Up
+ALTER TABLE public.transactions
+    ADD COLUMN tx_type                  INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN chain_id                 NUMERIC,
+    ADD COLUMN max_fee_per_gas          NUMERIC,
+    ADD COLUMN max_priority_fee_per_gas NUMERIC,
+    ADD COLUMN max_fee_per_blob_gas     NUMERIC,
+    ADD COLUMN access_list              JSONB,
+    ADD COLUMN blob_versioned_hashes    VARCHAR(66)[];
+
+COMMENT ON COLUMN public.transactions.tx_type
+    IS 'EIP-2718 transaction type; 0 for legacy transactions';
+COMMENT ON COLUMN public.transactions.raw
+    IS 'Canonical encoding of the transaction: RLP for legacy transactions, the typed envelope otherwise';
+
+ALTER TABLE public.headers
+    ADD COLUMN base_fee_per_gas NUMERIC;
+
+DROP FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR, raw JSONB, block_timestamp NUMERIC, eth_node_id INTEGER, reorg_window INTEGER);
+
+-- +goose StatementBegin
+CREATE FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR(66), raw JSONB,
+                                            block_timestamp NUMERIC, eth_node_id INTEGER,
+                                            reorg_window INTEGER, base_fee_per_gas NUMERIC) RETURNS INTEGER AS
+$$
+DECLARE
+    matching_header_id    INTEGER := (
+        SELECT id
+        FROM public.headers
+        WHERE headers.block_number = get_or_create_header.block_number
+          AND headers.hash = get_or_create_header.hash
+    );
+    nonmatching_header_id INTEGER := (
+        SELECT id
+        FROM public.headers
+        WHERE headers.block_number = get_or_create_header.block_number
+          AND headers.hash != get_or_create_header.hash
+    );
+    max_block_number      BIGINT  := (
+        SELECT MAX(headers.block_number)
+        FROM public.headers
+    );
+    inserted_header_id    INTEGER;
+BEGIN
+    IF matching_header_id != 0 THEN
+        UPDATE public.headers
+        SET base_fee_per_gas = get_or_create_header.base_fee_per_gas
+        WHERE id = matching_header_id
+          AND headers.base_fee_per_gas IS NULL;
+        RETURN matching_header_id;
+    END IF;
+
+    IF nonmatching_header_id != 0 AND block_number <= max_block_number - reorg_window THEN
+        RETURN nonmatching_header_id;
+    END IF;
+
+    IF nonmatching_header_id != 0 AND block_number > max_block_number - reorg_window THEN
+        DELETE FROM public.headers WHERE id = nonmatching_header_id;
+    END IF;
+
+    INSERT INTO public.headers (hash, block_number, raw, block_timestamp, eth_node_id, base_fee_per_gas)
+    VALUES (get_or_create_header.hash, get_or_create_header.block_number, get_or_create_header.raw,
+            get_or_create_header.block_timestamp, get_or_create_header.eth_node_id,
+            get_or_create_header.base_fee_per_gas)
+    RETURNING id INTO inserted_header_id;
+
+    RETURN inserted_header_id;
+END
+$$
+    LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+COMMENT ON FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR, raw JSONB, block_timestamp NUMERIC, eth_node_id INTEGER, reorg_window INTEGER, base_fee_per_gas NUMERIC)
+    IS E'@omit';
+
+-- +goose Down
+DROP FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR, raw JSONB, block_timestamp NUMERIC, eth_node_id INTEGER, reorg_window INTEGER, base_fee_per_gas NUMERIC);
+
+-- +goose StatementBegin
+CREATE FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR(66), raw JSONB,
+                                            block_timestamp NUMERIC, eth_node_id INTEGER,
+                                            reorg_window INTEGER) RETURNS INTEGER AS
+$$
+DECLARE
+    matching_header_id    INTEGER := (
+        SELECT id
+        FROM public.headers
+        WHERE headers.block_number = get_or_create_header.block_number
+          AND headers.hash = get_or_create_header.hash
+    );
+    nonmatching_header_id INTEGER := (
+        SELECT id
+        FROM public.headers
+        WHERE headers.block_number = get_or_create_header.block_number
+          AND headers.hash != get_or_create_header.hash
+    );
+    max_block_number      BIGINT  := (
+        SELECT MAX(headers.block_number)
+        FROM public.headers
+    );
+    inserted_header_id    INTEGER;
+BEGIN
+    IF matching_header_id != 0 THEN
+        RETURN matching_header_id;
+    END IF;
+
+    IF nonmatching_header_id != 0 AND block_number <= max_block_number - reorg_window THEN
+        RETURN nonmatching_header_id;
+    END IF;
+
+    IF nonmatching_header_id != 0 AND block_number > max_block_number - reorg_window THEN
+        DELETE FROM public.headers WHERE id = nonmatching_header_id;
+    END IF;
+
+    INSERT INTO public.headers (hash, block_number, raw, block_timestamp, eth_node_id)
+    VALUES (get_or_create_header.hash, get_or_create_header.block_number, get_or_create_header.raw,
+            get_or_create_header.block_timestamp, get_or_create_header.eth_node_id)
+    RETURNING id INTO inserted_header_id;
+
+    RETURN inserted_header_id;
+END
+$$
+    LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+COMMENT ON FUNCTION public.get_or_create_header(block_number BIGINT, hash VARCHAR, raw JSONB, block_timestamp NUMERIC, eth_node_id INTEGER, reorg_window INTEGER)
+    IS E'@omit';
+
+ALTER TABLE public.headers
+    DROP COLUMN base_fee_per_gas;
+
+COMMENT ON COLUMN public.transactions.raw IS NULL;
+
+ALTER TABLE public.transactions
+    DROP COLUMN tx_type,
+    DROP COLUMN chain_id,
+    DROP COLUMN max_fee_per_gas,
+    DROP COLUMN max_priority_fee_per_gas,
+    DROP COLUMN max_fee_per_blob_gas,
+    DROP COLUMN access_list,
+    DROP COLUMN blob_versioned_hashes;
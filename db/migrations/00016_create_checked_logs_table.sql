@@ -0,0 +1,83 @@
+-- +goose Up
+DELETE
+FROM public.watched_logs duplicate
+    USING public.watched_logs original
+WHERE duplicate.id > original.id
+  AND duplicate.contract_address = original.contract_address
+  AND duplicate.topic_zero = original.topic_zero;
+
+ALTER TABLE public.watched_logs
+    ADD CONSTRAINT watched_logs_contract_address_topic_zero_key UNIQUE (contract_address, topic_zero);
+
+CREATE TABLE public.checked_logs
+(
+    id             SERIAL PRIMARY KEY,
+    header_id      INTEGER NOT NULL REFERENCES public.headers (id) ON DELETE CASCADE,
+    watched_log_id INTEGER NOT NULL REFERENCES public.watched_logs (id) ON DELETE CASCADE,
+    check_count    INTEGER NOT NULL DEFAULT 1,
+    UNIQUE (header_id, watched_log_id)
+);
+
+COMMENT ON TABLE public.checked_logs
+    IS 'Number of times each header has been checked for each watched log';
+
+CREATE INDEX checked_logs_watched_log_id
+    ON public.checked_logs (watched_log_id);
+
+ALTER TABLE public.watched_logs
+    ADD COLUMN checked_through_block_number BIGINT;
+
+COMMENT ON COLUMN public.watched_logs.checked_through_block_number
+    IS 'Block through which headers were checked before checks were tracked per watched log; headers at or below it are only checked again once reset';
+
+-- Seed watched logs that predate per watched log checks with the last block that every schema's checked_headers
+-- had checked contiguously (stopping at the first unchecked or missing header), less the 225 blocks still subject to
+-- rechecks (15 * 5 * 6 / 2 for the default recheck cap of 5), so that existing transformers resume near the head
+-- rather than re-extracting from their starting blocks.
+-- +goose StatementBegin
+DO
+$$
+    DECLARE
+        checked_headers_schema TEXT;
+        schema_checked_through BIGINT;
+        checked_through        BIGINT;
+    BEGIN
+        FOR checked_headers_schema IN
+            SELECT table_schema FROM information_schema.tables WHERE table_name = 'checked_headers'
+            LOOP
+                EXECUTE format('
+                    WITH checked AS (
+                        SELECT h.block_number,
+                               COALESCE(ch.check_count, 0)                          AS check_count,
+                               LEAD(h.block_number) OVER (ORDER BY h.block_number) AS next_block_number
+                        FROM public.headers h
+                                 LEFT JOIN %I.checked_headers ch ON ch.header_id = h.id
+                        WHERE h.block_number >= (SELECT MIN(h2.block_number)
+                                                 FROM public.headers h2
+                                                          JOIN %I.checked_headers ch2 ON ch2.header_id = h2.id
+                                                 WHERE ch2.check_count > 0)
+                    )
+                    SELECT LEAST((SELECT MIN(block_number) - 1 FROM checked WHERE check_count < 1),
+                                 (SELECT MIN(block_number) FROM checked WHERE next_block_number > block_number + 1),
+                                 (SELECT MAX(block_number) FROM checked WHERE check_count > 0) - 225)',
+                               checked_headers_schema, checked_headers_schema)
+                    INTO schema_checked_through;
+                IF schema_checked_through IS NOT NULL THEN
+                    checked_through := LEAST(checked_through, schema_checked_through);
+                END IF;
+            END LOOP;
+
+        UPDATE public.watched_logs SET checked_through_block_number = checked_through;
+    END
+$$;
+-- +goose StatementEnd
+
+
+-- +goose Down
+ALTER TABLE public.watched_logs
+    DROP COLUMN checked_through_block_number;
+
+DROP TABLE public.checked_logs;
+
+ALTER TABLE public.watched_logs
+    DROP CONSTRAINT watched_logs_contract_address_topic_zero_key;
@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE public.watched_logs
+    ADD COLUMN topic_one   VARCHAR(66)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN topic_two   VARCHAR(66)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN topic_three VARCHAR(66)[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN public.watched_logs.topic_one
+    IS 'Values accepted in the topic1 position of watched logs; empty accepts any value';
+COMMENT ON COLUMN public.watched_logs.topic_two
+    IS 'Values accepted in the topic2 position of watched logs; empty accepts any value';
+COMMENT ON COLUMN public.watched_logs.topic_three
+    IS 'Values accepted in the topic3 position of watched logs; empty accepts any value';
+
+ALTER TABLE public.watched_logs
+    DROP CONSTRAINT watched_logs_contract_address_topic_zero_key;
+
+ALTER TABLE public.watched_logs
+    ADD CONSTRAINT watched_logs_contract_address_topics_key
+        UNIQUE (contract_address, topic_zero, topic_one, topic_two, topic_three);
+
+
+-- +goose Down
+DELETE
+FROM public.watched_logs
+WHERE topic_one <> '{}'
+   OR topic_two <> '{}'
+   OR topic_three <> '{}';
+
+ALTER TABLE public.watched_logs
+    DROP CONSTRAINT watched_logs_contract_address_topics_key;
+
+ALTER TABLE public.watched_logs
+    ADD CONSTRAINT watched_logs_contract_address_topic_zero_key UNIQUE (contract_address, topic_zero);
+
+ALTER TABLE public.watched_logs
+    DROP COLUMN topic_one,
+    DROP COLUMN topic_two,
+    DROP COLUMN topic_three;
@@ -0,0 +1,17 @@
+-- +goose Up
+COMMENT ON COLUMN public.watched_logs.contract_address
+    IS 'Address emitting watched logs; NULL watches logs emitted by any address';
+
+CREATE UNIQUE INDEX watched_logs_any_address_topics_key
+    ON public.watched_logs (topic_zero, topic_one, topic_two, topic_three)
+    WHERE contract_address IS NULL;
+
+
+-- +goose Down
+DROP INDEX public.watched_logs_any_address_topics_key;
+
+DELETE
+FROM public.watched_logs
+WHERE contract_address IS NULL;
+
+COMMENT ON COLUMN public.watched_logs.contract_address IS NULL;
@@ -0,0 +1,61 @@
+-- +goose Up
+CREATE TABLE public.registered_addresses
+(
+    id           SERIAL PRIMARY KEY,
+    registry     TEXT        NOT NULL,
+    address      VARCHAR(42) NOT NULL,
+    header_id    INTEGER     NOT NULL REFERENCES public.headers (id) ON DELETE CASCADE,
+    block_number BIGINT      NOT NULL,
+    backfilled   BOOLEAN     NOT NULL DEFAULT FALSE,
+    UNIQUE (registry, address)
+);
+
+COMMENT ON TABLE public.registered_addresses
+    IS 'Contract addresses registered by factory event transformers, grouped by address registry name';
+COMMENT ON COLUMN public.registered_addresses.header_id
+    IS 'Header of the factory event that registered the address';
+COMMENT ON COLUMN public.registered_addresses.block_number
+    IS 'Block the address was registered at, from which its logs are extracted';
+COMMENT ON COLUMN public.registered_addresses.backfilled
+    IS 'Whether the address''s logs have been extracted from headers already checked for its registry when it was registered';
+
+CREATE INDEX registered_addresses_header_id
+    ON public.registered_addresses (header_id);
+
+CREATE INDEX registered_addresses_registry_id
+    ON public.registered_addresses (registry, id);
+
+ALTER TABLE public.watched_logs
+    ADD COLUMN address_registry TEXT;
+
+COMMENT ON COLUMN public.watched_logs.address_registry
+    IS 'Address registry whose registered addresses emit watched logs; checked once per header for every address in the registry';
+
+DROP INDEX public.watched_logs_any_address_topics_key;
+
+CREATE UNIQUE INDEX watched_logs_any_address_topics_key
+    ON public.watched_logs (topic_zero, topic_one, topic_two, topic_three)
+    WHERE contract_address IS NULL AND address_registry IS NULL;
+
+CREATE UNIQUE INDEX watched_logs_address_registry_topics_key
+    ON public.watched_logs (address_registry, topic_zero, topic_one, topic_two, topic_three)
+    WHERE address_registry IS NOT NULL;
+
+
+-- +goose Down
+DELETE
+FROM public.watched_logs
+WHERE address_registry IS NOT NULL;
+
+DROP INDEX public.watched_logs_address_registry_topics_key;
+
+DROP INDEX public.watched_logs_any_address_topics_key;
+
+CREATE UNIQUE INDEX watched_logs_any_address_topics_key
+    ON public.watched_logs (topic_zero, topic_one, topic_two, topic_three)
+    WHERE contract_address IS NULL;
+
+ALTER TABLE public.watched_logs
+    DROP COLUMN address_registry;
+
+DROP TABLE public.registered_addresses;
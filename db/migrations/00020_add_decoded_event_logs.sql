@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE public.abis
+(
+    id      SERIAL PRIMARY KEY,
+    address VARCHAR(42) NOT NULL UNIQUE,
+    abi     JSONB       NOT NULL
+);
+
+COMMENT ON TABLE public.abis
+    IS 'Contract ABIs used to decode event logs as they are persisted';
+
+ALTER TABLE public.event_logs
+    ADD COLUMN event_name TEXT,
+    ADD COLUMN decoded    JSONB;
+
+COMMENT ON COLUMN public.event_logs.event_name
+    IS 'Name of the event in the ABI of the emitting contract, NULL if the log was not decoded';
+COMMENT ON COLUMN public.event_logs.decoded
+    IS 'Event arguments decoded with the ABI of the emitting contract, keyed by argument name';
+
+
+-- +goose Down
+ALTER TABLE public.event_logs
+    DROP COLUMN event_name,
+    DROP COLUMN decoded;
+
+DROP TABLE public.abis;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE public.transform_errors
+(
+    log_id      BIGINT    NOT NULL REFERENCES public.event_logs (id) ON DELETE CASCADE,
+    transformer TEXT      NOT NULL,
+    error_count INTEGER   NOT NULL DEFAULT 0,
+    last_error  TEXT,
+    quarantined BOOLEAN   NOT NULL DEFAULT FALSE,
+    next_retry  TIMESTAMP,
+    PRIMARY KEY (log_id, transformer)
+);
+
+COMMENT ON TABLE public.transform_errors
+    IS 'Event logs a transformer failed to transform, retried until they succeed or are quarantined';
+COMMENT ON COLUMN public.transform_errors.error_count
+    IS 'Number of times the transformer has failed on the log since it was last retried';
+COMMENT ON COLUMN public.transform_errors.last_error
+    IS 'Error from the transformer''s most recent failure on the log';
+COMMENT ON COLUMN public.transform_errors.quarantined
+    IS 'Whether the transformer failed on the log too many times, and skips it until retried';
+COMMENT ON COLUMN public.transform_errors.next_retry
+    IS 'Time before which the transformer does not retry the log';
+
+CREATE INDEX transform_errors_transformer
+    ON public.transform_errors (transformer, log_id);
+
+
+-- +goose Down
+DROP TABLE public.transform_errors;
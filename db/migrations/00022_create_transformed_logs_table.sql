@@ -0,0 +1,52 @@
+-- +goose Up
+CREATE TABLE public.transformed_logs
+(
+    log_id      BIGINT    NOT NULL REFERENCES public.event_logs (id) ON DELETE CASCADE,
+    transformer TEXT      NOT NULL,
+    created     TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (log_id, transformer)
+);
+
+COMMENT ON TABLE public.transformed_logs
+    IS 'Event logs each transformer has handled. Logs it failed on are tracked in transform_errors.';
+
+CREATE INDEX transformed_logs_transformer
+    ON public.transformed_logs (transformer, log_id);
+
+-- Carries the state of event_logs.transformed over to transformed_logs, for each transformer the first time it runs
+CREATE TABLE public.legacy_transformed_logs
+(
+    log_id BIGINT PRIMARY KEY REFERENCES public.event_logs (id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE public.legacy_transformed_logs
+    IS 'Event logs that were transformed before transformed logs were tracked per transformer';
+
+INSERT INTO public.legacy_transformed_logs (log_id)
+SELECT id
+FROM public.event_logs
+WHERE transformed = true;
+
+CREATE TABLE public.legacy_seeded_transformers
+(
+    transformer TEXT      PRIMARY KEY,
+    created     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.legacy_seeded_transformers
+    IS 'Transformers whose transformed_logs have been seeded from legacy_transformed_logs';
+
+CREATE INDEX event_logs_topic0
+    ON public.event_logs ((topics[1]), id);
+
+COMMENT ON COLUMN public.event_logs.transformed
+    IS 'Deprecated: whether any transformer has transformed the log. Transformed logs are tracked per transformer in transformed_logs.';
+
+
+-- +goose Down
+COMMENT ON COLUMN public.event_logs.transformed IS NULL;
+
+DROP INDEX public.event_logs_topic0;
+DROP TABLE public.legacy_seeded_transformers;
+DROP TABLE public.legacy_transformed_logs;
+DROP TABLE public.transformed_logs;
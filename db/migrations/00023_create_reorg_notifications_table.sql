@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE public.reorg_notifications
+(
+    transformer   TEXT      PRIMARY KEY,
+    last_reorg_id BIGINT    NOT NULL,
+    updated       TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE public.reorg_notifications
+    IS 'The most recent reorg each transformer handling reorgs has been notified of';
+
+
+-- +goose Down
+DROP TABLE public.reorg_notifications;
@@ -89,10 +89,10 @@ COMMENT ON FUNCTION public.create_back_filled_diff(block_height bigint, block_ha
 
 
 --
--- Name: get_or_create_header(bigint, character varying, jsonb, numeric, integer); Type: FUNCTION; Schema: public; Owner: -
+-- Name: get_or_create_header(bigint, character varying, jsonb, numeric, integer, integer, numeric); Type: FUNCTION; Schema: public; Owner: -
 --
 
-CREATE FUNCTION public.get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer) RETURNS integer
+CREATE FUNCTION public.get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer, reorg_window integer, base_fee_per_gas numeric) RETURNS integer
     LANGUAGE plpgsql
     AS $$
 DECLARE
@@ -115,17 +115,22 @@ DECLARE
     inserted_header_id    INTEGER;
 BEGIN
     IF matching_header_id != 0 THEN
+        UPDATE public.headers
+        SET base_fee_per_gas = get_or_create_header.base_fee_per_gas
+        WHERE id = matching_header_id
+          AND headers.base_fee_per_gas IS NULL;
         RETURN matching_header_id;
     END IF;
-    IF nonmatching_header_id != 0 AND block_number <= max_block_number - 15 THEN
+    IF nonmatching_header_id != 0 AND block_number <= max_block_number - reorg_window THEN
         RETURN nonmatching_header_id;
     END IF;
-    IF nonmatching_header_id != 0 AND block_number > max_block_number - 15 THEN
+    IF nonmatching_header_id != 0 AND block_number > max_block_number - reorg_window THEN
         DELETE FROM public.headers WHERE id = nonmatching_header_id;
     END IF;
-    INSERT INTO public.headers (hash, block_number, raw, block_timestamp, eth_node_id)
+    INSERT INTO public.headers (hash, block_number, raw, block_timestamp, eth_node_id, base_fee_per_gas)
     VALUES (get_or_create_header.hash, get_or_create_header.block_number, get_or_create_header.raw,
-            get_or_create_header.block_timestamp, get_or_create_header.eth_node_id)
+            get_or_create_header.block_timestamp, get_or_create_header.eth_node_id,
+            get_or_create_header.base_fee_per_gas)
     RETURNING id INTO inserted_header_id;
     RETURN inserted_header_id;
 END
@@ -133,10 +138,10 @@ $$;
 
 
 --
--- Name: FUNCTION get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer); Type: COMMENT; Schema: public; Owner: -
+-- Name: FUNCTION get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer, reorg_window integer, base_fee_per_gas numeric); Type: COMMENT; Schema: public; Owner: -
 --
 
-COMMENT ON FUNCTION public.get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer) IS '@omit';
+COMMENT ON FUNCTION public.get_or_create_header(block_number bigint, hash character varying, raw jsonb, block_timestamp numeric, eth_node_id integer, reorg_window integer, base_fee_per_gas numeric) IS '@omit';
 
 
 --
@@ -213,6 +218,44 @@ SET default_tablespace = '';
 
 SET default_with_oids = false;
 
+--
+-- Name: abis; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.abis (
+    id integer NOT NULL,
+    address character varying(42) NOT NULL,
+    abi jsonb NOT NULL
+);
+
+
+--
+-- Name: TABLE abis; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.abis IS 'Contract ABIs used to decode event logs as they are persisted';
+
+
+--
+-- Name: abis_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.abis_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: abis_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.abis_id_seq OWNED BY public.abis.id;
+
+
 --
 -- Name: addresses; Type: TABLE; Schema: public; Owner: -
 --
@@ -272,6 +315,45 @@ CREATE SEQUENCE public.checked_headers_id_seq
 ALTER SEQUENCE public.checked_headers_id_seq OWNED BY public.checked_headers.id;
 
 
+--
+-- Name: checked_logs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.checked_logs (
+    id integer NOT NULL,
+    header_id integer NOT NULL,
+    watched_log_id integer NOT NULL,
+    check_count integer DEFAULT 1 NOT NULL
+);
+
+
+--
+-- Name: TABLE checked_logs; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.checked_logs IS 'Number of times each header has been checked for each watched log';
+
+
+--
+-- Name: checked_logs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.checked_logs_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: checked_logs_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.checked_logs_id_seq OWNED BY public.checked_logs.id;
+
+
 --
 -- Name: eth_nodes; Type: TABLE; Schema: public; Owner: -
 --
@@ -323,10 +405,33 @@ CREATE TABLE public.event_logs (
     raw jsonb,
     transformed boolean DEFAULT false NOT NULL,
     created timestamp without time zone DEFAULT now() NOT NULL,
-    updated timestamp without time zone DEFAULT now() NOT NULL
+    updated timestamp without time zone DEFAULT now() NOT NULL,
+    event_name text,
+    decoded jsonb
 );
 
 
+--
+-- Name: COLUMN event_logs.transformed; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.event_logs.transformed IS 'Deprecated: whether any transformer has transformed the log. Transformed logs are tracked per transformer in transformed_logs.';
+
+
+--
+-- Name: COLUMN event_logs.event_name; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.event_logs.event_name IS 'Name of the event in the ABI of the emitting contract, NULL if the log was not decoded';
+
+
+--
+-- Name: COLUMN event_logs.decoded; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.event_logs.decoded IS 'Event arguments decoded with the ABI of the emitting contract, keyed by argument name';
+
+
 --
 -- Name: event_logs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
@@ -390,10 +495,19 @@ CREATE TABLE public.headers (
     block_timestamp numeric,
     eth_node_id integer NOT NULL,
     created timestamp without time zone DEFAULT now() NOT NULL,
-    updated timestamp without time zone DEFAULT now() NOT NULL
+    updated timestamp without time zone DEFAULT now() NOT NULL,
+    transactions_synced boolean DEFAULT false NOT NULL,
+    base_fee_per_gas numeric
 );
 
 
+--
+-- Name: COLUMN headers.transactions_synced; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.headers.transactions_synced IS 'Whether every transaction in the block body has been checked against the block transaction sync address filter';
+
+
 --
 -- Name: headers_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
@@ -414,6 +528,39 @@ CREATE SEQUENCE public.headers_id_seq
 ALTER SEQUENCE public.headers_id_seq OWNED BY public.headers.id;
 
 
+--
+-- Name: legacy_seeded_transformers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.legacy_seeded_transformers (
+    transformer text NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: TABLE legacy_seeded_transformers; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.legacy_seeded_transformers IS 'Transformers whose transformed_logs have been seeded from legacy_transformed_logs';
+
+
+--
+-- Name: legacy_transformed_logs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.legacy_transformed_logs (
+    log_id bigint NOT NULL
+);
+
+
+--
+-- Name: TABLE legacy_transformed_logs; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.legacy_transformed_logs IS 'Event logs that were transformed before transformed logs were tracked per transformer';
+
+
 --
 -- Name: receipts; Type: TABLE; Schema: public; Owner: -
 --
@@ -454,6 +601,129 @@ CREATE SEQUENCE public.receipts_id_seq
 ALTER SEQUENCE public.receipts_id_seq OWNED BY public.receipts.id;
 
 
+--
+-- Name: registered_addresses; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.registered_addresses (
+    id integer NOT NULL,
+    registry text NOT NULL,
+    address character varying(42) NOT NULL,
+    header_id integer NOT NULL,
+    block_number bigint NOT NULL,
+    backfilled boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: TABLE registered_addresses; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.registered_addresses IS 'Contract addresses registered by factory event transformers, grouped by address registry name';
+
+
+--
+-- Name: COLUMN registered_addresses.header_id; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.registered_addresses.header_id IS 'Header of the factory event that registered the address';
+
+
+--
+-- Name: COLUMN registered_addresses.block_number; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.registered_addresses.block_number IS 'Block the address was registered at, from which its logs are extracted';
+
+
+--
+-- Name: COLUMN registered_addresses.backfilled; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.registered_addresses.backfilled IS 'Whether the address''s logs have been extracted from headers already checked for its registry when it was registered';
+
+
+--
+-- Name: registered_addresses_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.registered_addresses_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: registered_addresses_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.registered_addresses_id_seq OWNED BY public.registered_addresses.id;
+
+
+--
+-- Name: reorg_notifications; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.reorg_notifications (
+    transformer text NOT NULL,
+    last_reorg_id bigint NOT NULL,
+    updated timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: TABLE reorg_notifications; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.reorg_notifications IS 'The most recent reorg each transformer handling reorgs has been notified of';
+
+
+--
+-- Name: reorgs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.reorgs (
+    id integer NOT NULL,
+    block_number bigint NOT NULL,
+    depth integer NOT NULL,
+    old_hashes character varying(66)[] NOT NULL,
+    new_hashes character varying(66)[] NOT NULL,
+    old_header_ids integer[] NOT NULL,
+    eth_node_id integer NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: COLUMN reorgs.block_number; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.reorgs.block_number IS 'Block number of the common ancestor of the orphaned and canonical headers';
+
+
+--
+-- Name: reorgs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.reorgs_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: reorgs_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.reorgs_id_seq OWNED BY public.reorgs.id;
+
+
 --
 -- Name: storage_diff; Type: TABLE; Schema: public; Owner: -
 --
@@ -510,10 +780,31 @@ CREATE TABLE public.transactions (
     tx_to character varying(44),
     value numeric,
     created timestamp without time zone DEFAULT now() NOT NULL,
-    updated timestamp without time zone DEFAULT now() NOT NULL
+    updated timestamp without time zone DEFAULT now() NOT NULL,
+    tx_type integer DEFAULT 0 NOT NULL,
+    chain_id numeric,
+    max_fee_per_gas numeric,
+    max_priority_fee_per_gas numeric,
+    max_fee_per_blob_gas numeric,
+    access_list jsonb,
+    blob_versioned_hashes character varying(66)[]
 );
 
 
+--
+-- Name: COLUMN transactions.raw; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transactions.raw IS 'Canonical encoding of the transaction: RLP for legacy transactions, the typed envelope otherwise';
+
+
+--
+-- Name: COLUMN transactions.tx_type; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transactions.tx_type IS 'EIP-2718 transaction type; 0 for legacy transactions';
+
+
 --
 -- Name: transactions_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
@@ -534,6 +825,73 @@ CREATE SEQUENCE public.transactions_id_seq
 ALTER SEQUENCE public.transactions_id_seq OWNED BY public.transactions.id;
 
 
+--
+-- Name: transform_errors; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.transform_errors (
+    log_id bigint NOT NULL,
+    transformer text NOT NULL,
+    error_count integer DEFAULT 0 NOT NULL,
+    last_error text,
+    quarantined boolean DEFAULT false NOT NULL,
+    next_retry timestamp without time zone
+);
+
+
+--
+-- Name: TABLE transform_errors; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.transform_errors IS 'Event logs a transformer failed to transform, retried until they succeed or are quarantined';
+
+
+--
+-- Name: COLUMN transform_errors.error_count; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transform_errors.error_count IS 'Number of times the transformer has failed on the log since it was last retried';
+
+
+--
+-- Name: COLUMN transform_errors.last_error; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transform_errors.last_error IS 'Error from the transformer''s most recent failure on the log';
+
+
+--
+-- Name: COLUMN transform_errors.quarantined; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transform_errors.quarantined IS 'Whether the transformer failed on the log too many times, and skips it until retried';
+
+
+--
+-- Name: COLUMN transform_errors.next_retry; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.transform_errors.next_retry IS 'Time before which the transformer does not retry the log';
+
+
+--
+-- Name: transformed_logs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.transformed_logs (
+    log_id bigint NOT NULL,
+    transformer text NOT NULL,
+    created timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: TABLE transformed_logs; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON TABLE public.transformed_logs IS 'Event logs each transformer has handled. Logs it failed on are tracked in transform_errors.';
+
+
 --
 -- Name: watched_logs; Type: TABLE; Schema: public; Owner: -
 --
@@ -541,10 +899,57 @@ ALTER SEQUENCE public.transactions_id_seq OWNED BY public.transactions.id;
 CREATE TABLE public.watched_logs (
     id integer NOT NULL,
     contract_address character varying(42),
-    topic_zero character varying(66)
+    topic_zero character varying(66),
+    checked_through_block_number bigint,
+    topic_one character varying(66)[] DEFAULT '{}'::character varying[] NOT NULL,
+    topic_two character varying(66)[] DEFAULT '{}'::character varying[] NOT NULL,
+    topic_three character varying(66)[] DEFAULT '{}'::character varying[] NOT NULL,
+    address_registry text
 );
 
 
+--
+-- Name: COLUMN watched_logs.contract_address; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.contract_address IS 'Address emitting watched logs; NULL watches logs emitted by any address';
+
+
+--
+-- Name: COLUMN watched_logs.checked_through_block_number; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.checked_through_block_number IS 'Block through which headers were checked before checks were tracked per watched log; headers at or below it are only checked again once reset';
+
+
+--
+-- Name: COLUMN watched_logs.topic_one; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.topic_one IS 'Values accepted in the topic1 position of watched logs; empty accepts any value';
+
+
+--
+-- Name: COLUMN watched_logs.topic_two; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.topic_two IS 'Values accepted in the topic2 position of watched logs; empty accepts any value';
+
+
+--
+-- Name: COLUMN watched_logs.topic_three; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.topic_three IS 'Values accepted in the topic3 position of watched logs; empty accepts any value';
+
+
+--
+-- Name: COLUMN watched_logs.address_registry; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN public.watched_logs.address_registry IS 'Address registry whose registered addresses emit watched logs; checked once per header for every address in the registry';
+
+
 --
 -- Name: watched_logs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
@@ -565,6 +970,13 @@ CREATE SEQUENCE public.watched_logs_id_seq
 ALTER SEQUENCE public.watched_logs_id_seq OWNED BY public.watched_logs.id;
 
 
+--
+-- Name: abis id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.abis ALTER COLUMN id SET DEFAULT nextval('public.abis_id_seq'::regclass);
+
+
 --
 -- Name: addresses id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -579,6 +991,13 @@ ALTER TABLE ONLY public.addresses ALTER COLUMN id SET DEFAULT nextval('public.ad
 ALTER TABLE ONLY public.checked_headers ALTER COLUMN id SET DEFAULT nextval('public.checked_headers_id_seq'::regclass);
 
 
+--
+-- Name: checked_logs id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.checked_logs ALTER COLUMN id SET DEFAULT nextval('public.checked_logs_id_seq'::regclass);
+
+
 --
 -- Name: eth_nodes id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -614,6 +1033,20 @@ ALTER TABLE ONLY public.headers ALTER COLUMN id SET DEFAULT nextval('public.head
 ALTER TABLE ONLY public.receipts ALTER COLUMN id SET DEFAULT nextval('public.receipts_id_seq'::regclass);
 
 
+--
+-- Name: registered_addresses id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.registered_addresses ALTER COLUMN id SET DEFAULT nextval('public.registered_addresses_id_seq'::regclass);
+
+
+--
+-- Name: reorgs id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs ALTER COLUMN id SET DEFAULT nextval('public.reorgs_id_seq'::regclass);
+
+
 --
 -- Name: storage_diff id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -635,6 +1068,22 @@ ALTER TABLE ONLY public.transactions ALTER COLUMN id SET DEFAULT nextval('public
 ALTER TABLE ONLY public.watched_logs ALTER COLUMN id SET DEFAULT nextval('public.watched_logs_id_seq'::regclass);
 
 
+--
+-- Name: abis abis_address_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.abis
+    ADD CONSTRAINT abis_address_key UNIQUE (address);
+
+
+--
+-- Name: abis abis_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.abis
+    ADD CONSTRAINT abis_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: addresses addresses_address_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -667,6 +1116,22 @@ ALTER TABLE ONLY public.checked_headers
     ADD CONSTRAINT checked_headers_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: checked_logs checked_logs_header_id_watched_log_id_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.checked_logs
+    ADD CONSTRAINT checked_logs_header_id_watched_log_id_key UNIQUE (header_id, watched_log_id);
+
+
+--
+-- Name: checked_logs checked_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.checked_logs
+    ADD CONSTRAINT checked_logs_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: eth_nodes eth_nodes_genesis_block_network_id_eth_node_id_client_name_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -723,6 +1188,22 @@ ALTER TABLE ONLY public.headers
     ADD CONSTRAINT headers_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: legacy_seeded_transformers legacy_seeded_transformers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.legacy_seeded_transformers
+    ADD CONSTRAINT legacy_seeded_transformers_pkey PRIMARY KEY (transformer);
+
+
+--
+-- Name: legacy_transformed_logs legacy_transformed_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.legacy_transformed_logs
+    ADD CONSTRAINT legacy_transformed_logs_pkey PRIMARY KEY (log_id);
+
+
 --
 -- Name: receipts receipts_header_id_transaction_id_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -739,6 +1220,38 @@ ALTER TABLE ONLY public.receipts
     ADD CONSTRAINT receipts_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: registered_addresses registered_addresses_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.registered_addresses
+    ADD CONSTRAINT registered_addresses_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: registered_addresses registered_addresses_registry_address_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.registered_addresses
+    ADD CONSTRAINT registered_addresses_registry_address_key UNIQUE (registry, address);
+
+
+--
+-- Name: reorg_notifications reorg_notifications_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorg_notifications
+    ADD CONSTRAINT reorg_notifications_pkey PRIMARY KEY (transformer);
+
+
+--
+-- Name: reorgs reorgs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs
+    ADD CONSTRAINT reorgs_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: storage_diff storage_diff_block_height_block_hash_address_storage_key_st_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -771,6 +1284,30 @@ ALTER TABLE ONLY public.transactions
     ADD CONSTRAINT transactions_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: transform_errors transform_errors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.transform_errors
+    ADD CONSTRAINT transform_errors_pkey PRIMARY KEY (log_id, transformer);
+
+
+--
+-- Name: transformed_logs transformed_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.transformed_logs
+    ADD CONSTRAINT transformed_logs_pkey PRIMARY KEY (log_id, transformer);
+
+
+--
+-- Name: watched_logs watched_logs_contract_address_topics_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.watched_logs
+    ADD CONSTRAINT watched_logs_contract_address_topics_key UNIQUE (contract_address, topic_zero, topic_one, topic_two, topic_three);
+
+
 --
 -- Name: watched_logs watched_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -779,6 +1316,13 @@ ALTER TABLE ONLY public.watched_logs
     ADD CONSTRAINT watched_logs_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: checked_logs_watched_log_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX checked_logs_watched_log_id ON public.checked_logs USING btree (watched_log_id);
+
+
 --
 -- Name: event_logs_address; Type: INDEX; Schema: public; Owner: -
 --
@@ -786,6 +1330,13 @@ ALTER TABLE ONLY public.watched_logs
 CREATE INDEX event_logs_address ON public.event_logs USING btree (address);
 
 
+--
+-- Name: event_logs_topic0; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX event_logs_topic0 ON public.event_logs USING btree ((topics[1]), id);
+
+
 --
 -- Name: event_logs_transaction; Type: INDEX; Schema: public; Owner: -
 --
@@ -821,6 +1372,13 @@ CREATE INDEX headers_block_timestamp_index ON public.headers USING btree (block_
 CREATE INDEX headers_eth_node ON public.headers USING btree (eth_node_id);
 
 
+--
+-- Name: headers_transactions_not_synced; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX headers_transactions_not_synced ON public.headers USING btree (block_number) WHERE (transactions_synced = false);
+
+
 --
 -- Name: receipts_contract_address; Type: INDEX; Schema: public; Owner: -
 --
@@ -835,6 +1393,27 @@ CREATE INDEX receipts_contract_address ON public.receipts USING btree (contract_
 CREATE INDEX receipts_transaction ON public.receipts USING btree (transaction_id);
 
 
+--
+-- Name: registered_addresses_header_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX registered_addresses_header_id ON public.registered_addresses USING btree (header_id);
+
+
+--
+-- Name: registered_addresses_registry_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX registered_addresses_registry_id ON public.registered_addresses USING btree (registry, id);
+
+
+--
+-- Name: reorgs_block_number; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX reorgs_block_number ON public.reorgs USING btree (block_number);
+
+
 --
 -- Name: storage_diff_eth_node; Type: INDEX; Schema: public; Owner: -
 --
@@ -863,6 +1442,34 @@ CREATE INDEX storage_diff_unrecognized_status_index ON public.storage_diff USING
 CREATE INDEX transactions_header ON public.transactions USING btree (header_id);
 
 
+--
+-- Name: transform_errors_transformer; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX transform_errors_transformer ON public.transform_errors USING btree (transformer, log_id);
+
+
+--
+-- Name: transformed_logs_transformer; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX transformed_logs_transformer ON public.transformed_logs USING btree (transformer, log_id);
+
+
+--
+-- Name: watched_logs_address_registry_topics_key; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX watched_logs_address_registry_topics_key ON public.watched_logs USING btree (address_registry, topic_zero, topic_one, topic_two, topic_three) WHERE (address_registry IS NOT NULL);
+
+
+--
+-- Name: watched_logs_any_address_topics_key; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX watched_logs_any_address_topics_key ON public.watched_logs USING btree (topic_zero, topic_one, topic_two, topic_three) WHERE ((contract_address IS NULL) AND (address_registry IS NULL));
+
+
 --
 -- Name: event_logs event_log_updated; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -906,6 +1513,22 @@ ALTER TABLE ONLY public.checked_headers
     ADD CONSTRAINT checked_headers_header_id_fkey FOREIGN KEY (header_id) REFERENCES public.headers(id) ON DELETE CASCADE;
 
 
+--
+-- Name: checked_logs checked_logs_header_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.checked_logs
+    ADD CONSTRAINT checked_logs_header_id_fkey FOREIGN KEY (header_id) REFERENCES public.headers(id) ON DELETE CASCADE;
+
+
+--
+-- Name: checked_logs checked_logs_watched_log_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.checked_logs
+    ADD CONSTRAINT checked_logs_watched_log_id_fkey FOREIGN KEY (watched_log_id) REFERENCES public.watched_logs(id) ON DELETE CASCADE;
+
+
 --
 -- Name: event_logs event_logs_address_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -938,6 +1561,14 @@ ALTER TABLE ONLY public.headers
     ADD CONSTRAINT headers_eth_node_id_fkey FOREIGN KEY (eth_node_id) REFERENCES public.eth_nodes(id) ON DELETE CASCADE;
 
 
+--
+-- Name: legacy_transformed_logs legacy_transformed_logs_log_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.legacy_transformed_logs
+    ADD CONSTRAINT legacy_transformed_logs_log_id_fkey FOREIGN KEY (log_id) REFERENCES public.event_logs(id) ON DELETE CASCADE;
+
+
 --
 -- Name: receipts receipts_contract_address_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -962,6 +1593,22 @@ ALTER TABLE ONLY public.receipts
     ADD CONSTRAINT receipts_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public.transactions(id) ON DELETE CASCADE;
 
 
+--
+-- Name: registered_addresses registered_addresses_header_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.registered_addresses
+    ADD CONSTRAINT registered_addresses_header_id_fkey FOREIGN KEY (header_id) REFERENCES public.headers(id) ON DELETE CASCADE;
+
+
+--
+-- Name: reorgs reorgs_eth_node_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.reorgs
+    ADD CONSTRAINT reorgs_eth_node_id_fkey FOREIGN KEY (eth_node_id) REFERENCES public.eth_nodes(id) ON DELETE CASCADE;
+
+
 --
 -- Name: storage_diff storage_diff_eth_node_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -978,6 +1625,22 @@ ALTER TABLE ONLY public.transactions
     ADD CONSTRAINT transactions_header_id_fkey FOREIGN KEY (header_id) REFERENCES public.headers(id) ON DELETE CASCADE;
 
 
+--
+-- Name: transform_errors transform_errors_log_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.transform_errors
+    ADD CONSTRAINT transform_errors_log_id_fkey FOREIGN KEY (log_id) REFERENCES public.event_logs(id) ON DELETE CASCADE;
+
+
+--
+-- Name: transformed_logs transformed_logs_log_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.transformed_logs
+    ADD CONSTRAINT transformed_logs_log_id_fkey FOREIGN KEY (log_id) REFERENCES public.event_logs(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
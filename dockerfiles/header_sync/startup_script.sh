@@ -29,13 +29,13 @@ if test -z "$VDB_PG_CONNECT"; then
     exit 1
   fi
 
-  # Construct the connection string for postgres
-  VDB_PG_CONNECT=postgresql://$DATABASE_USER:$DATABASE_PASSWORD@$DATABASE_HOSTNAME:$DATABASE_PORT/$DATABASE_NAME?sslmode=disable
+  # Run the DB migrations embedded in the binary, which reads the DATABASE_* variables from the environment
+  ./vulcanizedb migrate up
+else
+  # Run the DB migrations embedded in the binary against the given connection string
+  ./vulcanizedb migrate up --database-url "$VDB_PG_CONNECT"
 fi
 
-# Run the DB migrations
-./goose -dir db/migrations postgres "$VDB_PG_CONNECT" up
-
 if [ $? -ne 0 ]; then
   echo "Could not run migrations. Are the database details correct?"
   exit 1
@@ -43,4 +43,4 @@ fi
 
 # Fire up headerSync
 echo "Starting headerSync..."
-./vulcanizedb headerSync -s $STARTING_BLOCK_NUMBER
+./vulcanizedb headerSync -s $STARTING_BLOCK_NUMBER --health-address :8080
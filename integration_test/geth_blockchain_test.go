@@ -17,6 +17,8 @@
 package integration_test
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	. "github.com/onsi/ginkgo"
@@ -45,7 +47,7 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 	It("retrieves transaction", func() {
 		// actual transaction: https://etherscan.io/tx/0x44d462f2a19ad267e276b234a62c542fc91c974d2e4754a325ca405f95440255
 		txHash := common.HexToHash("0x44d462f2a19ad267e276b234a62c542fc91c974d2e4754a325ca405f95440255")
-		transactions, err := blockChain.GetTransactions([]common.Hash{txHash})
+		transactions, err := blockChain.GetTransactions(context.Background(), []common.Hash{txHash})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(len(transactions)).To(Equal(1))
@@ -65,7 +67,7 @@ var _ = Describe("Reading from the Geth blockchain", func() {
 			Data:     expectedData,
 			From:     "0x3b08b99441086edd66f36f9f9aee733280698378",
 			GasLimit: 91741,
-			GasPrice: 1000000000,
+			GasPrice: "1000000000",
 			Hash:     "0x44d462f2a19ad267e276b234a62c542fc91c974d2e4754a325ca405f95440255",
 			Nonce:    9,
 			Raw:      expectedRaw,
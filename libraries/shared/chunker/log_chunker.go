@@ -26,44 +26,135 @@ import (
 
 type Chunker interface {
 	AddConfig(transformerConfig event.TransformerConfig)
+	AddRegisteredAddress(registry, address string)
+	RemoveRegisteredAddress(registry, address string)
 	ChunkLogs(logs []core.EventLog) map[string][]core.EventLog
 }
 
 type LogChunker struct {
-	AddressToNames map[string][]string
-	NameToTopic0   map[string]common.Hash
+	AddressToNames     map[string][]string
+	AnyAddressNames    []string // transformers watching logs emitted by any address
+	NameToTopic0       map[string]common.Hash
+	NameToTopicFilters map[string][][]common.Hash
+	RegistryToNames    map[string][]string // transformers watching addresses registered to each address registry
+	watchedAddresses   map[string]bool
 }
 
 // Returns a new log chunker with initialised maps.
 // Needs to have configs added with `AddConfigs` to consider logs for the respective transformer.
 func NewLogChunker() *LogChunker {
 	return &LogChunker{
-		AddressToNames: map[string][]string{},
-		NameToTopic0:   map[string]common.Hash{},
+		AddressToNames:     map[string][]string{},
+		NameToTopic0:       map[string]common.Hash{},
+		NameToTopicFilters: map[string][][]common.Hash{},
+		RegistryToNames:    map[string][]string{},
+		watchedAddresses:   map[string]bool{},
 	}
 }
 
 // Configures the chunker by adding one config with more addresses and topics to consider.
+// Configs without addresses are considered for logs emitted by any address, and configs with an address registry for
+// logs emitted by addresses added with `AddRegisteredAddress`.
 func (chunker *LogChunker) AddConfig(transformerConfig event.TransformerConfig) {
+	if transformerConfig.WatchesAnyAddress() {
+		chunker.AnyAddressNames = append(chunker.AnyAddressNames, transformerConfig.TransformerName)
+		chunker.NameToTopic0[transformerConfig.TransformerName] = common.HexToHash(transformerConfig.Topic)
+	}
+	if transformerConfig.AddressRegistry != "" {
+		chunker.RegistryToNames[transformerConfig.AddressRegistry] = append(chunker.RegistryToNames[transformerConfig.AddressRegistry], transformerConfig.TransformerName)
+		chunker.NameToTopic0[transformerConfig.TransformerName] = common.HexToHash(transformerConfig.Topic)
+	}
 	for _, address := range transformerConfig.ContractAddresses {
-		var lowerCaseAddress = strings.ToLower(address)
-		chunker.AddressToNames[lowerCaseAddress] = append(chunker.AddressToNames[lowerCaseAddress], transformerConfig.TransformerName)
+		chunker.addAddress(address, transformerConfig.TransformerName)
 		chunker.NameToTopic0[transformerConfig.TransformerName] = common.HexToHash(transformerConfig.Topic)
 	}
+	if topicFilters := transformerConfig.TopicFilters(); len(topicFilters) > 0 {
+		chunker.NameToTopicFilters[transformerConfig.TransformerName] = topicFilters
+	}
+}
+
+// Considers logs emitted by the address for the transformers watching the address registry
+func (chunker *LogChunker) AddRegisteredAddress(registry, address string) {
+	for _, name := range chunker.RegistryToNames[registry] {
+		chunker.addAddress(address, name)
+	}
+}
+
+// Stops considering logs emitted by the address for the transformers watching the address registry, e.g. once a reorg
+// removes its registration
+func (chunker *LogChunker) RemoveRegisteredAddress(registry, address string) {
+	lowerCaseAddress := strings.ToLower(address)
+	for _, name := range chunker.RegistryToNames[registry] {
+		key := name + lowerCaseAddress
+		if !chunker.watchedAddresses[key] {
+			continue
+		}
+		delete(chunker.watchedAddresses, key)
+		names := chunker.AddressToNames[lowerCaseAddress]
+		for i, n := range names {
+			if n == name {
+				names = append(names[:i:i], names[i+1:]...)
+				break
+			}
+		}
+		if len(names) == 0 {
+			delete(chunker.AddressToNames, lowerCaseAddress)
+		} else {
+			chunker.AddressToNames[lowerCaseAddress] = names
+		}
+	}
+}
+
+func (chunker *LogChunker) addAddress(address, name string) {
+	var lowerCaseAddress = strings.ToLower(address)
+	key := name + lowerCaseAddress
+	if chunker.watchedAddresses[key] {
+		return
+	}
+	chunker.watchedAddresses[key] = true
+	chunker.AddressToNames[lowerCaseAddress] = append(chunker.AddressToNames[lowerCaseAddress], name)
 }
 
-// Goes through a slice of logs, associating relevant logs (matching addresses and topic) with transformers
+// Goes through a slice of logs, associating relevant logs (matching addresses, topic0, and any topic filters) with transformers
 func (chunker *LogChunker) ChunkLogs(logs []core.EventLog) map[string][]core.EventLog {
 	chunks := map[string][]core.EventLog{}
 	for _, log := range logs {
+		if len(log.Log.Topics) == 0 {
+			continue
+		}
 		// Topic0 is not unique to each transformer, also need to consider the contract address
-		relevantTransformers := chunker.AddressToNames[strings.ToLower(log.Log.Address.Hex())]
+		addressTransformers := chunker.AddressToNames[strings.ToLower(log.Log.Address.Hex())]
 
-		for _, t := range relevantTransformers {
-			if chunker.NameToTopic0[t] == log.Log.Topics[0] {
-				chunks[t] = append(chunks[t], log)
+		for _, relevantTransformers := range [][]string{addressTransformers, chunker.AnyAddressNames} {
+			for _, t := range relevantTransformers {
+				if chunker.NameToTopic0[t] == log.Log.Topics[0] && matchesTopicFilters(chunker.NameToTopicFilters[t], log.Log.Topics) {
+					chunks[t] = append(chunks[t], log)
+				}
 			}
 		}
 	}
 	return chunks
 }
+
+// Reports whether each topic after topic0 is one of the values in the filter at its position; empty filters match anything
+func matchesTopicFilters(topicFilters [][]common.Hash, topics []common.Hash) bool {
+	for i, filter := range topicFilters {
+		if len(filter) == 0 {
+			continue
+		}
+		position := i + 1
+		if position >= len(topics) || !containsHash(filter, topics[position]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
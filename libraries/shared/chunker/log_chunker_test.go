@@ -106,6 +106,96 @@ var _ = Describe("Log chunker", func() {
 			Expect(chunks["TransformerB"]).To(BeEmpty())
 			Expect(chunks["TransformerC"]).To(ContainElement(log5))
 		})
+
+		It("associates logs from any address with transformers without addresses", func() {
+			anyAddressConfig := event.TransformerConfig{
+				TransformerName: "TransformerF",
+				Topic:           "0xF",
+			}
+			chunker.AddConfig(anyAddressConfig)
+			logFromAnAddress := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0xF1"),
+				Topics:  []common.Hash{common.HexToHash("0xF")},
+			}}
+			logFromAnotherAddress := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0xF2"),
+				Topics:  []common.Hash{common.HexToHash("0xF")},
+			}}
+
+			chunks := chunker.ChunkLogs([]core.EventLog{logFromAnAddress, logFromAnotherAddress, log1})
+
+			Expect(chunker.AnyAddressNames).To(Equal([]string{"TransformerF"}))
+			Expect(chunks["TransformerF"]).To(Equal([]core.EventLog{logFromAnAddress, logFromAnotherAddress}))
+			Expect(chunks["TransformerA"]).To(Equal([]core.EventLog{log1}))
+		})
+
+		It("associates logs from registered addresses with transformers watching the address registry", func() {
+			registryConfig := event.TransformerConfig{
+				TransformerName: "TransformerG",
+				Topic:           "0x7",
+				AddressRegistry: "pairs",
+			}
+			chunker.AddConfig(registryConfig)
+			registeredLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0x71"),
+				Topics:  []common.Hash{common.HexToHash("0x7")},
+			}}
+			unregisteredLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0x72"),
+				Topics:  []common.Hash{common.HexToHash("0x7")},
+			}}
+
+			chunker.AddRegisteredAddress("pairs", common.HexToAddress("0x71").Hex())
+			chunker.AddRegisteredAddress("pairs", common.HexToAddress("0x71").Hex())
+			chunker.AddRegisteredAddress("markets", common.HexToAddress("0x72").Hex())
+			chunks := chunker.ChunkLogs([]core.EventLog{registeredLog, unregisteredLog})
+
+			Expect(chunker.AnyAddressNames).To(BeEmpty())
+			Expect(chunks["TransformerG"]).To(Equal([]core.EventLog{registeredLog}))
+		})
+
+		It("stops associating logs from registered addresses once they're removed", func() {
+			chunker.AddConfig(event.TransformerConfig{TransformerName: "TransformerG", Topic: "0x7", AddressRegistry: "pairs"})
+			registeredLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0x71"),
+				Topics:  []common.Hash{common.HexToHash("0x7")},
+			}}
+			chunker.AddRegisteredAddress("pairs", common.HexToAddress("0x71").Hex())
+
+			chunker.RemoveRegisteredAddress("pairs", common.HexToAddress("0x71").Hex())
+
+			Expect(chunker.ChunkLogs([]core.EventLog{registeredLog})).To(BeEmpty())
+		})
+
+		It("only associates logs matching every topic filter to transformers with topic filters", func() {
+			configE := event.TransformerConfig{
+				TransformerName:   "TransformerE",
+				ContractAddresses: []string{"0x00000000000000000000000000000000000000E1"},
+				Topic:             "0xE",
+				Topic2:            []string{"0xE2", "0xE3"},
+			}
+			chunker.AddConfig(configE)
+			matchingLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0xE1"),
+				Topics:  []common.Hash{common.HexToHash("0xE"), common.HexToHash("0x1"), common.HexToHash("0xE3")},
+			}}
+			nonMatchingLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0xE1"),
+				Topics:  []common.Hash{common.HexToHash("0xE"), common.HexToHash("0x1"), common.HexToHash("0xE4")},
+			}}
+			missingTopicLog := core.EventLog{Log: types.Log{
+				Address: common.HexToAddress("0xE1"),
+				Topics:  []common.Hash{common.HexToHash("0xE"), common.HexToHash("0x1")},
+			}}
+
+			chunks := chunker.ChunkLogs([]core.EventLog{matchingLog, nonMatchingLog, missingTopicLog})
+
+			Expect(chunker.NameToTopicFilters["TransformerE"]).To(Equal([][]common.Hash{
+				nil,
+				{common.HexToHash("0xE2"), common.HexToHash("0xE3")},
+			}))
+			Expect(chunks["TransformerE"]).To(Equal([]core.EventLog{matchingLog}))
+		})
 	})
 })
 
@@ -22,4 +22,6 @@ const (
 	HeaderRecheck    TransformerExecution = true
 	HeaderUnchecked  TransformerExecution = false
 	RecheckHeaderCap                      = int64(5)
+	// Maximum number of headers to check for logs in a single batch
+	UncheckedHeadersLimit = 1000
 )
@@ -0,0 +1,438 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/libraries/shared/repository"
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/eth"
+)
+
+var ErrEventNotInAbi = errors.New("event not found in contract ABI")
+
+// AbiFetcher fetches the ABI of a contract, e.g. from Etherscan
+type AbiFetcher interface {
+	GetAbi(contractAddress, apiKey string) (string, error)
+}
+
+// SavedAbiFetcher fetches ABIs saved while extracting logs, falling back to the Fetcher for addresses without one, so
+// transformers can be rebuilt without fetching ABIs again
+type SavedAbiFetcher struct {
+	AbiRepository datastore.AbiRepository
+	Fetcher       AbiFetcher
+}
+
+// GetAbi returns the ABI saved for the contract, or fetches it if none is saved
+func (fetcher SavedAbiFetcher) GetAbi(contractAddress, apiKey string) (string, error) {
+	savedAbi, getErr := fetcher.AbiRepository.GetAbi(contractAddress)
+	if getErr != nil {
+		return "", getErr
+	}
+	if savedAbi != "" {
+		return savedAbi, nil
+	}
+	return fetcher.Fetcher.GetAbi(contractAddress, apiKey)
+}
+
+// AbiColumn is a table column holding one decoded event argument
+type AbiColumn struct {
+	Name     ColumnName
+	Type     string
+	Argument abi.Argument
+}
+
+// AbiTransformer decodes an event's arguments with the contract ABI and persists them to a table with a typed column
+// per argument, which Prepare creates. Indexed arguments of dynamic types (strings, bytes, arrays and tuples) are
+// stored as the keccak256 hash in their topic. The schema, table and column names are quoted identifiers.
+type AbiTransformer struct {
+	Config     TransformerConfig
+	Event      abi.Event
+	SchemaName SchemaName
+	TableName  TableName
+	Columns    []AbiColumn
+	DB         *postgres.DB
+}
+
+// NewAbiTransformerInitializers returns a transformer for each event watched by the contract config, with tables in
+// the given schema. ABIs missing from the config are fetched with the fetcher. Events sharing a name get tables with
+// a numbered suffix unless their columns match.
+func NewAbiTransformerInitializers(contractConfig config.ContractConfig, schema string, fetcher AbiFetcher) ([]TransformerInitializer, error) {
+	var addresses []string
+	for address := range contractConfig.Addresses {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	tableColumns := make(map[TableName][]AbiColumn)
+	var initializers []TransformerInitializer
+	for _, address := range addresses {
+		contractAbi := contractConfig.Abis[address]
+		if contractAbi == "" {
+			var fetchErr error
+			contractAbi, fetchErr = fetcher.GetAbi(address, contractConfig.ApiKey)
+			if fetchErr != nil {
+				return nil, fmt.Errorf("error fetching ABI for %s: %w", address, fetchErr)
+			}
+		}
+		parsedAbi, parseErr := eth.ParseAbi(contractAbi)
+		if parseErr != nil {
+			return nil, fmt.Errorf("error parsing ABI for %s: %w", address, parseErr)
+		}
+
+		events, eventsErr := watchedEvents(parsedAbi, contractConfig.Events[address], contractConfig.EventArgs[address])
+		if eventsErr != nil {
+			return nil, fmt.Errorf("error getting events to watch for %s: %w", address, eventsErr)
+		}
+		for _, abiEvent := range events {
+			columns := abiColumns(abiEvent)
+			tableName, unquotedTableName := abiTableName(abiEvent, columns, tableColumns)
+			tableColumns[tableName] = columns
+			t := AbiTransformer{
+				Config: TransformerConfig{
+					TransformerName:     fmt.Sprintf("%s_%s", unquotedTableName, address),
+					ContractAddresses:   []string{address},
+					ContractAbi:         contractAbi,
+					Topic:               abiEvent.ID.Hex(),
+					StartingBlockNumber: contractConfig.StartingBlocks[address],
+					EndingBlockNumber:   -1,
+				},
+				Event:      abiEvent,
+				SchemaName: SchemaName(pq.QuoteIdentifier(schema)),
+				TableName:  tableName,
+				Columns:    columns,
+			}
+			initializers = append(initializers, t.NewTransformer)
+		}
+	}
+	return initializers, nil
+}
+
+// NewTransformer instantiates a new ABI transformer with the DB connection
+func (at AbiTransformer) NewTransformer(db *postgres.DB) ITransformer {
+	at.DB = db
+	return &at
+}
+
+// Prepare creates the event's schema and table if they don't exist
+func (at *AbiTransformer) Prepare() error {
+	_, createErr := at.DB.Exec(at.CreateTableQuery())
+	if createErr != nil {
+		return fmt.Errorf("error creating table %s.%s for %s: %w", at.SchemaName, at.TableName, at.Config.TransformerName, createErr)
+	}
+	return nil
+}
+
+// Execute decodes the logs and persists them to the table created by Prepare
+func (at *AbiTransformer) Execute(logs []core.EventLog) error {
+	if len(logs) < 1 {
+		return nil
+	}
+
+	models, modelsErr := at.ToModels(logs)
+	if modelsErr != nil {
+		return fmt.Errorf("error converting logs to models in %s: %w", at.Config.TransformerName, modelsErr)
+	}
+
+	persistErr := PersistModels(models, at.DB)
+	if persistErr != nil {
+		return fmt.Errorf("error persisting %s record: %w", at.Config.TransformerName, persistErr)
+	}
+	return nil
+}
+
+// Cleanup deletes the models of the logs, so they can be transformed again
+func (at *AbiTransformer) Cleanup(logs []core.EventLog) error {
+	_, deleteErr := DeleteModelsForLogs(at.DB, at.SchemaName, at.TableName, logs)
+	if deleteErr != nil {
+		return fmt.Errorf("error cleaning up %s: %w", at.Config.TransformerName, deleteErr)
+	}
+	return nil
+}
+
+// GetConfig returns the config for the ABI transformer
+func (at *AbiTransformer) GetConfig() TransformerConfig {
+	return at.Config
+}
+
+// ToModels converts the logs to insertion models, creating an address record for the emitting contract if needed
+func (at *AbiTransformer) ToModels(logs []core.EventLog) ([]InsertionModel, error) {
+	orderedColumns := []ColumnName{HeaderFK, LogFK, AddressFK}
+	for _, column := range at.Columns {
+		orderedColumns = append(orderedColumns, column.Name)
+	}
+
+	var models []InsertionModel
+	for _, log := range logs {
+		columnValues, decodeErr := at.DecodeLog(log.Log)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("error decoding log %d: %w", log.ID, decodeErr)
+		}
+		addressID, addressErr := repository.GetOrCreateAddress(at.DB, log.Log.Address.Hex())
+		if addressErr != nil {
+			return nil, fmt.Errorf("error getting address id for %s: %w", log.Log.Address.Hex(), addressErr)
+		}
+		columnValues[HeaderFK] = log.HeaderID
+		columnValues[LogFK] = log.ID
+		columnValues[AddressFK] = addressID
+		models = append(models, InsertionModel{
+			SchemaName:     at.SchemaName,
+			TableName:      at.TableName,
+			OrderedColumns: orderedColumns,
+			ColumnValues:   columnValues,
+		})
+	}
+	return models, nil
+}
+
+// DecodeLog decodes the event arguments in the log's topics and data into values for their columns
+func (at *AbiTransformer) DecodeLog(log types.Log) (ColumnValues, error) {
+	var indexed abi.Arguments
+	for _, arg := range at.Event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(log.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf("%w: got %d topics for %d indexed arguments", eth.ErrTopicCountMismatch, len(log.Topics), len(indexed))
+	}
+
+	nonIndexed := at.Event.Inputs.NonIndexed()
+	var dataValues []interface{}
+	if len(nonIndexed) > 0 {
+		var unpackErr error
+		dataValues, unpackErr = nonIndexed.UnpackValues(log.Data)
+		if unpackErr != nil {
+			return nil, fmt.Errorf("error unpacking log data: %w", unpackErr)
+		}
+	}
+
+	columnValues := make(ColumnValues)
+	topicIndex, dataIndex := 1, 0
+	for _, column := range at.Columns {
+		var value interface{}
+		if column.Argument.Indexed {
+			topicValue, topicErr := decodeTopic(column.Argument, log.Topics[topicIndex])
+			if topicErr != nil {
+				return nil, fmt.Errorf("error decoding topic %d: %w", topicIndex, topicErr)
+			}
+			value = topicValue
+			topicIndex++
+		} else {
+			columnValue, valueErr := toColumnValue(column.Argument.Type, dataValues[dataIndex])
+			if valueErr != nil {
+				return nil, fmt.Errorf("error converting %s: %w", column.Name, valueErr)
+			}
+			value = columnValue
+			dataIndex++
+		}
+		columnValues[column.Name] = value
+	}
+	return columnValues, nil
+}
+
+// CreateTableQuery returns the query creating the schema and table for the event, if they don't exist
+func (at *AbiTransformer) CreateTableQuery() string {
+	table := fmt.Sprintf("%s.%s", at.SchemaName, at.TableName)
+	columnDefinitions := []string{
+		"id         SERIAL PRIMARY KEY",
+		"header_id  INTEGER NOT NULL REFERENCES public.headers (id) ON DELETE CASCADE",
+		"log_id     BIGINT NOT NULL REFERENCES public.event_logs (id) ON DELETE CASCADE",
+		"address_id BIGINT NOT NULL REFERENCES public.addresses (id) ON DELETE CASCADE",
+	}
+	for _, column := range at.Columns {
+		columnDefinitions = append(columnDefinitions, fmt.Sprintf("%s %s", column.Name, column.Type))
+	}
+	columnDefinitions = append(columnDefinitions, "UNIQUE (header_id, log_id)")
+
+	return fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %s;
+CREATE TABLE IF NOT EXISTS %s (
+    %s
+);
+CREATE INDEX IF NOT EXISTS %s ON %s (header_id);`,
+		at.SchemaName, table, strings.Join(columnDefinitions, ",\n    "),
+		pq.QuoteIdentifier(unquoteIdentifier(string(at.TableName))+"_header_index"), table)
+}
+
+// Reverses pq.QuoteIdentifier, leaving identifiers that aren't quoted as they are
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) < 2 || !strings.HasPrefix(identifier, `"`) || !strings.HasSuffix(identifier, `"`) {
+		return identifier
+	}
+	return strings.ReplaceAll(identifier[1:len(identifier)-1], `""`, `"`)
+}
+
+func watchedEvents(parsedAbi abi.ABI, eventNames, eventArgs []string) ([]abi.Event, error) {
+	if len(eventNames) == 0 {
+		for name := range parsedAbi.Events {
+			eventNames = append(eventNames, name)
+		}
+		sort.Strings(eventNames)
+	}
+
+	var events []abi.Event
+	for _, name := range eventNames {
+		abiEvent, ok := parsedAbi.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEventNotInAbi, name)
+		}
+		if abiEvent.Anonymous {
+			continue
+		}
+		if len(eventArgs) > 0 && !hasArgument(abiEvent, eventArgs) {
+			continue
+		}
+		events = append(events, abiEvent)
+	}
+	return events, nil
+}
+
+func hasArgument(abiEvent abi.Event, argNames []string) bool {
+	for _, arg := range abiEvent.Inputs {
+		for _, name := range argNames {
+			if arg.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func abiColumns(abiEvent abi.Event) []AbiColumn {
+	taken := map[string]bool{"id": true, string(HeaderFK): true, string(LogFK): true, string(AddressFK): true}
+	var columns []AbiColumn
+	for i, arg := range abiEvent.Inputs {
+		name := toSnakeCase(arg.Name)
+		if name == "" || taken[name] {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		taken[name] = true
+		columns = append(columns, AbiColumn{
+			Name:     ColumnName(pq.QuoteIdentifier(name)),
+			Type:     columnType(arg),
+			Argument: arg,
+		})
+	}
+	return columns
+}
+
+// Returns the quoted table name for the event, along with the unquoted snake_case name it was quoted from
+func abiTableName(abiEvent abi.Event, columns []AbiColumn, tableColumns map[TableName][]AbiColumn) (TableName, string) {
+	baseName := toSnakeCase(abiEvent.RawName)
+	name := baseName
+	for i := 2; ; i++ {
+		tableName := TableName(pq.QuoteIdentifier(name))
+		existingColumns, taken := tableColumns[tableName]
+		if !taken || sameColumns(existingColumns, columns) {
+			return tableName, name
+		}
+		name = fmt.Sprintf("%s_%d", baseName, i)
+	}
+}
+
+func sameColumns(a, b []AbiColumn) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Type != b[i].Type || a[i].Argument.Indexed != b[i].Argument.Indexed {
+			return false
+		}
+	}
+	return true
+}
+
+func columnType(arg abi.Argument) string {
+	if arg.Indexed && eth.IsHashedInTopic(arg.Type) {
+		return "BYTEA"
+	}
+	switch arg.Type.T {
+	case abi.IntTy, abi.UintTy:
+		return "NUMERIC"
+	case abi.BoolTy:
+		return "BOOLEAN"
+	case abi.AddressTy:
+		return "VARCHAR(42)"
+	case abi.FixedBytesTy, abi.BytesTy, abi.HashTy, abi.FunctionTy:
+		return "BYTEA"
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return "JSONB"
+	default:
+		return "TEXT"
+	}
+}
+
+func decodeTopic(arg abi.Argument, topic common.Hash) (interface{}, error) {
+	value, unpackErr := eth.UnpackTopic(arg, topic)
+	if unpackErr != nil {
+		return nil, unpackErr
+	}
+	if eth.IsHashedInTopic(arg.Type) {
+		return value.(common.Hash).Bytes(), nil
+	}
+	return toColumnValue(arg.Type, value)
+}
+
+func toColumnValue(typ abi.Type, value interface{}) (interface{}, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprint(value), nil
+	case abi.AddressTy:
+		return value.(common.Address).Hex(), nil
+	case abi.FixedBytesTy, abi.BytesTy, abi.HashTy, abi.FunctionTy:
+		return eth.AbiBytes(value), nil
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		encoded, marshalErr := json.Marshal(eth.AbiValueToJSON(typ, value))
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		return string(encoded), nil
+	case abi.BoolTy, abi.StringTy:
+		return value, nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+func toSnakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				builder.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,313 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package event_test
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/eth"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const testAbi = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"tokenName","type":"string"},{"indexed":false,"name":"","type":"bytes32"},{"indexed":false,"name":"active","type":"bool"},{"indexed":false,"name":"amounts","type":"uint8[]"}],"name":"NameSet","type":"event"},
+	{"anonymous":true,"inputs":[{"indexed":false,"name":"value","type":"uint256"}],"name":"Anonymous","type":"event"}
+]`
+
+const otherTransferAbi = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"src","type":"address"},{"indexed":true,"name":"dst","type":"address"},{"indexed":false,"name":"wad","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+type mockAbiFetcher struct {
+	abi       string
+	err       error
+	addresses []string
+	apiKeys   []string
+}
+
+func (fetcher *mockAbiFetcher) GetAbi(contractAddress, apiKey string) (string, error) {
+	fetcher.addresses = append(fetcher.addresses, contractAddress)
+	fetcher.apiKeys = append(fetcher.apiKeys, apiKey)
+	return fetcher.abi, fetcher.err
+}
+
+var _ = Describe("ABI transformer", func() {
+	var (
+		addressOne     = "0x0000000000000000000000000000000000000001"
+		addressTwo     = "0x0000000000000000000000000000000000000002"
+		contractConfig config.ContractConfig
+		fetcher        *mockAbiFetcher
+	)
+
+	BeforeEach(func() {
+		contractConfig = config.ContractConfig{
+			ApiKey:         "apiKey",
+			Addresses:      map[string]bool{addressOne: true},
+			Abis:           map[string]string{addressOne: testAbi},
+			Events:         map[string][]string{},
+			EventArgs:      map[string][]string{},
+			StartingBlocks: map[string]int64{addressOne: 100},
+		}
+		fetcher = &mockAbiFetcher{}
+	})
+
+	transformers := func(initializers []event.TransformerInitializer) []*event.AbiTransformer {
+		var result []*event.AbiTransformer
+		for _, initializer := range initializers {
+			result = append(result, initializer(nil).(*event.AbiTransformer))
+		}
+		return result
+	}
+
+	Describe("NewAbiTransformerInitializers", func() {
+		It("returns a transformer for each non-anonymous event in the ABI", func() {
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			result := transformers(initializers)
+			Expect(len(result)).To(Equal(2))
+			Expect(result[0].GetConfig()).To(Equal(event.TransformerConfig{
+				TransformerName:     "name_set_" + addressOne,
+				ContractAddresses:   []string{addressOne},
+				ContractAbi:         testAbi,
+				Topic:               crypto.Keccak256Hash([]byte("NameSet(string,bytes32,bool,uint8[])")).Hex(),
+				StartingBlockNumber: 100,
+				EndingBlockNumber:   -1,
+			}))
+			Expect(result[0].SchemaName).To(Equal(event.SchemaName(`"example_schema"`)))
+			Expect(result[0].TableName).To(Equal(event.TableName(`"name_set"`)))
+			Expect(result[1].TableName).To(Equal(event.TableName(`"transfer"`)))
+			Expect(result[1].GetConfig().Topic).To(Equal(crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")).Hex()))
+		})
+
+		It("names and types a column for each argument", func() {
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			var names []event.ColumnName
+			var types []string
+			for _, column := range transformers(initializers)[0].Columns {
+				names = append(names, column.Name)
+				types = append(types, column.Type)
+			}
+			Expect(names).To(Equal([]event.ColumnName{`"token_name"`, `"arg1"`, `"active"`, `"amounts"`}))
+			Expect(types).To(Equal([]string{"BYTEA", "BYTEA", "BOOLEAN", "JSONB"}))
+		})
+
+		It("only watches configured events", func() {
+			contractConfig.Events[addressOne] = []string{"Transfer"}
+
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(initializers)).To(Equal(1))
+			Expect(transformers(initializers)[0].TableName).To(Equal(event.TableName(`"transfer"`)))
+		})
+
+		It("only watches events with a configured argument", func() {
+			contractConfig.EventArgs[addressOne] = []string{"active"}
+
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(initializers)).To(Equal(1))
+			Expect(transformers(initializers)[0].TableName).To(Equal(event.TableName(`"name_set"`)))
+		})
+
+		It("returns an error if a configured event isn't in the ABI", func() {
+			contractConfig.Events[addressOne] = []string{"Missing"}
+
+			_, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, event.ErrEventNotInAbi)).To(BeTrue())
+		})
+
+		It("fetches missing ABIs", func() {
+			contractConfig.Abis[addressOne] = ""
+			fetcher.abi = otherTransferAbi
+
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetcher.addresses).To(Equal([]string{addressOne}))
+			Expect(fetcher.apiKeys).To(Equal([]string{"apiKey"}))
+			Expect(transformers(initializers)[0].GetConfig().ContractAbi).To(Equal(otherTransferAbi))
+		})
+
+		It("returns an error if fetching an ABI fails", func() {
+			contractConfig.Abis[addressOne] = ""
+			fetcher.err = errors.New("fetch failed")
+
+			_, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, fetcher.err)).To(BeTrue())
+		})
+
+		It("shares tables between events with the same columns", func() {
+			contractConfig.Addresses[addressTwo] = true
+			contractConfig.Abis[addressTwo] = testAbi
+			contractConfig.Events[addressOne] = []string{"Transfer"}
+			contractConfig.Events[addressTwo] = []string{"Transfer"}
+
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			result := transformers(initializers)
+			Expect(result[0].TableName).To(Equal(event.TableName(`"transfer"`)))
+			Expect(result[1].TableName).To(Equal(event.TableName(`"transfer"`)))
+			Expect(result[1].GetConfig().ContractAddresses).To(Equal([]string{addressTwo}))
+		})
+
+		It("suffixes tables for events sharing a name but not columns", func() {
+			contractConfig.Addresses[addressTwo] = true
+			contractConfig.Abis[addressTwo] = otherTransferAbi
+			contractConfig.Events[addressOne] = []string{"Transfer"}
+
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+
+			Expect(err).NotTo(HaveOccurred())
+			result := transformers(initializers)
+			Expect(result[0].TableName).To(Equal(event.TableName(`"transfer"`)))
+			Expect(result[1].TableName).To(Equal(event.TableName(`"transfer_2"`)))
+		})
+	})
+
+	Describe("DecodeLog", func() {
+		var (
+			nameSet  *event.AbiTransformer
+			transfer *event.AbiTransformer
+		)
+
+		BeforeEach(func() {
+			initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+			Expect(err).NotTo(HaveOccurred())
+			result := transformers(initializers)
+			nameSet, transfer = result[0], result[1]
+		})
+
+		It("decodes indexed and non-indexed arguments", func() {
+			value := common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+			log := types.Log{
+				Topics: []common.Hash{
+					common.HexToHash(transfer.GetConfig().Topic),
+					common.HexToHash(addressOne),
+					common.HexToHash(addressTwo),
+				},
+				Data: value,
+			}
+
+			columnValues, err := transfer.DecodeLog(log)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(columnValues).To(Equal(event.ColumnValues{
+				`"from"`:  common.HexToAddress(addressOne).Hex(),
+				`"to"`:    common.HexToAddress(addressTwo).Hex(),
+				`"value"`: "1000",
+			}))
+		})
+
+		It("stores hashes of indexed dynamic arguments and encodes arrays as JSON", func() {
+			tokenNameHash := crypto.Keccak256Hash([]byte("token"))
+			bytes32 := common.HexToHash("0xabc")
+			data, packErr := nameSet.Event.Inputs.NonIndexed().Pack(bytes32, true, []uint8{1, 2})
+			Expect(packErr).NotTo(HaveOccurred())
+			log := types.Log{
+				Topics: []common.Hash{common.HexToHash(nameSet.GetConfig().Topic), tokenNameHash},
+				Data:   data,
+			}
+
+			columnValues, err := nameSet.DecodeLog(log)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(columnValues).To(Equal(event.ColumnValues{
+				`"token_name"`: tokenNameHash.Bytes(),
+				`"arg1"`:       bytes32.Bytes(),
+				`"active"`:     true,
+				`"amounts"`:    "[1,2]",
+			}))
+		})
+
+		It("returns an error if the topics don't match the indexed arguments", func() {
+			log := types.Log{Topics: []common.Hash{common.HexToHash(transfer.GetConfig().Topic)}}
+
+			_, err := transfer.DecodeLog(log)
+
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, eth.ErrTopicCountMismatch)).To(BeTrue())
+		})
+	})
+
+	Describe("SavedAbiFetcher", func() {
+		It("returns the saved ABI without fetching it", func() {
+			abiRepository := &fakes.MockAbiRepository{SavedAbis: map[string]string{addressOne: testAbi}}
+			savedFetcher := event.SavedAbiFetcher{AbiRepository: abiRepository, Fetcher: fetcher}
+
+			contractAbi, err := savedFetcher.GetAbi(addressOne, "apiKey")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contractAbi).To(Equal(testAbi))
+			Expect(fetcher.addresses).To(BeEmpty())
+		})
+
+		It("fetches the ABI if none is saved", func() {
+			fetcher.abi = otherTransferAbi
+			savedFetcher := event.SavedAbiFetcher{AbiRepository: &fakes.MockAbiRepository{}, Fetcher: fetcher}
+
+			contractAbi, err := savedFetcher.GetAbi(addressOne, "apiKey")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contractAbi).To(Equal(otherTransferAbi))
+			Expect(fetcher.addresses).To(Equal([]string{addressOne}))
+		})
+
+		It("returns an error if getting the saved ABI fails", func() {
+			abiRepository := &fakes.MockAbiRepository{GetAbiError: fakes.FakeError}
+			savedFetcher := event.SavedAbiFetcher{AbiRepository: abiRepository, Fetcher: fetcher}
+
+			_, err := savedFetcher.GetAbi(addressOne, "apiKey")
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fetcher.addresses).To(BeEmpty())
+		})
+	})
+
+	It("creates the schema and a table with a column for each argument", func() {
+		contractConfig.Events[addressOne] = []string{"Transfer"}
+		initializers, err := event.NewAbiTransformerInitializers(contractConfig, "example_schema", fetcher)
+		Expect(err).NotTo(HaveOccurred())
+
+		query := transformers(initializers)[0].CreateTableQuery()
+
+		Expect(query).To(ContainSubstring(`CREATE SCHEMA IF NOT EXISTS "example_schema";`))
+		Expect(query).To(ContainSubstring(`CREATE TABLE IF NOT EXISTS "example_schema"."transfer" (`))
+		Expect(query).To(ContainSubstring(`"from" VARCHAR(42),`))
+		Expect(query).To(ContainSubstring(`"value" NUMERIC,`))
+		Expect(query).To(ContainSubstring("UNIQUE (header_id, log_id)"))
+		Expect(query).To(ContainSubstring(`CREATE INDEX IF NOT EXISTS "transfer_header_index" ON "example_schema"."transfer" (header_id);`))
+	})
+})
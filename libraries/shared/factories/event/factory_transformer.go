@@ -0,0 +1,97 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package event
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+)
+
+var ErrMissingChildAddress = errors.New("factory event log doesn't contain the child address")
+
+// ChildAddressGetter returns the address of the child contract created by a factory event log
+type ChildAddressGetter func(log types.Log) (common.Address, error)
+
+// FactoryTransformer registers the child contracts created by a factory contract's events to an address registry, so
+// that transformers configured with that AddressRegistry extract the children's logs from the block they were created
+// at, without recompiling plugins as children are deployed
+type FactoryTransformer struct {
+	Config       TransformerConfig
+	Registry     string
+	ChildAddress ChildAddressGetter
+	Repository   datastore.AddressRegistryRepository
+}
+
+// NewTransformer instantiates a new factory transformer registering addresses with the DB connection
+func (ft FactoryTransformer) NewTransformer(db *postgres.DB) ITransformer {
+	ft.Repository = repositories.NewAddressRegistryRepository(db)
+	return ft
+}
+
+// Execute registers the child address of each factory event log
+func (ft FactoryTransformer) Execute(logs []core.EventLog) error {
+	for _, log := range logs {
+		childAddress, childAddressErr := ft.ChildAddress(log.Log)
+		if childAddressErr != nil {
+			return fmt.Errorf("error getting child address from log %d in %s: %w", log.ID, ft.Config.TransformerName, childAddressErr)
+		}
+		registerErr := ft.Repository.RegisterAddress(core.RegisteredAddress{
+			Registry:    ft.Registry,
+			Address:     childAddress.Hex(),
+			HeaderID:    log.HeaderID,
+			BlockNumber: int64(log.Log.BlockNumber),
+		})
+		if registerErr != nil {
+			return fmt.Errorf("error registering child address in %s: %w", ft.Config.TransformerName, registerErr)
+		}
+	}
+	return nil
+}
+
+// GetConfig returns the config for the factory transformer
+func (ft FactoryTransformer) GetConfig() TransformerConfig {
+	return ft.Config
+}
+
+// ChildAddressFromTopic gets the child address from an indexed event argument, at topic position 1-3
+func ChildAddressFromTopic(position int) ChildAddressGetter {
+	return func(log types.Log) (common.Address, error) {
+		if position >= len(log.Topics) {
+			return common.Address{}, fmt.Errorf("%w: no topic at position %d", ErrMissingChildAddress, position)
+		}
+		return common.BytesToAddress(log.Topics[position].Bytes()), nil
+	}
+}
+
+// ChildAddressFromData gets the child address from a non-indexed event argument, as the 32 byte word at wordIndex of
+// the log data
+func ChildAddressFromData(wordIndex int) ChildAddressGetter {
+	return func(log types.Log) (common.Address, error) {
+		end := (wordIndex + 1) * common.HashLength
+		if len(log.Data) < end {
+			return common.Address{}, fmt.Errorf("%w: no data word at index %d", ErrMissingChildAddress, wordIndex)
+		}
+		return common.BytesToAddress(log.Data[end-common.HashLength : end]), nil
+	}
+}
@@ -0,0 +1,99 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package event_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Factory transformer", func() {
+	var (
+		repository *fakes.MockAddressRegistryRepository
+		t          event.FactoryTransformer
+		childLog   core.EventLog
+	)
+
+	BeforeEach(func() {
+		repository = &fakes.MockAddressRegistryRepository{}
+		t = event.FactoryTransformer{
+			Config:       event.TransformerConfig{TransformerName: "PairCreated"},
+			Registry:     "pairs",
+			ChildAddress: event.ChildAddressFromTopic(1),
+			Repository:   repository,
+		}
+		childLog = core.EventLog{
+			HeaderID: 123,
+			Log: types.Log{
+				BlockNumber: 456,
+				Topics:      []common.Hash{common.HexToHash("0xabc"), common.HexToHash(fakes.FakeAddress.Hex())},
+			},
+		}
+	})
+
+	It("registers the child address of each log", func() {
+		err := t.Execute([]core.EventLog{childLog})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repository.RegisteredAddresses).To(Equal([]core.RegisteredAddress{{
+			ID:          1,
+			Registry:    "pairs",
+			Address:     fakes.FakeAddress.Hex(),
+			HeaderID:    123,
+			BlockNumber: 456,
+		}}))
+	})
+
+	It("returns an error if the log doesn't contain the child address", func() {
+		childLog.Log.Topics = childLog.Log.Topics[:1]
+
+		err := t.Execute([]core.EventLog{childLog})
+
+		Expect(err).To(MatchError(event.ErrMissingChildAddress))
+		Expect(repository.RegisteredAddresses).To(BeEmpty())
+	})
+
+	It("returns an error if registering the child address fails", func() {
+		repository.RegisterAddressError = fakes.FakeError
+
+		err := t.Execute([]core.EventLog{childLog})
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	Describe("ChildAddressFromData", func() {
+		It("gets the address from the data word at the index", func() {
+			data := append(common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes(fakes.FakeAddress.Bytes(), 32)...)
+
+			address, err := event.ChildAddressFromData(1)(types.Log{Data: data})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(fakes.FakeAddress))
+		})
+
+		It("returns an error if the data is too short", func() {
+			_, err := event.ChildAddressFromData(1)(types.Log{Data: common.LeftPadBytes([]byte{1}, 32)})
+
+			Expect(err).To(MatchError(event.ErrMissingChildAddress))
+		})
+	})
+})
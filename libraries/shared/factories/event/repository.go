@@ -22,12 +22,15 @@ import (
 	"strings"
 
 	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
-	"github.com/makerdao/vulcanizedb/utils"
 	"github.com/sirupsen/logrus"
 )
 
 // SetLogTransformedQuery marks the log as transformed in the database
+//
+// Deprecated: the log delegator records the logs each transformer has transformed, so transformers don't need to mark
+// them. The query still sets event_logs.transformed, which is kept for existing consumers of the column.
 const SetLogTransformedQuery = `UPDATE public.event_logs SET transformed = true WHERE id = $1`
 
 // ErrEmptyModelSlice is returned when PersistModel gets 0 InsertionModels
@@ -156,18 +159,26 @@ func PersistModels(models []InsertionModel, db *postgres.DB) error {
 			}
 			return execErr
 		}
-
-		_, logErr := tx.Exec(SetLogTransformedQuery, model.ColumnValues[LogFK])
-
-		if logErr != nil {
-			utils.RollbackAndLogFailure(tx, logErr, "event_logs.transformed")
-			return logErr
-		}
 	}
 
 	return tx.Commit()
 }
 
+// DeleteModelsForLogs deletes the models in a table for the given logs, e.g. to implement Cleaner. Returns the number
+// of models deleted.
+func DeleteModelsForLogs(db *postgres.DB, schema SchemaName, table TableName, logs []core.EventLog) (int64, error) {
+	var logIDs []int64
+	for _, log := range logs {
+		logIDs = append(logIDs, log.ID)
+	}
+	query := fmt.Sprintf(`DELETE FROM %v.%v WHERE %v = ANY($1::BIGINT[])`, schema, table, LogFK)
+	result, err := db.Exec(query, pq.Array(logIDs))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting models from %v.%v: %w", schema, table, err)
+	}
+	return result.RowsAffected()
+}
+
 func isValidValue(value interface{}) bool {
 	switch value.(type) {
 	case *pq.StringArray:
@@ -22,6 +22,7 @@ import (
 
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
@@ -186,14 +187,31 @@ var _ = Describe("Repository", func() {
 			Expect(actualQuery).To(Equal(expectedQuery))
 		})
 
-		It("marks log transformed", func() {
-			createErr := event.PersistModels([]event.InsertionModel{testModel}, db)
-			Expect(createErr).NotTo(HaveOccurred())
+		Describe("DeleteModelsForLogs", func() {
+			BeforeEach(func() {
+				createErr := event.PersistModels([]event.InsertionModel{testModel}, db)
+				Expect(createErr).NotTo(HaveOccurred())
+			})
+
+			It("deletes models for the given logs", func() {
+				deleted, err := event.DeleteModelsForLogs(db, "public", "testEvent", []core.EventLog{{ID: logID}})
 
-			var logTransformed bool
-			getErr := db.Get(&logTransformed, `SELECT transformed FROM public.event_logs WHERE id = $1`, logID)
-			Expect(getErr).NotTo(HaveOccurred())
-			Expect(logTransformed).To(BeTrue())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(Equal(int64(1)))
+				var count int
+				Expect(db.Get(&count, `SELECT count(*) FROM public.testEvent`)).To(Succeed())
+				Expect(count).To(BeZero())
+			})
+
+			It("keeps models for other logs", func() {
+				deleted, err := event.DeleteModelsForLogs(db, "public", "testEvent", []core.EventLog{{ID: logID + 1}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deleted).To(BeZero())
+				var count int
+				Expect(db.Get(&count, `SELECT count(*) FROM public.testEvent`)).To(Succeed())
+				Expect(count).To(Equal(1))
+			})
 		})
 	})
 })
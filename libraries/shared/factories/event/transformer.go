@@ -28,15 +28,53 @@ type ITransformer interface {
 	GetConfig() TransformerConfig
 }
 
+// Cleaner is optionally implemented by an ITransformer to delete the models it persisted for the given logs, before
+// they're retransformed
+type Cleaner interface {
+	Cleanup(logs []core.EventLog) error
+}
+
+// Preparer is optionally implemented by an ITransformer that needs to prepare the database before it's executed, e.g.
+// to create its tables. Prepare is called once when the transformer is added to a watcher.
+type Preparer interface {
+	Prepare() error
+}
+
 type TransformerInitializer func(db *postgres.DB) ITransformer
 
 type TransformerConfig struct {
 	TransformerName     string
-	ContractAddresses   []string
+	ContractAddresses   []string // Leave empty, without an AddressRegistry, to watch logs emitted by any address
 	ContractAbi         string
 	Topic               string
 	StartingBlockNumber int64
 	EndingBlockNumber   int64 // Set -1 for indefinite transformer
+	// Optional sets of values that a log's topic1-topic3 must be one of, e.g. Topic2 set to the
+	// recipients of an ERC-20 Transfer. Addresses are left-padded to 32 bytes. Empty sets match any value.
+	Topic1 []string
+	Topic2 []string
+	Topic3 []string
+	// Optional name of an address registry; addresses registered to it by a FactoryTransformer are watched in addition
+	// to ContractAddresses, from the block they were registered at
+	AddressRegistry string
+}
+
+// WatchesAnyAddress reports whether the transformer watches logs emitted by any address
+func (config TransformerConfig) WatchesAnyAddress() bool {
+	return len(config.ContractAddresses) == 0 && config.AddressRegistry == ""
+}
+
+// TopicFilters returns the topic1-topic3 filter sets as hashes, omitting trailing empty sets
+func (config TransformerConfig) TopicFilters() [][]common.Hash {
+	filters := [][]common.Hash{
+		HexStringsToHashes(config.Topic1),
+		HexStringsToHashes(config.Topic2),
+		HexStringsToHashes(config.Topic3),
+	}
+	for len(filters) > 0 && len(filters[len(filters)-1]) == 0 {
+		filters = filters[:len(filters)-1]
+	}
+	return filters
 }
 
 func HexStringsToAddresses(strings []string) (addresses []common.Address) {
@@ -46,6 +84,13 @@ func HexStringsToAddresses(strings []string) (addresses []common.Address) {
 	return
 }
 
+func HexStringsToHashes(strings []string) (hashes []common.Hash) {
+	for _, hexString := range strings {
+		hashes = append(hashes, common.HexToHash(hexString))
+	}
+	return
+}
+
 // ConfiguredTransformer implements the EventTransformer interface, to be run by the Watcher
 type ConfiguredTransformer struct {
 	Config      TransformerConfig
@@ -19,6 +19,7 @@ package event_test
 import (
 	"math/rand"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
 	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
@@ -48,10 +49,9 @@ var _ = Describe("Transformer", func() {
 		headerOne = core.Header{Id: rand.Int63(), BlockNumber: rand.Int63()}
 
 		logs = []core.EventLog{{
-			ID:          0,
-			HeaderID:    headerOne.Id,
-			Log:         test_data.GenericTestLog(),
-			Transformed: false,
+			ID:       0,
+			HeaderID: headerOne.Id,
+			Log:      test_data.GenericTestLog(),
 		}}
 	})
 
@@ -79,4 +79,19 @@ var _ = Describe("Transformer", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
 	})
+
+	Describe("TopicFilters", func() {
+		It("returns the topic1-topic3 filters as hashes, omitting trailing empty filters", func() {
+			filteredConfig := event.TransformerConfig{Topic2: []string{"0x1", "0x2"}}
+
+			Expect(filteredConfig.TopicFilters()).To(Equal([][]common.Hash{
+				nil,
+				{common.HexToHash("0x1"), common.HexToHash("0x2")},
+			}))
+		})
+
+		It("returns no filters if none are configured", func() {
+			Expect(event.TransformerConfig{}.TopicFilters()).To(BeEmpty())
+		})
+	})
 })
@@ -17,6 +17,12 @@
 package fetcher
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -24,9 +30,19 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
+var (
+	ErrTooManyResults = errors.New("log query returned too many results")
+	// Substrings of the errors returned by common node providers when a log query exceeds their result limits
+	tooManyResultsMessages = []string{
+		"query returned more than",
+		"log response size exceeded",
+		"too many results",
+	}
+)
+
 type ILogFetcher interface {
-	FetchLogs(contractAddresses []common.Address, topics []common.Hash, missingHeader core.Header) ([]types.Log, error)
-	// TODO Extend FetchLogs for doing several blocks at a time
+	FetchLogs(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, missingHeader core.Header) ([]types.Log, error)
+	FetchLogsInRange(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, startingBlock, endingBlock int64) ([]types.Log, error)
 }
 
 type LogFetcher struct {
@@ -39,17 +55,17 @@ func NewLogFetcher(blockchain core.BlockChain) *LogFetcher {
 	}
 }
 
-// Checks all topic0s, on all addresses, fetching matching logs for the given header
-func (logFetcher LogFetcher) FetchLogs(addresses []common.Address, topic0s []common.Hash, header core.Header) ([]types.Log, error) {
+// Checks all topics, on all addresses, fetching matching logs for the given header. Topics are positional: a log
+// matches if its topic at each position is _any_ of the hashes at that position; see docs on `FilterQuery`
+func (logFetcher LogFetcher) FetchLogs(ctx context.Context, addresses []common.Address, topics [][]common.Hash, header core.Header) ([]types.Log, error) {
 	blockHash := common.HexToHash(header.Hash)
 	query := ethereum.FilterQuery{
 		BlockHash: &blockHash,
 		Addresses: addresses,
-		// Search for _any_ of the topics in topic0 position; see docs on `FilterQuery`
-		Topics: [][]common.Hash{topic0s},
+		Topics:    topics,
 	}
 
-	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(query)
+	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(ctx, query)
 	if err != nil {
 		// TODO review aggregate fetching error handling
 		return []types.Log{}, err
@@ -57,3 +73,34 @@ func (logFetcher LogFetcher) FetchLogs(addresses []common.Address, topic0s []com
 
 	return logs, nil
 }
+
+// Checks all topics, on all addresses, fetching matching logs for every block in the given (inclusive) range.
+// Returns an error wrapping ErrTooManyResults if the node refuses the query because of its size.
+func (logFetcher LogFetcher) FetchLogsInRange(ctx context.Context, addresses []common.Address, topics [][]common.Hash, startingBlock, endingBlock int64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(startingBlock),
+		ToBlock:   big.NewInt(endingBlock),
+		Addresses: addresses,
+		Topics:    topics,
+	}
+
+	logs, err := logFetcher.blockChain.GetEthLogsWithCustomQuery(ctx, query)
+	if err != nil {
+		if isTooManyResultsError(err) {
+			return []types.Log{}, fmt.Errorf("%w: %s", ErrTooManyResults, err.Error())
+		}
+		return []types.Log{}, err
+	}
+
+	return logs, nil
+}
+
+func isTooManyResultsError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, tooManyResultsMessage := range tooManyResultsMessages {
+		if strings.Contains(msg, tooManyResultsMessage) {
+			return true
+		}
+	}
+	return false
+}
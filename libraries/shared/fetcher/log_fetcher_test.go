@@ -17,6 +17,10 @@
 package fetcher_test
 
 import (
+	"context"
+	"errors"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	. "github.com/onsi/ginkgo"
@@ -39,9 +43,13 @@ var _ = Describe("LogFetcher", func() {
 				common.HexToAddress("0xanotherFakeAddress"),
 			}
 
-			topicZeros := []common.Hash{common.BytesToHash([]byte{1, 2, 3, 4, 5})}
+			topics := [][]common.Hash{
+				{common.BytesToHash([]byte{1, 2, 3, 4, 5})},
+				nil,
+				{common.BytesToHash([]byte{6, 7, 8})},
+			}
 
-			_, err := logFetcher.FetchLogs(addresses, topicZeros, header)
+			_, err := logFetcher.FetchLogs(context.Background(), addresses, topics, header)
 
 			address1 := common.HexToAddress("0xfakeAddress")
 			address2 := common.HexToAddress("0xanotherFakeAddress")
@@ -51,7 +59,7 @@ var _ = Describe("LogFetcher", func() {
 			expectedQuery := ethereum.FilterQuery{
 				BlockHash: &blockHash,
 				Addresses: []common.Address{address1, address2},
-				Topics:    [][]common.Hash{topicZeros},
+				Topics:    topics,
 			}
 			blockChain.AssertGetEthLogsWithCustomQueryCalledWith(expectedQuery)
 		})
@@ -61,10 +69,50 @@ var _ = Describe("LogFetcher", func() {
 			blockChain.SetGetEthLogsWithCustomQueryErr(fakes.FakeError)
 			logFetcher := fetcher.NewLogFetcher(blockChain)
 
-			_, err := logFetcher.FetchLogs([]common.Address{}, []common.Hash{}, core.Header{})
+			_, err := logFetcher.FetchLogs(context.Background(), []common.Address{}, [][]common.Hash{}, core.Header{})
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
 	})
+
+	Describe("FetchLogsInRange", func() {
+		It("fetches logs for the given block range", func() {
+			blockChain := fakes.NewMockBlockChain()
+			logFetcher := fetcher.NewLogFetcher(blockChain)
+			addresses := []common.Address{fakes.FakeAddress, fakes.AnotherFakeAddress}
+			topics := [][]common.Hash{{fakes.FakeHash}}
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), addresses, topics, 10, 20)
+
+			Expect(err).NotTo(HaveOccurred())
+			expectedQuery := ethereum.FilterQuery{
+				FromBlock: big.NewInt(10),
+				ToBlock:   big.NewInt(20),
+				Addresses: addresses,
+				Topics:    topics,
+			}
+			blockChain.AssertGetEthLogsWithCustomQueryCalledWith(expectedQuery)
+		})
+
+		It("returns an error if fetching the logs fails", func() {
+			blockChain := fakes.NewMockBlockChain()
+			blockChain.SetGetEthLogsWithCustomQueryErr(fakes.FakeError)
+			logFetcher := fetcher.NewLogFetcher(blockChain)
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), []common.Address{}, [][]common.Hash{}, 10, 20)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns ErrTooManyResults if the node rejects the query size", func() {
+			blockChain := fakes.NewMockBlockChain()
+			blockChain.SetGetEthLogsWithCustomQueryErr(errors.New("query returned more than 10000 results"))
+			logFetcher := fetcher.NewLogFetcher(blockChain)
+
+			_, err := logFetcher.FetchLogsInRange(context.Background(), []common.Address{}, [][]common.Hash{}, 10, 20)
+
+			Expect(err).To(MatchError(fetcher.ErrTooManyResults))
+		})
+	})
 })
@@ -17,14 +17,19 @@
 package logs
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/makerdao/vulcanizedb/libraries/shared/chunker"
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -33,72 +38,251 @@ var (
 	ErrNoTransformers = errors.New("no event transformers configured in the log delegator")
 )
 
+const (
+	// DefaultMaxTransformErrors is the default number of times a log may fail to transform before it's quarantined
+	DefaultMaxTransformErrors int64 = 3
+	// DefaultTransformErrorBackoff is the default time a log waits to be transformed again after first failing
+	DefaultTransformErrorBackoff = time.Minute
+)
+
 type ILogDelegator interface {
 	AddTransformer(t event.ITransformer)
-	DelegateLogs(limit int) error
+	DelegateLogs(ctx context.Context, limit int) error
 }
 
 type LogDelegator struct {
-	Chunker       chunker.Chunker
-	LogRepository datastore.EventLogRepository
-	Transformers  []event.ITransformer
+	AddressRegistryRepository datastore.AddressRegistryRepository
+	Chunker                   chunker.Chunker
+	LogRepository             datastore.EventLogRepository
+	Transformers              []event.ITransformer
+	// Number of times a log may fail to transform before it's quarantined, and skipped until retried
+	MaxTransformErrors int64
+	// Time a log waits to be transformed again after failing, doubling with each failure
+	TransformErrorBackoff time.Duration
+	registeredAddresses   *registeredAddressCache
 }
 
 func NewLogDelegator(db *postgres.DB) *LogDelegator {
 	return &LogDelegator{
-		Chunker:       chunker.NewLogChunker(),
-		LogRepository: repositories.NewEventLogRepository(db),
+		AddressRegistryRepository: repositories.NewAddressRegistryRepository(db),
+		Chunker:                   chunker.NewLogChunker(),
+		LogRepository:             repositories.NewEventLogRepository(db),
+		MaxTransformErrors:        DefaultMaxTransformErrors,
+		TransformErrorBackoff:     DefaultTransformErrorBackoff,
 	}
 }
 
 func (delegator *LogDelegator) AddTransformer(t event.ITransformer) {
 	delegator.Transformers = append(delegator.Transformers, t)
-	delegator.Chunker.AddConfig(t.GetConfig())
+	config := t.GetConfig()
+	delegator.Chunker.AddConfig(config)
+	if config.AddressRegistry != "" {
+		if delegator.registeredAddresses == nil {
+			delegator.registeredAddresses = newRegisteredAddressCache()
+		}
+		delegator.registeredAddresses.addRegistry(config.AddressRegistry)
+	}
 }
 
-func (delegator *LogDelegator) DelegateLogs(limit int) error {
+// Transforms each transformer's untransformed logs in batches of limit, stopping between batches if the context is
+// cancelled. Returns ErrNoLogs if no logs were delegated to any transformer.
+func (delegator *LogDelegator) DelegateLogs(ctx context.Context, limit int) error {
 	if len(delegator.Transformers) < 1 {
 		return ErrNoTransformers
 	}
 
+	delegatedAny := false
+	for _, t := range delegator.Transformers {
+		config := t.GetConfig()
+		fetchLogs := func(minID int) ([]core.EventLog, error) {
+			return delegator.LogRepository.GetUntransformedEventLogs(config.TransformerName,
+				common.HexToHash(config.Topic), minID, limit)
+		}
+		delegated, err := delegator.delegateTransformerLogs(ctx, t, limit, false, fetchLogs)
+		if err != nil {
+			return err
+		}
+		delegatedAny = delegatedAny || delegated > 0
+	}
+	if !delegatedAny {
+		return ErrNoLogs
+	}
+	return nil
+}
+
+// Passes each transformer its logs between the given blocks again, in batches of limit, whether or not it has already
+// transformed them. Transformers implementing event.Cleaner clean up the models of each batch of logs before
+// transforming it again, so models of logs that aren't passed again (e.g. quarantined logs) are kept. Returns the
+// number of logs delegated.
+func (delegator *LogDelegator) RedelegateLogs(ctx context.Context, startingBlock, endingBlock int64, limit int) (int, error) {
+	if len(delegator.Transformers) < 1 {
+		return 0, ErrNoTransformers
+	}
+
+	delegatedCount := 0
+	for _, t := range delegator.Transformers {
+		config := t.GetConfig()
+		fetchLogs := func(minID int) ([]core.EventLog, error) {
+			return delegator.LogRepository.GetEventLogsInRange(config.TransformerName, common.HexToHash(config.Topic),
+				startingBlock, endingBlock, minID, limit)
+		}
+		delegated, err := delegator.delegateTransformerLogs(ctx, t, limit, true, fetchLogs)
+		delegatedCount += delegated
+		if err != nil {
+			return delegatedCount, err
+		}
+	}
+	return delegatedCount, nil
+}
+
+// Passes the transformer the logs returned by fetchLogs, paging through them by id, and records the logs it handled.
+// Logs being redelegated are cleaned up first. Returns the number of logs delegated to it.
+func (delegator *LogDelegator) delegateTransformerLogs(ctx context.Context, t event.ITransformer, limit int, redelegate bool,
+	fetchLogs func(minID int) ([]core.EventLog, error)) (int, error) {
+	config := t.GetConfig()
+	delegated := 0
 	minID := 0
 	for {
-		persistedLogs, fetchErr := delegator.LogRepository.GetUntransformedEventLogs(minID, limit)
+		if ctx.Err() != nil {
+			return delegated, ctx.Err()
+		}
+		persistedLogs, fetchErr := fetchLogs(minID)
 		if fetchErr != nil {
 			logrus.Errorf("error loading logs from db: %s", fetchErr.Error())
-			return fetchErr
+			return delegated, fetchErr
 		}
 
 		lenPersistedLogs := len(persistedLogs)
-
 		if lenPersistedLogs < 1 {
-			return ErrNoLogs
-		} else {
-			minID = int(persistedLogs[lenPersistedLogs-1].ID)
+			return delegated, nil
+		}
+		minID = int(persistedLogs[lenPersistedLogs-1].ID)
+
+		registeredAddressesErr := delegator.refreshRegisteredAddresses()
+		if registeredAddressesErr != nil {
+			return delegated, registeredAddressesErr
 		}
 
-		transformErr := delegator.delegateLogs(persistedLogs)
+		logChunk := delegator.Chunker.ChunkLogs(persistedLogs)[config.TransformerName]
+		if redelegate {
+			cleanupErr := cleanUpLogs(t, logChunk)
+			if cleanupErr != nil {
+				return delegated, cleanupErr
+			}
+		}
+		failedLogIDs, transformErr := delegator.delegateLogs(t, logChunk)
 		if transformErr != nil {
 			logrus.Errorf("error transforming logs: %s", transformErr)
-			return transformErr
+			return delegated, transformErr
+		}
+		delegated += len(logChunk) - len(failedLogIDs)
+
+		markErr := delegator.LogRepository.MarkEventLogsTransformed(config.TransformerName,
+			handledLogIDs(persistedLogs, failedLogIDs))
+		if markErr != nil {
+			return delegated, markErr
 		}
 
 		if lenPersistedLogs < limit {
-			return nil
+			return delegated, nil
 		}
 	}
 }
 
-func (delegator *LogDelegator) delegateLogs(logs []core.EventLog) error {
-	chunkedLogs := delegator.Chunker.ChunkLogs(logs)
-	for _, t := range delegator.Transformers {
-		transformerName := t.GetConfig().TransformerName
-		logChunk := chunkedLogs[transformerName]
-		err := t.Execute(logChunk)
-		if err != nil {
-			logrus.Errorf("%v transformer failed to execute in watcher: %v", transformerName, err)
-			return err
+// Deletes the models the transformer persisted for the logs, if it implements event.Cleaner
+func cleanUpLogs(t event.ITransformer, logChunk []core.EventLog) error {
+	cleaner, ok := t.(event.Cleaner)
+	if !ok || len(logChunk) == 0 {
+		return nil
+	}
+	cleanupErr := cleaner.Cleanup(logChunk)
+	if cleanupErr != nil {
+		return fmt.Errorf("error cleaning up models of %s: %w", t.GetConfig().TransformerName, cleanupErr)
+	}
+	return nil
+}
+
+// Returns the ids of the fetched logs the transformer has handled, whether or not they were routed to it
+func handledLogIDs(fetchedLogs []core.EventLog, failedLogIDs map[int64]bool) []int64 {
+	var logIDs []int64
+	for _, log := range fetchedLogs {
+		if !failedLogIDs[log.ID] {
+			logIDs = append(logIDs, log.ID)
+		}
+	}
+	return logIDs
+}
+
+// Routes logs from addresses registered since the last batch (e.g. by a factory transformer) to the transformers
+// watching their address registry, and stops routing logs from addresses whose registrations were removed by a reorg
+func (delegator *LogDelegator) refreshRegisteredAddresses() error {
+	if delegator.registeredAddresses == nil {
+		return nil
+	}
+	changes, refreshErr := delegator.registeredAddresses.refresh(delegator.AddressRegistryRepository)
+	if refreshErr != nil {
+		return fmt.Errorf("error getting registered addresses to delegate logs for: %w", refreshErr)
+	}
+	for registry, change := range changes {
+		for _, registeredAddress := range change.removed {
+			delegator.Chunker.RemoveRegisteredAddress(registry, registeredAddress.Address)
+		}
+		for _, registeredAddress := range change.added {
+			delegator.Chunker.AddRegisteredAddress(registry, registeredAddress.Address)
 		}
 	}
 	return nil
 }
+
+func containsString(strings []string, s string) bool {
+	for _, str := range strings {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Executes the transformer on its logs. If it fails, it's executed on each log separately and the failures are recorded
+// against the logs that fail, so that they don't block other logs and transformers. Returns the ids of the failed logs.
+func (delegator *LogDelegator) delegateLogs(t event.ITransformer, logChunk []core.EventLog) (map[int64]bool, error) {
+	transformerName := t.GetConfig().TransformerName
+	err := t.Execute(logChunk)
+	if err != nil {
+		logrus.Errorf("%v transformer failed to execute in watcher: %v", transformerName, err)
+		if len(logChunk) == 0 {
+			return nil, err
+		}
+		return delegator.executeEachLog(t, logChunk, err)
+	}
+	metrics.LogsDelegated.WithLabelValues(transformerName).Add(float64(len(logChunk)))
+	return nil, nil
+}
+
+func (delegator *LogDelegator) executeEachLog(t event.ITransformer, logs []core.EventLog, chunkErr error) (map[int64]bool, error) {
+	transformerName := t.GetConfig().TransformerName
+	failedLogIDs := make(map[int64]bool)
+	for _, log := range logs {
+		executeErr := chunkErr
+		if len(logs) > 1 {
+			executeErr = t.Execute([]core.EventLog{log})
+		}
+		if executeErr == nil {
+			metrics.LogsDelegated.WithLabelValues(transformerName).Inc()
+			continue
+		}
+
+		failedLogIDs[log.ID] = true
+		transformErr := fmt.Errorf("%s transformer failed: %w", transformerName, executeErr)
+		quarantined, recordErr := delegator.LogRepository.RecordTransformError(log.ID, transformerName, transformErr,
+			delegator.MaxTransformErrors, delegator.TransformErrorBackoff)
+		if recordErr != nil {
+			return nil, recordErr
+		}
+		if quarantined {
+			logrus.Errorf("quarantining log %d after it failed to transform %d times: %v", log.ID, delegator.MaxTransformErrors, transformErr)
+			metrics.LogsQuarantined.WithLabelValues(transformerName).Inc()
+		}
+	}
+	return failedLogIDs, nil
+}
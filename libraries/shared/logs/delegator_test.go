@@ -17,7 +17,10 @@
 package logs_test
 
 import (
+	"context"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -63,7 +66,7 @@ var _ = Describe("Log delegator", func() {
 		It("returns error if no transformers configured", func() {
 			delegator := newDelegator(&fakes.MockEventLogRepository{})
 
-			err := delegator.DelegateLogs(0)
+			err := delegator.DelegateLogs(context.Background(), 0)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(logs.ErrNoTransformers))
@@ -75,7 +78,7 @@ var _ = Describe("Log delegator", func() {
 			delegator := newDelegator(mockLogRepository)
 			delegator.AddTransformer(&mocks.MockEventTransformer{})
 
-			err := delegator.DelegateLogs(0)
+			err := delegator.DelegateLogs(context.Background(), 0)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -85,7 +88,7 @@ var _ = Describe("Log delegator", func() {
 			delegator := newDelegator(&fakes.MockEventLogRepository{})
 			delegator.AddTransformer(&mocks.MockEventTransformer{})
 
-			err := delegator.DelegateLogs(0)
+			err := delegator.DelegateLogs(context.Background(), 0)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(logs.ErrNoLogs))
@@ -106,48 +109,227 @@ var _ = Describe("Log delegator", func() {
 			delegator.AddTransformer(fakeTransformer)
 
 			limitGreaterThanUntransformedLogs := 2
-			err := delegator.DelegateLogs(limitGreaterThanUntransformedLogs)
+			err := delegator.DelegateLogs(context.Background(), limitGreaterThanUntransformedLogs)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeTransformer.ExecuteWasCalled).To(BeTrue())
 			Expect(fakeTransformer.PassedLogs).To(Equal(fakeEventLogs))
 		})
 
+		It("looks up the logs each transformer hasn't transformed", func() {
+			config := mocks.FakeTransformerConfig
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(config)
+			otherConfig := config
+			otherConfig.TransformerName = "OtherTransformer"
+			otherTransformer := &mocks.MockEventTransformer{}
+			otherTransformer.SetTransformerConfig(otherConfig)
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+			delegator.AddTransformer(otherTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).To(MatchError(logs.ErrNoLogs))
+			Expect(mockLogRepository.PassedTransformerNames).To(Equal([]string{config.TransformerName, otherConfig.TransformerName}))
+			Expect(mockLogRepository.PassedTopic0s).To(ConsistOf(common.HexToHash(config.Topic), common.HexToHash(config.Topic)))
+		})
+
 		It("repeats logs lookup with minID from last result when repository returns maximum number of logs", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			fakeTransformer.SetTransformerConfig(config)
+			fakeGethLog := types.Log{
+				Address: common.HexToAddress(config.ContractAddresses[0]),
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}
 			mockLogRepository := &fakes.MockEventLogRepository{}
-			returnLogs := []core.EventLog{{ID: 1}, {ID: 2}, {ID: 3}}
+			returnLogs := []core.EventLog{{ID: 1, Log: fakeGethLog}, {ID: 2, Log: fakeGethLog}, {ID: 3, Log: fakeGethLog}}
 			mockLogRepository.ReturnLogs = returnLogs
 			delegator := newDelegator(mockLogRepository)
-			delegator.AddTransformer(&mocks.MockEventTransformer{})
+			delegator.AddTransformer(fakeTransformer)
 
 			limit := len(returnLogs) - 1
-			err := delegator.DelegateLogs(limit)
+			err := delegator.DelegateLogs(context.Background(), limit)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockLogRepository.PassedMinIDs).To(ConsistOf(0, int(returnLogs[1].ID)))
 			Expect(mockLogRepository.PassedLimits).To(ConsistOf(limit, limit))
 		})
 
-		It("returns logs.ErrNoLogs if no logs returned on subsequent call", func() {
+		It("returns logs.ErrNoLogs if no logs are delegated to a transformer", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(mocks.FakeTransformerConfig)
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = []core.EventLog{{ID: 1, Log: types.Log{Address: fakes.AnotherFakeAddress}}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(logs.ErrNoLogs))
+		})
+
+		It("marks fetched logs transformed by the transformer", func() {
 			fakeTransformer := &mocks.MockEventTransformer{}
 			config := mocks.FakeTransformerConfig
 			fakeTransformer.SetTransformerConfig(config)
-			fakeGethLog := types.Log{
+			routedLog := core.EventLog{ID: 1, Log: types.Log{
+				Address: common.HexToAddress(config.ContractAddresses[0]),
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			otherAddressLog := core.EventLog{ID: 2, Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = []core.EventLog{routedLog, otherAddressLog}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 3)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeTransformer.PassedLogs).To(Equal([]core.EventLog{routedLog}))
+			Expect(mockLogRepository.TransformedLogIDs).To(Equal(map[string][]int64{
+				config.TransformerName: {routedLog.ID, otherAddressLog.ID},
+			}))
+		})
+
+		It("returns error if marking logs transformed fails", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			fakeTransformer.SetTransformerConfig(config)
+			mockLogRepository := &fakes.MockEventLogRepository{MarkTransformedError: fakes.FakeError}
+			mockLogRepository.ReturnLogs = []core.EventLog{{ID: 1, Log: types.Log{
+				Address: common.HexToAddress(config.ContractAddresses[0]),
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("doesn't clean up models of a transformer implementing event.Cleaner", func() {
+			cleanerTransformer := &mocks.MockCleanerTransformer{}
+			config := mocks.FakeTransformerConfig
+			cleanerTransformer.SetTransformerConfig(config)
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = []core.EventLog{{ID: 1, Log: types.Log{
 				Address: common.HexToAddress(config.ContractAddresses[0]),
 				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddTransformer(cleanerTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleanerTransformer.CleanedLogs).To(BeEmpty())
+		})
+
+		It("delegates logs from addresses registered to a transformer's address registry", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			config.ContractAddresses = nil
+			config.AddressRegistry = "pairs"
+			fakeTransformer.SetTransformerConfig(config)
+			fakeEventLogs := []core.EventLog{{Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}}
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = fakeEventLogs
+			mockRegistryRepository := &fakes.MockAddressRegistryRepository{
+				RegisteredAddresses: []core.RegisteredAddress{{ID: 1, Registry: "pairs", Address: fakes.AnotherFakeAddress.Hex()}},
 			}
-			fakeEventLogs := []core.EventLog{{Log: fakeGethLog}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddressRegistryRepository = mockRegistryRepository
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockRegistryRepository.GetRegisteredAddressesRegistries).To(Equal([]string{"pairs"}))
+			Expect(fakeTransformer.PassedLogs).To(Equal(fakeEventLogs))
+		})
+
+		It("marks logs from unregistered addresses transformed by a transformer watching an address registry", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			config.ContractAddresses = nil
+			config.AddressRegistry = "pairs"
+			fakeTransformer.SetTransformerConfig(config)
+			registeredLog := core.EventLog{ID: 1, Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			unregisteredLog := core.EventLog{ID: 2, Log: types.Log{
+				Address: fakes.FakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = []core.EventLog{registeredLog, unregisteredLog}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddressRegistryRepository = &fakes.MockAddressRegistryRepository{
+				RegisteredAddresses: []core.RegisteredAddress{{ID: 1, Registry: "pairs", Address: fakes.AnotherFakeAddress.Hex()}},
+			}
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 3)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeTransformer.PassedLogs).To(Equal([]core.EventLog{registeredLog}))
+			Expect(mockLogRepository.TransformedLogIDs[config.TransformerName]).To(ConsistOf(registeredLog.ID, unregisteredLog.ID))
+		})
+
+		It("stops delegating logs from addresses whose registrations were removed by a reorg", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			config.ContractAddresses = nil
+			config.AddressRegistry = "pairs"
+			fakeTransformer.SetTransformerConfig(config)
+			fakeEventLogs := []core.EventLog{{ID: 1, Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}}
 			mockLogRepository := &fakes.MockEventLogRepository{}
 			mockLogRepository.ReturnLogs = fakeEventLogs
+			mockRegistryRepository := &fakes.MockAddressRegistryRepository{
+				RegisteredAddresses: []core.RegisteredAddress{{ID: 1, Registry: "pairs", Address: fakes.AnotherFakeAddress.Hex()}},
+			}
 			delegator := newDelegator(mockLogRepository)
+			delegator.AddressRegistryRepository = mockRegistryRepository
 			delegator.AddTransformer(fakeTransformer)
+			Expect(delegator.DelegateLogs(context.Background(), 2)).To(Succeed())
+			mockRegistryRepository.RegisteredAddresses = nil
 
-			err := delegator.DelegateLogs(1)
+			err := delegator.DelegateLogs(context.Background(), 2)
 
-			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(logs.ErrNoLogs))
-			Expect(fakeTransformer.ExecuteWasCalled).To(BeTrue())
-			Expect(fakeTransformer.PassedLogs).To(Equal(fakeEventLogs))
+			Expect(fakeTransformer.TransformedLogs).To(Equal(fakeEventLogs))
+		})
+
+		It("returns error if getting registered addresses fails", func() {
+			fakeTransformer := &mocks.MockEventTransformer{}
+			config := mocks.FakeTransformerConfig
+			config.AddressRegistry = "pairs"
+			fakeTransformer.SetTransformerConfig(config)
+			mockLogRepository := &fakes.MockEventLogRepository{}
+			mockLogRepository.ReturnLogs = []core.EventLog{{}}
+			delegator := newDelegator(mockLogRepository)
+			delegator.AddressRegistryRepository = &fakes.MockAddressRegistryRepository{GetRegisteredAddressesError: fakes.FakeError}
+			delegator.AddTransformer(fakeTransformer)
+
+			err := delegator.DelegateLogs(context.Background(), 2)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.ExecuteWasCalled).To(BeFalse())
 		})
 
 		It("returns error if transformer returns an error", func() {
@@ -157,11 +339,222 @@ var _ = Describe("Log delegator", func() {
 			fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
 			delegator.AddTransformer(fakeTransformer)
 
-			err := delegator.DelegateLogs(1)
+			err := delegator.DelegateLogs(context.Background(), 1)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
+
+		Describe("when a transformer fails to transform logs", func() {
+			var (
+				config            event.TransformerConfig
+				failingLog        core.EventLog
+				otherLog          core.EventLog
+				mockLogRepository *fakes.MockEventLogRepository
+				delegator         *logs.LogDelegator
+			)
+
+			BeforeEach(func() {
+				config = mocks.FakeTransformerConfig
+				fakeGethLog := types.Log{
+					Address: common.HexToAddress(config.ContractAddresses[0]),
+					Topics:  []common.Hash{common.HexToHash(config.Topic)},
+				}
+				failingLog = core.EventLog{ID: 1, Log: fakeGethLog}
+				otherLog = core.EventLog{ID: 2, Log: fakeGethLog}
+				mockLogRepository = &fakes.MockEventLogRepository{}
+				mockLogRepository.ReturnLogs = []core.EventLog{failingLog, otherLog}
+				delegator = newDelegator(mockLogRepository)
+				delegator.MaxTransformErrors = 3
+			})
+
+			It("records the error against each log that fails, and transforms the others", func() {
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError, FailingLogIDs: []int64{failingLog.ID}}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeTransformer.TransformedLogs).To(Equal([]core.EventLog{otherLog}))
+				Expect(mockLogRepository.RecordedTransformErrors).To(HaveLen(1))
+				Expect(mockLogRepository.RecordedTransformErrors[failingLog.ID]).To(HaveLen(1))
+				Expect(errors.Is(mockLogRepository.RecordedTransformErrors[failingLog.ID][0], fakes.FakeError)).To(BeTrue())
+				Expect(mockLogRepository.RecordedTransformErrorMaxCount).To(Equal(int64(3)))
+				Expect(mockLogRepository.RecordedTransformErrorNames).To(Equal([]string{config.TransformerName}))
+			})
+
+			It("records the error with the backoff before the log is retried", func() {
+				delegator.TransformErrorBackoff = time.Second
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError, FailingLogIDs: []int64{failingLog.ID}}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.RecordedTransformErrorBackoff).To(Equal(time.Second))
+			})
+
+			It("returns logs.ErrNoLogs if every log fails to transform", func() {
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).To(MatchError(logs.ErrNoLogs))
+				Expect(mockLogRepository.RecordedTransformErrors).To(HaveLen(2))
+			})
+
+			It("doesn't mark logs that fail transformed", func() {
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError, FailingLogIDs: []int64{failingLog.ID}}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.TransformedLogIDs[config.TransformerName]).To(Equal([]int64{otherLog.ID}))
+			})
+
+			It("keeps executing other transformers", func() {
+				failingTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError}
+				failingTransformer.SetTransformerConfig(config)
+				otherConfig := config
+				otherConfig.TransformerName = "OtherTransformer"
+				otherTransformer := &mocks.MockEventTransformer{}
+				otherTransformer.SetTransformerConfig(otherConfig)
+				delegator.AddTransformer(failingTransformer)
+				delegator.AddTransformer(otherTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.RecordedTransformErrors).To(HaveLen(2))
+				Expect(otherTransformer.TransformedLogs).To(Equal([]core.EventLog{failingLog, otherLog}))
+			})
+
+			It("quarantines logs that fail MaxTransformErrors times", func() {
+				delegator.MaxTransformErrors = 1
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: fakes.FakeError, FailingLogIDs: []int64{failingLog.ID}}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.QuarantinedLogIDs).To(Equal([]int64{failingLog.ID}))
+			})
+
+			It("returns error if recording the transform error fails", func() {
+				mockLogRepository.RecordTransformErrorError = fakes.FakeError
+				fakeTransformer := &mocks.MockEventTransformer{ExecuteError: errors.New("transform failed")}
+				fakeTransformer.SetTransformerConfig(config)
+				delegator.AddTransformer(fakeTransformer)
+
+				err := delegator.DelegateLogs(context.Background(), 3)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
+		})
+	})
+
+	Describe("RedelegateLogs", func() {
+		var (
+			config            event.TransformerConfig
+			fakeTransformer   *mocks.MockEventTransformer
+			mockLogRepository *fakes.MockEventLogRepository
+			routedLog         core.EventLog
+			delegator         *logs.LogDelegator
+		)
+
+		BeforeEach(func() {
+			config = mocks.FakeTransformerConfig
+			fakeTransformer = &mocks.MockEventTransformer{}
+			fakeTransformer.SetTransformerConfig(config)
+			routedLog = core.EventLog{ID: 1, Log: types.Log{
+				Address: common.HexToAddress(config.ContractAddresses[0]),
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			mockLogRepository = &fakes.MockEventLogRepository{}
+			delegator = newDelegator(mockLogRepository)
+		})
+
+		It("returns error if no transformers configured", func() {
+			_, err := delegator.RedelegateLogs(context.Background(), 0, 10, 2)
+
+			Expect(err).To(MatchError(logs.ErrNoTransformers))
+		})
+
+		It("looks up each transformer's logs in the block range", func() {
+			delegator.AddTransformer(fakeTransformer)
+
+			_, err := delegator.RedelegateLogs(context.Background(), 5, 10, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.PassedTransformerNames).To(Equal([]string{config.TransformerName}))
+			Expect(mockLogRepository.PassedTopic0s).To(Equal([]common.Hash{common.HexToHash(config.Topic)}))
+			Expect(mockLogRepository.PassedStartingBlock).To(Equal(int64(5)))
+			Expect(mockLogRepository.PassedEndingBlock).To(Equal(int64(10)))
+		})
+
+		It("delegates the logs and marks them transformed", func() {
+			otherAddressLog := core.EventLog{ID: 2, Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			mockLogRepository.ReturnLogs = []core.EventLog{routedLog, otherAddressLog}
+			delegator.AddTransformer(fakeTransformer)
+
+			delegated, err := delegator.RedelegateLogs(context.Background(), 0, 10, 3)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(delegated).To(Equal(1))
+			Expect(fakeTransformer.PassedLogs).To(Equal([]core.EventLog{routedLog}))
+			Expect(mockLogRepository.TransformedLogIDs).To(Equal(map[string][]int64{
+				config.TransformerName: {routedLog.ID, otherAddressLog.ID},
+			}))
+		})
+
+		It("cleans up the models of the logs routed to a transformer implementing event.Cleaner before transforming them", func() {
+			otherAddressLog := core.EventLog{ID: 2, Log: types.Log{
+				Address: fakes.AnotherFakeAddress,
+				Topics:  []common.Hash{common.HexToHash(config.Topic)},
+			}}
+			mockLogRepository.ReturnLogs = []core.EventLog{routedLog, otherAddressLog}
+			cleanerTransformer := &mocks.MockCleanerTransformer{}
+			cleanerTransformer.SetTransformerConfig(config)
+			delegator.AddTransformer(cleanerTransformer)
+
+			_, err := delegator.RedelegateLogs(context.Background(), 0, 10, 3)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleanerTransformer.CleanedLogs).To(Equal([]core.EventLog{routedLog}))
+			Expect(cleanerTransformer.PassedLogs).To(Equal([]core.EventLog{routedLog}))
+		})
+
+		It("doesn't transform the logs if cleaning up their models fails", func() {
+			mockLogRepository.ReturnLogs = []core.EventLog{routedLog}
+			cleanerTransformer := &mocks.MockCleanerTransformer{CleanupError: fakes.FakeError}
+			cleanerTransformer.SetTransformerConfig(config)
+			delegator.AddTransformer(cleanerTransformer)
+
+			_, err := delegator.RedelegateLogs(context.Background(), 0, 10, 3)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(cleanerTransformer.ExecuteWasCalled).To(BeFalse())
+			Expect(mockLogRepository.TransformedLogIDs).To(BeEmpty())
+		})
+
+		It("returns error if getting logs in range fails", func() {
+			mockLogRepository.GetError = fakes.FakeError
+			delegator.AddTransformer(fakeTransformer)
+
+			_, err := delegator.RedelegateLogs(context.Background(), 0, 10, 2)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
 	})
 })
 
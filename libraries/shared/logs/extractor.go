@@ -17,10 +17,14 @@
 package logs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/libraries/shared/chunker"
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/libraries/shared/fetcher"
@@ -29,6 +33,8 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/eth"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -38,47 +44,72 @@ var (
 	StartInterval         BlockIdentifier = "start"
 	EndInterval           BlockIdentifier = "end"
 	ErrNoUncheckedHeaders                 = errors.New("no unchecked headers available for log fetching")
-	ErrNoWatchedAddresses                 = errors.New("no watched addresses configured in the log extractor")
+	ErrNoWatchedLogs                      = errors.New("no watched logs configured in the log extractor")
+	ErrHeaderHashMismatch                 = errors.New("fetched log block hash doesn't match persisted header hash")
 	HeaderChunkSize       int64           = 1000
+	// Maximum number of addresses to pass the node in a single log filter
+	MaxFilterAddresses = 1000
+
+	// Deprecated: use ErrNoWatchedLogs; transformers may watch logs emitted by any address
+	ErrNoWatchedAddresses = ErrNoWatchedLogs
 )
 
 type ILogExtractor interface {
 	AddTransformerConfig(config event.TransformerConfig) error
-	BackFillLogs(endingBlock int64) error
-	ExtractLogs(recheckHeaders constants.TransformerExecution) error
+	BackFillLogs(ctx context.Context, endingBlock int64) error
+	ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error
 }
 
 type LogExtractor struct {
-	Addresses                []common.Address
-	CheckedHeadersRepository datastore.CheckedHeadersRepository
-	CheckedLogsRepository    datastore.CheckedLogsRepository
-	Fetcher                  fetcher.ILogFetcher
-	HeaderRepository         datastore.HeaderRepository
-	LogRepository            datastore.EventLogRepository
-	StartingBlock            *int64
-	EndingBlock              *int64
-	Syncer                   transactions.ITransactionsSyncer
-	Topics                   []common.Hash
-	RecheckHeaderCap         int64
-}
-
-func NewLogExtractor(db *postgres.DB, bc core.BlockChain, chr datastore.CheckedHeadersRepository) *LogExtractor {
+	AbiRepository             datastore.AbiRepository
+	Addresses                 []common.Address
+	AddressRegistryRepository datastore.AddressRegistryRepository
+	CheckedLogsRepository     datastore.CheckedLogsRepository
+	Chunker                   chunker.Chunker // attributes extracted logs to transformers for metrics
+	Fetcher                   fetcher.ILogFetcher
+	HeaderRepository          datastore.HeaderRepository
+	LogRepository             datastore.EventLogRepository
+	StartingBlock             *int64
+	EndingBlock               *int64
+	Syncer                    transactions.ITransactionsSyncer
+	Topics                    []common.Hash
+	// Each configured address + topic0, tracked separately so that headers are checked from each
+	// transformer's own starting block, letting newly added transformers catch up on their own
+	WatchedLogs      []core.WatchedLog
+	RecheckHeaderCap int64
+	// Maximum number of blocks to request logs for in a single query. Values <= 1 fetch logs
+	// per header by block hash; larger values fetch logs over block ranges and map them back
+	// to persisted headers, shrinking the range when the node reports too many results.
+	BlockRangeSize int64
+	// Configs watching addresses registered to each address registry, and the addresses registered to them
+	registryConfigs     map[string][]event.TransformerConfig
+	registeredAddresses *registeredAddressCache
+	// Registered addresses whose logs from headers already checked for their registry are yet to be extracted
+	unbackfilledAddresses map[string][]core.RegisteredAddress
+}
+
+// Returns the addresses registered to the registry at or before the block
+type registeredAddressesFunc func(registry string, blockNumber int64) []common.Address
+
+func NewLogExtractor(db *postgres.DB, bc core.BlockChain) *LogExtractor {
 	return &LogExtractor{
-		CheckedHeadersRepository: chr,
-		CheckedLogsRepository:    repositories.NewCheckedLogsRepository(db),
-		Fetcher:                  fetcher.NewLogFetcher(bc),
-		HeaderRepository:         repositories.NewHeaderRepository(db),
-		LogRepository:            repositories.NewEventLogRepository(db),
-		Syncer:                   transactions.NewTransactionsSyncer(db, bc),
-		RecheckHeaderCap:         constants.RecheckHeaderCap,
+		AbiRepository:             repositories.NewAbiRepository(db),
+		AddressRegistryRepository: repositories.NewAddressRegistryRepository(db),
+		CheckedLogsRepository:     repositories.NewCheckedLogsRepository(db),
+		Chunker:                   chunker.NewLogChunker(),
+		Fetcher:                   fetcher.NewLogFetcher(bc),
+		HeaderRepository:          repositories.NewHeaderRepository(db),
+		LogRepository:             repositories.NewEventLogRepository(db),
+		Syncer:                    transactions.NewTransactionsSyncer(db, bc),
+		RecheckHeaderCap:          constants.RecheckHeaderCap,
 	}
 }
 
 // AddTransformerConfig adds additional logs to extract
 func (extractor *LogExtractor) AddTransformerConfig(config event.TransformerConfig) error {
-	checkedHeadersErr := extractor.updateCheckedHeaders(config)
-	if checkedHeadersErr != nil {
-		return checkedHeadersErr
+	watchErr := extractor.watchLogs(config)
+	if watchErr != nil {
+		return watchErr
 	}
 
 	if shouldResetStartingBlockToEarlierTransformerBlock(config.StartingBlockNumber, extractor.StartingBlock) {
@@ -89,9 +120,27 @@ func (extractor *LogExtractor) AddTransformerConfig(config event.TransformerConf
 		extractor.EndingBlock = &config.EndingBlockNumber
 	}
 
+	for _, address := range config.ContractAddresses {
+		saveErr := extractor.saveAbi(config, address)
+		if saveErr != nil {
+			return saveErr
+		}
+	}
+
+	extractor.Chunker.AddConfig(config)
 	addresses := event.HexStringsToAddresses(config.ContractAddresses)
 	extractor.Addresses = append(extractor.Addresses, addresses...)
 	extractor.Topics = append(extractor.Topics, common.HexToHash(config.Topic))
+
+	if config.AddressRegistry != "" {
+		if extractor.registryConfigs == nil {
+			extractor.registryConfigs = make(map[string][]event.TransformerConfig)
+			extractor.registeredAddresses = newRegisteredAddressCache()
+			extractor.unbackfilledAddresses = make(map[string][]core.RegisteredAddress)
+		}
+		extractor.registeredAddresses.addRegistry(config.AddressRegistry)
+		extractor.registryConfigs[config.AddressRegistry] = append(extractor.registryConfigs[config.AddressRegistry], config)
+	}
 	return nil
 }
 
@@ -119,43 +168,45 @@ func shouldResetEndingBlockToLaterTransformerBlock(currentTransformerBlock int64
 	return isCurrentBlockNegativeOne && isTransformerBlockGreater
 }
 
-// ExtractLogs fetches and persists watched logs from unchecked headers
-func (extractor LogExtractor) ExtractLogs(recheckHeaders constants.TransformerExecution) error {
-	if len(extractor.Addresses) < 1 {
-		logrus.Errorf("error extracting logs: %s", ErrNoWatchedAddresses.Error())
-		return fmt.Errorf("error extracting logs: %w", ErrNoWatchedAddresses)
+// ExtractLogs fetches and persists watched logs from headers that have not been checked for them, stopping between
+// headers if the context is cancelled. Addresses registered since the last call are watched from the block they were
+// registered at.
+func (extractor *LogExtractor) ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
+	if len(extractor.WatchedLogs) < 1 {
+		logrus.Errorf("error extracting logs: %s", ErrNoWatchedLogs.Error())
+		return fmt.Errorf("error extracting logs: %w", ErrNoWatchedLogs)
+	}
+
+	refreshErr := extractor.refreshRegisteredAddresses(ctx)
+	if refreshErr != nil {
+		return refreshErr
 	}
 
-	uncheckedHeaders, uncheckedHeadersErr := extractor.CheckedHeadersRepository.UncheckedHeaders(*extractor.StartingBlock, *extractor.EndingBlock, extractor.getCheckCount(recheckHeaders))
+	uncheckedHeaders, uncheckedHeadersErr := extractor.CheckedLogsRepository.UncheckedHeaders(extractor.WatchedLogs, extractor.getCheckCount(recheckHeaders),
+		constants.UncheckedHeadersLimit)
 	if uncheckedHeadersErr != nil {
 		logrus.Errorf("error fetching missing headers: %s", uncheckedHeadersErr)
 		return fmt.Errorf("error getting unchecked headers to check for logs: %w", uncheckedHeadersErr)
 	}
 
+	metrics.UncheckedHeaders.Set(float64(len(uncheckedHeaders)))
 	if len(uncheckedHeaders) < 1 {
 		return ErrNoUncheckedHeaders
 	}
 
-	for _, header := range uncheckedHeaders {
-		err := extractor.fetchAndPersistLogsForHeader(header)
-		if err != nil {
-			return fmt.Errorf("error fetching and persisting logs for header with id %d: %w", header.Id, err)
-		}
-
-		markHeaderCheckedErr := extractor.CheckedHeadersRepository.MarkHeaderChecked(header.Id)
-		if markHeaderCheckedErr != nil {
-			logError("error marking header checked: %s", markHeaderCheckedErr, header)
-			return markHeaderCheckedErr
-		}
-	}
-	return nil
+	return extractor.fetchAndPersistLogs(ctx, uncheckedHeaders, true, extractor.registeredThrough)
 }
 
 // BackFillLogs fetches and persists watched logs from provided range of headers
-func (extractor LogExtractor) BackFillLogs(endingBlock int64) error {
-	if len(extractor.Addresses) < 1 {
-		logrus.Errorf("error extracting logs: %s", ErrNoWatchedAddresses.Error())
-		return fmt.Errorf("error extracting logs: %w", ErrNoWatchedAddresses)
+func (extractor *LogExtractor) BackFillLogs(ctx context.Context, endingBlock int64) error {
+	if len(extractor.WatchedLogs) < 1 {
+		logrus.Errorf("error extracting logs: %s", ErrNoWatchedLogs.Error())
+		return fmt.Errorf("error extracting logs: %w", ErrNoWatchedLogs)
+	}
+
+	refreshErr := extractor.refreshRegisteredAddresses(ctx)
+	if refreshErr != nil {
+		return refreshErr
 	}
 
 	ranges, chunkErr := ChunkRanges(*extractor.StartingBlock, endingBlock, HeaderChunkSize)
@@ -170,11 +221,9 @@ func (extractor LogExtractor) BackFillLogs(endingBlock int64) error {
 			return fmt.Errorf("error getting unchecked headers to check for logs: %w", headersErr)
 		}
 
-		for _, header := range headers {
-			err := extractor.fetchAndPersistLogsForHeader(header)
-			if err != nil {
-				return fmt.Errorf("error fetching and persisting logs for header with id %d: %w", header.Id, err)
-			}
+		err := extractor.fetchAndPersistLogs(ctx, extractor.withAllWatchedLogs(headers), false, extractor.registeredThrough)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -223,30 +272,549 @@ func (extractor *LogExtractor) getCheckCount(recheckHeaders constants.Transforme
 	return extractor.RecheckHeaderCap
 }
 
-func (extractor *LogExtractor) updateCheckedHeaders(config event.TransformerConfig) error {
-	alreadyWatchingLog, watchingLogErr := extractor.CheckedLogsRepository.AlreadyWatchingLog(config.ContractAddresses, config.Topic)
-	if watchingLogErr != nil {
-		return watchingLogErr
+// Persists each of the transformer's address + topic0 pairs (along with any topic filters) as watched, so that headers
+// are checked for them from the transformer's starting block regardless of whether other watched logs have already
+// been checked. A transformer without addresses or an address registry watches its topic0 on any address, and one
+// with an address registry watches its topic0 once for every address registered to it.
+func (extractor *LogExtractor) watchLogs(config event.TransformerConfig) error {
+	if config.AddressRegistry != "" {
+		return extractor.watchRegistryLog(config)
+	}
+	addresses := config.ContractAddresses
+	if config.WatchesAnyAddress() {
+		addresses = []string{""}
+	}
+	for _, address := range addresses {
+		watchErr := extractor.watchLog(config, address)
+		if watchErr != nil {
+			return watchErr
+		}
+	}
+	return nil
+}
+
+// Routes logs from addresses registered since the last call to the transformers watching their registry, and stops
+// routing logs from addresses whose registrations were removed by a reorg. Logs of newly registered addresses that
+// haven't been backfilled are extracted from the headers their registry may already have been checked for.
+func (extractor *LogExtractor) refreshRegisteredAddresses(ctx context.Context) error {
+	if extractor.registeredAddresses == nil {
+		return nil
+	}
+	changes, refreshErr := extractor.registeredAddresses.refresh(extractor.AddressRegistryRepository)
+	if refreshErr != nil {
+		return fmt.Errorf("error getting registered addresses to watch: %w", refreshErr)
+	}
+	for _, registry := range extractor.registeredAddresses.registries {
+		change := changes[registry]
+		removedIDs := make(map[int64]bool, len(change.removed))
+		for _, registeredAddress := range change.removed {
+			removedIDs[registeredAddress.ID] = true
+			extractor.Chunker.RemoveRegisteredAddress(registry, registeredAddress.Address)
+		}
+		var unbackfilled []core.RegisteredAddress
+		for _, registeredAddress := range extractor.unbackfilledAddresses[registry] {
+			if !removedIDs[registeredAddress.ID] {
+				unbackfilled = append(unbackfilled, registeredAddress)
+			}
+		}
+		for _, registeredAddress := range change.added {
+			extractor.Chunker.AddRegisteredAddress(registry, registeredAddress.Address)
+			if !registeredAddress.Backfilled {
+				unbackfilled = append(unbackfilled, registeredAddress)
+			}
+		}
+		extractor.unbackfilledAddresses[registry] = unbackfilled
+
+		for _, registeredAddress := range unbackfilled {
+			for _, config := range extractor.registryConfigs[registry] {
+				saveErr := extractor.saveAbi(config, registeredAddress.Address)
+				if saveErr != nil {
+					return saveErr
+				}
+			}
+		}
+
+		backfillErr := extractor.backfillRegisteredAddresses(ctx, registry, unbackfilled)
+		if backfillErr != nil {
+			return backfillErr
+		}
+		delete(extractor.unbackfilledAddresses, registry)
+	}
+	return nil
+}
+
+// Extracts the registered addresses' logs for their registry's watched logs, from the block each was registered at
+// through the most recent header, and marks them backfilled. Headers after that are checked with the addresses
+// included.
+func (extractor *LogExtractor) backfillRegisteredAddresses(ctx context.Context, registry string, registeredAddresses []core.RegisteredAddress) error {
+	if len(registeredAddresses) < 1 {
+		return nil
 	}
-	if !alreadyWatchingLog {
-		logrus.Warnf("new event log for topic 0 %s detected, back-fill may be required for already-checked headers", config.Topic)
-		markLogWatchedErr := extractor.CheckedLogsRepository.MarkLogWatched(config.ContractAddresses, config.Topic)
-		if markLogWatchedErr != nil {
-			return markLogWatchedErr
+	var (
+		watchedLogIDs []int64
+		ids           = make([]int64, len(registeredAddresses))
+		sorted        = make([]core.RegisteredAddress, len(registeredAddresses))
+		startingBlock = int64(-1)
+	)
+	for _, watchedLog := range extractor.WatchedLogs {
+		if watchedLog.AddressRegistry == registry {
+			watchedLogIDs = append(watchedLogIDs, watchedLog.ID)
+			if startingBlock == -1 || watchedLog.StartingBlockNumber < startingBlock {
+				startingBlock = watchedLog.StartingBlockNumber
+			}
+		}
+	}
+	copy(sorted, registeredAddresses)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].BlockNumber < sorted[j].BlockNumber })
+	for i, registeredAddress := range registeredAddresses {
+		ids[i] = registeredAddress.ID
+	}
+	registered := func(_ string, blockNumber int64) []common.Address {
+		return addressesRegisteredThrough(sorted, blockNumber)
+	}
+
+	fromBlock := sorted[0].BlockNumber
+	if startingBlock > fromBlock {
+		fromBlock = startingBlock
+	}
+	toBlock, headErr := extractor.HeaderRepository.GetMostRecentHeaderBlockNumber()
+	if headErr != nil {
+		return fmt.Errorf("error getting most recent header to backfill registered addresses: %w", headErr)
+	}
+	for start := fromBlock; start <= toBlock && len(watchedLogIDs) > 0; start += HeaderChunkSize {
+		end := start + HeaderChunkSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		headers, headersErr := extractor.HeaderRepository.GetHeadersInRange(start, end)
+		if headersErr != nil {
+			return fmt.Errorf("error getting headers to backfill registered addresses: %w", headersErr)
+		}
+		uncheckedHeaders := make([]core.UncheckedHeader, len(headers))
+		for i, header := range headers {
+			uncheckedHeaders[i] = core.UncheckedHeader{Header: header, WatchedLogIDs: watchedLogIDs}
+		}
+		fetchErr := extractor.fetchAndPersistLogs(ctx, uncheckedHeaders, false, registered)
+		if fetchErr != nil {
+			return fmt.Errorf("error backfilling addresses registered to %s: %w", registry, fetchErr)
 		}
 	}
+	markErr := extractor.AddressRegistryRepository.MarkRegisteredAddressesBackfilled(ids)
+	if markErr != nil {
+		return fmt.Errorf("error marking addresses registered to %s backfilled: %w", registry, markErr)
+	}
+	return nil
+}
+
+func (extractor *LogExtractor) registeredThrough(registry string, blockNumber int64) []common.Address {
+	if extractor.registeredAddresses == nil {
+		return nil
+	}
+	return extractor.registeredAddresses.registeredThrough(registry, blockNumber)
+}
+
+// Saves the transformer's ABI to decode logs emitted by the address, skipping ABIs that can't be parsed
+func (extractor *LogExtractor) saveAbi(config event.TransformerConfig, address string) error {
+	if _, parseErr := eth.ParseAbi(config.ContractAbi); parseErr != nil {
+		logrus.Debugf("not saving abi of %s for %s: %s", config.TransformerName, address, parseErr.Error())
+		return nil
+	}
+	saveErr := extractor.AbiRepository.SaveAbi(address, config.ContractAbi)
+	if saveErr != nil {
+		return fmt.Errorf("error saving abi of %s: %w", config.TransformerName, saveErr)
+	}
+	return nil
+}
+
+// Persists the transformer's topic0 and topic filters on the address as watched, from the starting block. An empty
+// address watches logs emitted by any address.
+func (extractor *LogExtractor) watchLog(config event.TransformerConfig, address string) error {
+	topicFilters, topicFilterStrings := watchedTopicFilters(config)
+	id, watchErr := extractor.CheckedLogsRepository.WatchLog(address, config.Topic, topicFilterStrings)
+	if watchErr != nil {
+		return fmt.Errorf("error watching logs for address %q and topic0 %s: %w", address, config.Topic, watchErr)
+	}
+	extractor.WatchedLogs = append(extractor.WatchedLogs, core.WatchedLog{
+		ID:                  id,
+		Address:             common.HexToAddress(address),
+		AnyAddress:          address == "",
+		TopicZero:           common.HexToHash(config.Topic),
+		TopicFilters:        topicFilters,
+		StartingBlockNumber: config.StartingBlockNumber,
+		EndingBlockNumber:   config.EndingBlockNumber,
+	})
+	return nil
+}
+
+// Persists the transformer's topic0 and topic filters on its address registry as watched, from the starting block
+func (extractor *LogExtractor) watchRegistryLog(config event.TransformerConfig) error {
+	topicFilters, topicFilterStrings := watchedTopicFilters(config)
+	id, watchErr := extractor.CheckedLogsRepository.WatchRegistryLog(config.AddressRegistry, config.Topic, topicFilterStrings)
+	if watchErr != nil {
+		return fmt.Errorf("error watching logs for address registry %q and topic0 %s: %w", config.AddressRegistry, config.Topic, watchErr)
+	}
+	extractor.WatchedLogs = append(extractor.WatchedLogs, core.WatchedLog{
+		ID:                  id,
+		AddressRegistry:     config.AddressRegistry,
+		TopicZero:           common.HexToHash(config.Topic),
+		TopicFilters:        topicFilters,
+		StartingBlockNumber: config.StartingBlockNumber,
+		EndingBlockNumber:   config.EndingBlockNumber,
+	})
 	return nil
 }
 
-func (extractor *LogExtractor) fetchAndPersistLogsForHeader(header core.Header) error {
-	logs, fetchLogsErr := extractor.Fetcher.FetchLogs(extractor.Addresses, extractor.Topics, header)
+// Returns the transformer's normalized topic filters, along with their hex strings to persist
+func watchedTopicFilters(config event.TransformerConfig) ([][]common.Hash, [][]string) {
+	topicFilters := normalizeTopicFilters(config.TopicFilters())
+	var topicFilterStrings [][]string
+	for _, filter := range topicFilters {
+		var filterStrings []string
+		for _, topic := range filter {
+			filterStrings = append(filterStrings, topic.Hex())
+		}
+		topicFilterStrings = append(topicFilterStrings, filterStrings)
+	}
+	return topicFilters, topicFilterStrings
+}
+
+// Sorts and de-duplicates each topic filter, so that equivalent filters are watched (and fetched) together
+func normalizeTopicFilters(topicFilters [][]common.Hash) [][]common.Hash {
+	if len(topicFilters) == 0 {
+		return nil
+	}
+	result := make([][]common.Hash, len(topicFilters))
+	for i, filter := range topicFilters {
+		seen := make(map[common.Hash]bool, len(filter))
+		for _, topic := range filter {
+			if !seen[topic] {
+				seen[topic] = true
+				result[i] = append(result[i], topic)
+			}
+		}
+		sort.Slice(result[i], func(j, k int) bool {
+			return result[i][j].Hex() < result[i][k].Hex()
+		})
+	}
+	return result
+}
+
+// logFilter is a set of addresses and positional topics whose logs are fetched with a single query
+type logFilter struct {
+	addresses []common.Address
+	topics    [][]common.Hash
+}
+
+// Returns a filter for each distinct set of topic filters among the given watched logs, matching the distinct
+// addresses and topic0s of the watched logs sharing it, to fetch only the logs a header is unchecked for. Watched logs
+// on any address get filters of their own, without addresses, and watched logs on an address registry get filters
+// matching the addresses registered to it through the block. Filters are split so that none has more than
+// MaxFilterAddresses addresses.
+func (extractor *LogExtractor) filtersFor(watchedLogIDs []int64, blockNumber int64, registered registeredAddressesFunc) []logFilter {
+	ids := make(map[int64]bool, len(watchedLogIDs))
+	for _, id := range watchedLogIDs {
+		ids[id] = true
+	}
+
+	type filterGroup struct {
+		filter      logFilter
+		seenAddress map[common.Address]bool
+		seenTopic   map[common.Hash]bool
+	}
+	var (
+		groups     []*filterGroup
+		groupByKey = make(map[string]*filterGroup)
+	)
+	for _, watchedLog := range extractor.WatchedLogs {
+		if !ids[watchedLog.ID] {
+			continue
+		}
+		key := fmt.Sprint(watchedLog.AnyAddress, watchedLog.AddressRegistry, watchedLog.TopicFilters)
+		group, ok := groupByKey[key]
+		if !ok {
+			group = &filterGroup{
+				filter:      logFilter{topics: append([][]common.Hash{nil}, watchedLog.TopicFilters...)},
+				seenAddress: make(map[common.Address]bool),
+				seenTopic:   make(map[common.Hash]bool),
+			}
+			if watchedLog.AddressRegistry != "" {
+				group.filter.addresses = registered(watchedLog.AddressRegistry, blockNumber)
+				if len(group.filter.addresses) < 1 {
+					continue
+				}
+			}
+			groupByKey[key] = group
+			groups = append(groups, group)
+		}
+		if !watchedLog.AnyAddress && watchedLog.AddressRegistry == "" && !group.seenAddress[watchedLog.Address] {
+			group.seenAddress[watchedLog.Address] = true
+			group.filter.addresses = append(group.filter.addresses, watchedLog.Address)
+		}
+		if !group.seenTopic[watchedLog.TopicZero] {
+			group.seenTopic[watchedLog.TopicZero] = true
+			group.filter.topics[0] = append(group.filter.topics[0], watchedLog.TopicZero)
+		}
+	}
+
+	var filters []logFilter
+	for _, group := range groups {
+		addresses := group.filter.addresses
+		for len(addresses) > MaxFilterAddresses {
+			filters = append(filters, logFilter{addresses: addresses[:MaxFilterAddresses], topics: group.filter.topics})
+			addresses = addresses[MaxFilterAddresses:]
+		}
+		filters = append(filters, logFilter{addresses: addresses, topics: group.filter.topics})
+	}
+	return filters
+}
+
+// Reports whether the node would return the log for the filter
+func (filter logFilter) matches(log types.Log) bool {
+	if len(filter.addresses) > 0 && !containsAddress(filter.addresses, log.Address) {
+		return false
+	}
+	for i, topics := range filter.topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) || !containsHash(topics, log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetches logs matching each of the filters, dropping logs matched by more than one of them
+func fetchLogsForFilters(filters []logFilter, fetch func(addresses []common.Address, topics [][]common.Hash) ([]types.Log, error)) ([]types.Log, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	if len(filters) == 1 {
+		return fetch(filters[0].addresses, filters[0].topics)
+	}
+
+	type logKey struct {
+		blockHash common.Hash
+		index     uint
+	}
+	var (
+		result []types.Log
+		seen   = make(map[logKey]bool)
+	)
+	for _, filter := range filters {
+		logs, err := fetch(filter.addresses, filter.topics)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			key := logKey{blockHash: log.BlockHash, index: log.Index}
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, log)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (extractor *LogExtractor) allWatchedLogIDs() []int64 {
+	watchedLogIDs := make([]int64, len(extractor.WatchedLogs))
+	for i, watchedLog := range extractor.WatchedLogs {
+		watchedLogIDs[i] = watchedLog.ID
+	}
+	return watchedLogIDs
+}
+
+func (extractor *LogExtractor) withAllWatchedLogs(headers []core.Header) []core.UncheckedHeader {
+	watchedLogIDs := extractor.allWatchedLogIDs()
+	result := make([]core.UncheckedHeader, len(headers))
+	for i, header := range headers {
+		result[i] = core.UncheckedHeader{Header: header, WatchedLogIDs: watchedLogIDs}
+	}
+	return result
+}
+
+// Fetches and persists logs for the given headers, matching the addresses registered to watched address registries
+// through each header's block, and optionally marks the headers checked
+func (extractor *LogExtractor) fetchAndPersistLogs(ctx context.Context, headers []core.UncheckedHeader, markChecked bool, registered registeredAddressesFunc) error {
+	if extractor.BlockRangeSize > 1 {
+		return extractor.fetchAndPersistLogsInRanges(ctx, headers, markChecked, registered)
+	}
+
+	for _, uncheckedHeader := range headers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		header := uncheckedHeader.Header
+		filters := extractor.filtersFor(uncheckedHeader.WatchedLogIDs, header.BlockNumber, registered)
+		err := extractor.fetchAndPersistLogsForHeader(ctx, header, filters)
+		if err != nil {
+			return fmt.Errorf("error fetching and persisting logs for header with id %d: %w", header.Id, err)
+		}
+		if !markChecked {
+			continue
+		}
+
+		markHeaderCheckedErr := extractor.CheckedLogsRepository.MarkHeaderChecked(header.Id, uncheckedHeader.WatchedLogIDs)
+		if markHeaderCheckedErr != nil {
+			logError("error marking header checked: %s", markHeaderCheckedErr, header)
+			return markHeaderCheckedErr
+		}
+	}
+	return nil
+}
+
+func (extractor *LogExtractor) fetchAndPersistLogsForHeader(ctx context.Context, header core.Header, filters []logFilter) error {
+	logs, fetchLogsErr := fetchLogsForFilters(filters, func(addresses []common.Address, topics [][]common.Hash) ([]types.Log, error) {
+		return extractor.Fetcher.FetchLogs(ctx, addresses, topics, header)
+	})
 	if fetchLogsErr != nil {
 		logError("error fetching logs for header: %s", fetchLogsErr, header)
 		return fmt.Errorf("error fetching logs for block %d: %w", header.BlockNumber, fetchLogsErr)
 	}
 
+	return extractor.persistLogsForHeader(ctx, header, logs)
+}
+
+// Fetches logs for the given headers over block ranges of at most BlockRangeSize blocks,
+// halving the range whenever the node reports too many results. Headers are only persisted
+// (and optionally marked checked) if every log returned for their block number matches their hash.
+// Logs are fetched for every watched log any header in a range is unchecked for, so each header only
+// persists the logs matching the watched logs it is unchecked for.
+func (extractor *LogExtractor) fetchAndPersistLogsInRanges(ctx context.Context, headers []core.UncheckedHeader, markChecked bool, registered registeredAddressesFunc) error {
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Header.BlockNumber < headers[j].Header.BlockNumber
+	})
+
+	rangeSize := extractor.BlockRangeSize
+	for i := 0; i < len(headers); {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		startingBlock := headers[i].Header.BlockNumber
+		j := i
+		var watchedLogIDs []int64
+		for j < len(headers) && headers[j].Header.BlockNumber < startingBlock+rangeSize {
+			watchedLogIDs = append(watchedLogIDs, headers[j].WatchedLogIDs...)
+			j++
+		}
+		headersInRange := headers[i:j]
+		endingBlock := headersInRange[len(headersInRange)-1].Header.BlockNumber
+
+		logs, fetchLogsErr := fetchLogsForFilters(extractor.filtersFor(watchedLogIDs, endingBlock, registered), func(addresses []common.Address, topics [][]common.Hash) ([]types.Log, error) {
+			return extractor.Fetcher.FetchLogsInRange(ctx, addresses, topics, startingBlock, endingBlock)
+		})
+		if fetchLogsErr != nil {
+			if errors.Is(fetchLogsErr, fetcher.ErrTooManyResults) && rangeSize > 1 {
+				rangeSize = rangeSize / 2
+				logrus.Debugf("too many logs in blocks %d to %d, reducing block range to %d", startingBlock, endingBlock, rangeSize)
+				continue
+			}
+			return fmt.Errorf("error fetching logs for blocks %d to %d: %w", startingBlock, endingBlock, fetchLogsErr)
+		}
+
+		persistErr := extractor.persistLogsForHeaders(ctx, headersInRange, logs, markChecked, registered)
+		if persistErr != nil {
+			return persistErr
+		}
+
+		if rangeSize < extractor.BlockRangeSize {
+			rangeSize = rangeSize * 2
+			if rangeSize > extractor.BlockRangeSize {
+				rangeSize = extractor.BlockRangeSize
+			}
+		}
+		i = j
+	}
+	return nil
+}
+
+func (extractor *LogExtractor) persistLogsForHeaders(ctx context.Context, headers []core.UncheckedHeader, logs []types.Log, markChecked bool, registered registeredAddressesFunc) error {
+	headersByHash := make(map[common.Hash]core.Header, len(headers))
+	for _, uncheckedHeader := range headers {
+		headersByHash[common.HexToHash(uncheckedHeader.Header.Hash)] = uncheckedHeader.Header
+	}
+
+	logsByHeaderID := make(map[int64][]types.Log)
+	mismatchedBlockNumbers := make(map[int64]bool)
+	for _, log := range logs {
+		header, ok := headersByHash[log.BlockHash]
+		if !ok {
+			logrus.WithFields(logrus.Fields{
+				"blockNumber": log.BlockNumber,
+				"blockHash":   log.BlockHash.Hex(),
+				"txHash":      log.TxHash.Hex(),
+			}).Warn("rejecting log with block hash that doesn't match any persisted header")
+			mismatchedBlockNumbers[int64(log.BlockNumber)] = true
+			continue
+		}
+		logsByHeaderID[header.Id] = append(logsByHeaderID[header.Id], log)
+	}
+
+	for _, uncheckedHeader := range headers {
+		header := uncheckedHeader.Header
+		if mismatchedBlockNumbers[header.BlockNumber] {
+			logError("not persisting logs for header: %s", ErrHeaderHashMismatch, header)
+			continue
+		}
+
+		headerLogs := extractor.matchingLogs(logsByHeaderID[header.Id], uncheckedHeader.WatchedLogIDs, header.BlockNumber, registered)
+		persistErr := extractor.persistLogsForHeader(ctx, header, headerLogs)
+		if persistErr != nil {
+			return fmt.Errorf("error persisting logs for header with id %d: %w", header.Id, persistErr)
+		}
+
+		if markChecked {
+			markHeaderCheckedErr := extractor.CheckedLogsRepository.MarkHeaderChecked(header.Id, uncheckedHeader.WatchedLogIDs)
+			if markHeaderCheckedErr != nil {
+				logError("error marking header checked: %s", markHeaderCheckedErr, header)
+				return markHeaderCheckedErr
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the logs matching the given watched logs at the block, dropping logs of watched logs that the header has
+// already been checked for or that start after the block
+func (extractor *LogExtractor) matchingLogs(logs []types.Log, watchedLogIDs []int64, blockNumber int64, registered registeredAddressesFunc) []types.Log {
+	if len(logs) == 0 {
+		return nil
+	}
+	filters := extractor.filtersFor(watchedLogIDs, blockNumber, registered)
+	var result []types.Log
+	for _, log := range logs {
+		for _, filter := range filters {
+			if filter.matches(log) {
+				result = append(result, log)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (extractor *LogExtractor) persistLogsForHeader(ctx context.Context, header core.Header, logs []types.Log) error {
 	if len(logs) > 0 {
-		transactionsSyncErr := extractor.Syncer.SyncTransactions(header.Id, logs)
+		transactionsSyncErr := extractor.Syncer.SyncTransactions(ctx, header.Id, logs)
 		if transactionsSyncErr != nil {
 			logError("error syncing transactions: %s", transactionsSyncErr, header)
 			return fmt.Errorf("error syncing transactions for block %d: %w", header.BlockNumber, transactionsSyncErr)
@@ -257,6 +825,17 @@ func (extractor *LogExtractor) fetchAndPersistLogsForHeader(header core.Header)
 			logError("error persisting logs: %s", createLogsErr, header)
 			return fmt.Errorf("error persisting logs for block %d: %w", header.BlockNumber, createLogsErr)
 		}
+		extractor.recordExtractedLogs(logs)
 	}
 	return nil
 }
+
+func (extractor *LogExtractor) recordExtractedLogs(logs []types.Log) {
+	eventLogs := make([]core.EventLog, len(logs))
+	for i, log := range logs {
+		eventLogs[i] = core.EventLog{Log: log}
+	}
+	for transformerName, transformerLogs := range extractor.Chunker.ChunkLogs(eventLogs) {
+		metrics.LogsExtracted.WithLabelValues(transformerName).Add(float64(len(transformerLogs)))
+	}
+}
@@ -17,14 +17,20 @@
 package logs_test
 
 import (
+	"context"
+	"errors"
+	"math/big"
 	"math/rand"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/libraries/shared/chunker"
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
+	"github.com/makerdao/vulcanizedb/libraries/shared/fetcher"
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
+	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	. "github.com/onsi/ginkgo"
@@ -33,22 +39,23 @@ import (
 
 var _ = Describe("Log extractor", func() {
 	var (
-		checkedHeadersRepository *fakes.MockCheckedHeadersRepository
+		abiRepository            *fakes.MockAbiRepository
 		checkedLogsRepository    *fakes.MockCheckedLogsRepository
 		extractor                *logs.LogExtractor
 		defaultEndingBlockNumber = int64(-1)
 	)
 
 	BeforeEach(func() {
-		checkedHeadersRepository = &fakes.MockCheckedHeadersRepository{}
+		abiRepository = &fakes.MockAbiRepository{}
 		checkedLogsRepository = &fakes.MockCheckedLogsRepository{}
 		extractor = &logs.LogExtractor{
-			CheckedHeadersRepository: checkedHeadersRepository,
-			CheckedLogsRepository:    checkedLogsRepository,
-			Fetcher:                  &mocks.MockLogFetcher{},
-			LogRepository:            &fakes.MockEventLogRepository{},
-			Syncer:                   &fakes.MockTransactionSyncer{},
-			RecheckHeaderCap:         constants.RecheckHeaderCap,
+			AbiRepository:         abiRepository,
+			CheckedLogsRepository: checkedLogsRepository,
+			Chunker:               chunker.NewLogChunker(),
+			Fetcher:               &mocks.MockLogFetcher{},
+			LogRepository:         &fakes.MockEventLogRepository{},
+			Syncer:                &fakes.MockTransactionSyncer{},
+			RecheckHeaderCap:      constants.RecheckHeaderCap,
 		}
 	})
 
@@ -121,8 +128,77 @@ var _ = Describe("Log extractor", func() {
 			Expect(extractor.Topics).To(Equal([]common.Hash{common.HexToHash(topic)}))
 		})
 
-		It("returns error if checking whether log has been checked returns error", func() {
-			checkedLogsRepository.AlreadyWatchingLogError = fakes.FakeError
+		It("persists that each of the transformer's address + topic0 is watched", func() {
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAddresses = []string{"0xA", "0xB"}
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedLogsRepository.WatchLogAddresses).To(Equal(config.ContractAddresses))
+			Expect(checkedLogsRepository.WatchLogTopicZeros).To(Equal([]string{config.Topic, config.Topic}))
+		})
+
+		It("adds watched logs with the transformer's block range", func() {
+			config := getTransformerConfig(rand.Int63(), rand.Int63())
+			config.ContractAddresses = []string{"0xA", "0xB"}
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extractor.WatchedLogs).To(Equal([]core.WatchedLog{{
+				ID:                  1,
+				Address:             common.HexToAddress("0xA"),
+				TopicZero:           common.HexToHash(config.Topic),
+				StartingBlockNumber: config.StartingBlockNumber,
+				EndingBlockNumber:   config.EndingBlockNumber,
+			}, {
+				ID:                  2,
+				Address:             common.HexToAddress("0xB"),
+				TopicZero:           common.HexToHash(config.Topic),
+				StartingBlockNumber: config.StartingBlockNumber,
+				EndingBlockNumber:   config.EndingBlockNumber,
+			}}))
+		})
+
+		It("watches the transformer's topic0 on any address if it has no addresses", func() {
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAddresses = nil
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedLogsRepository.WatchLogAddresses).To(Equal([]string{""}))
+			Expect(extractor.WatchedLogs).To(Equal([]core.WatchedLog{{
+				ID:                  1,
+				AnyAddress:          true,
+				TopicZero:           common.HexToHash(config.Topic),
+				StartingBlockNumber: config.StartingBlockNumber,
+				EndingBlockNumber:   config.EndingBlockNumber,
+			}}))
+		})
+
+		It("watches logs on the address registry for transformers with an address registry", func() {
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAddresses = nil
+			config.AddressRegistry = "pairs"
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedLogsRepository.WatchLogAddresses).To(BeEmpty())
+			Expect(checkedLogsRepository.WatchRegistryLogRegistries).To(Equal([]string{"pairs"}))
+			Expect(extractor.WatchedLogs).To(Equal([]core.WatchedLog{{
+				ID:                  1,
+				AddressRegistry:     "pairs",
+				TopicZero:           common.HexToHash(config.Topic),
+				StartingBlockNumber: config.StartingBlockNumber,
+				EndingBlockNumber:   config.EndingBlockNumber,
+			}}))
+		})
+
+		It("returns error if watching log returns error", func() {
+			checkedLogsRepository.WatchLogError = fakes.FakeError
 
 			err := extractor.AddTransformerConfig(getTransformerConfig(rand.Int63(), defaultEndingBlockNumber))
 
@@ -130,83 +206,295 @@ var _ = Describe("Log extractor", func() {
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
 
-		Describe("when log has not previously been checked", func() {
+		It("saves the transformer's abi for each of its addresses", func() {
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAddresses = []string{"0xA", "0xB"}
+			config.ContractAbi = test_data.TransferAbi
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(abiRepository.SavedAbis).To(Equal(map[string]string{
+				"0xA": test_data.TransferAbi,
+				"0xB": test_data.TransferAbi,
+			}))
+		})
+
+		It("doesn't save abis that can't be parsed", func() {
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAbi = "not an abi"
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(abiRepository.SavedAbis).To(BeEmpty())
+		})
+
+		It("returns error if saving the abi fails", func() {
+			abiRepository.SaveAbiError = fakes.FakeError
+			config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			config.ContractAbi = test_data.TransferAbi
+
+			err := extractor.AddTransformerConfig(config)
+
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, fakes.FakeError)).To(BeTrue())
+		})
+	})
+
+	Describe("ExtractLogs", func() {
+		It("returns error if no watched logs configured", func() {
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(logs.ErrNoWatchedLogs))
+		})
+
+		Describe("when transformers watch an address registry", func() {
+			var (
+				config                    event.TransformerConfig
+				addressRegistryRepository *fakes.MockAddressRegistryRepository
+				headerRepository          *fakes.MockHeaderRepository
+				mockLogFetcher            *mocks.MockLogFetcher
+				addressA                  = common.HexToAddress("0xA")
+				addressB                  = common.HexToAddress("0xB")
+			)
+
 			BeforeEach(func() {
-				checkedLogsRepository.AlreadyWatchingLogReturn = false
+				config = getTransformerConfig(100, defaultEndingBlockNumber)
+				config.ContractAddresses = nil
+				config.AddressRegistry = "pairs"
+				addressRegistryRepository = &fakes.MockAddressRegistryRepository{}
+				extractor.AddressRegistryRepository = addressRegistryRepository
+				headerRepository = &fakes.MockHeaderRepository{}
+				extractor.HeaderRepository = headerRepository
+				mockLogFetcher = &mocks.MockLogFetcher{}
+				extractor.Fetcher = mockLogFetcher
+				Expect(extractor.AddTransformerConfig(config)).To(Succeed())
 			})
 
-			It("persists that tranformer's log is watched", func() {
-				config := getTransformerConfig(rand.Int63(), defaultEndingBlockNumber)
+			It("gets headers unchecked for the registry's watched log before any addresses are registered", func() {
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
-				err := extractor.AddTransformerConfig(config)
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(addressRegistryRepository.GetRegisteredAddressesRegistries).To(Equal([]string{"pairs"}))
+				Expect(checkedLogsRepository.UncheckedHeadersWatchedLogs).To(Equal(extractor.WatchedLogs))
+			})
+
+			It("fetches logs from the addresses registered to the registry through each header's block", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 50, Backfilled: true},
+					{ID: 2, Registry: "pairs", Address: addressB.Hex(), BlockNumber: 200, Backfilled: true},
+					{ID: 3, Registry: "markets", Address: "0xC", BlockNumber: 100, Backfilled: true},
+				}
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{
+					Header:        core.Header{Id: 1, BlockNumber: 150},
+					WatchedLogIDs: []int64{1},
+				}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(checkedLogsRepository.MarkLogWatchedAddresses).To(Equal(config.ContractAddresses))
-				Expect(checkedLogsRepository.MarkLogWatchedTopicZero).To(Equal(config.Topic))
+				Expect(mockLogFetcher.AddressesPerFetch).To(Equal([][]common.Address{{addressA}}))
+				Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(Equal([]int64{1}))
 			})
 
-			It("returns error if marking logs watched returns error", func() {
-				checkedLogsRepository.MarkLogWatchedError = fakes.FakeError
+			It("marks headers checked without fetching logs if no addresses were registered through their block", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 200, Backfilled: true},
+				}
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{
+					Header:        core.Header{Id: 1, BlockNumber: 150},
+					WatchedLogIDs: []int64{1},
+				}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
-				err := extractor.AddTransformerConfig(getTransformerConfig(rand.Int63(), defaultEndingBlockNumber))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.FetchCalled).To(BeFalse())
+				Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(Equal([]int64{1}))
+			})
+
+			It("splits the registered addresses across filters of at most MaxFilterAddresses addresses", func() {
+				maxFilterAddresses := logs.MaxFilterAddresses
+				logs.MaxFilterAddresses = 1
+				defer func() { logs.MaxFilterAddresses = maxFilterAddresses }()
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 50, Backfilled: true},
+					{ID: 2, Registry: "pairs", Address: addressB.Hex(), BlockNumber: 50, Backfilled: true},
+				}
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{
+					Header:        core.Header{Id: 1, BlockNumber: 150},
+					WatchedLogIDs: []int64{1},
+				}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.AddressesPerFetch).To(Equal([][]common.Address{{addressA}, {addressB}}))
+			})
+
+			It("stops fetching logs from addresses whose registrations were removed by a reorg", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 50, Backfilled: true},
+					{ID: 2, Registry: "pairs", Address: addressB.Hex(), BlockNumber: 50, Backfilled: true},
+				}
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{
+					Header:        core.Header{Id: 1, BlockNumber: 150},
+					WatchedLogIDs: []int64{1},
+				}}
+				Expect(extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)).To(Succeed())
+				addressRegistryRepository.RegisteredAddresses = addressRegistryRepository.RegisteredAddresses[:1]
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.AddressesPerFetch).To(Equal([][]common.Address{{addressA, addressB}, {addressA}}))
+			})
+
+			It("only loads addresses registered since the last call", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 50, Backfilled: true},
+				}
+				Expect(extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)).To(MatchError(logs.ErrNoUncheckedHeaders))
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(addressRegistryRepository.GetRegisteredAddressesAfterIDs).To(Equal([]int64{0, 1}))
+			})
+
+			It("backfills logs of newly registered addresses through the most recent header", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 150},
+				}
+				headerRepository.MostRecentHeaderBlockNumber = 300
+				headerRepository.AllHeaders = []core.Header{{Id: 5, BlockNumber: 200}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(headerRepository.GetHeadersInRangeStartingBlocks).To(Equal([]int64{150}))
+				Expect(headerRepository.GetHeadersInRangeEndingBlocks).To(Equal([]int64{300}))
+				Expect(mockLogFetcher.AddressesPerFetch).To(Equal([][]common.Address{{addressA}}))
+				Expect(mockLogFetcher.MissingHeader.Id).To(Equal(int64(5)))
+				Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(BeEmpty())
+				Expect(addressRegistryRepository.BackfilledIDs).To(Equal([]int64{1}))
+			})
+
+			It("backfills from the transformer's starting block if the address was registered before it", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 50},
+				}
+				headerRepository.MostRecentHeaderBlockNumber = 300
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(headerRepository.GetHeadersInRangeStartingBlocks).To(Equal([]int64{config.StartingBlockNumber}))
+			})
+
+			It("doesn't backfill addresses that were already backfilled", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 150, Backfilled: true},
+				}
+				headerRepository.MostRecentHeaderBlockNumber = 300
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(headerRepository.GetHeadersInRangeStartingBlocks).To(BeEmpty())
+				Expect(addressRegistryRepository.BackfilledIDs).To(BeEmpty())
+			})
+
+			It("retries backfilling on the next call if it fails", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 150},
+				}
+				headerRepository.MostRecentHeaderBlockNumber = 300
+				headerRepository.AllHeaders = []core.Header{{Id: 5, BlockNumber: 200}}
+				mockLogFetcher.ReturnError = fakes.FakeError
+				Expect(extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)).To(MatchError(fakes.FakeError))
+				mockLogFetcher.ReturnError = nil
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(headerRepository.GetHeadersInRangeStartingBlocks).To(Equal([]int64{150, 150}))
+				Expect(addressRegistryRepository.BackfilledIDs).To(Equal([]int64{1}))
+			})
+
+			It("saves the abis of transformers watching newly registered addresses", func() {
+				abiConfig := getTransformerConfig(100, defaultEndingBlockNumber)
+				abiConfig.TransformerName = "swap"
+				abiConfig.ContractAddresses = nil
+				abiConfig.AddressRegistry = "pairs"
+				abiConfig.ContractAbi = test_data.TransferAbi
+				Expect(extractor.AddTransformerConfig(abiConfig)).To(Succeed())
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{{ID: 1, Registry: "pairs", Address: "0xA"}}
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
+				Expect(abiRepository.SavedAbis).To(Equal(map[string]string{"0xA": test_data.TransferAbi}))
+			})
+
+			It("returns error if getting registered addresses fails", func() {
+				addressRegistryRepository.GetRegisteredAddressesError = fakes.FakeError
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
-				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
 			})
-		})
-	})
 
-	Describe("ExtractLogs", func() {
-		It("returns error if no watched addresses configured", func() {
-			err := extractor.ExtractLogs(constants.HeaderUnchecked)
+			It("returns error if marking registered addresses backfilled fails", func() {
+				addressRegistryRepository.RegisteredAddresses = []core.RegisteredAddress{
+					{ID: 1, Registry: "pairs", Address: addressA.Hex(), BlockNumber: 150},
+				}
+				addressRegistryRepository.MarkBackfilledError = fakes.FakeError
 
-			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError(logs.ErrNoWatchedAddresses))
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).To(MatchError(fakes.FakeError))
+			})
 		})
 
 		Describe("when checking unchecked headers", func() {
-			It("gets headers since configured starting block with check_count < 1", func() {
-				mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
-				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{}}
-				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+			It("gets headers unchecked for each watched log with check_count < 1", func() {
+				addUncheckedHeader(extractor)
 				startingBlockNumber := rand.Int63()
 				addErr := extractor.AddTransformerConfig(getTransformerConfig(startingBlockNumber, defaultEndingBlockNumber))
 				Expect(addErr).NotTo(HaveOccurred())
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersStartingBlockNumber).To(Equal(startingBlockNumber))
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersEndingBlockNumber).To(Equal(defaultEndingBlockNumber))
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersCheckCount).To(Equal(int64(1)))
+				Expect(checkedLogsRepository.UncheckedHeadersWatchedLogs).To(Equal(extractor.WatchedLogs))
+				Expect(checkedLogsRepository.UncheckedHeadersWatchedLogs[0].StartingBlockNumber).To(Equal(startingBlockNumber))
+				Expect(checkedLogsRepository.UncheckedHeadersWatchedLogs[0].EndingBlockNumber).To(Equal(defaultEndingBlockNumber))
+				Expect(checkedLogsRepository.UncheckedHeadersCheckCount).To(Equal(int64(1)))
+				Expect(checkedLogsRepository.UncheckedHeadersLimit).To(Equal(constants.UncheckedHeadersLimit))
 			})
 		})
 
 		Describe("when rechecking headers", func() {
-			It("gets headers since configured starting block with check_count < RecheckHeaderCap", func() {
-				mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
-				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{}}
-				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
-				startingBlockNumber := rand.Int63()
-				addErr := extractor.AddTransformerConfig(getTransformerConfig(startingBlockNumber, defaultEndingBlockNumber))
-				Expect(addErr).NotTo(HaveOccurred())
+			It("gets headers unchecked for each watched log with check_count < RecheckHeaderCap", func() {
+				addUncheckedHeader(extractor)
+				addTransformerConfig(extractor)
 
-				err := extractor.ExtractLogs(constants.HeaderRecheck)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderRecheck)
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersStartingBlockNumber).To(Equal(startingBlockNumber))
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersEndingBlockNumber).To(Equal(defaultEndingBlockNumber))
-				Expect(mockCheckedHeadersRepository.UncheckedHeadersCheckCount).To(Equal(constants.RecheckHeaderCap))
+				Expect(checkedLogsRepository.UncheckedHeadersWatchedLogs).To(Equal(extractor.WatchedLogs))
+				Expect(checkedLogsRepository.UncheckedHeadersCheckCount).To(Equal(constants.RecheckHeaderCap))
 			})
 		})
 
 		It("returns error if getting unchecked headers fails", func() {
 			addTransformerConfig(extractor)
-			mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
-			mockCheckedHeadersRepository.UncheckedHeadersReturnError = fakes.FakeError
-			extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+			checkedLogsRepository.UncheckedHeadersReturnError = fakes.FakeError
 
-			err := extractor.ExtractLogs(constants.HeaderUnchecked)
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -218,7 +506,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				_ = extractor.ExtractLogs(constants.HeaderUnchecked)
+				_ = extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(mockLogFetcher.FetchCalled).To(BeFalse())
 			})
@@ -228,7 +516,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(MatchError(logs.ErrNoUncheckedHeaders))
 			})
@@ -247,16 +535,154 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher := &mocks.MockLogFetcher{}
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockLogFetcher.FetchCalled).To(BeTrue())
-				expectedTopics := []common.Hash{common.HexToHash(config.Topic)}
+				expectedTopics := [][]common.Hash{{common.HexToHash(config.Topic)}}
 				Expect(mockLogFetcher.Topics).To(Equal(expectedTopics))
 				expectedAddresses := event.HexStringsToAddresses(config.ContractAddresses)
 				Expect(mockLogFetcher.ContractAddresses).To(Equal(expectedAddresses))
 			})
 
+			It("only fetches logs for the watched logs the header is unchecked for", func() {
+				addTransformerConfig(extractor)
+				newConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0xB",
+					StartingBlockNumber: rand.Int63(),
+				}
+				addTransformerErr := extractor.AddTransformerConfig(newConfig)
+				Expect(addTransformerErr).NotTo(HaveOccurred())
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{2}}}
+				mockLogFetcher := &mocks.MockLogFetcher{}
+				extractor.Fetcher = mockLogFetcher
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{common.HexToAddress("0xA")}))
+				Expect(mockLogFetcher.Topics).To(Equal([][]common.Hash{{common.HexToHash("0xB")}}))
+			})
+
+			It("fetches logs for watched logs sharing topic filters with a single query", func() {
+				configA := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					Topic2:              []string{"0x3", "0x2"},
+					StartingBlockNumber: rand.Int63(),
+				}
+				configB := event.TransformerConfig{
+					ContractAddresses:   []string{"0xB"},
+					Topic:               "0x4",
+					Topic2:              []string{"0x2", "0x3"},
+					StartingBlockNumber: rand.Int63(),
+				}
+				Expect(extractor.AddTransformerConfig(configA)).To(Succeed())
+				Expect(extractor.AddTransformerConfig(configB)).To(Succeed())
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{1, 2}}}
+				mockLogFetcher := &mocks.MockLogFetcher{}
+				extractor.Fetcher = mockLogFetcher
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.ContractAddresses).To(Equal([]common.Address{common.HexToAddress("0xA"), common.HexToAddress("0xB")}))
+				Expect(mockLogFetcher.TopicsPerFetch).To(Equal([][][]common.Hash{{
+					{common.HexToHash("0x1"), common.HexToHash("0x4")},
+					nil,
+					{common.HexToHash("0x2"), common.HexToHash("0x3")},
+				}}))
+			})
+
+			It("fetches logs for watched logs with different topic filters with separate queries", func() {
+				unfilteredConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					StartingBlockNumber: rand.Int63(),
+				}
+				filteredConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					Topic1:              []string{"0x2"},
+					StartingBlockNumber: rand.Int63(),
+				}
+				Expect(extractor.AddTransformerConfig(unfilteredConfig)).To(Succeed())
+				Expect(extractor.AddTransformerConfig(filteredConfig)).To(Succeed())
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{1, 2}}}
+				mockLogFetcher := &mocks.MockLogFetcher{}
+				extractor.Fetcher = mockLogFetcher
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkedLogsRepository.WatchLogTopicFilters).To(Equal([][][]string{
+					nil,
+					{{common.HexToHash("0x2").Hex()}},
+				}))
+				Expect(mockLogFetcher.TopicsPerFetch).To(Equal([][][]common.Hash{
+					{{common.HexToHash("0x1")}},
+					{{common.HexToHash("0x1")}, {common.HexToHash("0x2")}},
+				}))
+			})
+
+			It("fetches logs for watched logs on any address without an address filter", func() {
+				addressConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					StartingBlockNumber: rand.Int63(),
+				}
+				anyAddressConfig := event.TransformerConfig{
+					Topic:               "0x2",
+					StartingBlockNumber: rand.Int63(),
+				}
+				Expect(extractor.AddTransformerConfig(addressConfig)).To(Succeed())
+				Expect(extractor.AddTransformerConfig(anyAddressConfig)).To(Succeed())
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{1, 2}}}
+				mockLogFetcher := &mocks.MockLogFetcher{}
+				extractor.Fetcher = mockLogFetcher
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogFetcher.TopicsPerFetch).To(Equal([][][]common.Hash{
+					{{common.HexToHash("0x1")}},
+					{{common.HexToHash("0x2")}},
+				}))
+				Expect(mockLogFetcher.ContractAddresses).To(BeNil())
+			})
+
+			It("persists logs matched by more than one query once", func() {
+				unfilteredConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					StartingBlockNumber: rand.Int63(),
+				}
+				filteredConfig := event.TransformerConfig{
+					ContractAddresses:   []string{"0xA"},
+					Topic:               "0x1",
+					Topic1:              []string{"0x2"},
+					StartingBlockNumber: rand.Int63(),
+				}
+				Expect(extractor.AddTransformerConfig(unfilteredConfig)).To(Succeed())
+				Expect(extractor.AddTransformerConfig(filteredConfig)).To(Succeed())
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{1, 2}}}
+				fetchedLog := types.Log{
+					Address: common.HexToAddress("0xA"),
+					Topics:  []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")},
+					Index:   1,
+				}
+				mockLogFetcher := &mocks.MockLogFetcher{ReturnLogs: []types.Log{fetchedLog}}
+				extractor.Fetcher = mockLogFetcher
+				mockLogRepository := &fakes.MockEventLogRepository{}
+				extractor.LogRepository = mockLogRepository
+
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mockLogRepository.PassedLogs).To(Equal([]types.Log{fetchedLog}))
+			})
+
 			It("returns error if fetching logs fails", func() {
 				addUncheckedHeader(extractor)
 				addTransformerConfig(extractor)
@@ -264,7 +690,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogFetcher.ReturnError = fakes.FakeError
 				extractor.Fetcher = mockLogFetcher
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -277,7 +703,7 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeFalse())
@@ -292,7 +718,7 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeTrue())
@@ -306,7 +732,7 @@ var _ = Describe("Log extractor", func() {
 					mockTransactionSyncer.SyncTransactionsError = fakes.FakeError
 					extractor.Syncer = mockTransactionSyncer
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(fakes.FakeError))
@@ -326,7 +752,7 @@ var _ = Describe("Log extractor", func() {
 					mockLogRepository := &fakes.MockEventLogRepository{}
 					extractor.LogRepository = mockLogRepository
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(mockLogRepository.PassedLogs).To(Equal(fakeLogs))
@@ -340,36 +766,36 @@ var _ = Describe("Log extractor", func() {
 					mockLogRepository.CreateError = fakes.FakeError
 					extractor.LogRepository = mockLogRepository
 
-					err := extractor.ExtractLogs(constants.HeaderUnchecked)
+					err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(fakes.FakeError))
 				})
 			})
 
-			It("marks header checked", func() {
+			It("marks header checked for the watched logs it was unchecked for", func() {
 				addFetchedLog(extractor)
 				addTransformerConfig(extractor)
-				mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
 				headerID := rand.Int63()
-				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: headerID}}
-				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{
+					Header:        core.Header{Id: headerID},
+					WatchedLogIDs: []int64{1},
+				}}
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
-				Expect(mockCheckedHeadersRepository.MarkHeaderCheckedHeaderID).To(Equal(headerID))
+				Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(Equal([]int64{headerID}))
+				Expect(checkedLogsRepository.MarkHeaderCheckedWatchedLogIDs).To(Equal([][]int64{{1}}))
 			})
 
 			It("returns error if marking header checked fails", func() {
 				addFetchedLog(extractor)
 				addTransformerConfig(extractor)
-				mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
-				mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{Id: rand.Int63()}}
-				mockCheckedHeadersRepository.MarkHeaderCheckedReturnError = fakes.FakeError
-				extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+				addUncheckedHeader(extractor)
+				checkedLogsRepository.MarkHeaderCheckedReturnError = fakes.FakeError
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -379,19 +805,136 @@ var _ = Describe("Log extractor", func() {
 				addUncheckedHeader(extractor)
 				addTransformerConfig(extractor)
 
-				err := extractor.ExtractLogs(constants.HeaderUnchecked)
+				err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
 
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 	})
 
+	Describe("ExtractLogs with a block range size", func() {
+		var (
+			mockLogFetcher    *mocks.MockLogFetcher
+			mockLogRepository *fakes.MockEventLogRepository
+			headers           []core.Header
+		)
+
+		BeforeEach(func() {
+			addTransformerConfig(extractor)
+			extractor.BlockRangeSize = 4
+			headers = []core.Header{
+				fakes.GetFakeHeader(13),
+				fakes.GetFakeHeader(10),
+				fakes.GetFakeHeader(11),
+				fakes.GetFakeHeader(15),
+			}
+			for i := range headers {
+				headers[i].Id = headers[i].BlockNumber
+				headers[i].Hash = common.BigToHash(big.NewInt(headers[i].BlockNumber)).Hex()
+			}
+			for _, header := range headers {
+				checkedLogsRepository.UncheckedHeadersReturnHeaders = append(checkedLogsRepository.UncheckedHeadersReturnHeaders,
+					core.UncheckedHeader{Header: header, WatchedLogIDs: []int64{1}})
+			}
+			mockLogFetcher = &mocks.MockLogFetcher{}
+			extractor.Fetcher = mockLogFetcher
+			mockLogRepository = &fakes.MockEventLogRepository{}
+			extractor.LogRepository = mockLogRepository
+		})
+
+		It("fetches logs over ranges of unchecked headers", func() {
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogFetcher.FetchCalled).To(BeFalse())
+			Expect(mockLogFetcher.FetchInRangeStarts).To(Equal([]int64{10, 15}))
+			Expect(mockLogFetcher.FetchInRangeEnds).To(Equal([]int64{13, 15}))
+		})
+
+		It("persists fetched logs for the header with the matching hash", func() {
+			fakeLog := types.Log{BlockNumber: 11, BlockHash: common.BigToHash(big.NewInt(11)), Address: fakes.FakeAddress,
+				Topics: []common.Hash{fakes.FakeHash}}
+			mockLogFetcher.ReturnLogs = []types.Log{fakeLog}
+
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.PassedHeaderIDs).To(Equal([]int64{11}))
+			Expect(mockLogRepository.PassedLogs).To(Equal([]types.Log{fakeLog}))
+			Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(ConsistOf(int64(10), int64(11), int64(13), int64(15)))
+		})
+
+		It("only persists logs of the watched logs each header is unchecked for", func() {
+			otherConfig := event.TransformerConfig{
+				ContractAddresses:   []string{fakes.AnotherFakeAddress.Hex()},
+				Topic:               fakes.FakeHash.Hex(),
+				StartingBlockNumber: 13,
+			}
+			Expect(extractor.AddTransformerConfig(otherConfig)).To(Succeed())
+			// the header at block 13 has already been checked for the first watched log
+			checkedLogsRepository.UncheckedHeadersReturnHeaders[0].WatchedLogIDs = []int64{2}
+			watchedLog := types.Log{BlockNumber: 13, BlockHash: common.BigToHash(big.NewInt(13)), Address: fakes.FakeAddress,
+				Topics: []common.Hash{fakes.FakeHash}}
+			otherWatchedLog := types.Log{BlockNumber: 13, BlockHash: common.BigToHash(big.NewInt(13)),
+				Address: fakes.AnotherFakeAddress, Topics: []common.Hash{fakes.FakeHash}, Index: 1}
+			logBeforeStartingBlock := types.Log{BlockNumber: 11, BlockHash: common.BigToHash(big.NewInt(11)),
+				Address: fakes.AnotherFakeAddress, Topics: []common.Hash{fakes.FakeHash}}
+			mockLogFetcher.ReturnLogs = []types.Log{watchedLog, otherWatchedLog, logBeforeStartingBlock}
+
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.PassedHeaderIDs).To(Equal([]int64{13}))
+			Expect(mockLogRepository.PassedLogs).To(Equal([]types.Log{otherWatchedLog}))
+		})
+
+		It("rejects logs whose block hash doesn't match the persisted header", func() {
+			mockLogFetcher.ReturnLogs = []types.Log{{BlockNumber: 11, BlockHash: fakes.FakeHash}}
+
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogRepository.PassedHeaderIDs).To(BeEmpty())
+			Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(ConsistOf(int64(10), int64(13), int64(15)))
+		})
+
+		It("reduces the block range if the node returns too many results", func() {
+			mockLogFetcher.FetchInRangeErrors = []error{fetcher.ErrTooManyResults}
+
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogFetcher.FetchInRangeStarts).To(Equal([]int64{10, 10, 13}))
+			Expect(mockLogFetcher.FetchInRangeEnds).To(Equal([]int64{13, 11, 15}))
+		})
+
+		It("returns error if fetching logs fails", func() {
+			mockLogFetcher.FetchInRangeErrors = []error{fakes.FakeError}
+
+			err := extractor.ExtractLogs(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(BeEmpty())
+		})
+
+		It("does not mark headers checked when back-filling", func() {
+			mockHeaderRepository := &fakes.MockHeaderRepository{AllHeaders: headers}
+			extractor.HeaderRepository = mockHeaderRepository
+
+			err := extractor.BackFillLogs(context.Background(), *extractor.StartingBlock+1)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockLogFetcher.FetchInRangeCalled).To(BeTrue())
+			Expect(checkedLogsRepository.MarkHeaderCheckedHeaderIDs).To(BeEmpty())
+		})
+	})
+
 	Describe("BackFillLogs", func() {
-		It("returns error if no watched addresses configured", func() {
-			err := extractor.BackFillLogs(0)
+		It("returns error if no watched logs configured", func() {
+			err := extractor.BackFillLogs(context.Background(), 0)
 
 			Expect(err).To(HaveOccurred())
-			Expect(err).To(MatchError(logs.ErrNoWatchedAddresses))
+			Expect(err).To(MatchError(logs.ErrNoWatchedLogs))
 		})
 
 		It("gets headers from transformer's starting block through passed ending block", func() {
@@ -400,7 +943,7 @@ var _ = Describe("Log extractor", func() {
 			extractor.HeaderRepository = mockHeaderRepository
 			endingBlock := startingBlock + 1
 
-			_ = extractor.BackFillLogs(endingBlock)
+			_ = extractor.BackFillLogs(context.Background(), endingBlock)
 
 			Expect(mockHeaderRepository.GetHeadersInRangeStartingBlocks).To(ContainElement(startingBlock))
 			Expect(mockHeaderRepository.GetHeadersInRangeEndingBlocks).To(ContainElement(endingBlock))
@@ -412,7 +955,7 @@ var _ = Describe("Log extractor", func() {
 			extractor.HeaderRepository = mockHeaderRepository
 			endingBlock := startingBlock + logs.HeaderChunkSize*2
 
-			err := extractor.BackFillLogs(endingBlock)
+			err := extractor.BackFillLogs(context.Background(), endingBlock)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockHeaderRepository.GetHeadersInRangeStartingBlocks).To(ConsistOf([]int64{
@@ -431,7 +974,7 @@ var _ = Describe("Log extractor", func() {
 			extractor.HeaderRepository = mockHeaderRepository
 			startingBlock := addTransformerConfig(extractor)
 
-			err := extractor.BackFillLogs(startingBlock + 1)
+			err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -444,7 +987,7 @@ var _ = Describe("Log extractor", func() {
 			mockLogFetcher := &mocks.MockLogFetcher{}
 			extractor.Fetcher = mockLogFetcher
 
-			_ = extractor.BackFillLogs(startingBlock + 1)
+			_ = extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 			Expect(mockLogFetcher.FetchCalled).To(BeFalse())
 		})
@@ -461,11 +1004,11 @@ var _ = Describe("Log extractor", func() {
 			mockLogFetcher := &mocks.MockLogFetcher{}
 			extractor.Fetcher = mockLogFetcher
 
-			err := extractor.BackFillLogs(config.StartingBlockNumber + 1)
+			err := extractor.BackFillLogs(context.Background(), config.StartingBlockNumber+1)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockLogFetcher.FetchCalled).To(BeTrue())
-			expectedTopics := []common.Hash{common.HexToHash(config.Topic)}
+			expectedTopics := [][]common.Hash{{common.HexToHash(config.Topic)}}
 			Expect(mockLogFetcher.Topics).To(Equal(expectedTopics))
 			expectedAddresses := event.HexStringsToAddresses(config.ContractAddresses)
 			Expect(mockLogFetcher.ContractAddresses).To(Equal(expectedAddresses))
@@ -478,7 +1021,7 @@ var _ = Describe("Log extractor", func() {
 			mockLogFetcher.ReturnError = fakes.FakeError
 			extractor.Fetcher = mockLogFetcher
 
-			err := extractor.BackFillLogs(startingBlock + 1)
+			err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -490,7 +1033,7 @@ var _ = Describe("Log extractor", func() {
 			mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 			extractor.Syncer = mockTransactionSyncer
 
-			err := extractor.BackFillLogs(startingBlock + 1)
+			err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeFalse())
@@ -504,7 +1047,7 @@ var _ = Describe("Log extractor", func() {
 				mockTransactionSyncer := &fakes.MockTransactionSyncer{}
 				extractor.Syncer = mockTransactionSyncer
 
-				err := extractor.BackFillLogs(startingBlock + 1)
+				err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockTransactionSyncer.SyncTransactionsCalled).To(BeTrue())
@@ -518,7 +1061,7 @@ var _ = Describe("Log extractor", func() {
 				mockTransactionSyncer.SyncTransactionsError = fakes.FakeError
 				extractor.Syncer = mockTransactionSyncer
 
-				err := extractor.BackFillLogs(startingBlock + 1)
+				err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -538,7 +1081,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogRepository := &fakes.MockEventLogRepository{}
 				extractor.LogRepository = mockLogRepository
 
-				err := extractor.BackFillLogs(startingBlock + 1)
+				err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(mockLogRepository.PassedLogs).To(Equal(fakeLogs))
@@ -552,7 +1095,7 @@ var _ = Describe("Log extractor", func() {
 				mockLogRepository.CreateError = fakes.FakeError
 				extractor.LogRepository = mockLogRepository
 
-				err := extractor.BackFillLogs(startingBlock + 1)
+				err := extractor.BackFillLogs(context.Background(), startingBlock+1)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -601,10 +1144,10 @@ func addTransformerConfig(extractor *logs.LogExtractor) int64 {
 	return fakeConfig.StartingBlockNumber
 }
 
+// Returns a header unchecked for the first watched log added to the extractor
 func addUncheckedHeader(extractor *logs.LogExtractor) {
-	mockCheckedHeadersRepository := &fakes.MockCheckedHeadersRepository{}
-	mockCheckedHeadersRepository.UncheckedHeadersReturnHeaders = []core.Header{{}}
-	extractor.CheckedHeadersRepository = mockCheckedHeadersRepository
+	mockCheckedLogsRepository := extractor.CheckedLogsRepository.(*fakes.MockCheckedLogsRepository)
+	mockCheckedLogsRepository.UncheckedHeadersReturnHeaders = []core.UncheckedHeader{{WatchedLogIDs: []int64{1}}}
 }
 
 func addHeaderInRange(extractor *logs.LogExtractor) {
@@ -0,0 +1,143 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package logs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+)
+
+// Caches the addresses registered to each address registry. Refreshing only loads addresses registered since the last
+// refresh, unless the registry's count shows registrations were removed (i.e. a reorg replaced the headers that
+// registered them), in which case the registry is reloaded.
+type registeredAddressCache struct {
+	registries []string
+	// Registered addresses by registry, sorted by the block they were registered at
+	addresses map[string][]core.RegisteredAddress
+	lastIDs   map[string]int64
+}
+
+// Registered addresses added to and removed from a registry by a refresh
+type registryChanges struct {
+	added, removed []core.RegisteredAddress
+}
+
+func newRegisteredAddressCache() *registeredAddressCache {
+	return &registeredAddressCache{
+		addresses: make(map[string][]core.RegisteredAddress),
+		lastIDs:   make(map[string]int64),
+	}
+}
+
+func (cache *registeredAddressCache) addRegistry(registry string) {
+	if !containsString(cache.registries, registry) {
+		cache.registries = append(cache.registries, registry)
+	}
+}
+
+// Loads registrations added or removed since the last refresh, returning the changes to each registry
+func (cache *registeredAddressCache) refresh(repository datastore.AddressRegistryRepository) (map[string]registryChanges, error) {
+	changes := make(map[string]registryChanges)
+	for _, registry := range cache.registries {
+		registryChange, err := cache.refreshRegistry(repository, registry)
+		if err != nil {
+			return nil, fmt.Errorf("error refreshing addresses registered to %s: %w", registry, err)
+		}
+		if len(registryChange.added) > 0 || len(registryChange.removed) > 0 {
+			changes[registry] = registryChange
+		}
+	}
+	return changes, nil
+}
+
+func (cache *registeredAddressCache) refreshRegistry(repository datastore.AddressRegistryRepository, registry string) (registryChanges, error) {
+	count, countErr := repository.CountRegisteredAddresses(registry)
+	if countErr != nil {
+		return registryChanges{}, countErr
+	}
+	added, getErr := repository.GetRegisteredAddresses(registry, cache.lastIDs[registry])
+	if getErr != nil {
+		return registryChanges{}, getErr
+	}
+	cached := cache.addresses[registry]
+	if int64(len(cached)+len(added)) == count {
+		if len(added) == 0 {
+			return registryChanges{}, nil
+		}
+		cache.set(registry, append(cached[:len(cached):len(cached)], added...))
+		return registryChanges{added: added}, nil
+	}
+
+	all, reloadErr := repository.GetRegisteredAddresses(registry, 0)
+	if reloadErr != nil {
+		return registryChanges{}, reloadErr
+	}
+	current := make(map[int64]bool, len(all))
+	for _, registeredAddress := range all {
+		current[registeredAddress.ID] = true
+	}
+	previous := make(map[int64]bool, len(cached))
+	var changes registryChanges
+	for _, registeredAddress := range cached {
+		previous[registeredAddress.ID] = true
+		if !current[registeredAddress.ID] {
+			changes.removed = append(changes.removed, registeredAddress)
+		}
+	}
+	for _, registeredAddress := range all {
+		if !previous[registeredAddress.ID] {
+			changes.added = append(changes.added, registeredAddress)
+		}
+	}
+	cache.set(registry, all)
+	return changes, nil
+}
+
+func (cache *registeredAddressCache) set(registry string, registeredAddresses []core.RegisteredAddress) {
+	var lastID int64
+	for _, registeredAddress := range registeredAddresses {
+		if registeredAddress.ID > lastID {
+			lastID = registeredAddress.ID
+		}
+	}
+	sort.SliceStable(registeredAddresses, func(i, j int) bool {
+		return registeredAddresses[i].BlockNumber < registeredAddresses[j].BlockNumber
+	})
+	cache.addresses[registry] = registeredAddresses
+	cache.lastIDs[registry] = lastID
+}
+
+// Returns the addresses registered to the registry at or before the block
+func (cache *registeredAddressCache) registeredThrough(registry string, blockNumber int64) []common.Address {
+	return addressesRegisteredThrough(cache.addresses[registry], blockNumber)
+}
+
+// Returns the addresses registered at or before the block, from registered addresses sorted by block number
+func addressesRegisteredThrough(registeredAddresses []core.RegisteredAddress, blockNumber int64) []common.Address {
+	end := sort.Search(len(registeredAddresses), func(i int) bool {
+		return registeredAddresses[i].BlockNumber > blockNumber
+	})
+	addresses := make([]common.Address, end)
+	for i, registeredAddress := range registeredAddresses[:end] {
+		addresses[i] = common.HexToAddress(registeredAddress.Address)
+	}
+	return addresses
+}
@@ -0,0 +1,61 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mocks
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+type MockContractTransformer struct {
+	Config        config.ContractConfig
+	InitCallCount int
+	InitError     error
+	InitErrors    []error // Returned by successive calls to Init before InitError
+	ExecuteCount  int
+	ExecuteErrors []error
+}
+
+func (t *MockContractTransformer) Init() error {
+	t.InitCallCount++
+	if len(t.InitErrors) > 0 {
+		var errorThisRun error
+		errorThisRun, t.InitErrors = t.InitErrors[0], t.InitErrors[1:]
+		return errorThisRun
+	}
+	return t.InitError
+}
+
+func (t *MockContractTransformer) Execute() error {
+	t.ExecuteCount++
+	if len(t.ExecuteErrors) > 0 {
+		var errorThisRun error
+		errorThisRun, t.ExecuteErrors = t.ExecuteErrors[0], t.ExecuteErrors[1:]
+		return errorThisRun
+	}
+	return nil
+}
+
+func (t *MockContractTransformer) GetConfig() config.ContractConfig {
+	return t.Config
+}
+
+func (t *MockContractTransformer) FakeTransformerInitializer(db *postgres.DB, bc core.BlockChain) transformer.ContractTransformer {
+	return t
+}
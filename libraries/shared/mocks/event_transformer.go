@@ -26,19 +26,33 @@ import (
 type MockEventTransformer struct {
 	ExecuteWasCalled bool
 	ExecuteError     error
+	FailingLogIDs    []int64 // If set, ExecuteError is only returned for batches including one of these logs
 	PassedLogs       []core.EventLog
+	TransformedLogs  []core.EventLog
 	config           event.TransformerConfig
 }
 
 func (t *MockEventTransformer) Execute(logs []core.EventLog) error {
-	if t.ExecuteError != nil {
+	if t.ExecuteError != nil && (len(t.FailingLogIDs) == 0 || t.includesFailingLog(logs)) {
 		return t.ExecuteError
 	}
 	t.ExecuteWasCalled = true
 	t.PassedLogs = logs
+	t.TransformedLogs = append(t.TransformedLogs, logs...)
 	return nil
 }
 
+func (t *MockEventTransformer) includesFailingLog(logs []core.EventLog) bool {
+	for _, log := range logs {
+		for _, id := range t.FailingLogIDs {
+			if log.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (t *MockEventTransformer) GetConfig() event.TransformerConfig {
 	return t.config
 }
@@ -51,6 +65,37 @@ func (t *MockEventTransformer) FakeTransformerInitializer(db *postgres.DB) event
 	return t
 }
 
+// MockCleanerTransformer is a MockEventTransformer that implements event.Cleaner
+type MockCleanerTransformer struct {
+	MockEventTransformer
+	CleanedLogs  []core.EventLog
+	CleanupError error
+}
+
+func (t *MockCleanerTransformer) Cleanup(logs []core.EventLog) error {
+	if t.CleanupError != nil {
+		return t.CleanupError
+	}
+	t.CleanedLogs = append(t.CleanedLogs, logs...)
+	return nil
+}
+
+// MockPreparerTransformer is a MockEventTransformer that implements event.Preparer
+type MockPreparerTransformer struct {
+	MockEventTransformer
+	PrepareCalled bool
+	PrepareError  error
+}
+
+func (t *MockPreparerTransformer) Prepare() error {
+	t.PrepareCalled = true
+	return t.PrepareError
+}
+
+func (t *MockPreparerTransformer) FakeTransformerInitializer(db *postgres.DB) event.ITransformer {
+	return t
+}
+
 var FakeTransformerConfig = event.TransformerConfig{
 	TransformerName:   "FakeTransformer",
 	ContractAddresses: []string{fakes.FakeAddress.Hex()},
@@ -17,6 +17,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 )
@@ -32,7 +34,7 @@ func (delegator *MockLogDelegator) AddTransformer(t event.ITransformer) {
 	delegator.AddedTransformers = append(delegator.AddedTransformers, t)
 }
 
-func (delegator *MockLogDelegator) DelegateLogs(limit int) error {
+func (delegator *MockLogDelegator) DelegateLogs(ctx context.Context, limit int) error {
 	delegator.DelegateCallCount++
 	delegator.DelegatePassedLimit = limit
 	if len(delegator.DelegateErrors) > 1 {
@@ -17,6 +17,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/constants"
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
@@ -34,7 +36,7 @@ func (extractor *MockLogExtractor) AddTransformerConfig(config event.Transformer
 	return extractor.AddTransformerConfigError
 }
 
-func (extractor *MockLogExtractor) ExtractLogs(recheckHeaders constants.TransformerExecution) error {
+func (extractor *MockLogExtractor) ExtractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
 	extractor.ExtractLogsCount++
 	if len(extractor.ExtractLogsErrors) > 1 {
 		var errorThisRun error
@@ -49,6 +51,6 @@ func (extractor *MockLogExtractor) ExtractLogs(recheckHeaders constants.Transfor
 	return logs.ErrNoUncheckedHeaders
 }
 
-func (extractor *MockLogExtractor) BackFillLogs(endingBlock int64) error {
+func (extractor *MockLogExtractor) BackFillLogs(ctx context.Context, endingBlock int64) error {
 	panic("implement me")
 }
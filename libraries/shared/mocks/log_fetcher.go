@@ -17,24 +17,58 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
 type MockLogFetcher struct {
-	ContractAddresses []common.Address
-	FetchCalled       bool
-	MissingHeader     core.Header
-	ReturnError       error
-	ReturnLogs        []types.Log
-	Topics            []common.Hash
+	AddressesPerFetch  [][]common.Address
+	ContractAddresses  []common.Address
+	FetchCalled        bool
+	FetchInRangeCalled bool
+	FetchInRangeErrors []error
+	FetchInRangeStarts []int64
+	FetchInRangeEnds   []int64
+	MissingHeader      core.Header
+	ReturnError        error
+	ReturnLogs         []types.Log
+	Topics             [][]common.Hash
+	TopicsPerFetch     [][][]common.Hash
 }
 
-func (fetcher *MockLogFetcher) FetchLogs(contractAddresses []common.Address, topics []common.Hash, missingHeader core.Header) ([]types.Log, error) {
+func (fetcher *MockLogFetcher) FetchLogs(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, missingHeader core.Header) ([]types.Log, error) {
 	fetcher.FetchCalled = true
 	fetcher.ContractAddresses = contractAddresses
+	fetcher.AddressesPerFetch = append(fetcher.AddressesPerFetch, contractAddresses)
 	fetcher.Topics = topics
+	fetcher.TopicsPerFetch = append(fetcher.TopicsPerFetch, topics)
 	fetcher.MissingHeader = missingHeader
 	return fetcher.ReturnLogs, fetcher.ReturnError
 }
+
+func (fetcher *MockLogFetcher) FetchLogsInRange(ctx context.Context, contractAddresses []common.Address, topics [][]common.Hash, startingBlock, endingBlock int64) ([]types.Log, error) {
+	fetcher.FetchInRangeCalled = true
+	fetcher.ContractAddresses = contractAddresses
+	fetcher.AddressesPerFetch = append(fetcher.AddressesPerFetch, contractAddresses)
+	fetcher.Topics = topics
+	fetcher.TopicsPerFetch = append(fetcher.TopicsPerFetch, topics)
+	fetcher.FetchInRangeStarts = append(fetcher.FetchInRangeStarts, startingBlock)
+	fetcher.FetchInRangeEnds = append(fetcher.FetchInRangeEnds, endingBlock)
+	if len(fetcher.FetchInRangeErrors) > 0 {
+		var errorThisRun error
+		errorThisRun, fetcher.FetchInRangeErrors = fetcher.FetchInRangeErrors[0], fetcher.FetchInRangeErrors[1:]
+		if errorThisRun != nil {
+			return nil, errorThisRun
+		}
+	}
+	var logs []types.Log
+	for _, log := range fetcher.ReturnLogs {
+		if int64(log.BlockNumber) >= startingBlock && int64(log.BlockNumber) <= endingBlock {
+			logs = append(logs, log)
+		}
+	}
+	return logs, fetcher.ReturnError
+}
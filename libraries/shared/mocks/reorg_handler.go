@@ -0,0 +1,54 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package mocks
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
+	"github.com/makerdao/vulcanizedb/libraries/shared/factories/storage"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+type MockReorgHandler struct {
+	HandleReorgError         error
+	HandleReorgPassedHeaders [][]core.Header
+}
+
+func (handler *MockReorgHandler) HandleReorg(removedHeaders []core.Header) error {
+	handler.HandleReorgPassedHeaders = append(handler.HandleReorgPassedHeaders, removedHeaders)
+	return handler.HandleReorgError
+}
+
+// MockReorgHandlingEventTransformer is an event transformer that also implements transformer.ReorgHandler
+type MockReorgHandlingEventTransformer struct {
+	MockEventTransformer
+	MockReorgHandler
+}
+
+func (t *MockReorgHandlingEventTransformer) FakeTransformerInitializer(db *postgres.DB) event.ITransformer {
+	return t
+}
+
+// MockReorgHandlingStorageTransformer is a storage transformer that also implements transformer.ReorgHandler
+type MockReorgHandlingStorageTransformer struct {
+	MockStorageTransformer
+	MockReorgHandler
+}
+
+func (t *MockReorgHandlingStorageTransformer) FakeTransformerInitializer(db *postgres.DB) storage.ITransformer {
+	return t
+}
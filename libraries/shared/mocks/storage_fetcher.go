@@ -17,6 +17,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage/types"
 )
 
@@ -30,7 +32,7 @@ func NewMockStorageFetcher() *MockStorageFetcher {
 	return &MockStorageFetcher{}
 }
 
-func (fetcher *MockStorageFetcher) FetchStorageDiffs(out chan<- types.RawDiff, errs chan<- error) {
+func (fetcher *MockStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- types.RawDiff, errs chan<- error) {
 	fetcher.FetchStorageDiffsCalled = true
 	for _, diff := range fetcher.DiffsToReturn {
 		out <- diff
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
@@ -13,7 +15,7 @@ type MockStoragediffStreamer struct {
 	streamPayloads     []filters.Payload
 }
 
-func (streamer *MockStoragediffStreamer) Stream(statediffPayloadChan chan filters.Payload) (core.Subscription, error) {
+func (streamer *MockStoragediffStreamer) Stream(ctx context.Context, statediffPayloadChan chan filters.Payload) (core.Subscription, error) {
 	streamer.PassedPayloadChan = statediffPayloadChan
 
 	go func() {
@@ -46,7 +46,7 @@ var _ = Describe("receipt updated trigger", func() {
 			Data:     []byte{},
 			From:     fromAddress.Hex(),
 			GasLimit: 0,
-			GasPrice: 0,
+			GasPrice: "0",
 			Hash:     txHash.Hex(),
 			Nonce:    0,
 			Raw:      []byte{},
@@ -39,7 +39,7 @@ var _ = Describe("transaction updated trigger", func() {
 			Data:     []byte{},
 			From:     fromAddress.Hex(),
 			GasLimit: 0,
-			GasPrice: 0,
+			GasPrice: "0",
 			Hash:     txHash.Hex(),
 			Nonce:    0,
 			Raw:      []byte{},
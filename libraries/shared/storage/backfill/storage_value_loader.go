@@ -1,6 +1,7 @@
 package backfill
 
 import (
+	"context"
 	"errors"
 	"math/big"
 
@@ -49,7 +50,8 @@ type StorageValueLoader struct {
 	endingBlock      int64
 }
 
-func (r *StorageValueLoader) Run() error {
+// Loads storage values for each header in range, stopping between headers if the context is cancelled
+func (r *StorageValueLoader) Run(ctx context.Context) error {
 	if r.storageByAddress == nil {
 		return ErrNoTransformers
 	}
@@ -63,7 +65,10 @@ func (r *StorageValueLoader) Run() error {
 	}
 
 	for _, header := range headers {
-		persistStorageErr := r.getAndPersistStorageValues(header.BlockNumber, header.Hash)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		persistStorageErr := r.getAndPersistStorageValues(ctx, header.BlockNumber, header.Hash)
 		if persistStorageErr != nil {
 			return persistStorageErr
 		}
@@ -98,7 +103,7 @@ func (r *StorageValueLoader) addKeysToStorageByAddress() error {
 	return nil
 }
 
-func (r *StorageValueLoader) getAndPersistStorageValues(blockNumber int64, headerHashStr string) error {
+func (r *StorageValueLoader) getAndPersistStorageValues(ctx context.Context, blockNumber int64, headerHashStr string) error {
 	blockNumberBigInt := big.NewInt(blockNumber)
 	blockHash := common.HexToHash(headerHashStr)
 
@@ -112,7 +117,7 @@ func (r *StorageValueLoader) getAndPersistStorageValues(blockNumber int64, heade
 				"Address":     address.Hex(),
 				"BlockNumber": blockNumber,
 			}).Infof("Getting and persisting %v storage values", len(keys))
-			newKeysToValues, getStorageValuesErr := r.bc.BatchGetStorageAt(address, keys, blockNumberBigInt)
+			newKeysToValues, getStorageValuesErr := r.bc.BatchGetStorageAt(ctx, address, keys, blockNumberBigInt)
 			if getStorageValuesErr != nil {
 				return getStorageValuesErr
 			}
@@ -1,6 +1,7 @@
 package backfill_test
 
 import (
+	"context"
 	"math/big"
 	"math/rand"
 
@@ -96,14 +97,14 @@ var _ = Describe("StorageValueLoader", func() {
 	It("returns error if loader initialized without transformers", func() {
 		runner = backfill.StorageValueLoader{}
 
-		err := runner.Run()
+		err := runner.Run(context.Background())
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(backfill.ErrNoTransformers))
 	})
 
 	It("gets the storage keys for each transformer", func() {
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
@@ -113,14 +114,14 @@ var _ = Describe("StorageValueLoader", func() {
 	It("returns an error if getting the keys from the KeysLookup fails", func() {
 		keysLookupTwo.GetKeysError = fakes.FakeError
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
 		Expect(runnerErr).To(HaveOccurred())
 		Expect(runnerErr).To(Equal(fakes.FakeError))
 	})
 
 	It("fetches headers in the given block range", func() {
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 		Expect(headerRepo.GetHeadersInRangeStartingBlocks).To(ConsistOf(blockOne))
 		Expect(headerRepo.GetHeadersInRangeEndingBlocks).To(ConsistOf(blockTwo))
@@ -128,13 +129,13 @@ var _ = Describe("StorageValueLoader", func() {
 
 	It("returns an error if a header for the given block cannot be retrieved", func() {
 		headerRepo.GetHeadersInRangeError = fakes.FakeError
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).To(HaveOccurred())
 		Expect(runnerErr).To(Equal(fakes.FakeError))
 	})
 
 	It("gets the storage values for each transformer's keys", func() {
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
 		Expect(keysLookupTwo.GetKeysCalled).To(BeTrue())
@@ -153,7 +154,7 @@ var _ = Describe("StorageValueLoader", func() {
 		}
 		keysLookupTwo.KeysToReturn = manyKeys
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
 		Expect(keysLookupTwo.GetKeysCalled).To(BeTrue())
@@ -179,7 +180,7 @@ var _ = Describe("StorageValueLoader", func() {
 		}
 		keysLookupTwo.KeysToReturn = manyKeys
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 		Expect(bc.BatchGetStorageAtCalls).NotTo(ContainElement(fakes.BatchGetStorageAtCall{BlockNumber: bigIntBlockOne, Account: addressTwo, Keys: nil}))
 	})
@@ -190,7 +191,7 @@ var _ = Describe("StorageValueLoader", func() {
 			{BlockNumber: blockTwo},
 		}
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
@@ -207,14 +208,14 @@ var _ = Describe("StorageValueLoader", func() {
 		keysLookupOne.KeysToReturn = []common.Hash{keyOne}
 		bc.BatchGetStorageAtError = fakes.FakeError
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(keysLookupOne.GetKeysCalled).To(BeTrue())
 		Expect(runnerErr).To(HaveOccurred())
 		Expect(runnerErr).To(Equal(fakes.FakeError))
 	})
 
 	It("persists the non-zero storage values for each transformer", func() {
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 
 		headerHashBytes := common.HexToHash(blockOneHeader.Hash)
@@ -247,7 +248,7 @@ var _ = Describe("StorageValueLoader", func() {
 		// same value for address two at block two
 		bc.SetStorageValuesToReturn(blockTwo, addressTwo, valueTwo[:])
 
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).NotTo(HaveOccurred())
 
 		headerHashBytes := common.HexToHash(blockOneHeader.Hash)
@@ -278,7 +279,7 @@ var _ = Describe("StorageValueLoader", func() {
 
 	It("returns an error if inserting a diff fails", func() {
 		diffRepo.CreateBackFilledStorageValueReturnError = fakes.FakeError
-		runnerErr := runner.Run()
+		runnerErr := runner.Run(context.Background())
 		Expect(runnerErr).To(HaveOccurred())
 		Expect(runnerErr).To(Equal(fakes.FakeError))
 	})
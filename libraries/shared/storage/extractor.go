@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -24,22 +25,43 @@ func NewDiffExtractor(fetcher fetcher.IStorageFetcher, db *postgres.DB) DiffExtr
 	}
 }
 
-func (extractor DiffExtractor) ExtractDiffs() error {
+// Persists fetched diffs until fetching fails or the context is cancelled. On cancellation, diffs already
+// sent by the fetcher are persisted before returning the context's error.
+func (extractor DiffExtractor) ExtractDiffs(ctx context.Context) error {
 	diffsChan := make(chan types.RawDiff)
 	errsChan := make(chan error)
+	fetchCtx, cancelFetch := context.WithCancel(ctx)
+	defer cancelFetch()
+	fetchDone := make(chan struct{})
 
-	defer close(diffsChan)
-	defer close(errsChan)
-
-	go extractor.StorageFetcher.FetchStorageDiffs(diffsChan, errsChan)
+	go func() {
+		defer close(fetchDone)
+		extractor.StorageFetcher.FetchStorageDiffs(fetchCtx, diffsChan, errsChan)
+	}()
 
 	for {
 		select {
 		case fetchErr := <-errsChan:
 			logrus.Warnf("error fetching storage diffs: %s", fetchErr.Error())
+			cancelFetch()
+			waitForFetcher(fetchDone, diffsChan, errsChan)
 			return fmt.Errorf("error fetching storage diffs: %w", fetchErr)
 		case diff := <-diffsChan:
 			extractor.persistDiff(diff)
+		case <-fetchDone:
+			return ctx.Err()
+		}
+	}
+}
+
+// Discards anything the fetcher sends while it stops, so that it's never blocked on a send
+func waitForFetcher(fetchDone <-chan struct{}, diffs <-chan types.RawDiff, errs <-chan error) {
+	for {
+		select {
+		case <-fetchDone:
+			return
+		case <-diffs:
+		case <-errs:
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package storage_test
 
 import (
+	"context"
 	"math/rand"
 
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
@@ -32,7 +33,7 @@ var _ = Describe("Storage diff extractor", func() {
 		It("fetches storage diffs", func() {
 			mockFetcher.ErrsToReturn = []error{fakes.FakeError}
 
-			_ = extractor.ExtractDiffs()
+			_ = extractor.ExtractDiffs(context.Background())
 
 			Expect(mockFetcher.FetchStorageDiffsCalled).To(BeTrue())
 		})
@@ -40,12 +41,21 @@ var _ = Describe("Storage diff extractor", func() {
 		It("returns error if fetching storage diffs fails", func() {
 			mockFetcher.ErrsToReturn = []error{fakes.FakeError}
 
-			err := extractor.ExtractDiffs()
+			err := extractor.ExtractDiffs(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
 
+		It("returns context error once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := extractor.ExtractDiffs(ctx)
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
 		It("persists fetched storage diff", func() {
 			fakeDiff := types.RawDiff{
 				Address:      test_data.FakeAddress(),
@@ -57,7 +67,7 @@ var _ = Describe("Storage diff extractor", func() {
 			mockFetcher.DiffsToReturn = []types.RawDiff{fakeDiff}
 			mockFetcher.ErrsToReturn = []error{fakes.FakeError}
 
-			_ = extractor.ExtractDiffs()
+			_ = extractor.ExtractDiffs(context.Background())
 
 			Expect(mockRepository.CreatePassedRawDiffs).To(Equal([]types.RawDiff{fakeDiff}))
 		})
@@ -17,40 +17,77 @@
 package fetcher
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage/types"
 	"github.com/makerdao/vulcanizedb/pkg/fs"
+	"github.com/sirupsen/logrus"
 )
 
+// Interval at which the fetcher reports it is still tailing when no new diffs have been written to the csv
+const DefaultStatusInterval = 30 * time.Second
+
 type CsvTailStorageFetcher struct {
-	tailer       fs.Tailer
-	statusWriter fs.StatusWriter
+	tailer         fs.Tailer
+	statusWriter   fs.StatusWriter
+	StatusInterval time.Duration
 }
 
 func NewCsvTailStorageFetcher(tailer fs.Tailer, statusWriter fs.StatusWriter) CsvTailStorageFetcher {
 	return CsvTailStorageFetcher{
-		tailer:       tailer,
-		statusWriter: statusWriter,
+		tailer:         tailer,
+		statusWriter:   statusWriter,
+		StatusInterval: DefaultStatusInterval,
 	}
 }
 
-func (storageFetcher CsvTailStorageFetcher) FetchStorageDiffs(out chan<- types.RawDiff, errs chan<- error) {
+// Tails the csv until the context is cancelled, then stops tailing and returns. Writes the status on every
+// StatusInterval as well as on each diff, so that a quiet csv isn't reported as a stalled fetcher.
+func (storageFetcher CsvTailStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- types.RawDiff, errs chan<- error) {
 	t, tailErr := storageFetcher.tailer.Tail()
 	if tailErr != nil {
 		errs <- tailErr
+		return
+	}
+	defer t.Cleanup()
+	storageFetcher.writeStatus(errs)
+	ticker := time.NewTicker(storageFetcher.StatusInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			stopErr := t.Stop()
+			if stopErr != nil {
+				logrus.Warnf("error stopping csv tail: %s", stopErr.Error())
+			}
+			return
+		case line, ok := <-t.Lines:
+			if !ok {
+				return
+			}
+			storageFetcher.handleLine(line.Text, out, errs)
+		case <-ticker.C:
+			storageFetcher.writeStatus(errs)
+		}
+	}
+}
+
+func (storageFetcher CsvTailStorageFetcher) handleLine(text string, out chan<- types.RawDiff, errs chan<- error) {
+	diff, parseErr := types.FromParityCsvRow(strings.Split(text, ","))
+	if parseErr != nil {
+		errs <- parseErr
+		return
 	}
+	out <- diff
+	storageFetcher.writeStatus(errs)
+}
+
+func (storageFetcher CsvTailStorageFetcher) writeStatus(errs chan<- error) {
 	writeErr := storageFetcher.statusWriter.Write()
 	if writeErr != nil {
 		errs <- writeErr
 	}
-
-	for line := range t.Lines {
-		diff, parseErr := types.FromParityCsvRow(strings.Split(line.Text, ","))
-		if parseErr != nil {
-			errs <- parseErr
-		} else {
-			out <- diff
-		}
-	}
 }
@@ -17,6 +17,7 @@
 package fetcher_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -50,7 +51,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 	It("adds error to errors channel if tailing file fails", func(done Done) {
 		mockTailer.TailErr = fakes.FakeError
 
-		go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+		go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 
 		Expect(<-errorsChannel).To(MatchError(fakes.FakeError))
 		close(done)
@@ -58,7 +59,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 
 	Describe("when establishing connection succeeds", func() {
 		It("creates file for health check when connection established", func(done Done) {
-			go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+			go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 
 			Eventually(func() bool {
 				return mockStatusWriter.WriteCalled
@@ -66,10 +67,21 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 			close(done)
 		})
 
+		It("writes the status on each interval while no diffs arrive", func(done Done) {
+			storageFetcher.StatusInterval = time.Millisecond
+
+			go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
+
+			Eventually(func() int {
+				return mockStatusWriter.WriteCount
+			}).Should(BeNumerically(">", 1))
+			close(done)
+		})
+
 		It("adds parsed csv row to rows channel for storage diff", func(done Done) {
 			line := getFakeLine()
 
-			go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+			go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 			mockTailer.Lines <- line
 
 			expectedRow, err := types.FromParityCsvRow(strings.Split(line.Text, ","))
@@ -81,7 +93,7 @@ var _ = Describe("Csv Tail Storage Fetcher", func() {
 		It("adds error to errors channel if parsing csv fails", func(done Done) {
 			line := &tail.Line{Text: "invalid"}
 
-			go storageFetcher.FetchStorageDiffs(diffsChannel, errorsChannel)
+			go storageFetcher.FetchStorageDiffs(context.Background(), diffsChannel, errorsChannel)
 			mockTailer.Lines <- line
 
 			Expect(<-errorsChannel).To(HaveOccurred())
@@ -15,6 +15,7 @@
 package fetcher
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -45,9 +46,10 @@ var (
 	addingDiffsLogString     = "adding storage diff to out channel. keccak of address: %v, block height: %v, storage key: %v, storage value: %v"
 )
 
-func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(out chan<- types.RawDiff, errs chan<- error) {
+// Streams diffs until the context is cancelled, then unsubscribes and returns
+func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(ctx context.Context, out chan<- types.RawDiff, errs chan<- error) {
 	ethStatediffPayloadChan := fetcher.statediffPayloadChan
-	clientSubscription, clientSubErr := fetcher.streamer.Stream(ethStatediffPayloadChan)
+	clientSubscription, clientSubErr := fetcher.streamer.Stream(ctx, ethStatediffPayloadChan)
 	if clientSubErr != nil {
 		errs <- clientSubErr
 		panic(fmt.Sprintf("Error creating a geth client subscription: %v", clientSubErr))
@@ -61,12 +63,20 @@ func (fetcher GethRpcStorageFetcher) FetchStorageDiffs(out chan<- types.RawDiff,
 
 	for {
 		select {
+		case <-ctx.Done():
+			logrus.Info("unsubscribing from geth state diffs")
+			clientSubscription.Unsubscribe()
+			return
 		case err := <-clientSubscription.Err():
 			logrus.Errorf("error with client subscription: %s", err.Error())
 			errs <- err
 		case diffPayload := <-ethStatediffPayloadChan:
 			logrus.Trace("received a statediff payload")
 			fetcher.handleDiffPayload(diffPayload, out, errs)
+			writeErr := fetcher.statusWriter.Write()
+			if writeErr != nil {
+				errs <- writeErr
+			}
 		}
 	}
 }
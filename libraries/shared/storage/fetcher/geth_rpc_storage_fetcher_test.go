@@ -15,6 +15,7 @@
 package fetcher_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 
@@ -61,7 +62,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 
 			go func() {
 				failedSub := func() {
-					statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+					statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 				}
 				Expect(failedSub).To(Panic())
 			}()
@@ -73,7 +74,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 		It("streams StatediffPayloads from a Geth RPC subscription", func(done Done) {
 			streamer.SetPayloads(stateDiffPayloads)
 
-			go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+			go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 			streamedPayload := <-statediffPayloadChan
 			Expect(streamedPayload).To(Equal(test_data.MockStatediffPayload))
@@ -83,7 +84,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 
 		Describe("when subscription established", func() {
 			It("creates file for health check when connection established", func(done Done) {
-				go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 				Eventually(func() bool {
 					return statusWriter.WriteCalled
@@ -91,8 +92,18 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 				close(done)
 			})
 
+			It("unsubscribes once the context is cancelled", func(done Done) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				statediffFetcher.FetchStorageDiffs(ctx, storagediffChan, errorChan)
+
+				Expect(subscription.UnsubscribeCalled).To(BeTrue())
+				close(done)
+			})
+
 			It("adds error to errors channel if the subscription fails", func(done Done) {
-				go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 				subscription.Errs <- fakes.FakeError
 
@@ -103,7 +114,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 			It("adds errors to error channel if decoding the state diff RLP fails", func(done Done) {
 				streamer.SetPayloads(badStateDiffPayloads)
 
-				go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 				expectedErr := fmt.Errorf("error decoding storage diff from geth payload: %w", io.EOF)
 				Expect(<-errorChan).To(MatchError(expectedErr))
@@ -113,7 +124,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 			It("adds parsed statediff payloads to the out channel", func(done Done) {
 				streamer.SetPayloads(stateDiffPayloads)
 
-				go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 				height := test_data.BlockNumber
 				intHeight := int(height.Int64())
@@ -159,7 +170,7 @@ var _ = Describe("Geth RPC Storage Fetcher", func() {
 
 				streamer.SetPayloads([]filters.Payload{payloadToReturn})
 
-				go statediffFetcher.FetchStorageDiffs(storagediffChan, errorChan)
+				go statediffFetcher.FetchStorageDiffs(context.Background(), storagediffChan, errorChan)
 
 				Expect(<-errorChan).To(MatchError(rlp.ErrMoreThanOneValue))
 
@@ -15,9 +15,11 @@
 package fetcher
 
 import (
+	"context"
+
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage/types"
 )
 
 type IStorageFetcher interface {
-	FetchStorageDiffs(out chan<- types.RawDiff, errs chan<- error)
+	FetchStorageDiffs(ctx context.Context, out chan<- types.RawDiff, errs chan<- error)
 }
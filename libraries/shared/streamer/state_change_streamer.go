@@ -24,7 +24,7 @@ import (
 )
 
 type Streamer interface {
-	Stream(chan filters.Payload) (core.Subscription, error)
+	Stream(ctx context.Context, payloadChan chan filters.Payload) (core.Subscription, error)
 }
 
 type EthStateChangeStreamer struct {
@@ -39,7 +39,7 @@ func NewEthStateChangeStreamer(ethClient core.EthClient, filterQuery ethereum.Fi
 	}
 }
 
-func (streamer *EthStateChangeStreamer) Stream(payloadChan chan filters.Payload) (core.Subscription, error) {
+func (streamer *EthStateChangeStreamer) Stream(ctx context.Context, payloadChan chan filters.Payload) (core.Subscription, error) {
 	logrus.Info("streaming diffs from geth")
-	return streamer.ethClient.SubscribeNewStateChanges(context.Background(), streamer.filterQuery, payloadChan)
+	return streamer.ethClient.SubscribeNewStateChanges(ctx, streamer.filterQuery, payloadChan)
 }
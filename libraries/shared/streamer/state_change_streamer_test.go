@@ -15,6 +15,8 @@
 package streamer_test
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/eth/filters"
@@ -32,7 +34,7 @@ var _ = Describe("State Change Streamer", func() {
 		}
 		streamer := streamer.NewEthStateChangeStreamer(ethClient, filterQuery)
 		payloadChan := make(chan filters.Payload)
-		_, err := streamer.Stream(payloadChan)
+		_, err := streamer.Stream(context.Background(), payloadChan)
 		Expect(err).NotTo(HaveOccurred())
 
 		ethClient.AssertSubscribeNewStateChangesCalledWith(filterQuery, payloadChan)
@@ -26,6 +26,9 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/factories/event"
 )
 
+// TransferAbi is the ABI of an ERC-20 Transfer event
+const TransferAbi = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
 var startingBlockNumber = rand.Int63()
 var topic0 = "0x" + randomString(64)
 
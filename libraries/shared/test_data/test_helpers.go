@@ -58,7 +58,7 @@ func getFakeTransactionFromHash(txHash common.Hash) core.TransactionModel {
 		Data:     nil,
 		From:     getRandomAddress(),
 		GasLimit: 0,
-		GasPrice: 0,
+		GasPrice: "0",
 		Hash:     hashToPrefixedString(txHash),
 		Nonce:    0,
 		Raw:      nil,
@@ -92,7 +92,10 @@ func getRandomAddress() string {
 
 /// ExpectCheckedHeadersInThisSchema is provided so that
 /// plugins can easily validate that they have the necessary checked_headers
-/// table in their schema for the execute process.
+/// table in their schema.
+///
+/// Deprecated: the execute process records checked headers per watched log in
+/// public.checked_logs, so plugins no longer need a checked_headers table.
 ///
 /// Use like so in your tests:
 /// var _ = Describe("Your Schema", func() {
@@ -0,0 +1,138 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultBlockSyncBatchSize = 100
+
+var (
+	ErrNoUnsyncedHeaders = errors.New("no headers with unsynced transactions")
+	ErrNoHeadersSynced   = errors.New("no headers with unsynced transactions match their blocks on the node")
+)
+
+type IBlockTransactionsSyncer interface {
+	SyncBlockTransactions(ctx context.Context, startingBlockNumber int64) error
+}
+
+// BlockTransactionsSyncer persists every transaction in a synced header's block body that is sent from or to
+// a watched address, including transactions that emit no logs.
+type BlockTransactionsSyncer struct {
+	BlockChain core.BlockChain
+	Repository datastore.HeaderRepository
+	Addresses  map[common.Address]bool // empty to persist every transaction
+	BatchSize  int
+}
+
+func NewBlockTransactionsSyncer(db *postgres.DB, blockChain core.BlockChain, addresses []string) BlockTransactionsSyncer {
+	watchedAddresses := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		watchedAddresses[common.HexToAddress(address)] = true
+	}
+	return BlockTransactionsSyncer{
+		BlockChain: blockChain,
+		Repository: repositories.NewHeaderRepository(db),
+		Addresses:  watchedAddresses,
+		BatchSize:  DefaultBlockSyncBatchSize,
+	}
+}
+
+// Syncs transactions for a batch of headers whose block bodies haven't been synced.
+// Returns ErrNoUnsyncedHeaders if there are none, ErrNoHeadersSynced if every header was skipped to await its
+// replacement by headerSync, or the context's error if it's cancelled between headers.
+func (syncer BlockTransactionsSyncer) SyncBlockTransactions(ctx context.Context, startingBlockNumber int64) error {
+	headers, getHeadersErr := syncer.Repository.GetHeadersWithUnsyncedTransactions(startingBlockNumber, syncer.BatchSize)
+	if getHeadersErr != nil {
+		return fmt.Errorf("error getting headers with unsynced transactions: %w", getHeadersErr)
+	}
+	if len(headers) < 1 {
+		return ErrNoUnsyncedHeaders
+	}
+	syncedAny := false
+	for _, header := range headers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		synced, syncErr := syncer.syncHeader(ctx, header)
+		if syncErr != nil {
+			return syncErr
+		}
+		syncedAny = syncedAny || synced
+	}
+	if !syncedAny {
+		return ErrNoHeadersSynced
+	}
+	return nil
+}
+
+// Returns whether the header was synced, rather than skipped because it no longer matches the node's block
+func (syncer BlockTransactionsSyncer) syncHeader(ctx context.Context, header core.Header) (bool, error) {
+	block, getBlockErr := syncer.BlockChain.GetBlockWithTransactions(ctx, header.BlockNumber)
+	if getBlockErr != nil {
+		return false, fmt.Errorf("error getting block %d: %w", header.BlockNumber, getBlockErr)
+	}
+	// leave the header unsynced; headerSync will replace it and the replacement will be synced
+	if block.Hash != header.Hash {
+		logrus.Infof("skipping transactions for block %d: header hash %s doesn't match block hash %s",
+			header.BlockNumber, header.Hash, block.Hash)
+		return false, nil
+	}
+
+	watchedTransactions := syncer.filterTransactions(block.Transactions)
+	if len(watchedTransactions) > 0 {
+		createErr := syncer.Repository.CreateTransactions(header.Id, watchedTransactions)
+		if createErr != nil {
+			return false, fmt.Errorf("error persisting transactions for block %d: %w", header.BlockNumber, createErr)
+		}
+	}
+	markErr := syncer.Repository.MarkTransactionsSynced(header.Id)
+	if markErr != nil {
+		return false, fmt.Errorf("error marking transactions synced for block %d: %w", header.BlockNumber, markErr)
+	}
+	return true, nil
+}
+
+func (syncer BlockTransactionsSyncer) filterTransactions(transactions []core.TransactionModel) []core.TransactionModel {
+	if len(syncer.Addresses) == 0 {
+		return transactions
+	}
+	var result []core.TransactionModel
+	for _, transaction := range transactions {
+		if syncer.isWatched(transaction.From) || syncer.isWatched(transaction.To) {
+			result = append(result, transaction)
+		}
+	}
+	return result
+}
+
+func (syncer BlockTransactionsSyncer) isWatched(address string) bool {
+	if address == "" {
+		return false
+	}
+	return syncer.Addresses[common.HexToAddress(address)]
+}
@@ -0,0 +1,162 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transactions_test
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transactions"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Block transactions syncer", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		syncer           transactions.BlockTransactionsSyncer
+		header           core.Header
+		watchedAddress   = "0x0000000000000000000000000000000000000123"
+		otherAddress     = "0x0000000000000000000000000000000000000456"
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		headerRepository = fakes.NewMockHeaderRepository()
+		header = core.Header{Id: 1, BlockNumber: 10, Hash: fakes.FakeHash.Hex()}
+		headerRepository.UnsyncedTransactionsHeaders = []core.Header{header}
+		syncer = transactions.BlockTransactionsSyncer{
+			BlockChain: blockChain,
+			Repository: headerRepository,
+			Addresses:  map[common.Address]bool{common.HexToAddress(watchedAddress): true},
+			BatchSize:  transactions.DefaultBlockSyncBatchSize,
+		}
+	})
+
+	It("gets a batch of headers with unsynced transactions", func() {
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {Hash: header.Hash}}
+
+		err := syncer.SyncBlockTransactions(context.Background(), 5)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.GetUnsyncedTransactionsPassedStart).To(Equal(int64(5)))
+		Expect(headerRepository.GetUnsyncedTransactionsPassedLimit).To(Equal(transactions.DefaultBlockSyncBatchSize))
+	})
+
+	It("returns ErrNoUnsyncedHeaders if there are no headers to sync", func() {
+		headerRepository.UnsyncedTransactionsHeaders = nil
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(transactions.ErrNoUnsyncedHeaders))
+	})
+
+	It("returns error if getting headers fails", func() {
+		headerRepository.GetUnsyncedTransactionsError = fakes.FakeError
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("persists transactions from or to watched addresses", func() {
+		fromWatched := core.TransactionModel{Hash: "0x1", From: watchedAddress, To: otherAddress}
+		toWatched := core.TransactionModel{Hash: "0x2", From: otherAddress, To: watchedAddress}
+		unwatched := core.TransactionModel{Hash: "0x3", From: otherAddress, To: otherAddress}
+		contractCreation := core.TransactionModel{Hash: "0x4", From: otherAddress}
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {
+			Hash:         header.Hash,
+			Number:       header.BlockNumber,
+			Transactions: []core.TransactionModel{fromWatched, toWatched, unwatched, contractCreation},
+		}}
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blockChain.GetBlockWithTransactionsPassed).To(Equal([]int64{header.BlockNumber}))
+		Expect(headerRepository.CreateTransactionsPassedHeaderIDs).To(Equal([]int64{header.Id}))
+		Expect(headerRepository.CreateTransactionsPassedTransactions).To(Equal([][]core.TransactionModel{{fromWatched, toWatched}}))
+		Expect(headerRepository.MarkTransactionsSyncedHeaderIDs).To(Equal([]int64{header.Id}))
+	})
+
+	It("persists every transaction if no addresses are watched", func() {
+		syncer.Addresses = map[common.Address]bool{}
+		blockTransactions := []core.TransactionModel{{Hash: "0x1", From: otherAddress, To: otherAddress}}
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {Hash: header.Hash, Transactions: blockTransactions}}
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.CreateTransactionsPassedTransactions).To(Equal([][]core.TransactionModel{blockTransactions}))
+	})
+
+	It("marks the header synced without persisting if no transactions are watched", func() {
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {Hash: header.Hash}}
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.CreateTransactionsCalled).To(BeFalse())
+		Expect(headerRepository.MarkTransactionsSyncedHeaderIDs).To(Equal([]int64{header.Id}))
+	})
+
+	It("leaves the header unsynced if the block hash doesn't match", func() {
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {
+			Hash:         "0xother",
+			Transactions: []core.TransactionModel{{Hash: "0x1", From: watchedAddress}},
+		}}
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(transactions.ErrNoHeadersSynced))
+		Expect(headerRepository.CreateTransactionsCalled).To(BeFalse())
+		Expect(headerRepository.MarkTransactionsSyncedHeaderIDs).To(BeEmpty())
+	})
+
+	It("returns error if getting the block fails", func() {
+		blockChain.GetBlockWithTransactionsError = fakes.FakeError
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns error if persisting transactions fails", func() {
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {
+			Hash:         header.Hash,
+			Transactions: []core.TransactionModel{{Hash: "0x1", From: watchedAddress}},
+		}}
+		headerRepository.CreateTransactionsError = fakes.FakeError
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(headerRepository.MarkTransactionsSyncedHeaderIDs).To(BeEmpty())
+	})
+
+	It("returns error if marking the header synced fails", func() {
+		blockChain.BlocksByNumber = map[int64]core.Block{header.BlockNumber: {Hash: header.Hash}}
+		headerRepository.MarkTransactionsSyncedError = fakes.FakeError
+
+		err := syncer.SyncBlockTransactions(context.Background(), 0)
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+})
@@ -17,6 +17,10 @@
 package transactions
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -25,8 +29,10 @@ import (
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 )
 
+var ErrMissingReceipt = errors.New("no receipt fetched for transaction")
+
 type ITransactionsSyncer interface {
-	SyncTransactions(headerID int64, logs []types.Log) error
+	SyncTransactions(ctx context.Context, headerID int64, logs []types.Log) error
 }
 
 type TransactionsSyncer struct {
@@ -42,22 +48,47 @@ func NewTransactionsSyncer(db *postgres.DB, blockChain core.BlockChain) Transact
 	}
 }
 
-func (syncer TransactionsSyncer) SyncTransactions(headerID int64, logs []types.Log) error {
+func (syncer TransactionsSyncer) SyncTransactions(ctx context.Context, headerID int64, logs []types.Log) error {
 	transactionHashes := getUniqueTransactionHashes(logs)
 	if len(transactionHashes) < 1 {
 		return nil
 	}
-	transactions, transactionErr := syncer.BlockChain.GetTransactions(transactionHashes)
+	transactions, transactionErr := syncer.BlockChain.GetTransactions(ctx, transactionHashes)
 	if transactionErr != nil {
 		return transactionErr
 	}
-	writeErr := syncer.Repository.CreateTransactions(headerID, transactions)
+	receipts, receiptsErr := syncer.BlockChain.GetTransactionReceipts(ctx, transactionHashes)
+	if receiptsErr != nil {
+		return fmt.Errorf("error getting transaction receipts: %w", receiptsErr)
+	}
+	transactionsWithReceipts, matchErr := addReceipts(transactions, receipts)
+	if matchErr != nil {
+		return matchErr
+	}
+	writeErr := syncer.Repository.CreateTransactionsWithReceipts(headerID, transactionsWithReceipts)
 	if writeErr != nil {
 		return writeErr
 	}
 	return nil
 }
 
+func addReceipts(transactions []core.TransactionModel, receipts []core.Receipt) ([]core.TransactionModel, error) {
+	receiptsByHash := make(map[common.Hash]core.Receipt, len(receipts))
+	for _, receipt := range receipts {
+		receiptsByHash[common.HexToHash(receipt.TxHash)] = receipt
+	}
+	result := make([]core.TransactionModel, 0, len(transactions))
+	for _, transaction := range transactions {
+		receipt, ok := receiptsByHash[common.HexToHash(transaction.Hash)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingReceipt, transaction.Hash)
+		}
+		transaction.Receipt = receipt
+		result = append(result, transaction)
+	}
+	return result, nil
+}
+
 func getUniqueTransactionHashes(logs []types.Log) []common.Hash {
 	seen := make(map[common.Hash]struct{}, len(logs))
 	var result []common.Hash
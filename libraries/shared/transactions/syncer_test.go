@@ -17,6 +17,9 @@
 package transactions_test
 
 import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/libraries/shared/transactions"
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -40,21 +43,21 @@ var _ = Describe("Transaction syncer", func() {
 	})
 
 	It("fetches transactions for logs", func() {
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(blockChain.GetTransactionsCalled).To(BeTrue())
 	})
 
 	It("does not fetch transactions if no logs", func() {
-		err := syncer.SyncTransactions(0, []types.Log{})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{})
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(blockChain.GetTransactionsCalled).To(BeFalse())
 	})
 
 	It("only fetches transactions with unique hashes", func() {
-		err := syncer.SyncTransactions(0, []types.Log{{
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{
 			TxHash: fakes.FakeHash,
 		}, {
 			TxHash: fakes.FakeHash,
@@ -67,30 +70,59 @@ var _ = Describe("Transaction syncer", func() {
 	It("returns error if fetching transactions fails", func() {
 		blockChain.GetTransactionsError = fakes.FakeError
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
 	})
 
-	It("passes transactions to repository for persistence", func() {
-		blockChain.Transactions = []core.TransactionModel{{}}
+	It("fetches receipts for logs' transactions", func() {
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blockChain.GetReceiptsPassedHashes).To(Equal([]common.Hash{fakes.FakeHash}))
+	})
+
+	It("returns error if fetching receipts fails", func() {
+		blockChain.GetReceiptsError = fakes.FakeError
+
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns error if a transaction's receipt wasn't fetched", func() {
+		blockChain.Transactions = []core.TransactionModel{{Hash: fakes.FakeHash.Hex()}}
+
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
+
+		Expect(err).To(MatchError(transactions.ErrMissingReceipt))
+	})
+
+	It("passes transactions with their receipts to repository for persistence", func() {
+		receipt := core.Receipt{GasUsed: 21000, Status: 1, TxHash: fakes.FakeHash.Hex()}
+		blockChain.Transactions = []core.TransactionModel{{Hash: fakes.FakeHash.Hex()}}
+		blockChain.Receipts = []core.Receipt{receipt}
 		mockHeaderRepository := fakes.NewMockHeaderRepository()
 		syncer.Repository = mockHeaderRepository
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).NotTo(HaveOccurred())
-		Expect(mockHeaderRepository.CreateTransactionsCalled).To(BeTrue())
+		Expect(mockHeaderRepository.CreateWithReceiptsPassedTransactions).To(Equal([]core.TransactionModel{{
+			Hash:    fakes.FakeHash.Hex(),
+			Receipt: receipt,
+		}}))
 	})
 
 	It("returns error if persisting transactions fails", func() {
-		blockChain.Transactions = []core.TransactionModel{{}}
+		blockChain.Transactions = []core.TransactionModel{{Hash: fakes.FakeHash.Hex()}}
+		blockChain.Receipts = []core.Receipt{{TxHash: fakes.FakeHash.Hex()}}
 		mockHeaderRepository := fakes.NewMockHeaderRepository()
-		mockHeaderRepository.CreateTransactionsError = fakes.FakeError
+		mockHeaderRepository.CreateWithReceiptsError = fakes.FakeError
 		syncer.Repository = mockHeaderRepository
 
-		err := syncer.SyncTransactions(0, []types.Log{{TxHash: fakes.FakeHash}})
+		err := syncer.SyncTransactions(context.Background(), 0, []types.Log{{TxHash: fakes.FakeHash}})
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
@@ -0,0 +1,26 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package transformer
+
+import "github.com/makerdao/vulcanizedb/pkg/core"
+
+// ReorgHandler can optionally be implemented by event and storage transformers to unwind derived state
+// when headers are replaced by a reorg. Rows keyed on a removed header's ID have already been deleted by
+// the time it is called, and it may be called more than once for the same reorg if handling fails.
+type ReorgHandler interface {
+	HandleReorg(removedHeaders []core.Header) error
+}
@@ -0,0 +1,131 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/fs"
+	"github.com/sirupsen/logrus"
+)
+
+type ContractWatcher struct {
+	blockChain                   core.BlockChain
+	db                           *postgres.DB
+	Transformers                 []transformer.ContractTransformer
+	MaxConsecutiveUnexpectedErrs int
+	RetryInterval                time.Duration
+	StatusWriter                 fs.StatusWriter
+}
+
+func NewContractWatcher(db *postgres.DB, bc core.BlockChain, maxConsecutiveUnexpectedErrs int, retryInterval time.Duration, statusWriter fs.StatusWriter) ContractWatcher {
+	return ContractWatcher{
+		blockChain:                   bc,
+		db:                           db,
+		MaxConsecutiveUnexpectedErrs: maxConsecutiveUnexpectedErrs,
+		RetryInterval:                retryInterval,
+		StatusWriter:                 statusWriter,
+	}
+}
+
+// Adds transformers to the watcher so that they will be initialized and executed.
+func (watcher *ContractWatcher) AddTransformers(initializers []transformer.ContractTransformerInitializer) {
+	for _, initializer := range initializers {
+		t := initializer(watcher.db, watcher.blockChain)
+		watcher.Transformers = append(watcher.Transformers, t)
+	}
+}
+
+// Initializes each transformer once, then executes them until the maximum number of consecutive errors is exceeded.
+// Failed initializations are retried on the same terms. Stops between attempts once the context is cancelled,
+// returning the context's error.
+func (watcher *ContractWatcher) Execute(ctx context.Context) error {
+	writeErr := watcher.StatusWriter.Write()
+	if writeErr != nil {
+		return fmt.Errorf("error confirming health check: %w", writeErr)
+	}
+
+	initErr := watcher.initTransformers(ctx)
+	if initErr != nil {
+		return initErr
+	}
+
+	consecutiveUnexpectedErrCount := 0
+	for {
+		if ctx.Err() != nil {
+			logrus.Info("contract watcher shutting down")
+			return ctx.Err()
+		}
+		err := watcher.executeTransformers()
+		if err == nil {
+			consecutiveUnexpectedErrCount = 0
+			writeErr := watcher.StatusWriter.Write()
+			if writeErr != nil {
+				logrus.Errorf("error confirming health check: %s", writeErr.Error())
+			}
+		} else {
+			logrus.Errorf("error executing contract transformers in contract watcher: %s", err.Error())
+			consecutiveUnexpectedErrCount++
+			if consecutiveUnexpectedErrCount > watcher.MaxConsecutiveUnexpectedErrs {
+				return err
+			}
+		}
+		sleep(ctx, watcher.RetryInterval)
+	}
+}
+
+// Initializes each transformer in turn, retrying a transformer that fails to initialize after RetryInterval until the
+// maximum number of consecutive errors is exceeded
+func (watcher *ContractWatcher) initTransformers(ctx context.Context) error {
+	consecutiveUnexpectedErrCount := 0
+	for i := 0; i < len(watcher.Transformers); {
+		if ctx.Err() != nil {
+			logrus.Info("contract watcher shutting down")
+			return ctx.Err()
+		}
+		t := watcher.Transformers[i]
+		initErr := t.Init()
+		if initErr == nil {
+			consecutiveUnexpectedErrCount = 0
+			i++
+			continue
+		}
+		err := fmt.Errorf("error initializing contract transformer %s: %w", t.GetConfig().Name, initErr)
+		logrus.Errorf("error initializing contract transformers in contract watcher: %s", err.Error())
+		consecutiveUnexpectedErrCount++
+		if consecutiveUnexpectedErrCount > watcher.MaxConsecutiveUnexpectedErrs {
+			return err
+		}
+		sleep(ctx, watcher.RetryInterval)
+	}
+	return nil
+}
+
+func (watcher *ContractWatcher) executeTransformers() error {
+	for _, t := range watcher.Transformers {
+		executeErr := t.Execute()
+		if executeErr != nil {
+			return fmt.Errorf("error executing contract transformer %s: %w", t.GetConfig().Name, executeErr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Contract Watcher", func() {
+	var (
+		contractWatcher watcher.ContractWatcher
+		statusWriter    fakes.MockStatusWriter
+		fakeTransformer *mocks.MockContractTransformer
+	)
+
+	BeforeEach(func() {
+		bc := fakes.MockBlockChain{}
+		statusWriter = fakes.MockStatusWriter{}
+		contractWatcher = watcher.NewContractWatcher(nil, &bc, 0, time.Nanosecond, &statusWriter)
+		fakeTransformer = &mocks.MockContractTransformer{}
+		contractWatcher.AddTransformers([]transformer.ContractTransformerInitializer{fakeTransformer.FakeTransformerInitializer})
+	})
+
+	Describe("AddTransformers", func() {
+		It("adds initialized transformers", func() {
+			Expect(contractWatcher.Transformers).To(Equal([]transformer.ContractTransformer{fakeTransformer}))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("creates file for health check", func() {
+			fakeTransformer.ExecuteErrors = []error{fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(statusWriter.WriteCalled).To(BeTrue())
+		})
+
+		It("confirms health check after each successful execution", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.ExecuteErrors = []error{nil, nil, fakes.FakeError, fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(statusWriter.WriteCount).To(Equal(3))
+		})
+
+		It("initializes transformers once", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.ExecuteErrors = []error{nil, fakes.FakeError, fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.InitCallCount).To(Equal(1))
+			Expect(fakeTransformer.ExecuteCount).To(Equal(3))
+		})
+
+		It("returns error if initializing transformers fails", func() {
+			fakeTransformer.InitError = fakes.FakeError
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.ExecuteCount).To(BeZero())
+		})
+
+		It("retries initializing transformers if watcher configured with greater than zero maximum consecutive errors", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.InitErrors = []error{fakes.FakeError, nil}
+			fakeTransformer.ExecuteErrors = []error{fakes.FakeError, fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.InitCallCount).To(Equal(2))
+			Expect(fakeTransformer.ExecuteCount).To(Equal(2))
+		})
+
+		It("returns error if maximum consecutive errors exceeded while initializing transformers", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.InitError = fakes.FakeError
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.InitCallCount).To(Equal(2))
+			Expect(fakeTransformer.ExecuteCount).To(BeZero())
+		})
+
+		It("stops retrying initialization once the context is cancelled", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.InitError = fakes.FakeError
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := contractWatcher.Execute(ctx)
+
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(fakeTransformer.InitCallCount).To(BeZero())
+		})
+
+		It("retries on execute error if watcher configured with greater than zero maximum consecutive errors", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.ExecuteErrors = []error{fakes.FakeError, nil, fakes.FakeError, fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.ExecuteCount).To(Equal(4))
+		})
+
+		It("returns error if maximum consecutive errors exceeded", func() {
+			contractWatcher.MaxConsecutiveUnexpectedErrs = 1
+			fakeTransformer.ExecuteErrors = []error{fakes.FakeError, fakes.FakeError}
+
+			err := contractWatcher.Execute(context.Background())
+
+			Expect(err).To(MatchError(fakes.FakeError))
+			Expect(fakeTransformer.ExecuteCount).To(Equal(2))
+		})
+	})
+})
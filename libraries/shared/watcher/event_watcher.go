@@ -17,6 +17,7 @@
 package watcher
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
@@ -26,7 +27,9 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/fs"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -38,11 +41,14 @@ type EventWatcher struct {
 	LogDelegator                 logs.ILogDelegator
 	ExpectedDelegatorError       error
 	MaxConsecutiveUnexpectedErrs int
+	ReorgNotifier                *ReorgNotifier
 	RetryInterval                time.Duration
-	StatusWriter                 fs.StatusWriter
+	ExtractStatusWriter          fs.StatusWriter
+	DelegateStatusWriter         fs.StatusWriter
 }
 
-func NewEventWatcher(db *postgres.DB, bc core.BlockChain, extractor logs.ILogExtractor, delegator logs.ILogDelegator, maxConsecutiveUnexpectedErrs int, retryInterval time.Duration, statusWriter fs.StatusWriter) EventWatcher {
+// Takes a status writer for each of the extraction and delegation loops, so that either stalling is reported
+func NewEventWatcher(db *postgres.DB, bc core.BlockChain, extractor logs.ILogExtractor, delegator logs.ILogDelegator, maxConsecutiveUnexpectedErrs int, retryInterval time.Duration, extractStatusWriter, delegateStatusWriter fs.StatusWriter) EventWatcher {
 	return EventWatcher{
 		blockChain:                   bc,
 		db:                           db,
@@ -51,8 +57,10 @@ func NewEventWatcher(db *postgres.DB, bc core.BlockChain, extractor logs.ILogExt
 		LogDelegator:                 delegator,
 		ExpectedDelegatorError:       logs.ErrNoLogs,
 		MaxConsecutiveUnexpectedErrs: maxConsecutiveUnexpectedErrs,
+		ReorgNotifier:                NewReorgNotifier(repositories.NewReorgRepository(db)),
 		RetryInterval:                retryInterval,
-		StatusWriter:                 statusWriter,
+		ExtractStatusWriter:          extractStatusWriter,
+		DelegateStatusWriter:         delegateStatusWriter,
 	}
 }
 
@@ -60,8 +68,15 @@ func NewEventWatcher(db *postgres.DB, bc core.BlockChain, extractor logs.ILogExt
 func (watcher *EventWatcher) AddTransformers(initializers []event.TransformerInitializer) error {
 	for _, initializer := range initializers {
 		t := initializer(watcher.db)
+		if preparer, ok := t.(event.Preparer); ok {
+			prepareErr := preparer.Prepare()
+			if prepareErr != nil {
+				return prepareErr
+			}
+		}
 
 		watcher.LogDelegator.AddTransformer(t)
+		watcher.ReorgNotifier.AddTransformer(t.GetConfig().TransformerName, t)
 		err := watcher.LogExtractor.AddTransformerConfig(t.GetConfig())
 		if err != nil {
 			return err
@@ -70,11 +85,14 @@ func (watcher *EventWatcher) AddTransformers(initializers []event.TransformerIni
 	return nil
 }
 
-// Extracts and delegates watched log events.
-func (watcher *EventWatcher) Execute(recheckHeaders constants.TransformerExecution) error {
-	writeErr := watcher.StatusWriter.Write()
-	if writeErr != nil {
-		return fmt.Errorf("error confirming health check: %w", writeErr)
+// Extracts and delegates watched log events. When the context is cancelled, waits for in-flight extraction and
+// delegation to finish before returning the context's error.
+func (watcher *EventWatcher) Execute(ctx context.Context, recheckHeaders constants.TransformerExecution) error {
+	for _, statusWriter := range []fs.StatusWriter{watcher.ExtractStatusWriter, watcher.DelegateStatusWriter} {
+		writeErr := statusWriter.Write()
+		if writeErr != nil {
+			return fmt.Errorf("error confirming health check: %w", writeErr)
+		}
 	}
 
 	//only writers should close channels
@@ -82,60 +100,114 @@ func (watcher *EventWatcher) Execute(recheckHeaders constants.TransformerExecuti
 	extractErrsChan := make(chan error)
 	executeQuitChan := make(chan bool)
 
-	go watcher.extractLogs(recheckHeaders, extractErrsChan, executeQuitChan)
-	go watcher.delegateLogs(delegateErrsChan, executeQuitChan)
+	go watcher.extractLogs(ctx, recheckHeaders, extractErrsChan, executeQuitChan)
+	go watcher.delegateLogs(ctx, delegateErrsChan, executeQuitChan)
 
-	for {
-		select {
-		case delegateErr := <-delegateErrsChan:
-			logrus.Errorf("error delegating logs in event watcher: %s", delegateErr.Error())
-			close(executeQuitChan)
-			return delegateErr
-		case extractErr := <-extractErrsChan:
-			logrus.Errorf("error extracting logs in event watcher: %s", extractErr.Error())
-			close(executeQuitChan)
-			return extractErr
+	select {
+	case <-ctx.Done():
+		return shutDown(ctx, executeQuitChan, extractErrsChan, delegateErrsChan)
+	case delegateErr, ok := <-delegateErrsChan:
+		if !ok {
+			// error channels are only closed without an error once the context is cancelled
+			return shutDown(ctx, executeQuitChan, extractErrsChan, delegateErrsChan)
 		}
+		logrus.Errorf("error delegating logs in event watcher: %s", delegateErr.Error())
+		close(executeQuitChan)
+		return delegateErr
+	case extractErr, ok := <-extractErrsChan:
+		if !ok {
+			return shutDown(ctx, executeQuitChan, extractErrsChan, delegateErrsChan)
+		}
+		logrus.Errorf("error extracting logs in event watcher: %s", extractErr.Error())
+		close(executeQuitChan)
+		return extractErr
 	}
 }
 
-func (watcher *EventWatcher) extractLogs(recheckHeaders constants.TransformerExecution, errs chan error, quitChan chan bool) {
-	call := func() error { return watcher.LogExtractor.ExtractLogs(recheckHeaders) }
+// Stops both loops and waits for their in-flight calls to finish
+func shutDown(ctx context.Context, quitChan chan bool, extractErrs, delegateErrs chan error) error {
+	logrus.Info("event watcher shutting down")
+	close(quitChan)
+	waitForClose(extractErrs)
+	waitForClose(delegateErrs)
+	return ctx.Err()
+}
+
+func (watcher *EventWatcher) extractLogs(ctx context.Context, recheckHeaders constants.TransformerExecution, errs chan error, quitChan chan bool) {
+	call := func() error { return watcher.LogExtractor.ExtractLogs(ctx, recheckHeaders) }
 	// io.ErrUnexpectedEOF errors are sometimes returned from fetching logs at the head of the chain when fetching from an uncle or fork block
 	expectedErrors := []error{watcher.ExpectedExtractorError, io.ErrUnexpectedEOF}
-	watcher.withRetry(call, expectedErrors, "extracting", errs, quitChan)
+	watcher.withRetry(ctx, call, expectedErrors, "extracting", watcher.ExtractStatusWriter, errs, quitChan)
 }
 
-func (watcher *EventWatcher) delegateLogs(errs chan error, quitChan chan bool) {
-	call := func() error { return watcher.LogDelegator.DelegateLogs(ResultsLimit) }
-	watcher.withRetry(call, []error{watcher.ExpectedDelegatorError}, "delegating", errs, quitChan)
+func (watcher *EventWatcher) delegateLogs(ctx context.Context, errs chan error, quitChan chan bool) {
+	call := func() error {
+		notifyErr := watcher.ReorgNotifier.NotifyNewReorgs()
+		if notifyErr != nil {
+			return notifyErr
+		}
+		return watcher.LogDelegator.DelegateLogs(ctx, ResultsLimit)
+	}
+	watcher.withRetry(ctx, call, []error{watcher.ExpectedDelegatorError}, "delegating", watcher.DelegateStatusWriter, errs, quitChan)
 }
 
-func (watcher *EventWatcher) withRetry(call func() error, expectedErrors []error, operation string, errs chan error, quitChan chan bool) {
+// Calls until an unexpected error exceeds the maximum consecutive count, the watcher quits, or the context is cancelled
+func (watcher *EventWatcher) withRetry(ctx context.Context, call func() error, expectedErrors []error, operation string, statusWriter fs.StatusWriter, errs chan error, quitChan chan bool) {
 	defer close(errs)
 	consecutiveUnexpectedErrCount := 0
 	for {
 		select {
 		case <-quitChan:
 			return
+		case <-ctx.Done():
+			return
 		default:
 			err := call()
+			if ctx.Err() != nil {
+				return
+			}
 			if err == nil {
 				consecutiveUnexpectedErrCount = 0
+				reportHealthy(statusWriter, operation)
 			} else {
 				if isUnexpectedError(err, expectedErrors) {
+					metrics.Retries.WithLabelValues(operation, metrics.UnexpectedError).Inc()
 					consecutiveUnexpectedErrCount++
 					if consecutiveUnexpectedErrCount > watcher.MaxConsecutiveUnexpectedErrs {
 						errs <- err
 						return
 					}
+				} else {
+					metrics.Retries.WithLabelValues(operation, metrics.ExpectedError).Inc()
+					reportHealthy(statusWriter, operation)
 				}
-				time.Sleep(watcher.RetryInterval)
+				sleep(ctx, watcher.RetryInterval)
 			}
 		}
 	}
 }
 
+// Expected errors (e.g. no unchecked headers) still count as a healthy iteration of the loop
+func reportHealthy(statusWriter fs.StatusWriter, operation string) {
+	writeErr := statusWriter.Write()
+	if writeErr != nil {
+		logrus.Errorf("error confirming health check after %s: %s", operation, writeErr.Error())
+	}
+}
+
+// Sleeps for the duration, returning early if the context is cancelled
+func sleep(ctx context.Context, duration time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+}
+
+func waitForClose(errs chan error) {
+	for range errs {
+	}
+}
+
 func isUnexpectedError(currentError error, expectedErrors []error) bool {
 	for _, expectedError := range expectedErrors {
 		if currentError == expectedError {
@@ -17,6 +17,7 @@
 package watcher_test
 
 import (
+	"context"
 	"errors"
 	"io"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/logs"
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
 	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -35,18 +37,20 @@ var errExecuteClosed = errors.New("this error means the mocks were finished exec
 
 var _ = Describe("Event Watcher", func() {
 	var (
-		delegator    *mocks.MockLogDelegator
-		extractor    *mocks.MockLogExtractor
-		eventWatcher watcher.EventWatcher
-		statusWriter fakes.MockStatusWriter
+		delegator            *mocks.MockLogDelegator
+		extractor            *mocks.MockLogExtractor
+		eventWatcher         watcher.EventWatcher
+		extractStatusWriter  fakes.MockStatusWriter
+		delegateStatusWriter fakes.MockStatusWriter
 	)
 
 	BeforeEach(func() {
 		delegator = &mocks.MockLogDelegator{}
 		extractor = &mocks.MockLogExtractor{}
 		bc := fakes.MockBlockChain{}
-		statusWriter = fakes.MockStatusWriter{}
-		eventWatcher = watcher.NewEventWatcher(nil, &bc, extractor, delegator, 0, time.Nanosecond, &statusWriter)
+		extractStatusWriter = fakes.MockStatusWriter{}
+		delegateStatusWriter = fakes.MockStatusWriter{}
+		eventWatcher = watcher.NewEventWatcher(nil, &bc, extractor, delegator, 0, time.Nanosecond, &extractStatusWriter, &delegateStatusWriter)
 	})
 
 	Describe("AddTransformers", func() {
@@ -83,31 +87,67 @@ var _ = Describe("Event Watcher", func() {
 			}
 			Expect(extractor.AddedConfigs).To(Equal(expectedConfigs))
 		})
+
+		It("prepares transformers that implement event.Preparer", func() {
+			preparer := &mocks.MockPreparerTransformer{}
+
+			err := eventWatcher.AddTransformers([]event.TransformerInitializer{preparer.FakeTransformerInitializer})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preparer.PrepareCalled).To(BeTrue())
+		})
+
+		It("returns error if preparing a transformer fails", func() {
+			preparer := &mocks.MockPreparerTransformer{PrepareError: fakes.FakeError}
+
+			err := eventWatcher.AddTransformers([]event.TransformerInitializer{preparer.FakeTransformerInitializer})
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
 	})
 
 	Describe("Execute", func() {
 		It("creates file for health check", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(errExecuteClosed))
+			Expect(extractStatusWriter.WriteCalled).To(BeTrue())
+			Expect(delegateStatusWriter.WriteCalled).To(BeTrue())
+		})
+
+		It("reports extraction health with the extraction loop's status writer", func() {
+			extractor.ExtractLogsErrors = []error{nil, nil, errExecuteClosed}
+
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
-			Expect(statusWriter.WriteCalled).To(BeTrue())
+			Expect(extractStatusWriter.WriteCount > 1).To(BeTrue())
 		})
 
 		It("extracts watched logs", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 0).To(BeTrue())
 		})
 
+		It("returns context error once the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := eventWatcher.Execute(ctx, constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
 		It("returns error if extracting logs fails", func() {
 			extractor.ExtractLogsErrors = []error{fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -116,7 +156,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			extractor.ExtractLogsErrors = []error{fakes.FakeError, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 1).To(BeTrue())
@@ -126,7 +166,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			extractor.ExtractLogsErrors = []error{fakes.FakeError, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -134,7 +174,7 @@ var _ = Describe("Event Watcher", func() {
 		It("does not treat absence of unchecked headers as an unexpected error", func() {
 			extractor.ExtractLogsErrors = []error{logs.ErrNoUncheckedHeaders, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 		})
@@ -142,7 +182,7 @@ var _ = Describe("Event Watcher", func() {
 		It("does not treat an io.ErrUnexpectedEOF error from the node as an unexpected error", func() {
 			extractor.ExtractLogsErrors = []error{io.ErrUnexpectedEOF, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 		})
@@ -150,7 +190,7 @@ var _ = Describe("Event Watcher", func() {
 		It("extracts watched logs again if missing headers found", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(extractor.ExtractLogsCount > 1).To(BeTrue())
@@ -159,7 +199,7 @@ var _ = Describe("Event Watcher", func() {
 		It("returns error if extracting logs fails on subsequent run", func() {
 			extractor.ExtractLogsErrors = []error{nil, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -167,7 +207,7 @@ var _ = Describe("Event Watcher", func() {
 		It("delegates untransformed logs", func() {
 			delegator.DelegateErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 0).To(BeTrue())
@@ -176,16 +216,43 @@ var _ = Describe("Event Watcher", func() {
 		It("passes results limit to delegator", func() {
 			delegator.DelegateErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegatePassedLimit).To(Equal(watcher.ResultsLimit))
 		})
 
+		It("notifies transformers that handle reorgs of new reorgs", func() {
+			reorgRepository := &fakes.MockReorgRepository{}
+			reorg := core.Reorg{ID: 1, BlockNumber: 9, Depth: 1, OldHashes: []string{"0xa10"}, OldHeaderIDs: []int64{10}}
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+			eventWatcher.ReorgNotifier.ReorgRepository = reorgRepository
+			reorgHandlingTransformer := &mocks.MockReorgHandlingEventTransformer{}
+			addErr := eventWatcher.AddTransformers([]event.TransformerInitializer{reorgHandlingTransformer.FakeTransformerInitializer})
+			Expect(addErr).NotTo(HaveOccurred())
+			delegator.DelegateErrors = []error{nil, errExecuteClosed}
+
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(errExecuteClosed))
+			Expect(reorgHandlingTransformer.HandleReorgPassedHeaders).To(ContainElement(reorg.RemovedHeaders()))
+		})
+
+		It("returns error if notifying transformers of reorgs fails", func() {
+			eventWatcher.ReorgNotifier.ReorgRepository = &fakes.MockReorgRepository{GetLastNotifiedReorgIDError: fakes.FakeError}
+			reorgHandlingTransformer := &mocks.MockReorgHandlingEventTransformer{}
+			addErr := eventWatcher.AddTransformers([]event.TransformerInitializer{reorgHandlingTransformer.FakeTransformerInitializer})
+			Expect(addErr).NotTo(HaveOccurred())
+
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
 		It("returns error if delegating logs fails", func() {
 			delegator.DelegateErrors = []error{fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -194,7 +261,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			delegator.DelegateErrors = []error{fakes.FakeError, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 1).To(BeTrue())
@@ -204,7 +271,7 @@ var _ = Describe("Event Watcher", func() {
 			eventWatcher.MaxConsecutiveUnexpectedErrs = 1
 			delegator.DelegateErrors = []error{fakes.FakeError, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -212,7 +279,7 @@ var _ = Describe("Event Watcher", func() {
 		It("does not treat absence of unchecked logs as an unexpected error", func() {
 			delegator.DelegateErrors = []error{logs.ErrNoLogs, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 		})
@@ -220,7 +287,7 @@ var _ = Describe("Event Watcher", func() {
 		It("delegates logs again if untransformed logs found", func() {
 			delegator.DelegateErrors = []error{nil, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 1).To(BeTrue())
@@ -229,7 +296,7 @@ var _ = Describe("Event Watcher", func() {
 		It("returns error if delegating logs fails on subsequent run", func() {
 			delegator.DelegateErrors = []error{nil, fakes.FakeError}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(fakes.FakeError))
 		})
@@ -238,7 +305,7 @@ var _ = Describe("Event Watcher", func() {
 			extractor.ExtractLogsErrors = []error{nil, errExecuteClosed, errExecuteClosed}
 			delegator.DelegateErrors = []error{nil, errExecuteClosed, errExecuteClosed}
 
-			err := eventWatcher.Execute(constants.HeaderUnchecked)
+			err := eventWatcher.Execute(context.Background(), constants.HeaderUnchecked)
 
 			Expect(err).To(MatchError(errExecuteClosed))
 			Expect(delegator.DelegateCallCount > 0 || extractor.ExtractLogsCount > 0).To(BeTrue())
@@ -0,0 +1,92 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/sirupsen/logrus"
+)
+
+// ReorgNotifier passes reorgs recorded by headerSync to transformers that implement transformer.ReorgHandler.
+// The last reorg each transformer was notified of is persisted, so reorgs recorded while the notifier isn't running
+// are passed along once it restarts. A transformer is only notified of reorgs recorded after it is first checked.
+type ReorgNotifier struct {
+	ReorgRepository datastore.ReorgRepository
+	Handlers        map[string]transformer.ReorgHandler
+}
+
+func NewReorgNotifier(reorgRepository datastore.ReorgRepository) *ReorgNotifier {
+	return &ReorgNotifier{ReorgRepository: reorgRepository, Handlers: map[string]transformer.ReorgHandler{}}
+}
+
+// Registers the transformer under the given name if it implements transformer.ReorgHandler. The name identifies the
+// transformer's notified reorgs across restarts.
+func (notifier *ReorgNotifier) AddTransformer(name string, t interface{}) {
+	if handler, ok := t.(transformer.ReorgHandler); ok {
+		notifier.Handlers[name] = handler
+	}
+}
+
+// Calls every handler with the headers removed by each reorg it hasn't been notified of. A reorg is marked as
+// notified for each handler once that handler has handled it without error, so a failing handler doesn't hold back
+// the others and is retried on the next call. Returns the first error after every handler has been notified.
+func (notifier *ReorgNotifier) NotifyNewReorgs() error {
+	var names []string
+	for name := range notifier.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var firstErr error
+	for _, name := range names {
+		notifyErr := notifier.notifyHandler(name, notifier.Handlers[name])
+		if notifyErr != nil {
+			logrus.Errorf("error notifying %s of reorgs: %s", name, notifyErr.Error())
+			if firstErr == nil {
+				firstErr = notifyErr
+			}
+		}
+	}
+	return firstErr
+}
+
+func (notifier *ReorgNotifier) notifyHandler(name string, handler transformer.ReorgHandler) error {
+	lastReorgID, getIDErr := notifier.ReorgRepository.GetLastNotifiedReorgID(name)
+	if getIDErr != nil {
+		return fmt.Errorf("error getting last notified reorg: %w", getIDErr)
+	}
+	reorgs, getReorgsErr := notifier.ReorgRepository.GetReorgsAfterID(lastReorgID)
+	if getReorgsErr != nil {
+		return fmt.Errorf("error getting new reorgs: %w", getReorgsErr)
+	}
+	for _, reorg := range reorgs {
+		handleErr := handler.HandleReorg(reorg.RemovedHeaders())
+		if handleErr != nil {
+			return fmt.Errorf("error handling reorg at block %d: %w", reorg.BlockNumber, handleErr)
+		}
+		markErr := notifier.ReorgRepository.MarkReorgNotified(name, reorg.ID)
+		if markErr != nil {
+			return fmt.Errorf("error marking reorg at block %d notified: %w", reorg.BlockNumber, markErr)
+		}
+		logrus.Infof("notified %s of reorg at block %d", name, reorg.BlockNumber)
+	}
+	return nil
+}
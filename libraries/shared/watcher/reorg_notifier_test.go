@@ -0,0 +1,171 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package watcher_test
+
+import (
+	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
+	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg notifier", func() {
+	var (
+		reorgRepository *fakes.MockReorgRepository
+		notifier        *watcher.ReorgNotifier
+		handler         *mocks.MockReorgHandler
+		reorg           core.Reorg
+	)
+
+	BeforeEach(func() {
+		reorgRepository = &fakes.MockReorgRepository{MostRecentReorgID: 1}
+		notifier = watcher.NewReorgNotifier(reorgRepository)
+		handler = &mocks.MockReorgHandler{}
+		reorg = core.Reorg{ID: 2, BlockNumber: 9, Depth: 1, OldHashes: []string{"0xa10"}, OldHeaderIDs: []int64{10}}
+	})
+
+	Describe("AddTransformer", func() {
+		It("adds transformers that handle reorgs", func() {
+			notifier.AddTransformer("handler", handler)
+
+			Expect(notifier.Handlers).To(Equal(map[string]transformer.ReorgHandler{"handler": handler}))
+		})
+
+		It("ignores transformers that don't handle reorgs", func() {
+			notifier.AddTransformer("transformer", &mocks.MockEventTransformer{})
+
+			Expect(notifier.Handlers).To(BeEmpty())
+		})
+	})
+
+	Describe("NotifyNewReorgs", func() {
+		It("does not query reorgs if no transformers handle them", func() {
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgRepository.GetReorgsAfterIDPassedIDs).To(BeEmpty())
+		})
+
+		It("notifies handlers of reorgs after the last reorg they were notified of", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{{ID: 1}, reorg}
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgRepository.GetReorgsAfterIDPassedIDs).To(Equal([]int64{1}))
+			Expect(handler.HandleReorgPassedHeaders).To(Equal([][]core.Header{reorg.RemovedHeaders()}))
+		})
+
+		It("notifies handlers of reorgs recorded before a restart", func() {
+			reorgRepository.LastNotifiedReorgIDs = map[string]int64{"handler": 1}
+			reorgRepository.MostRecentReorgID = reorg.ID
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+			notifier.AddTransformer("handler", handler)
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(handler.HandleReorgPassedHeaders).To(Equal([][]core.Header{reorg.RemovedHeaders()}))
+		})
+
+		It("marks the reorg notified for the handler", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgRepository.LastNotifiedReorgIDs).To(Equal(map[string]int64{"handler": reorg.ID}))
+		})
+
+		It("does not notify handlers of the same reorg twice", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+			Expect(notifier.NotifyNewReorgs()).To(Succeed())
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgRepository.GetReorgsAfterIDPassedIDs).To(Equal([]int64{1, 2}))
+			Expect(handler.HandleReorgPassedHeaders).To(HaveLen(1))
+		})
+
+		It("retries a reorg if handling it fails", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+			handler.HandleReorgError = fakes.FakeError
+
+			err := notifier.NotifyNewReorgs()
+			Expect(err).To(MatchError(fakes.FakeError))
+
+			handler.HandleReorgError = nil
+			retryErr := notifier.NotifyNewReorgs()
+			Expect(retryErr).NotTo(HaveOccurred())
+			Expect(reorgRepository.GetReorgsAfterIDPassedIDs).To(Equal([]int64{1, 1}))
+			Expect(handler.HandleReorgPassedHeaders).To(HaveLen(2))
+		})
+
+		It("does not renotify handlers that succeeded when another handler fails", func() {
+			failingHandler := &mocks.MockReorgHandler{HandleReorgError: fakes.FakeError}
+			notifier.AddTransformer("failing", failingHandler)
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+
+			err := notifier.NotifyNewReorgs()
+			Expect(err).To(MatchError(fakes.FakeError))
+
+			failingHandler.HandleReorgError = nil
+			retryErr := notifier.NotifyNewReorgs()
+			Expect(retryErr).NotTo(HaveOccurred())
+			Expect(handler.HandleReorgPassedHeaders).To(HaveLen(1))
+			Expect(failingHandler.HandleReorgPassedHeaders).To(HaveLen(2))
+			Expect(reorgRepository.LastNotifiedReorgIDs).To(Equal(map[string]int64{"failing": reorg.ID, "handler": reorg.ID}))
+		})
+
+		It("returns error if getting the last notified reorg fails", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.GetLastNotifiedReorgIDError = fakes.FakeError
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns error if getting new reorgs fails", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.GetReorgsAfterIDError = fakes.FakeError
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns error if marking the reorg notified fails", func() {
+			notifier.AddTransformer("handler", handler)
+			reorgRepository.ReorgsToReturn = []core.Reorg{reorg}
+			reorgRepository.MarkReorgNotifiedError = fakes.FakeError
+
+			err := notifier.NotifyNewReorgs()
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+	})
+})
@@ -17,6 +17,7 @@
 package watcher
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -33,14 +34,14 @@ import (
 )
 
 var (
-	ErrHeaderMismatch = errors.New("header hash doesn't match between db and diff")
-	ReorgWindow       = 250
-	ResultsLimit      = 500
+	ErrHeaderMismatch  = errors.New("header hash doesn't match between db and diff")
+	DefaultReorgWindow = 250
+	ResultsLimit       = 500
 )
 
 type IStorageWatcher interface {
 	AddTransformers(initializers []storage2.TransformerInitializer)
-	Execute() error
+	Execute(ctx context.Context) error
 }
 
 type StorageWatcher struct {
@@ -51,6 +52,8 @@ type StorageWatcher struct {
 	DiffBlocksFromHeadOfChain int64 // the number of blocks from the head of the chain where diffs should be processed
 	StatusWriter              fs.StatusWriter
 	DiffStatus                DiffStatusToWatch
+	ReorgWindow               int // the number of blocks from the most recent header within which a diff's header may still be reorged
+	ReorgNotifier             *ReorgNotifier
 }
 
 type DiffStatusToWatch int
@@ -72,6 +75,8 @@ func NewStorageWatcher(db *postgres.DB, backFromHeadOfChain int64, statusWriter
 		DiffBlocksFromHeadOfChain: backFromHeadOfChain,
 		StatusWriter:              statusWriter,
 		DiffStatus:                diffStatusToWatch,
+		ReorgWindow:               DefaultReorgWindow,
+		ReorgNotifier:             NewReorgNotifier(repositories.NewReorgRepository(db)),
 	}
 }
 
@@ -79,21 +84,45 @@ func (watcher StorageWatcher) AddTransformers(initializers []storage2.Transforme
 	for _, initializer := range initializers {
 		storageTransformer := initializer(watcher.db)
 		watcher.AddressTransformers[storageTransformer.GetContractAddress()] = storageTransformer
+		// both diff status watchers run the same transformers, so only the new diff watcher notifies them of reorgs
+		if watcher.DiffStatus == New {
+			watcher.ReorgNotifier.AddTransformer(storageReorgHandlerName(storageTransformer), storageTransformer)
+		}
 	}
 }
 
-func (watcher StorageWatcher) Execute() error {
+// Storage transformers are keyed on their contract address, so it identifies them to the reorg notifier
+func storageReorgHandlerName(t storage2.ITransformer) string {
+	return "storage " + t.GetContractAddress().Hex()
+}
+
+// Transforms diffs until an error occurs or the context is cancelled, finishing the diff being transformed
+// before returning the context's error.
+func (watcher StorageWatcher) Execute(ctx context.Context) error {
 	writeErr := watcher.StatusWriter.Write()
 	if writeErr != nil {
 		return fmt.Errorf("error confirming health check: %w", writeErr)
 	}
 
 	for {
-		err := watcher.transformDiffs()
+		if ctx.Err() != nil {
+			logrus.Info("storage watcher shutting down")
+			return ctx.Err()
+		}
+		notifyErr := watcher.ReorgNotifier.NotifyNewReorgs()
+		if notifyErr != nil {
+			logrus.Errorf("error notifying transformers of reorgs: %s", notifyErr.Error())
+			return notifyErr
+		}
+		err := watcher.transformDiffs(ctx)
 		if err != nil {
 			logrus.Errorf("error transforming diffs: %s", err.Error())
 			return err
 		}
+		writeErr := watcher.StatusWriter.Write()
+		if writeErr != nil {
+			logrus.Errorf("error confirming health check: %s", writeErr.Error())
+		}
 	}
 }
 
@@ -107,7 +136,7 @@ func (watcher StorageWatcher) getDiffs(minID, ResultsLimit int) ([]types.Persist
 	return nil, errors.New("Unrecognized diff status")
 }
 
-func (watcher StorageWatcher) transformDiffs() error {
+func (watcher StorageWatcher) transformDiffs(ctx context.Context) error {
 	minID, minIDErr := watcher.getMinDiffID()
 	if minIDErr != nil && !errors.Is(minIDErr, sql.ErrNoRows) {
 		return fmt.Errorf("error getting min diff ID: %w", minIDErr)
@@ -120,6 +149,9 @@ func (watcher StorageWatcher) transformDiffs() error {
 			return fmt.Errorf("error getting new diffs: %w", extractErr)
 		}
 		for _, diff := range diffs {
+			if ctx.Err() != nil {
+				return nil
+			}
 			transformErr := watcher.transformDiff(diff)
 			if handleErr := watcher.handleTransformError(transformErr, diff); handleErr != nil {
 				return fmt.Errorf("error transforming diff: %w", handleErr)
@@ -212,7 +244,7 @@ func (watcher StorageWatcher) handleDiffWithInvalidHeaderHash(diff types.Persist
 		msg := "error getting max block while handling diff %d with invalid header hash: %w"
 		return fmt.Errorf(msg, diff.ID, maxBlockErr)
 	}
-	if diff.BlockHeight < int(maxBlock)-ReorgWindow {
+	if diff.BlockHeight < int(maxBlock)-watcher.ReorgWindow {
 		return watcher.StorageDiffRepository.MarkNoncanonical(diff.ID)
 	}
 	return nil
@@ -17,6 +17,7 @@
 package watcher_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"math/rand"
@@ -26,6 +27,7 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/mocks"
 	"github.com/makerdao/vulcanizedb/libraries/shared/storage/types"
 	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
+	"github.com/makerdao/vulcanizedb/libraries/shared/transformer"
 	"github.com/makerdao/vulcanizedb/libraries/shared/watcher"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	"github.com/makerdao/vulcanizedb/test_config"
@@ -45,6 +47,26 @@ var _ = Describe("Storage Watcher", func() {
 
 			Expect(w.AddressTransformers[fakeAddress]).To(Equal(fakeTransformer))
 		})
+
+		It("adds transformers that handle reorgs to the reorg notifier of a 'new' diff watcher", func() {
+			fakeTransformer := &mocks.MockReorgHandlingStorageTransformer{}
+			w := watcher.NewStorageWatcher(test_config.NewTestDB(test_config.NewTestNode()), -1, &statusWriter, watcher.New)
+
+			w.AddTransformers([]storage.TransformerInitializer{fakeTransformer.FakeTransformerInitializer})
+
+			Expect(w.ReorgNotifier.Handlers).To(Equal(map[string]transformer.ReorgHandler{
+				"storage " + fakeTransformer.GetContractAddress().Hex(): fakeTransformer,
+			}))
+		})
+
+		It("does not add transformers to the reorg notifier of an 'unrecognized' diff watcher", func() {
+			fakeTransformer := &mocks.MockReorgHandlingStorageTransformer{}
+			w := watcher.NewStorageWatcher(test_config.NewTestDB(test_config.NewTestNode()), -1, &statusWriter, watcher.Unrecognized)
+
+			w.AddTransformers([]storage.TransformerInitializer{fakeTransformer.FakeTransformerInitializer})
+
+			Expect(w.ReorgNotifier.Handlers).To(BeEmpty())
+		})
 	})
 
 	Describe("Execute", func() {
@@ -99,7 +121,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 		It("creates file for health check", func() {
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{fakes.FakeError})
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -109,7 +131,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 		It("fetches diffs with results limit", func() {
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{fakes.FakeError})
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -132,7 +154,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, diffs)
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -155,7 +177,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, diffs)
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -173,7 +195,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, []types.PersistedDiff{unwatchedDiff})
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -193,7 +215,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 			mockHeaderRepository.GetHeaderByBlockNumberError = sql.ErrNoRows
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -213,7 +235,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 			mockHeaderRepository.GetHeaderByBlockNumberError = sql.ErrNoRows
 
-			err := storageWatcher.Execute()
+			err := storageWatcher.Execute(context.Background())
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -252,7 +274,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				expectedFirstMinDiffID := int(diffs[0].ID - 1)
 				expectedSecondMinDiffID := int(diffs[len(diffs)-1].ID)
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -283,7 +305,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 
 				expectedFirstMinDiffID := int(diffs[0].ID - 1)
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -295,7 +317,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockHeaderRepository.MostRecentHeaderBlockNumberErr = sql.ErrNoRows
 				setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, diffs)
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -309,7 +331,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockDiffsRepository.GetFirstDiffIDErr = sql.ErrNoRows
 				setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, diffs)
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -350,7 +372,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockHeaderRepository.MostRecentHeaderBlockNumberErr = maxHeaderErr
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(maxHeaderErr))
@@ -358,10 +380,10 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			})
 
 			It("marks diff 'noncanonical' if block height less than max known block height minus reorg window", func() {
-				mockHeaderRepository.MostRecentHeaderBlockNumber = int64(blockNumber + watcher.ReorgWindow + 1)
+				mockHeaderRepository.MostRecentHeaderBlockNumber = int64(blockNumber + storageWatcher.ReorgWindow + 1)
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -369,10 +391,10 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 			})
 
 			It("does not mark diff as 'noncanonical' if block height is within reorg window", func() {
-				mockHeaderRepository.MostRecentHeaderBlockNumber = int64(blockNumber + watcher.ReorgWindow)
+				mockHeaderRepository.MostRecentHeaderBlockNumber = int64(blockNumber + storageWatcher.ReorgWindow)
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -405,7 +427,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockTransformer.ExecuteErr = executeErr
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(executeErr))
@@ -416,7 +438,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockTransformer.ExecuteErr = types.ErrKeyNotFound
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -427,7 +449,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				mockTransformer.ExecuteErr = types.ErrKeyNotFound
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -437,7 +459,7 @@ func SharedExecuteBehavior(input *ExecuteInput) {
 				setDiffsToReturn(storageWatcher.DiffStatus, mockDiffsRepository, []types.PersistedDiff{fakePersistedDiff})
 				setGetDiffsErrors(storageWatcher.DiffStatus, mockDiffsRepository, []error{nil, fakes.FakeError})
 
-				err := storageWatcher.Execute()
+				err := storageWatcher.Execute(context.Background())
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -31,6 +31,9 @@ type ContractConfig struct {
 	// Ethereum network name; default "" is mainnet
 	Network string
 
+	// Etherscan API key used to fetch missing ABIs
+	ApiKey string
+
 	// List of contract addresses (map to ensure no duplicates)
 	Addresses map[string]bool
 
@@ -56,6 +59,7 @@ type ContractConfig struct {
 func (contractConfig *ContractConfig) PrepConfig() {
 	addrs := viper.GetStringSlice("contract.addresses")
 	contractConfig.Network = viper.GetString("contract.network")
+	contractConfig.ApiKey = viper.GetString("contract.apiKey")
 	contractConfig.Addresses = make(map[string]bool, len(addrs))
 	contractConfig.Abis = make(map[string]string, len(addrs))
 	contractConfig.Events = make(map[string][]string, len(addrs))
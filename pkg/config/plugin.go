@@ -19,12 +19,20 @@ package config
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/makerdao/vulcanizedb/pkg/plugin/helpers"
 )
 
+// Returns the directory of a version of a transformer repository's module
+var ModuleDir = helpers.ModuleDir
+
+// Returns the directory of the version of a transformer repository's module required by the home module
+var RequiredModuleDir = helpers.RequiredModuleDir
+
 type Plugin struct {
 	Transformers map[string]Transformer
 	FilePath     string
@@ -32,6 +40,8 @@ type Plugin struct {
 	Save         bool
 	Home         string
 	Schema       string
+	// Compose a vulcanizedb binary with the transformers compiled in, instead of a plugin
+	Static bool
 }
 
 type Transformer struct {
@@ -40,6 +50,9 @@ type Transformer struct {
 	MigrationPath  string
 	MigrationRank  uint64
 	RepositoryPath string
+	// Version of the repository's module to build the plugin with. Optional: the version required by the home
+	// module's go.mod (or its local replacement) is used if it's empty.
+	Version string
 }
 
 func (pluginConfig *Plugin) GetPluginPaths() (string, string, error) {
@@ -55,18 +68,54 @@ func (pluginConfig *Plugin) GetPluginPaths() (string, string, error) {
 	return goFile, soFile, nil
 }
 
-// Removes duplicate migration paths and returns them in ranked order
+// Returns the path of the binary composed in static mode
+func (pluginConfig *Plugin) GetBinaryPath() (string, error) {
+	path, err := helpers.CleanPath(pluginConfig.FilePath)
+	if err != nil {
+		return "", err
+	}
+	name := strings.Split(pluginConfig.FileName, ".")[0]
+	return filepath.Join(path, name), nil
+}
+
+// Returns the directory of the vulcanizedb module the plugin is built against: Home if it's a filesystem path, the
+// $GOPATH/src directory of Home if it's an import path, or the working directory if Home isn't set
+func (pluginConfig *Plugin) GetHomePath() (string, error) {
+	if pluginConfig.Home == "" {
+		return os.Getwd()
+	}
+	if filepath.IsAbs(pluginConfig.Home) || strings.HasPrefix(pluginConfig.Home, ".") || strings.HasPrefix(pluginConfig.Home, "~") {
+		path, err := helpers.CleanPath(pluginConfig.Home)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(path)
+	}
+	return helpers.CleanPath(filepath.Join("$GOPATH/src", pluginConfig.Home))
+}
+
+// Removes duplicate migration paths and returns them in ranked order. Migrations are read from the transformers'
+// repositories as resolved by go mod download, which fetches them into the Go module cache if they're missing, or
+// from the version the home module requires for repositories without a configured version.
 func (pluginConfig *Plugin) GetMigrationsPaths() ([]string, error) {
-	paths := make(map[uint64]string)
-	highestRank := -1
-	for name, transformer := range pluginConfig.Transformers {
-		repo := transformer.RepositoryPath
-		mig := transformer.MigrationPath
-		path := filepath.Join("$GOPATH/src", pluginConfig.Home, "vendor", repo, mig)
-		cleanPath, err := helpers.CleanPath(path)
+	versions, versionsErr := pluginConfig.GetRepoVersions()
+	if versionsErr != nil {
+		return nil, versionsErr
+	}
+	repoPaths := make(map[string]string)
+	for repository, version := range versions {
+		repoPath, err := pluginConfig.getRepoDir(repository, version)
 		if err != nil {
 			return nil, err
 		}
+		repoPaths[repository] = repoPath
+	}
+
+	paths := make(map[uint64]string)
+	highestRank := -1
+	for name, transformer := range pluginConfig.Transformers {
+		repoPath := repoPaths[transformer.RepositoryPath]
+		cleanPath := filepath.Join(repoPath, transformer.MigrationPath)
 		// If there is a different path with the same rank then we have a conflict
 		_, ok := paths[transformer.MigrationRank]
 		if ok {
@@ -96,14 +145,44 @@ func (pluginConfig *Plugin) GetMigrationsPaths() ([]string, error) {
 	return sortedPaths, nil
 }
 
-// Removes duplicate repo paths before returning them
-func (pluginConfig *Plugin) GetRepoPaths() map[string]bool {
-	paths := make(map[string]bool)
-	for _, transformer := range pluginConfig.Transformers {
-		paths[transformer.RepositoryPath] = true
+func (pluginConfig *Plugin) getRepoDir(repository, version string) (string, error) {
+	if version != "" {
+		return ModuleDir(repository, version)
+	}
+	homePath, homeErr := pluginConfig.GetHomePath()
+	if homeErr != nil {
+		return "", homeErr
+	}
+	return RequiredModuleDir(homePath, repository)
+}
+
+// Returns the names of the configured transformers in sorted order
+func (pluginConfig *Plugin) GetTransformerNames() []string {
+	names := make([]string, 0, len(pluginConfig.Transformers))
+	for name := range pluginConfig.Transformers {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	return paths
+// Returns the version configured for each transformer repository, or an empty version if none of its transformers
+// configures one, failing if a repository is given different versions
+func (pluginConfig *Plugin) GetRepoVersions() (map[string]string, error) {
+	versions := make(map[string]string)
+	for name, transformer := range pluginConfig.Transformers {
+		version := versions[transformer.RepositoryPath]
+		if transformer.Version == "" {
+			versions[transformer.RepositoryPath] = version
+			continue
+		}
+		if version != "" && version != transformer.Version {
+			return nil, fmt.Errorf("transformer %s requires %s@%s, but another transformer requires version %s",
+				name, transformer.RepositoryPath, transformer.Version, version)
+		}
+		versions[transformer.RepositoryPath] = transformer.Version
+	}
+	return versions, nil
 }
 
 type TransformerType int
@@ -17,11 +17,12 @@
 package config_test
 
 import (
+	"errors"
+	"path/filepath"
+
 	"github.com/makerdao/vulcanizedb/pkg/config"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"os"
-	"path/filepath"
 )
 
 var allDifferentPathsConfig = config.Plugin{
@@ -32,6 +33,7 @@ var allDifferentPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
@@ -39,6 +41,7 @@ var allDifferentPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path2",
 			MigrationRank:  2,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer3": {
 			Path:           "test/init/path2",
@@ -46,6 +49,7 @@ var allDifferentPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
@@ -58,6 +62,7 @@ var overlappingPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
@@ -65,6 +70,7 @@ var overlappingPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer3": {
 			Path:           "test/init/path2",
@@ -72,6 +78,7 @@ var overlappingPathsConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
@@ -84,6 +91,7 @@ var conflictErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
@@ -91,6 +99,7 @@ var conflictErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path2",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer3": {
 			Path:           "test/init/path2",
@@ -98,6 +107,7 @@ var conflictErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
@@ -110,6 +120,7 @@ var gapErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
@@ -117,6 +128,7 @@ var gapErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path2",
 			MigrationRank:  3,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer3": {
 			Path:           "test/init/path2",
@@ -124,6 +136,7 @@ var gapErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
@@ -136,12 +149,14 @@ var missingRankErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
 			Type:           config.EthEvent,
 			MigrationPath:  "test/migration/path2",
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer3": {
 			Path:           "test/init/path2",
@@ -149,6 +164,7 @@ var missingRankErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
@@ -161,12 +177,14 @@ var duplicateErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path1",
 			MigrationRank:  0,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 		"transformer2": {
 			Path:           "test/init/path",
 			Type:           config.EthEvent,
 			MigrationPath:  "test/migration/path1",
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 			MigrationRank:  2,
 		},
 		"transformer3": {
@@ -175,21 +193,45 @@ var duplicateErrorConfig = config.Plugin{
 			MigrationPath:  "test/migration/path3",
 			MigrationRank:  1,
 			RepositoryPath: "test/repo/path",
+			Version:        "v1.0.0",
 		},
 	},
 }
 
 var _ = Describe("GetMigrationsPaths", func() {
+	var (
+		originalModuleDir         func(string, string) (string, error)
+		originalRequiredModuleDir func(string, string) (string, error)
+		resolvedModules           []string
+	)
+
+	BeforeEach(func() {
+		originalModuleDir = config.ModuleDir
+		originalRequiredModuleDir = config.RequiredModuleDir
+		resolvedModules = nil
+		config.ModuleDir = func(modulePath, version string) (string, error) {
+			resolvedModules = append(resolvedModules, modulePath+"@"+version)
+			return filepath.Join("/mod/cache", modulePath+"@"+version), nil
+		}
+		config.RequiredModuleDir = func(moduleDir, modulePath string) (string, error) {
+			return filepath.Join(moduleDir, "vendor", modulePath), nil
+		}
+	})
+
+	AfterEach(func() {
+		config.ModuleDir = originalModuleDir
+		config.RequiredModuleDir = originalRequiredModuleDir
+	})
+
 	It("Sorts migration paths by rank", func() {
 		plugin := allDifferentPathsConfig
 		migrationPaths, err := plugin.GetMigrationsPaths()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(len(migrationPaths)).To(Equal(3))
 
-		env := os.Getenv("GOPATH")
-		path1 := filepath.Join(env, "src/vendor/test/repo/path/test/migration/path1")
-		path2 := filepath.Join(env, "src/vendor/test/repo/path/test/migration/path3")
-		path3 := filepath.Join(env, "src/vendor/test/repo/path/test/migration/path2")
+		path1 := filepath.Join("/mod/cache", "test/repo/path@v1.0.0/test/migration/path1")
+		path2 := filepath.Join("/mod/cache", "test/repo/path@v1.0.0/test/migration/path3")
+		path3 := filepath.Join("/mod/cache", "test/repo/path@v1.0.0/test/migration/path2")
 		expectedMigrationPaths := []string{path1, path2, path3}
 		Expect(migrationPaths).To(Equal(expectedMigrationPaths))
 	})
@@ -200,9 +242,8 @@ var _ = Describe("GetMigrationsPaths", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(len(migrationPaths)).To(Equal(2))
 
-		env := os.Getenv("GOPATH")
-		path1 := filepath.Join(env, "src/vendor/test/repo/path/test/migration/path1")
-		path2 := filepath.Join(env, "src/vendor/test/repo/path/test/migration/path3")
+		path1 := filepath.Join("/mod/cache", "test/repo/path@v1.0.0/test/migration/path1")
+		path2 := filepath.Join("/mod/cache", "test/repo/path@v1.0.0/test/migration/path3")
 		expectedMigrationPaths := []string{path1, path2}
 		Expect(migrationPaths).To(Equal(expectedMigrationPaths))
 	})
@@ -238,4 +279,85 @@ var _ = Describe("GetMigrationsPaths", func() {
 		Expect(len(migrationPaths)).To(Equal(0))
 		Expect(err.Error()).To(ContainSubstring("duplicate paths with different ranks present"))
 	})
+
+	It("Resolves the directory of each repository once", func() {
+		plugin := allDifferentPathsConfig
+
+		_, err := plugin.GetMigrationsPaths()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedModules).To(Equal([]string{"test/repo/path@v1.0.0"}))
+	})
+
+	It("Fails if a repository's directory can't be resolved", func() {
+		config.ModuleDir = func(modulePath, version string) (string, error) {
+			return "", errors.New("module not found")
+		}
+		plugin := allDifferentPathsConfig
+
+		_, err := plugin.GetMigrationsPaths()
+
+		Expect(err).To(MatchError("module not found"))
+	})
+
+	It("Reads migrations from the version the home module requires if a repository has no version", func() {
+		plugin := config.Plugin{Home: "/home/vulcanizedb", Transformers: map[string]config.Transformer{
+			"transformer1": {MigrationPath: "db/migrations", RepositoryPath: "github.com/account/repo"},
+		}}
+
+		migrationPaths, err := plugin.GetMigrationsPaths()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrationPaths).To(Equal([]string{"/home/vulcanizedb/vendor/github.com/account/repo/db/migrations"}))
+		Expect(resolvedModules).To(BeEmpty())
+	})
+})
+
+var _ = Describe("GetRepoVersions", func() {
+	It("Returns the version of each repository", func() {
+		plugin := allDifferentPathsConfig
+
+		versions, err := plugin.GetRepoVersions()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(Equal(map[string]string{"test/repo/path": "v1.0.0"}))
+	})
+
+	It("Returns an empty version for repositories without a configured version", func() {
+		plugin := config.Plugin{Transformers: map[string]config.Transformer{
+			"transformer1": {RepositoryPath: "github.com/account/repo"},
+			"transformer2": {RepositoryPath: "github.com/account/other"},
+			"transformer3": {RepositoryPath: "github.com/account/other", Version: "v1.0.0"},
+		}}
+
+		versions, err := plugin.GetRepoVersions()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(versions).To(Equal(map[string]string{
+			"github.com/account/repo":  "",
+			"github.com/account/other": "v1.0.0",
+		}))
+	})
+
+	It("Fails if a repository is given different versions", func() {
+		plugin := config.Plugin{Transformers: map[string]config.Transformer{
+			"transformer1": {RepositoryPath: "github.com/account/repo", Version: "v1.0.0"},
+			"transformer2": {RepositoryPath: "github.com/account/repo", Version: "v1.1.0"},
+		}}
+
+		_, err := plugin.GetRepoVersions()
+
+		Expect(err).To(MatchError(ContainSubstring("github.com/account/repo")))
+	})
+})
+
+var _ = Describe("GetTransformerNames", func() {
+	It("Returns the transformer names in sorted order", func() {
+		plugin := config.Plugin{Transformers: map[string]config.Transformer{
+			"transformer2": {},
+			"transformer1": {},
+		}}
+
+		Expect(plugin.GetTransformerNames()).To(Equal([]string{"transformer1", "transformer2"}))
+	})
 })
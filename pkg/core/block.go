@@ -0,0 +1,31 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// RpcBlock is a block body returned by eth_getBlockByNumber with full transactions
+type RpcBlock struct {
+	Hash         string           `json:"hash"`
+	Number       string           `json:"number"`
+	Transactions []RpcTransaction `json:"transactions"`
+}
+
+// Block is a block's hash along with its converted transactions
+type Block struct {
+	Hash         string
+	Number       int64
+	Transactions []TransactionModel
+}
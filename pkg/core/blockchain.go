@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
@@ -26,13 +27,16 @@ import (
 
 type BlockChain interface {
 	ContractDataFetcher
-	GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error)
-	GetHeaderByNumber(blockNumber int64) (Header, error)
-	GetHeadersByNumbers(blockNumbers []int64) ([]Header, error)
-	GetTransactions(transactionHashes []common.Hash) ([]TransactionModel, error)
-	ChainHead() (*big.Int, error)
-	BatchGetStorageAt(account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error)
+	GetBlockWithTransactions(ctx context.Context, blockNumber int64) (Block, error)
+	GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+	GetHeaderByNumber(ctx context.Context, blockNumber int64) (Header, error)
+	GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) ([]Header, error)
+	GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]TransactionModel, error)
+	GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]Receipt, error)
+	ChainHead(ctx context.Context) (*big.Int, error)
+	BatchGetStorageAt(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error)
 	Node() Node
+	SubscribeNewHeads(ctx context.Context, payloadChan chan *types.Header) (Subscription, error)
 }
 
 type ContractDataFetcher interface {
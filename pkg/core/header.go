@@ -17,6 +17,8 @@
 package core
 
 import (
+	"encoding/json"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -24,7 +26,8 @@ import (
 
 type Header struct {
 	Id          int64
-	BlockNumber int64 `db:"block_number"`
+	BaseFee     string `db:"base_fee_per_gas"` // empty for blocks before EIP-1559
+	BlockNumber int64  `db:"block_number"`
 	Hash        string
 	Raw         []byte
 	Timestamp   string `db:"block_timestamp"`
@@ -45,4 +48,32 @@ type POAHeader struct {
 	Time        hexutil.Uint64 `json:"timestamp"        gencodec:"required"`
 	Extra       hexutil.Bytes  `json:"extraData"        gencodec:"required"`
 	Hash        common.Hash    `json:"hash"`
+	BaseFee     *hexutil.Big   `json:"baseFeePerGas"`
+}
+
+// POWHeader decodes an eth_getBlockByNumber result into a geth header, along with the fields that the
+// geth header type doesn't know about. The node-reported hash is kept because geth's derived Hash() omits
+// post-London header fields.
+type POWHeader struct {
+	types.Header
+	BaseFee   *hexutil.Big
+	BlockHash common.Hash
+}
+
+func (header *POWHeader) UnmarshalJSON(input []byte) error {
+	headerErr := header.Header.UnmarshalJSON(input)
+	if headerErr != nil {
+		return headerErr
+	}
+	var extraFields struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+		Hash    common.Hash  `json:"hash"`
+	}
+	extraFieldsErr := json.Unmarshal(input, &extraFields)
+	if extraFieldsErr != nil {
+		return extraFieldsErr
+	}
+	header.BaseFee = extraFields.BaseFee
+	header.BlockHash = extraFields.Hash
+	return nil
 }
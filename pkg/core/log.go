@@ -28,8 +28,17 @@ type ReceiptLog struct {
 }
 
 type EventLog struct {
-	ID          int64
-	HeaderID    int64 `db:"header_id"`
-	Log         types.Log
+	ID       int64
+	HeaderID int64 `db:"header_id"`
+	Log      types.Log
+	// Deprecated: whether any transformer has transformed the log. Logs are transformed per transformer, so a
+	// transformer is passed the logs it hasn't transformed whatever this is.
 	Transformed bool
 }
+
+// QuarantinedEventLog is an event log that is no longer transformed after failing to transform too many times
+type QuarantinedEventLog struct {
+	EventLog
+	TransformErrorCount int64
+	LastTransformError  string
+}
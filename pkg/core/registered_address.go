@@ -0,0 +1,28 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// RegisteredAddress is a contract address registered to a named address registry, typically a child contract
+// created by a factory contract
+type RegisteredAddress struct {
+	ID          int64
+	Registry    string
+	Address     string
+	HeaderID    int64 `db:"header_id"`
+	BlockNumber int64 `db:"block_number"`
+	Backfilled  bool  // whether logs have been extracted from headers checked for the registry before it was registered
+}
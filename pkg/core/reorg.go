@@ -0,0 +1,40 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+// Reorg records the replacement of previously synced headers with headers from a different fork
+type Reorg struct {
+	ID           int64
+	BlockNumber  int64 // common ancestor of the orphaned and canonical headers
+	Depth        int64
+	OldHashes    []string
+	NewHashes    []string
+	OldHeaderIDs []int64
+}
+
+// Returns the headers that were orphaned by the reorg, ordered by block number
+func (reorg Reorg) RemovedHeaders() []Header {
+	removed := make([]Header, 0, len(reorg.OldHashes))
+	for i, hash := range reorg.OldHashes {
+		header := Header{BlockNumber: reorg.BlockNumber + int64(i) + 1, Hash: hash}
+		if i < len(reorg.OldHeaderIDs) {
+			header.Id = reorg.OldHeaderIDs[i]
+		}
+		removed = append(removed, header)
+	}
+	return removed
+}
@@ -22,7 +22,7 @@ import (
 
 type RpcClient interface {
 	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
-	BatchCall(batch []BatchElem) error
+	BatchCall(ctx context.Context, batch []BatchElem) error
 	IpcPath() string
-	Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (Subscription, error)
+	Subscribe(ctx context.Context, namespace string, payloadChan interface{}, args ...interface{}) (Subscription, error)
 }
@@ -16,31 +16,61 @@
 
 package core
 
+import "github.com/ethereum/go-ethereum/common"
+
+// EIP-2718 transaction envelope types
+const (
+	LegacyTxType     = 0
+	AccessListTxType = 1
+	DynamicFeeTxType = 2
+	BlobTxType       = 3
+)
+
 type TransactionModel struct {
-	Data     []byte `db:"input_data"`
-	From     string `db:"tx_from"`
-	GasLimit uint64 `db:"gas_limit"`
-	GasPrice int64  `db:"gas_price"`
-	Hash     string
-	Nonce    uint64
-	Raw      []byte `db:"raw"`
+	AccessList           []byte `db:"access_list"` // JSON encoded access list; nil for legacy transactions
+	BlobVersionedHashes  []string
+	ChainID              string `db:"chain_id"`
+	Data                 []byte `db:"input_data"`
+	From                 string `db:"tx_from"`
+	GasLimit             uint64 `db:"gas_limit"`
+	GasPrice             string `db:"gas_price"`
+	Hash                 string
+	MaxFeePerBlobGas     string `db:"max_fee_per_blob_gas"`
+	MaxFeePerGas         string `db:"max_fee_per_gas"`
+	MaxPriorityFeePerGas string `db:"max_priority_fee_per_gas"`
+	Nonce                uint64
+	Raw                  []byte `db:"raw"`
 	Receipt
 	To      string `db:"tx_to"`
 	TxIndex int64  `db:"tx_index"`
+	Type    int64  `db:"tx_type"`
 	Value   string
 }
 
 type RpcTransaction struct {
-	Nonce            string `json:"nonce"`
-	GasPrice         string `json:"gasPrice"`
-	GasLimit         string `json:"gas"`
-	Recipient        string `json:"to"`
-	Amount           string `json:"value"`
-	Payload          string `json:"input"`
-	V                string `json:"v"`
-	R                string `json:"r"`
-	S                string `json:"s"`
-	Hash             string
-	From             string
-	TransactionIndex string `json:"transactionIndex"`
+	Type                 string        `json:"type"`
+	ChainID              string        `json:"chainId"`
+	Nonce                string        `json:"nonce"`
+	GasPrice             string        `json:"gasPrice"`
+	MaxFeePerGas         string        `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string        `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     string        `json:"maxFeePerBlobGas"`
+	GasLimit             string        `json:"gas"`
+	Recipient            string        `json:"to"`
+	Amount               string        `json:"value"`
+	Payload              string        `json:"input"`
+	AccessList           []AccessTuple `json:"accessList"`
+	BlobVersionedHashes  []string      `json:"blobVersionedHashes"`
+	V                    string        `json:"v"`
+	R                    string        `json:"r"`
+	S                    string        `json:"s"`
+	Hash                 string
+	From                 string
+	TransactionIndex     string `json:"transactionIndex"`
+}
+
+// AccessTuple is an entry in an EIP-2930 access list
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
 }
@@ -0,0 +1,39 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import "github.com/ethereum/go-ethereum/common"
+
+// WatchedLog is a contract address and topic zero whose logs are extracted from the configured block range
+type WatchedLog struct {
+	ID         int64
+	Address    common.Address
+	AnyAddress bool // watches logs emitted by any address, ignoring Address
+	// Watches logs emitted by any address registered to the registry by the block being checked, ignoring Address
+	AddressRegistry string
+	TopicZero       common.Hash
+	// Values accepted in the topic1 through topic3 positions; an empty set accepts any value
+	TopicFilters        [][]common.Hash
+	StartingBlockNumber int64
+	EndingBlockNumber   int64 // -1 watches through the head of the chain
+}
+
+// UncheckedHeader is a header along with the watched logs it has not yet been checked for
+type UncheckedHeader struct {
+	Header        Header
+	WatchedLogIDs []int64
+}
@@ -30,17 +30,26 @@ type DB struct {
 }
 
 func NewDB(databaseConfig config.Database, node core.Node) (*DB, error) {
-	connectString := config.DbConnectionString(databaseConfig)
-	db, connectErr := sqlx.Connect("postgres", connectString)
+	pg, connectErr := NewDBWithoutNode(databaseConfig)
 	if connectErr != nil {
-		return &DB{}, ErrDBConnectionFailed(connectErr)
+		return &DB{}, connectErr
 	}
-	pg := DB{DB: db, Node: node}
+	pg.Node = node
 	nodeErr := pg.CreateNode(&node)
 	if nodeErr != nil {
 		return &DB{}, ErrUnableToSetNode(nodeErr)
 	}
-	return &pg, nil
+	return pg, nil
+}
+
+// Connects without recording a node, for commands that only work with data already synced from one
+func NewDBWithoutNode(databaseConfig config.Database) (*DB, error) {
+	connectString := config.DbConnectionString(databaseConfig)
+	db, connectErr := sqlx.Connect("postgres", connectString)
+	if connectErr != nil {
+		return &DB{}, ErrDBConnectionFailed(connectErr)
+	}
+	return &DB{DB: db}, nil
 }
 
 func (db *DB) CreateNode(node *core.Node) error {
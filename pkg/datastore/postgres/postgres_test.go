@@ -89,6 +89,14 @@ var _ = Describe("Postgres DB", func() {
 		Expect(err.Error()).To(ContainSubstring(postgres.DbConnectionFailedMsg))
 	})
 
+	It("connects without creating a node", func() {
+		db, err := postgres.NewDBWithoutNode(test_config.DBConfig)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.NodeID).To(BeZero())
+		Expect(db.Ping()).To(Succeed())
+	})
+
 	It("throws error when can't create node", func() {
 		badHash := fmt.Sprintf("x %s", strings.Repeat("1", 100))
 		node := core.Node{GenesisBlock: badHash, NetworkID: 1, ID: "x123", ClientName: "geth"}
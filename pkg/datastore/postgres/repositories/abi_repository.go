@@ -0,0 +1,117 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+// Counts the ABIs saved by this process, so that ABI caches drop the entries they held before an ABI was saved
+var abisSaved uint64
+
+// abiCache holds the parsed ABI of each address looked up, or nil if the address has none, until an ABI is saved
+type abiCache struct {
+	mutex sync.Mutex
+	abis  map[common.Address]*abi.ABI
+	saved uint64
+}
+
+func newAbiCache() *abiCache {
+	return &abiCache{abis: make(map[common.Address]*abi.ABI)}
+}
+
+// Returns the cached ABI for the address and whether it was cached, along with the generation to pass to set if it
+// wasn't
+func (cache *abiCache) get(address common.Address) (*abi.ABI, bool, uint64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	saved := atomic.LoadUint64(&abisSaved)
+	if saved != cache.saved {
+		cache.abis = make(map[common.Address]*abi.ABI)
+		cache.saved = saved
+	}
+	contractAbi, ok := cache.abis[address]
+	return contractAbi, ok, saved
+}
+
+// Caches the ABI looked up for the address, unless an ABI has been saved since the lookup started
+func (cache *abiCache) set(address common.Address, contractAbi *abi.ABI, generation uint64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if generation != atomic.LoadUint64(&abisSaved) || generation != cache.saved {
+		return
+	}
+	cache.abis[address] = contractAbi
+}
+
+type AbiRepository struct {
+	db *postgres.DB
+}
+
+func NewAbiRepository(db *postgres.DB) AbiRepository {
+	return AbiRepository{db: db}
+}
+
+// Appends the ABI's entries to those already saved for the address, omitting duplicates
+const saveAbiQuery = `INSERT INTO public.abis (address, abi) VALUES ($1, $2)
+ON CONFLICT (address) DO UPDATE SET abi = (
+	SELECT jsonb_agg(entry ORDER BY position)
+	FROM (
+		SELECT DISTINCT ON (entry) entry, position
+		FROM (
+			SELECT entry, position FROM jsonb_array_elements(abis.abi) WITH ORDINALITY AS saved (entry, position)
+			UNION ALL
+			SELECT entry, position + jsonb_array_length(abis.abi)
+			FROM jsonb_array_elements(excluded.abi) WITH ORDINALITY AS added (entry, position)
+		) AS entries
+		ORDER BY entry, position
+	) AS merged
+)`
+
+// Saves the ABI used to decode logs emitted by the address, merging its entries with any ABI already saved for it so
+// that transformers sharing an address can each decode their own events
+func (repository AbiRepository) SaveAbi(address, contractAbi string) error {
+	checksumAddress := common.HexToAddress(address).Hex()
+	_, err := repository.db.Exec(saveAbiQuery, checksumAddress, contractAbi)
+	if err != nil {
+		return fmt.Errorf("error saving abi for %s: %w", checksumAddress, err)
+	}
+	atomic.AddUint64(&abisSaved, 1)
+	return nil
+}
+
+// Returns the ABI saved for the address, or an empty string if none has been saved
+func (repository AbiRepository) GetAbi(address string) (string, error) {
+	checksumAddress := common.HexToAddress(address).Hex()
+	var contractAbi string
+	err := repository.db.Get(&contractAbi, `SELECT abi FROM public.abis WHERE address = $1`, checksumAddress)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting abi for %s: %w", checksumAddress, err)
+	}
+	return contractAbi, nil
+}
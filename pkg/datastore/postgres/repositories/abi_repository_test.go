@@ -0,0 +1,95 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Abi repository", func() {
+	var (
+		db   = test_config.NewTestDB(test_config.NewTestNode())
+		repo repositories.AbiRepository
+	)
+
+	BeforeEach(func() {
+		test_config.CleanTestDB(db)
+		repo = repositories.NewAbiRepository(db)
+	})
+
+	Describe("SaveAbi", func() {
+		It("persists the abi for the checksummed address", func() {
+			address := common.HexToAddress("0xabc")
+
+			err := repo.SaveAbi("0xabc", `[{"type":"event","name":"Event","inputs":[]}]`)
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbAbi string
+			readErr := db.Get(&dbAbi, `SELECT abi FROM public.abis WHERE address = $1`, address.Hex())
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbAbi).To(MatchJSON(`[{"type":"event","name":"Event","inputs":[]}]`))
+		})
+
+		It("merges the abi with the abi already saved for the address", func() {
+			Expect(repo.SaveAbi(fakes.FakeAddress.Hex(), `[{"type":"event","name":"Old","inputs":[]}]`)).To(Succeed())
+
+			err := repo.SaveAbi(fakes.FakeAddress.Hex(), `[{"type":"event","name":"New","inputs":[]}]`)
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbAbis []string
+			readErr := db.Select(&dbAbis, `SELECT abi FROM public.abis`)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(len(dbAbis)).To(Equal(1))
+			Expect(dbAbis[0]).To(MatchJSON(`[{"type":"event","name":"Old","inputs":[]},{"type":"event","name":"New","inputs":[]}]`))
+		})
+
+		It("doesn't duplicate entries already saved for the address", func() {
+			Expect(repo.SaveAbi(fakes.FakeAddress.Hex(), `[{"type":"event","name":"Old","inputs":[]}]`)).To(Succeed())
+
+			err := repo.SaveAbi(fakes.FakeAddress.Hex(), `[{"type":"event","name":"Old","inputs":[]},{"type":"event","name":"New","inputs":[]}]`)
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbAbi string
+			readErr := db.Get(&dbAbi, `SELECT abi FROM public.abis`)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbAbi).To(MatchJSON(`[{"type":"event","name":"Old","inputs":[]},{"type":"event","name":"New","inputs":[]}]`))
+		})
+	})
+
+	Describe("GetAbi", func() {
+		It("returns the abi saved for the address", func() {
+			Expect(repo.SaveAbi("0xabc", `[{"type":"event","name":"Event","inputs":[]}]`)).To(Succeed())
+
+			contractAbi, err := repo.GetAbi("0xABC")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contractAbi).To(MatchJSON(`[{"type":"event","name":"Event","inputs":[]}]`))
+		})
+
+		It("returns an empty string if no abi is saved for the address", func() {
+			contractAbi, err := repo.GetAbi("0xabc")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contractAbi).To(BeEmpty())
+		})
+	})
+})
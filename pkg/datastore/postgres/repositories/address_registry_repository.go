@@ -0,0 +1,76 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+type AddressRegistryRepository struct {
+	db *postgres.DB
+}
+
+func NewAddressRegistryRepository(db *postgres.DB) AddressRegistryRepository {
+	return AddressRegistryRepository{db: db}
+}
+
+// Returns the addresses registered to the registry with an id greater than afterID, in the order they were registered
+func (repository AddressRegistryRepository) GetRegisteredAddresses(registry string, afterID int64) ([]core.RegisteredAddress, error) {
+	var registeredAddresses []core.RegisteredAddress
+	err := repository.db.Select(&registeredAddresses, `SELECT id, registry, address, header_id, block_number, backfilled
+		FROM public.registered_addresses WHERE registry = $1 AND id > $2 ORDER BY id`, registry, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting addresses registered to %s: %w", registry, err)
+	}
+	return registeredAddresses, nil
+}
+
+// Returns the number of addresses registered to the registry, which drops when a reorg removes a registration
+func (repository AddressRegistryRepository) CountRegisteredAddresses(registry string) (int64, error) {
+	var count int64
+	err := repository.db.Get(&count, `SELECT COUNT(*) FROM public.registered_addresses WHERE registry = $1`, registry)
+	if err != nil {
+		return 0, fmt.Errorf("error counting addresses registered to %s: %w", registry, err)
+	}
+	return count, nil
+}
+
+// Records that the registered addresses' logs have been extracted from headers already checked for their registry
+func (repository AddressRegistryRepository) MarkRegisteredAddressesBackfilled(ids []int64) error {
+	_, err := repository.db.Exec(`UPDATE public.registered_addresses SET backfilled = TRUE WHERE id = ANY($1)`,
+		pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error marking registered addresses backfilled: %w", err)
+	}
+	return nil
+}
+
+// Registers the address, ignoring addresses that are already registered to the registry
+func (repository AddressRegistryRepository) RegisterAddress(registeredAddress core.RegisteredAddress) error {
+	_, err := repository.db.Exec(`INSERT INTO public.registered_addresses (registry, address, header_id, block_number)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (registry, address) DO NOTHING`, registeredAddress.Registry, registeredAddress.Address,
+		registeredAddress.HeaderID, registeredAddress.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("error registering address %s to %s: %w", registeredAddress.Address, registeredAddress.Registry, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories_test
+
+import (
+	"math/rand"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Address registry repository", func() {
+	var (
+		db                = test_config.NewTestDB(test_config.NewTestNode())
+		repo              repositories.AddressRegistryRepository
+		headerID          int64
+		registeredAddress core.RegisteredAddress
+	)
+
+	BeforeEach(func() {
+		test_config.CleanTestDB(db)
+		repo = repositories.NewAddressRegistryRepository(db)
+		blockNumber := rand.Int63n(1000000)
+		var headerErr error
+		headerID, headerErr = repositories.NewHeaderRepository(db).CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber))
+		Expect(headerErr).NotTo(HaveOccurred())
+		registeredAddress = core.RegisteredAddress{
+			Registry:    "pairs",
+			Address:     fakes.FakeAddress.Hex(),
+			HeaderID:    headerID,
+			BlockNumber: blockNumber,
+		}
+	})
+
+	Describe("RegisterAddress", func() {
+		It("persists the registered address", func() {
+			err := repo.RegisterAddress(registeredAddress)
+
+			Expect(err).NotTo(HaveOccurred())
+			var dbRegisteredAddress core.RegisteredAddress
+			readErr := db.Get(&dbRegisteredAddress, `SELECT registry, address, header_id, block_number
+				FROM public.registered_addresses`)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbRegisteredAddress).To(Equal(registeredAddress))
+		})
+
+		It("ignores an address that is already registered to the registry", func() {
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+			laterRegistration := registeredAddress
+			laterRegistration.BlockNumber = registeredAddress.BlockNumber + 1
+
+			err := repo.RegisterAddress(laterRegistration)
+
+			Expect(err).NotTo(HaveOccurred())
+			registeredAddresses, getErr := repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(withoutIDs(registeredAddresses)).To(Equal([]core.RegisteredAddress{registeredAddress}))
+		})
+
+		It("removes the registration if its header is removed", func() {
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+
+			_, deleteErr := db.Exec(`DELETE FROM public.headers WHERE id = $1`, headerID)
+
+			Expect(deleteErr).NotTo(HaveOccurred())
+			registeredAddresses, getErr := repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(registeredAddresses).To(BeEmpty())
+		})
+	})
+
+	Describe("GetRegisteredAddresses", func() {
+		It("returns the addresses registered to the registry in the order they were registered", func() {
+			anotherRegisteredAddress := registeredAddress
+			anotherRegisteredAddress.Address = fakes.AnotherFakeAddress.Hex()
+			otherRegistryAddress := registeredAddress
+			otherRegistryAddress.Registry = "markets"
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+			Expect(repo.RegisterAddress(anotherRegisteredAddress)).To(Succeed())
+			Expect(repo.RegisterAddress(otherRegistryAddress)).To(Succeed())
+
+			registeredAddresses, err := repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(withoutIDs(registeredAddresses)).To(Equal([]core.RegisteredAddress{registeredAddress, anotherRegisteredAddress}))
+		})
+
+		It("only returns addresses registered after the given id", func() {
+			anotherRegisteredAddress := registeredAddress
+			anotherRegisteredAddress.Address = fakes.AnotherFakeAddress.Hex()
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+			firstRegistered, firstErr := repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+			Expect(firstErr).NotTo(HaveOccurred())
+			Expect(repo.RegisterAddress(anotherRegisteredAddress)).To(Succeed())
+
+			registeredAddresses, err := repo.GetRegisteredAddresses(registeredAddress.Registry, firstRegistered[0].ID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(withoutIDs(registeredAddresses)).To(Equal([]core.RegisteredAddress{anotherRegisteredAddress}))
+		})
+	})
+
+	Describe("CountRegisteredAddresses", func() {
+		It("counts the addresses registered to the registry", func() {
+			anotherRegisteredAddress := registeredAddress
+			anotherRegisteredAddress.Address = fakes.AnotherFakeAddress.Hex()
+			otherRegistryAddress := registeredAddress
+			otherRegistryAddress.Registry = "markets"
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+			Expect(repo.RegisterAddress(anotherRegisteredAddress)).To(Succeed())
+			Expect(repo.RegisterAddress(otherRegistryAddress)).To(Succeed())
+
+			count, err := repo.CountRegisteredAddresses(registeredAddress.Registry)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("MarkRegisteredAddressesBackfilled", func() {
+		It("marks the registered addresses backfilled", func() {
+			anotherRegisteredAddress := registeredAddress
+			anotherRegisteredAddress.Address = fakes.AnotherFakeAddress.Hex()
+			Expect(repo.RegisterAddress(registeredAddress)).To(Succeed())
+			Expect(repo.RegisterAddress(anotherRegisteredAddress)).To(Succeed())
+			registeredAddresses, getErr := repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+			Expect(getErr).NotTo(HaveOccurred())
+
+			err := repo.MarkRegisteredAddressesBackfilled([]int64{registeredAddresses[0].ID})
+
+			Expect(err).NotTo(HaveOccurred())
+			registeredAddresses, getErr = repo.GetRegisteredAddresses(registeredAddress.Registry, 0)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(registeredAddresses[0].Backfilled).To(BeTrue())
+			Expect(registeredAddresses[1].Backfilled).To(BeFalse())
+		})
+	})
+})
+
+func withoutIDs(registeredAddresses []core.RegisteredAddress) []core.RegisteredAddress {
+	result := make([]core.RegisteredAddress, len(registeredAddresses))
+	for i, registeredAddress := range registeredAddresses {
+		registeredAddress.ID = 0
+		result[i] = registeredAddress
+	}
+	return result
+}
@@ -17,8 +17,89 @@
 package repositories
 
 import (
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
-	"github.com/sirupsen/logrus"
+)
+
+const (
+	insertWatchedLogQuery = `
+INSERT INTO public.watched_logs (contract_address, topic_zero, topic_one, topic_two, topic_three)
+VALUES ($1, $2, COALESCE($3::VARCHAR(66)[], '{}'), COALESCE($4::VARCHAR(66)[], '{}'), COALESCE($5::VARCHAR(66)[], '{}'))
+ON CONFLICT (contract_address, topic_zero, topic_one, topic_two, topic_three)
+	DO UPDATE SET contract_address = EXCLUDED.contract_address
+RETURNING id`
+
+	insertAnyAddressWatchedLogQuery = `
+INSERT INTO public.watched_logs (topic_zero, topic_one, topic_two, topic_three)
+VALUES ($1, COALESCE($2::VARCHAR(66)[], '{}'), COALESCE($3::VARCHAR(66)[], '{}'), COALESCE($4::VARCHAR(66)[], '{}'))
+ON CONFLICT (topic_zero, topic_one, topic_two, topic_three) WHERE contract_address IS NULL AND address_registry IS NULL
+	DO UPDATE SET topic_zero = EXCLUDED.topic_zero
+RETURNING id`
+
+	insertRegistryWatchedLogQuery = `
+INSERT INTO public.watched_logs (address_registry, topic_zero, topic_one, topic_two, topic_three)
+VALUES ($1, $2, COALESCE($3::VARCHAR(66)[], '{}'), COALESCE($4::VARCHAR(66)[], '{}'), COALESCE($5::VARCHAR(66)[], '{}'))
+ON CONFLICT (address_registry, topic_zero, topic_one, topic_two, topic_three) WHERE address_registry IS NOT NULL
+	DO UPDATE SET address_registry = EXCLUDED.address_registry
+RETURNING id`
+
+	insertCheckedLogsQuery = `
+INSERT INTO public.checked_logs (header_id, watched_log_id)
+SELECT $1, UNNEST($2::INTEGER[])
+ON CONFLICT (header_id, watched_log_id) DO UPDATE SET check_count = checked_logs.check_count + 1`
+
+	// Headers after each watched log's checked-through block are checked (and rechecked) as tracked in checked_logs;
+	// headers at or before it only once reset. Each watched log's headers are limited before they're combined so that
+	// a watched log added to a long-synced chain doesn't make the query scan every header.
+	uncheckedHeadersQuery = `
+WITH watched AS (
+	SELECT w.watched_log_id, w.starting_block_number, w.ending_block_number,
+		GREATEST(w.starting_block_number, COALESCE(wl.checked_through_block_number + 1, w.starting_block_number)) AS from_block_number
+	FROM UNNEST($1::INTEGER[], $2::BIGINT[], $3::BIGINT[]) AS w (watched_log_id, starting_block_number, ending_block_number)
+	JOIN public.watched_logs wl ON wl.id = w.watched_log_id
+), max_block AS (
+	SELECT MAX(block_number) AS block_number FROM public.headers
+), checks AS (
+	SELECT unchecked.*
+	FROM watched w
+	CROSS JOIN LATERAL (
+		SELECT h.id, h.block_number, h.hash, w.watched_log_id
+		FROM public.headers h
+		LEFT JOIN public.checked_logs cl
+			ON cl.header_id = h.id AND cl.watched_log_id = w.watched_log_id
+		WHERE h.block_number >= w.from_block_number
+			AND (w.ending_block_number = -1 OR h.block_number <= w.ending_block_number)
+			AND ( COALESCE(cl.check_count, 0) < 1
+				OR (cl.check_count < $4
+					AND h.block_number <= ((SELECT block_number FROM max_block) - ($5 * cl.check_count * (cl.check_count + 1) / 2))))
+		ORDER BY h.block_number
+		LIMIT $6
+	) unchecked
+	UNION
+	SELECT h.id, h.block_number, h.hash, w.watched_log_id
+	FROM watched w
+	JOIN public.checked_logs cl ON cl.watched_log_id = w.watched_log_id
+	JOIN public.headers h ON h.id = cl.header_id
+	WHERE h.block_number >= w.starting_block_number
+		AND h.block_number < w.from_block_number
+		AND cl.check_count < 1
+)
+SELECT id, block_number, hash, ARRAY_AGG(watched_log_id ORDER BY watched_log_id) AS watched_log_ids
+FROM checks
+GROUP BY id, block_number, hash
+ORDER BY block_number
+LIMIT $6`
+
+	markSingleHeaderUncheckedQuery = `
+INSERT INTO public.checked_logs (header_id, watched_log_id, check_count)
+SELECT h.id, wl.id, 0
+FROM public.headers h
+JOIN public.watched_logs wl
+	ON h.block_number <= wl.checked_through_block_number
+	OR EXISTS(SELECT 1 FROM public.checked_logs cl WHERE cl.header_id = h.id AND cl.watched_log_id = wl.id)
+WHERE h.block_number = $1
+ON CONFLICT (header_id, watched_log_id) DO UPDATE SET check_count = 0`
 )
 
 type CheckedLogsRepository struct {
@@ -29,41 +110,82 @@ func NewCheckedLogsRepository(db *postgres.DB) CheckedLogsRepository {
 	return CheckedLogsRepository{db: db}
 }
 
-// Return whether a given address + topic0 has been fetched on a previous run of vDB
-func (repository CheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0 string) (bool, error) {
-	for _, address := range addresses {
-		var addressExists bool
-		getAddressExistsErr := repository.db.Get(&addressExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE contract_address = $1)`, address)
-		if getAddressExistsErr != nil {
-			return false, getAddressExistsErr
-		}
-		if !addressExists {
-			return false, nil
-		}
-	}
-	var topicZeroExists bool
-	getTopicZeroExistsErr := repository.db.Get(&topicZeroExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE topic_zero = $1)`, topic0)
-	if getTopicZeroExistsErr != nil {
-		return false, getTopicZeroExistsErr
-	}
-	return topicZeroExists, nil
+// Increment check_count for each of the given watched logs on the header
+func (repository CheckedLogsRepository) MarkHeaderChecked(headerID int64, watchedLogIDs []int64) error {
+	_, err := repository.db.Exec(insertCheckedLogsQuery, headerID, pq.Array(watchedLogIDs))
+	return err
 }
 
-// Persist that a given address + topic0 has is being fetched on this run of vDB
-func (repository CheckedLogsRepository) MarkLogWatched(addresses []string, topic0 string) error {
-	tx, txErr := repository.db.Beginx()
-	if txErr != nil {
-		return txErr
+// Zero out check count for every watched log on the header with the given block number, including watched logs
+// that were seeded as checked through it
+func (repository CheckedLogsRepository) MarkSingleHeaderUnchecked(blockNumber int64) error {
+	_, err := repository.db.Exec(markSingleHeaderUncheckedQuery, blockNumber)
+	return err
+}
+
+// Return up to limit headers in each watched log's block range along with the watched logs whose check_count < passed
+// checkCount, in block order
+func (repository CheckedLogsRepository) UncheckedHeaders(watchedLogs []core.WatchedLog, checkCount int64, limit int) ([]core.UncheckedHeader, error) {
+	var (
+		ids, startingBlocks, endingBlocks []int64
+		recheckOffsetMultiplier           = 15
+	)
+	for _, watchedLog := range watchedLogs {
+		ids = append(ids, watchedLog.ID)
+		startingBlocks = append(startingBlocks, watchedLog.StartingBlockNumber)
+		endingBlocks = append(endingBlocks, watchedLog.EndingBlockNumber)
+	}
+
+	var rows []struct {
+		Id            int64
+		BlockNumber   int64 `db:"block_number"`
+		Hash          string
+		WatchedLogIDs pq.Int64Array `db:"watched_log_ids"`
+	}
+	err := repository.db.Select(&rows, uncheckedHeadersQuery, pq.Array(ids), pq.Array(startingBlocks),
+		pq.Array(endingBlocks), checkCount, recheckOffsetMultiplier, limit)
+	if err != nil {
+		return nil, err
 	}
-	for _, address := range addresses {
-		_, insertErr := tx.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, address, topic0)
-		if insertErr != nil {
-			rollbackErr := tx.Rollback()
-			if rollbackErr != nil {
-				logrus.Errorf("error rolling back transaction inserting checked logs: %s", rollbackErr.Error())
-			}
-			return insertErr
+
+	result := make([]core.UncheckedHeader, len(rows))
+	for i, row := range rows {
+		result[i] = core.UncheckedHeader{
+			Header:        core.Header{Id: row.Id, BlockNumber: row.BlockNumber, Hash: row.Hash},
+			WatchedLogIDs: row.WatchedLogIDs,
 		}
 	}
-	return tx.Commit()
+	return result, nil
+}
+
+// Persist that a given address + topic0 is being fetched, along with the values accepted in the topic1 through topic3
+// positions (empty accepts any value), returning the id of the watched log. An empty address watches logs emitted by
+// any address.
+func (repository CheckedLogsRepository) WatchLog(address, topic0 string, topicFilters [][]string) (int64, error) {
+	filters := make([][]string, 3)
+	copy(filters, topicFilters)
+	var (
+		id  int64
+		err error
+	)
+	if address == "" {
+		err = repository.db.Get(&id, insertAnyAddressWatchedLogQuery, topic0,
+			pq.Array(filters[0]), pq.Array(filters[1]), pq.Array(filters[2]))
+	} else {
+		err = repository.db.Get(&id, insertWatchedLogQuery, address, topic0,
+			pq.Array(filters[0]), pq.Array(filters[1]), pq.Array(filters[2]))
+	}
+	return id, err
+}
+
+// Persist that a given topic0 (along with the values accepted in the topic1 through topic3 positions) is being fetched
+// from every address registered to the registry, returning the id of the watched log. Headers are checked for the
+// registry as a whole rather than for each registered address.
+func (repository CheckedLogsRepository) WatchRegistryLog(registry, topic0 string, topicFilters [][]string) (int64, error) {
+	filters := make([][]string, 3)
+	copy(filters, topicFilters)
+	var id int64
+	err := repository.db.Get(&id, insertRegistryWatchedLogQuery, registry, topic0,
+		pq.Array(filters[0]), pq.Array(filters[1]), pq.Array(filters[2]))
+	return id, err
 }
@@ -17,7 +17,11 @@
 package repositories_test
 
 import (
+	"math/rand"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
@@ -29,16 +33,17 @@ import (
 
 var _ = Describe("Checked logs repository", func() {
 	var (
-		db            *postgres.DB
-		fakeAddress   = fakes.FakeAddress.Hex()
-		fakeAddresses = []string{fakeAddress}
-		fakeTopicZero = fakes.FakeHash.Hex()
-		repository    datastore.CheckedLogsRepository
+		db               *postgres.DB
+		fakeAddress      = fakes.FakeAddress.Hex()
+		fakeTopicZero    = fakes.FakeHash.Hex()
+		headerRepository datastore.HeaderRepository
+		repository       datastore.CheckedLogsRepository
 	)
 
 	BeforeEach(func() {
 		db = test_config.NewTestDB(test_config.NewTestNode())
 		test_config.CleanTestDB(db)
+		headerRepository = repositories.NewHeaderRepository(db)
 		repository = repositories.NewCheckedLogsRepository(db)
 	})
 
@@ -47,69 +52,307 @@ var _ = Describe("Checked logs repository", func() {
 		Expect(closeErr).NotTo(HaveOccurred())
 	})
 
-	Describe("AlreadyWatchingLog", func() {
-		It("returns true if all addresses and the topic0 are already present in the db", func() {
-			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, fakeTopicZero)
-			Expect(insertErr).NotTo(HaveOccurred())
-
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+	Describe("WatchLog", func() {
+		It("adds a row for the address + topic0", func() {
+			id, err := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(hasBeenChecked).To(BeTrue())
+			var watchedLogID int64
+			getErr := db.Get(&watchedLogID, `SELECT id FROM public.watched_logs WHERE contract_address = $1 AND topic_zero = $2`, fakeAddress, fakeTopicZero)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(id).To(Equal(watchedLogID))
 		})
 
-		It("returns true if addresses and topic0 were fetched because of a combination of other transformers", func() {
-			anotherFakeAddress := common.HexToAddress("0x" + fakes.RandomString(40)).Hex()
+		It("returns the existing id if the address + topic0 is already watched", func() {
+			firstID, firstErr := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+			Expect(firstErr).NotTo(HaveOccurred())
+
+			secondID, secondErr := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(secondID).To(Equal(firstID))
+			var count int
+			getErr := db.Get(&count, `SELECT COUNT(*) FROM public.watched_logs`)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("adds a separate row for another topic0 on the same address", func() {
 			anotherFakeTopicZero := common.HexToHash("0x" + fakes.RandomString(64)).Hex()
-			// insert row with matching address but different topic0
-			_, insertOneErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, anotherFakeTopicZero)
-			Expect(insertOneErr).NotTo(HaveOccurred())
-			// insert row with matching topic0 but different address
-			_, insertTwoErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, anotherFakeAddress, fakeTopicZero)
-			Expect(insertTwoErr).NotTo(HaveOccurred())
+			firstID, firstErr := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+			Expect(firstErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+			secondID, secondErr := repository.WatchLog(fakeAddress, anotherFakeTopicZero, nil)
 
-			Expect(err).NotTo(HaveOccurred())
-			Expect(hasBeenChecked).To(BeTrue())
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(secondID).NotTo(Equal(firstID))
 		})
 
-		It("returns false if any address has not been checked", func() {
-			anotherFakeAddress := common.HexToAddress("0x" + fakes.RandomString(40)).Hex()
-			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, fakeTopicZero)
-			Expect(insertErr).NotTo(HaveOccurred())
+		It("persists the topic filters", func() {
+			topicTwo := []string{common.HexToHash("0x1").Hex(), common.HexToHash("0x2").Hex()}
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(append(fakeAddresses, anotherFakeAddress), fakeTopicZero)
+			id, err := repository.WatchLog(fakeAddress, fakeTopicZero, [][]string{nil, topicTwo})
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(hasBeenChecked).To(BeFalse())
+			var watchedLog struct {
+				TopicOne   pq.StringArray `db:"topic_one"`
+				TopicTwo   pq.StringArray `db:"topic_two"`
+				TopicThree pq.StringArray `db:"topic_three"`
+			}
+			getErr := db.Get(&watchedLog, `SELECT topic_one, topic_two, topic_three FROM public.watched_logs WHERE id = $1`, id)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(watchedLog.TopicOne).To(BeEmpty())
+			Expect([]string(watchedLog.TopicTwo)).To(Equal(topicTwo))
+			Expect(watchedLog.TopicThree).To(BeEmpty())
 		})
 
-		It("returns false if topic0 has not been checked", func() {
-			anotherFakeTopicZero := common.HexToHash("0x" + fakes.RandomString(64)).Hex()
-			_, insertErr := db.Exec(`INSERT INTO public.watched_logs (contract_address, topic_zero) VALUES ($1, $2)`, fakeAddress, anotherFakeTopicZero)
-			Expect(insertErr).NotTo(HaveOccurred())
+		It("adds a separate row for other topic filters on the same address + topic0", func() {
+			firstID, firstErr := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+			Expect(firstErr).NotTo(HaveOccurred())
 
-			hasBeenChecked, err := repository.AlreadyWatchingLog(fakeAddresses, fakeTopicZero)
+			secondID, secondErr := repository.WatchLog(fakeAddress, fakeTopicZero, [][]string{{common.HexToHash("0x1").Hex()}})
 
-			Expect(err).NotTo(HaveOccurred())
-			Expect(hasBeenChecked).To(BeFalse())
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(secondID).NotTo(Equal(firstID))
+		})
+
+		Describe("when the address is empty", func() {
+			It("adds a row without a contract address", func() {
+				id, err := repository.WatchLog("", fakeTopicZero, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				var watchedLogID int64
+				getErr := db.Get(&watchedLogID, `SELECT id FROM public.watched_logs WHERE contract_address IS NULL AND topic_zero = $1`, fakeTopicZero)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(id).To(Equal(watchedLogID))
+			})
+
+			It("returns the existing id if the topic0 is already watched on any address", func() {
+				firstID, firstErr := repository.WatchLog("", fakeTopicZero, nil)
+				Expect(firstErr).NotTo(HaveOccurred())
+
+				secondID, secondErr := repository.WatchLog("", fakeTopicZero, nil)
+
+				Expect(secondErr).NotTo(HaveOccurred())
+				Expect(secondID).To(Equal(firstID))
+			})
+
+			It("adds a separate row from the topic0 watched on an address", func() {
+				firstID, firstErr := repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+				Expect(firstErr).NotTo(HaveOccurred())
+
+				secondID, secondErr := repository.WatchLog("", fakeTopicZero, nil)
+
+				Expect(secondErr).NotTo(HaveOccurred())
+				Expect(secondID).NotTo(Equal(firstID))
+			})
 		})
 	})
 
-	Describe("MarkLogWatched", func() {
-		It("adds a row for all of transformer's addresses + topic0", func() {
-			anotherFakeAddress := common.HexToAddress("0x" + fakes.RandomString(40)).Hex()
-			err := repository.MarkLogWatched(append(fakeAddresses, anotherFakeAddress), fakeTopicZero)
+	Describe("WatchRegistryLog", func() {
+		It("adds a row for the address registry + topic0", func() {
+			id, err := repository.WatchRegistryLog("pairs", fakeTopicZero, nil)
 
 			Expect(err).NotTo(HaveOccurred())
-			var comboOneExists, comboTwoExists bool
-			getComboOneErr := db.Get(&comboOneExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE contract_address = $1 AND topic_zero = $2)`, fakeAddress, fakeTopicZero)
-			Expect(getComboOneErr).NotTo(HaveOccurred())
-			Expect(comboOneExists).To(BeTrue())
-			getComboTwoErr := db.Get(&comboTwoExists, `SELECT EXISTS(SELECT 1 FROM public.watched_logs WHERE contract_address = $1 AND topic_zero = $2)`, anotherFakeAddress, fakeTopicZero)
-			Expect(getComboTwoErr).NotTo(HaveOccurred())
-			Expect(comboTwoExists).To(BeTrue())
+			var watchedLogID int64
+			getErr := db.Get(&watchedLogID, `SELECT id FROM public.watched_logs
+				WHERE address_registry = $1 AND contract_address IS NULL AND topic_zero = $2`, "pairs", fakeTopicZero)
+			Expect(getErr).NotTo(HaveOccurred())
+			Expect(id).To(Equal(watchedLogID))
+		})
+
+		It("returns the existing id if the address registry + topic0 is already watched", func() {
+			firstID, firstErr := repository.WatchRegistryLog("pairs", fakeTopicZero, nil)
+			Expect(firstErr).NotTo(HaveOccurred())
+
+			secondID, secondErr := repository.WatchRegistryLog("pairs", fakeTopicZero, nil)
+
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(secondID).To(Equal(firstID))
+		})
+
+		It("adds a separate row from the topic0 watched on any address", func() {
+			firstID, firstErr := repository.WatchLog("", fakeTopicZero, nil)
+			Expect(firstErr).NotTo(HaveOccurred())
+
+			secondID, secondErr := repository.WatchRegistryLog("pairs", fakeTopicZero, nil)
+
+			Expect(secondErr).NotTo(HaveOccurred())
+			Expect(secondID).NotTo(Equal(firstID))
+		})
+	})
+
+	Describe("checking headers", func() {
+		var (
+			blockNumber                     int64
+			headerID                        int64
+			watchedLogID, otherWatchedLogID int64
+		)
+
+		BeforeEach(func() {
+			blockNumber = rand.Int63n(1000000)
+			var headerErr error
+			headerID, headerErr = headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber))
+			Expect(headerErr).NotTo(HaveOccurred())
+			var watchErr, otherWatchErr error
+			watchedLogID, watchErr = repository.WatchLog(fakeAddress, fakeTopicZero, nil)
+			Expect(watchErr).NotTo(HaveOccurred())
+			otherWatchedLogID, otherWatchErr = repository.WatchLog(fakeAddress, common.HexToHash("0x"+fakes.RandomString(64)).Hex(), nil)
+			Expect(otherWatchErr).NotTo(HaveOccurred())
+		})
+
+		Describe("MarkHeaderChecked", func() {
+			It("marks the header as checked for the passed watched logs on insert", func() {
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID})).To(Succeed())
+
+				Expect(selectCheckedLogs(db, headerID, watchedLogID)).To(Equal(1))
+				Expect(selectCheckedLogs(db, headerID, otherWatchedLogID)).To(BeZero())
+			})
+
+			It("increments check count on update", func() {
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID, otherWatchedLogID})).To(Succeed())
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID})).To(Succeed())
+
+				Expect(selectCheckedLogs(db, headerID, watchedLogID)).To(Equal(2))
+				Expect(selectCheckedLogs(db, headerID, otherWatchedLogID)).To(Equal(1))
+			})
+		})
+
+		Describe("MarkSingleHeaderUnchecked", func() {
+			It("zeroes check counts for every watched log on headers with the matching block number", func() {
+				otherHeaderID, otherHeaderErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 1))
+				Expect(otherHeaderErr).NotTo(HaveOccurred())
+				watchedLogIDs := []int64{watchedLogID, otherWatchedLogID}
+				Expect(repository.MarkHeaderChecked(headerID, watchedLogIDs)).To(Succeed())
+				Expect(repository.MarkHeaderChecked(otherHeaderID, watchedLogIDs)).To(Succeed())
+
+				Expect(repository.MarkSingleHeaderUnchecked(blockNumber)).To(Succeed())
+
+				Expect(selectCheckedLogs(db, headerID, watchedLogID)).To(BeZero())
+				Expect(selectCheckedLogs(db, headerID, otherWatchedLogID)).To(BeZero())
+				Expect(selectCheckedLogs(db, otherHeaderID, watchedLogID)).To(Equal(1))
+			})
+
+			It("resets the header for watched logs seeded as checked through it", func() {
+				_, updateErr := db.Exec(`UPDATE public.watched_logs SET checked_through_block_number = $1 WHERE id = $2`,
+					blockNumber, watchedLogID)
+				Expect(updateErr).NotTo(HaveOccurred())
+
+				Expect(repository.MarkSingleHeaderUnchecked(blockNumber)).To(Succeed())
+
+				var checkedLogsCount int
+				Expect(db.Get(&checkedLogsCount, `SELECT COUNT(*) FROM public.checked_logs WHERE header_id = $1 AND watched_log_id = $2 AND check_count = 0`,
+					headerID, watchedLogID)).To(Succeed())
+				Expect(checkedLogsCount).To(Equal(1))
+				watchedLogs := []core.WatchedLog{{ID: watchedLogID, StartingBlockNumber: blockNumber, EndingBlockNumber: -1}}
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, 10)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(headerID))
+			})
+		})
+
+		Describe("UncheckedHeaders", func() {
+			var (
+				limit       = 10
+				watchedLogs []core.WatchedLog
+			)
+
+			BeforeEach(func() {
+				watchedLogs = []core.WatchedLog{
+					{ID: watchedLogID, StartingBlockNumber: blockNumber, EndingBlockNumber: -1},
+					{ID: otherWatchedLogID, StartingBlockNumber: blockNumber, EndingBlockNumber: -1},
+				}
+			})
+
+			It("returns headers with every watched log that has not been checked", func() {
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, limit)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(headerID))
+				Expect(uncheckedHeaders[0].Header.BlockNumber).To(Equal(blockNumber))
+				Expect(uncheckedHeaders[0].WatchedLogIDs).To(ConsistOf(watchedLogID, otherWatchedLogID))
+			})
+
+			It("excludes watched logs the header has already been checked for", func() {
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID})).To(Succeed())
+
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, limit)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].WatchedLogIDs).To(ConsistOf(otherWatchedLogID))
+			})
+
+			It("excludes headers that have been checked for every watched log", func() {
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID, otherWatchedLogID})).To(Succeed())
+
+				Expect(repository.UncheckedHeaders(watchedLogs, 1, limit)).To(BeEmpty())
+			})
+
+			It("only returns headers within each watched log's block range", func() {
+				laterHeaderID, laterHeaderErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 1))
+				Expect(laterHeaderErr).NotTo(HaveOccurred())
+				watchedLogs[0].EndingBlockNumber = blockNumber
+				watchedLogs[1].StartingBlockNumber = blockNumber + 1
+
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, limit)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(2))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(headerID))
+				Expect(uncheckedHeaders[0].WatchedLogIDs).To(ConsistOf(watchedLogID))
+				Expect(uncheckedHeaders[1].Header.Id).To(Equal(laterHeaderID))
+				Expect(uncheckedHeaders[1].WatchedLogIDs).To(ConsistOf(otherWatchedLogID))
+			})
+
+			It("excludes headers through the watched log's seeded checked-through block", func() {
+				laterHeaderID, laterHeaderErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 1))
+				Expect(laterHeaderErr).NotTo(HaveOccurred())
+				_, updateErr := db.Exec(`UPDATE public.watched_logs SET checked_through_block_number = $1`, blockNumber)
+				Expect(updateErr).NotTo(HaveOccurred())
+
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, limit)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(laterHeaderID))
+			})
+
+			It("returns at most limit headers, in block order", func() {
+				_, laterHeaderErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(blockNumber + 1))
+				Expect(laterHeaderErr).NotTo(HaveOccurred())
+
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 1, 1)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(headerID))
+			})
+
+			It("returns checked headers far enough behind the head when rechecking", func() {
+				recheckBlockNumber := blockNumber + 15
+				_, recheckHeaderErr := headerRepository.CreateOrUpdateHeader(fakes.GetFakeHeader(recheckBlockNumber))
+				Expect(recheckHeaderErr).NotTo(HaveOccurred())
+				Expect(repository.MarkHeaderChecked(headerID, []int64{watchedLogID, otherWatchedLogID})).To(Succeed())
+				watchedLogs = watchedLogs[:1]
+				watchedLogs[0].EndingBlockNumber = blockNumber
+
+				Expect(repository.UncheckedHeaders(watchedLogs, 1, limit)).To(BeEmpty())
+
+				uncheckedHeaders, err := repository.UncheckedHeaders(watchedLogs, 2, limit)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(uncheckedHeaders)).To(Equal(1))
+				Expect(uncheckedHeaders[0].Header.Id).To(Equal(headerID))
+			})
 		})
 	})
 })
+
+func selectCheckedLogs(db *postgres.DB, headerID, watchedLogID int64) (int, error) {
+	var checkCount int
+	err := db.Get(&checkCount, `SELECT COALESCE((SELECT check_count FROM public.checked_logs WHERE header_id = $1 AND watched_log_id = $2), 0)`, headerID, watchedLogID)
+	return checkCount, err
+}
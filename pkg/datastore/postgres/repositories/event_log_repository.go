@@ -17,8 +17,12 @@
 package repositories
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jmoiron/sqlx"
@@ -26,20 +30,26 @@ import (
 	"github.com/makerdao/vulcanizedb/libraries/shared/repository"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/makerdao/vulcanizedb/pkg/eth"
 	"github.com/sirupsen/logrus"
 )
 
 const insertEventLogQuery = `INSERT INTO public.event_logs
-		(header_id, address, topics, data, block_number, block_hash, tx_index, tx_hash, log_index, raw)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING`
+		(header_id, address, topics, data, block_number, block_hash, tx_index, tx_hash, log_index, raw, event_name, decoded)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING`
 
+// EventLogRepository persists event logs, decoding those emitted by addresses with an ABI in public.abis. Each
+// address's ABI, or the lack of one, is looked up the first time a log from it is persisted and cached until this
+// process saves an ABI. ABIs saved by other processes are picked up once the repository is recreated.
 type EventLogRepository struct {
-	db *postgres.DB
+	db   *postgres.DB
+	abis *abiCache
 }
 
 func NewEventLogRepository(db *postgres.DB) EventLogRepository {
 	return EventLogRepository{
-		db: db,
+		db:   db,
+		abis: newAbiCache(),
 	}
 }
 
@@ -54,53 +64,227 @@ type rawEventLog struct {
 	TxHash      string `db:"tx_hash"`
 	TxIndex     uint   `db:"tx_index"`
 	LogIndex    uint   `db:"log_index"`
-	Transformed bool
 	Raw         []byte
+	Transformed bool
 }
 
-func (repo EventLogRepository) GetUntransformedEventLogs(minID, limit int) ([]core.EventLog, error) {
+// Returns logs with the given topic0 that the named transformer hasn't handled, ordered by id: those without a
+// transform error, and those it failed on that are due to be retried. The first time a transformer's logs are fetched,
+// logs flagged transformed before transformed logs were tracked per transformer are recorded as handled by it.
+func (repo EventLogRepository) GetUntransformedEventLogs(transformer string, topic0 common.Hash, minID, limit int) ([]core.EventLog, error) {
+	seedErr := repo.seedLegacyTransformedLogs(transformer, topic0)
+	if seedErr != nil {
+		return nil, seedErr
+	}
 	var rawLogs []rawEventLog
-	query := fmt.Sprintf("SELECT id, header_id, address, topics, data, block_number, block_hash,"+
-		"tx_hash, tx_index, log_index, transformed, raw FROM public.event_logs "+
-		"WHERE transformed = false AND id > %d ORDER BY id ASC LIMIT %d", minID, limit)
-	err := repo.db.Select(&rawLogs, query)
+	err := repo.db.Select(&rawLogs, `SELECT id, header_id, address, topics, data, block_number, block_hash,
+		tx_hash, tx_index, log_index, raw, transformed FROM public.event_logs
+			LEFT JOIN public.transform_errors
+				ON transform_errors.log_id = event_logs.id AND transform_errors.transformer = $3
+		WHERE topics[1] = $1 AND id > $2
+			AND NOT EXISTS (SELECT 1 FROM public.transformed_logs
+				WHERE transformed_logs.log_id = event_logs.id AND transformed_logs.transformer = $3)
+			AND (transform_errors.log_id IS NULL OR (transform_errors.quarantined = false
+				AND (transform_errors.next_retry IS NULL OR transform_errors.next_retry <= NOW())))
+		ORDER BY id ASC LIMIT $4`, topic0.Bytes(), minID, transformer, limit)
 	if err != nil {
 		return nil, err
 	}
-	var results []core.EventLog
+	return repo.toEventLogs(rawLogs)
+}
+
+func (repo EventLogRepository) seedLegacyTransformedLogs(transformer string, topic0 common.Hash) error {
+	_, err := repo.db.Exec(`WITH seeded AS (
+			INSERT INTO public.legacy_seeded_transformers (transformer) VALUES ($1)
+			ON CONFLICT DO NOTHING
+			RETURNING transformer
+		)
+		INSERT INTO public.transformed_logs (log_id, transformer)
+		SELECT legacy_transformed_logs.log_id, seeded.transformer
+		FROM seeded, public.legacy_transformed_logs
+			JOIN public.event_logs ON event_logs.id = legacy_transformed_logs.log_id
+		WHERE event_logs.topics[1] = $2
+		ON CONFLICT DO NOTHING`, transformer, topic0.Bytes())
+	if err != nil {
+		return fmt.Errorf("error seeding event logs transformed by %s: %w", transformer, err)
+	}
+	return nil
+}
+
+// Returns logs with the given topic0 between the given blocks, whether or not the named transformer has transformed
+// them, ordered by id. Logs quarantined for the transformer are skipped.
+func (repo EventLogRepository) GetEventLogsInRange(transformer string, topic0 common.Hash, startingBlock, endingBlock int64, minID, limit int) ([]core.EventLog, error) {
+	var rawLogs []rawEventLog
+	err := repo.db.Select(&rawLogs, `SELECT id, header_id, address, topics, data, block_number, block_hash,
+		tx_hash, tx_index, log_index, raw, transformed FROM public.event_logs
+		WHERE topics[1] = $1 AND block_number BETWEEN $2 AND $3 AND id > $4
+			AND NOT EXISTS (SELECT 1 FROM public.transform_errors
+				WHERE transform_errors.log_id = event_logs.id AND transform_errors.transformer = $5
+					AND transform_errors.quarantined = true)
+		ORDER BY id ASC LIMIT $6`, topic0.Bytes(), startingBlock, endingBlock, minID, transformer, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toEventLogs(rawLogs)
+}
+
+// Returns logs the named transformer no longer transforms after failing on them too many times, ordered by id
+func (repo EventLogRepository) GetQuarantinedEventLogs(transformer string) ([]core.QuarantinedEventLog, error) {
+	var rawLogs []struct {
+		rawEventLog
+		ErrorCount int64          `db:"error_count"`
+		LastError  sql.NullString `db:"last_error"`
+	}
+	err := repo.db.Select(&rawLogs, `SELECT id, header_id, address, topics, data, block_number, block_hash,
+		tx_hash, tx_index, log_index, raw, transformed, error_count, last_error
+		FROM public.event_logs
+			JOIN public.transform_errors ON transform_errors.log_id = event_logs.id
+		WHERE transform_errors.transformer = $1 AND transform_errors.quarantined = true
+		ORDER BY id ASC`, transformer)
+	if err != nil {
+		return nil, fmt.Errorf("error getting event logs quarantined by %s: %w", transformer, err)
+	}
+	var results []core.QuarantinedEventLog
 	for _, rawLog := range rawLogs {
-		var logTopics []common.Hash
-		for _, topic := range rawLog.Topics {
-			logTopics = append(logTopics, common.BytesToHash(topic))
-		}
-		address, addrErr := repository.GetAddressById(repo.db, rawLog.Address)
-		if addrErr != nil {
-			return nil, addrErr
-		}
-		reconstructedLog := types.Log{
-			Address:     common.HexToAddress(address),
-			Topics:      logTopics,
-			Data:        rawLog.Data,
-			BlockNumber: rawLog.BlockNumber,
-			TxHash:      common.HexToHash(rawLog.TxHash),
-			TxIndex:     rawLog.TxIndex,
-			BlockHash:   common.HexToHash(rawLog.BlockHash),
-			Index:       rawLog.LogIndex,
-			// TODO: revisit if not cascade deleting logs when header removed
-			// currently, fetched logs are cascade deleted if removed
-			Removed: false,
+		eventLog, convertErr := repo.toEventLog(rawLog.rawEventLog)
+		if convertErr != nil {
+			return nil, convertErr
 		}
-		result := core.EventLog{
-			ID:          rawLog.ID,
-			HeaderID:    rawLog.HeaderID,
-			Log:         reconstructedLog,
-			Transformed: rawLog.Transformed,
+		results = append(results, core.QuarantinedEventLog{
+			EventLog:            eventLog,
+			TransformErrorCount: rawLog.ErrorCount,
+			LastTransformError:  rawLog.LastError.String,
+		})
+	}
+	return results, nil
+}
+
+// Records that the named transformer failed to transform the log, quarantining it for the transformer once it has
+// failed maxErrors times. Otherwise the log isn't returned to the transformer as untransformed again until the backoff
+// has passed, doubling with each failure. Returns whether the log is quarantined.
+func (repo EventLogRepository) RecordTransformError(logID int64, transformer string, transformErr error, maxErrors int64, backoff time.Duration) (bool, error) {
+	var quarantined bool
+	err := repo.db.Get(&quarantined, `INSERT INTO public.transform_errors
+			(log_id, transformer, error_count, last_error, quarantined, next_retry)
+		VALUES ($1, $2, 1, $3, 1 >= $4, NOW() + $5 * INTERVAL '1 millisecond')
+		ON CONFLICT (log_id, transformer) DO UPDATE
+		SET error_count = transform_errors.error_count + 1,
+			last_error = excluded.last_error,
+			quarantined = transform_errors.error_count + 1 >= $4,
+			next_retry = NOW() + $5 * POWER(2, LEAST(transform_errors.error_count, 16)) * INTERVAL '1 millisecond'
+		RETURNING quarantined`, logID, transformer, transformErr.Error(), maxErrors, backoff.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("error recording transform error by %s for log %d: %w", transformer, logID, err)
+	}
+	return quarantined, nil
+}
+
+// Releases the logs with the given ids quarantined for the named transformer, or all its quarantined logs if none are
+// given, so that it transforms them again. Returns the number of logs released.
+func (repo EventLogRepository) RetryQuarantinedEventLogs(transformer string, logIDs []int64) (int64, error) {
+	result, err := repo.db.Exec(`UPDATE public.transform_errors
+		SET quarantined = false, error_count = 0, last_error = NULL, next_retry = NULL
+		WHERE transformer = $1 AND quarantined = true
+			AND (COALESCE(cardinality($2::BIGINT[]), 0) = 0 OR log_id = ANY($2::BIGINT[]))`, transformer, pq.Array(logIDs))
+	if err != nil {
+		return 0, fmt.Errorf("error retrying event logs quarantined by %s: %w", transformer, err)
+	}
+	return result.RowsAffected()
+}
+
+// Records that the named transformer has handled the logs with the given ids, clearing any transform errors it had on
+// them. The deprecated event_logs.transformed flag is set on the logs too.
+func (repo EventLogRepository) MarkEventLogsTransformed(transformer string, logIDs []int64) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+	_, err := repo.db.Exec(`WITH cleared AS (
+			DELETE FROM public.transform_errors WHERE transformer = $1 AND log_id = ANY($2::BIGINT[])
+		), flagged AS (
+			UPDATE public.event_logs SET transformed = true WHERE id = ANY($2::BIGINT[]) AND transformed = false
+		)
+		INSERT INTO public.transformed_logs (log_id, transformer)
+		SELECT UNNEST($2::BIGINT[]), $1
+		ON CONFLICT DO NOTHING`, transformer, pq.Array(logIDs))
+	if err != nil {
+		return fmt.Errorf("error marking event logs transformed by %s: %w", transformer, err)
+	}
+	return nil
+}
+
+// Forgets the logs the named transformer has handled and its transform errors, so that it transforms every log again
+func (repo EventLogRepository) ResetTransformedEventLogs(transformer string) error {
+	tx, txErr := repo.db.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	_, deleteErrorsErr := tx.Exec(`DELETE FROM public.transform_errors WHERE transformer = $1`, transformer)
+	if deleteErrorsErr != nil {
+		return rollbackResetErr(tx, transformer, deleteErrorsErr)
+	}
+	_, deleteLogsErr := tx.Exec(`DELETE FROM public.transformed_logs WHERE transformer = $1`, transformer)
+	if deleteLogsErr != nil {
+		return rollbackResetErr(tx, transformer, deleteLogsErr)
+	}
+	// Logs transformed before they were tracked per transformer mustn't be seeded again
+	_, seededErr := tx.Exec(`INSERT INTO public.legacy_seeded_transformers (transformer) VALUES ($1)
+		ON CONFLICT DO NOTHING`, transformer)
+	if seededErr != nil {
+		return rollbackResetErr(tx, transformer, seededErr)
+	}
+	return tx.Commit()
+}
+
+func rollbackResetErr(tx *sqlx.Tx, transformer string, err error) error {
+	rollbackErr := tx.Rollback()
+	if rollbackErr != nil {
+		logrus.Errorf("failed to rollback resetting event logs transformed by %s: %s", transformer, rollbackErr.Error())
+	}
+	return fmt.Errorf("error resetting event logs transformed by %s: %w", transformer, err)
+}
+
+func (repo EventLogRepository) toEventLogs(rawLogs []rawEventLog) ([]core.EventLog, error) {
+	var results []core.EventLog
+	for _, rawLog := range rawLogs {
+		result, convertErr := repo.toEventLog(rawLog)
+		if convertErr != nil {
+			return nil, convertErr
 		}
 		results = append(results, result)
 	}
 	return results, nil
 }
 
+func (repo EventLogRepository) toEventLog(rawLog rawEventLog) (core.EventLog, error) {
+	var logTopics []common.Hash
+	for _, topic := range rawLog.Topics {
+		logTopics = append(logTopics, common.BytesToHash(topic))
+	}
+	address, addrErr := repository.GetAddressById(repo.db, rawLog.Address)
+	if addrErr != nil {
+		return core.EventLog{}, addrErr
+	}
+	reconstructedLog := types.Log{
+		Address:     common.HexToAddress(address),
+		Topics:      logTopics,
+		Data:        rawLog.Data,
+		BlockNumber: rawLog.BlockNumber,
+		TxHash:      common.HexToHash(rawLog.TxHash),
+		TxIndex:     rawLog.TxIndex,
+		BlockHash:   common.HexToHash(rawLog.BlockHash),
+		Index:       rawLog.LogIndex,
+		// TODO: revisit if not cascade deleting logs when header removed
+		// currently, fetched logs are cascade deleted if removed
+		Removed: false,
+	}
+	return core.EventLog{
+		ID:          rawLog.ID,
+		HeaderID:    rawLog.HeaderID,
+		Log:         reconstructedLog,
+		Transformed: rawLog.Transformed,
+	}, nil
+}
+
 func (repo EventLogRepository) CreateEventLogs(headerID int64, logs []types.Log) error {
 	tx, txErr := repo.db.Beginx()
 	if txErr != nil {
@@ -129,11 +313,57 @@ func (repo EventLogRepository) insertLog(headerID int64, log types.Log, tx *sqlx
 	if addrErr != nil {
 		return addrErr
 	}
+	eventName, decoded, decodeErr := repo.decodeLog(log, tx)
+	if decodeErr != nil {
+		return decodeErr
+	}
 	_, insertErr := tx.Exec(insertEventLogQuery, headerID, addressID, topics, log.Data, log.BlockNumber,
-		log.BlockHash.Hex(), log.TxIndex, log.TxHash.Hex(), log.Index, raw)
+		log.BlockHash.Hex(), log.TxIndex, log.TxHash.Hex(), log.Index, raw, eventName, decoded)
 	return insertErr
 }
 
+// Returns the event name and JSON encoded arguments of the log, or nils if it can't be decoded
+func (repo EventLogRepository) decodeLog(log types.Log, tx *sqlx.Tx) (interface{}, interface{}, error) {
+	contractAbi, abiErr := repo.getAbi(log.Address, tx)
+	if abiErr != nil || contractAbi == nil {
+		return nil, nil, abiErr
+	}
+	eventName, args, decodeErr := eth.DecodeLog(*contractAbi, log)
+	if decodeErr != nil {
+		logrus.Debugf("not decoding log %d in tx %s: %s", log.Index, log.TxHash.Hex(), decodeErr.Error())
+		return nil, nil, nil
+	}
+	decoded, jsonErr := json.Marshal(args)
+	if jsonErr != nil {
+		return nil, nil, fmt.Errorf("error encoding decoded log %d in tx %s: %w", log.Index, log.TxHash.Hex(), jsonErr)
+	}
+	return eventName, string(decoded), nil
+}
+
+func (repo EventLogRepository) getAbi(address common.Address, tx *sqlx.Tx) (*abi.ABI, error) {
+	contractAbi, cached, generation := repo.abis.get(address)
+	if cached {
+		return contractAbi, nil
+	}
+	var abiJSON string
+	getErr := tx.Get(&abiJSON, `SELECT abi FROM public.abis WHERE address = $1`, address.Hex())
+	if getErr != nil && getErr != sql.ErrNoRows {
+		return nil, fmt.Errorf("error getting abi for %s: %w", address.Hex(), getErr)
+	}
+	if getErr == sql.ErrNoRows {
+		repo.abis.set(address, nil, generation)
+		return nil, nil
+	}
+	parsedAbi, parseErr := eth.ParseAbi(abiJSON)
+	if parseErr != nil {
+		logrus.Warnf("not decoding logs emitted by %s: %s", address.Hex(), parseErr.Error())
+		repo.abis.set(address, nil, generation)
+		return nil, nil
+	}
+	repo.abis.set(address, &parsedAbi, generation)
+	return &parsedAbi, nil
+}
+
 func buildTopics(log types.Log) pq.ByteaArray {
 	var topics pq.ByteaArray
 	for _, topic := range log.Topics {
@@ -17,12 +17,18 @@
 package repositories_test
 
 import (
+	"database/sql"
+	"math/big"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/libraries/shared/repository"
 	"github.com/makerdao/vulcanizedb/libraries/shared/test_data"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
 	"github.com/makerdao/vulcanizedb/test_config"
@@ -60,7 +66,6 @@ var _ = Describe("Event log repository", func() {
 			TxHash      string `db:"tx_hash"`
 			TxIndex     uint   `db:"tx_index"`
 			LogIndex    uint   `db:"log_index"`
-			Transformed bool
 			Raw         []byte
 		}
 
@@ -72,7 +77,7 @@ var _ = Describe("Event log repository", func() {
 			Expect(err).NotTo(HaveOccurred())
 			var dbLog rawEventLog
 			lookupErr := db.Get(&dbLog, `SELECT id, header_id, address, topics, data, block_number, block_hash,
-       			tx_hash, tx_index, log_index, transformed, raw FROM public.event_logs`)
+       			tx_hash, tx_index, log_index, raw FROM public.event_logs`)
 			Expect(lookupErr).NotTo(HaveOccurred())
 			Expect(dbLog.ID).NotTo(BeZero())
 			Expect(dbLog.HeaderID).To(Equal(headerID))
@@ -90,7 +95,6 @@ var _ = Describe("Event log repository", func() {
 			expectedRaw, jsonErr := log.MarshalJSON()
 			Expect(jsonErr).NotTo(HaveOccurred())
 			Expect(dbLog.Raw).To(MatchJSON(expectedRaw))
-			Expect(dbLog.Transformed).To(BeFalse())
 		})
 
 		It("writes several logs to the db", func() {
@@ -121,7 +125,7 @@ var _ = Describe("Event log repository", func() {
 
 			var dbLog rawEventLog
 			lookupErr := db.Get(&dbLog, `SELECT id, header_id, address, topics, data, block_number, block_hash, 
-       			tx_hash, tx_index, log_index, transformed, raw FROM public.event_logs`)
+       			tx_hash, tx_index, log_index, raw FROM public.event_logs`)
 			Expect(lookupErr).NotTo(HaveOccurred())
 
 			var logTopics []common.Hash
@@ -156,23 +160,124 @@ var _ = Describe("Event log repository", func() {
 			Expect(lookupErr).NotTo(HaveOccurred())
 			Expect(count).To(Equal(1))
 		})
+
+		It("decodes logs emitted by addresses with an abi", func() {
+			log := test_data.GenericTestLog()
+			log.Topics = []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.HexToHash("0x1"),
+				common.HexToHash("0x2"),
+			}
+			log.Data = common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			saveErr := repositories.NewAbiRepository(db).SaveAbi(log.Address.Hex(), test_data.TransferAbi)
+			Expect(saveErr).NotTo(HaveOccurred())
+
+			err := repo.CreateEventLogs(headerID, []types.Log{log})
+
+			Expect(err).NotTo(HaveOccurred())
+			var decodedLog struct {
+				EventName string `db:"event_name"`
+				Decoded   []byte
+			}
+			lookupErr := db.Get(&decodedLog, `SELECT event_name, decoded FROM public.event_logs`)
+			Expect(lookupErr).NotTo(HaveOccurred())
+			Expect(decodedLog.EventName).To(Equal("Transfer"))
+			Expect(decodedLog.Decoded).To(MatchJSON(`{"from": "` + common.HexToAddress("0x1").Hex() + `", "to": "` +
+				common.HexToAddress("0x2").Hex() + `", "value": 1000}`))
+		})
+
+		It("leaves logs undecoded if their address has no abi", func() {
+			log := test_data.GenericTestLog()
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+
+			err := repo.CreateEventLogs(headerID, []types.Log{log})
+
+			Expect(err).NotTo(HaveOccurred())
+			var decodedLog struct {
+				EventName sql.NullString `db:"event_name"`
+				Decoded   []byte
+			}
+			lookupErr := db.Get(&decodedLog, `SELECT event_name, decoded FROM public.event_logs`)
+			Expect(lookupErr).NotTo(HaveOccurred())
+			Expect(decodedLog.EventName.Valid).To(BeFalse())
+			Expect(decodedLog.Decoded).To(BeNil())
+		})
+
+		It("decodes logs emitted by an address once an abi is saved for it", func() {
+			undecodedLog := test_data.GenericTestLog()
+			test_data.CreateMatchingTx(undecodedLog, headerID, headerRepository)
+			Expect(repo.CreateEventLogs(headerID, []types.Log{undecodedLog})).To(Succeed())
+			log := test_data.GenericTestLog()
+			log.Address = undecodedLog.Address
+			log.Topics = []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.HexToHash("0x1"),
+				common.HexToHash("0x2"),
+			}
+			log.Data = common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			saveErr := repositories.NewAbiRepository(db).SaveAbi(log.Address.Hex(), test_data.TransferAbi)
+			Expect(saveErr).NotTo(HaveOccurred())
+
+			err := repo.CreateEventLogs(headerID, []types.Log{log})
+
+			Expect(err).NotTo(HaveOccurred())
+			var eventName sql.NullString
+			lookupErr := db.Get(&eventName, `SELECT event_name FROM public.event_logs WHERE tx_hash = $1 AND log_index = $2`,
+				log.TxHash.Hex(), log.Index)
+			Expect(lookupErr).NotTo(HaveOccurred())
+			Expect(eventName.String).To(Equal("Transfer"))
+		})
+
+		It("caches that an address has no abi until one is saved", func() {
+			undecodedLog := test_data.GenericTestLog()
+			test_data.CreateMatchingTx(undecodedLog, headerID, headerRepository)
+			Expect(repo.CreateEventLogs(headerID, []types.Log{undecodedLog})).To(Succeed())
+			log := test_data.GenericTestLog()
+			log.Address = undecodedLog.Address
+			log.Topics = []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.HexToHash("0x1"),
+				common.HexToHash("0x2"),
+			}
+			log.Data = common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			// inserted without SaveAbi, so the cached lookup isn't invalidated
+			db.MustExec(`INSERT INTO public.abis (address, abi) VALUES ($1, $2)`, log.Address.Hex(), test_data.TransferAbi)
+
+			err := repo.CreateEventLogs(headerID, []types.Log{log})
+
+			Expect(err).NotTo(HaveOccurred())
+			var eventName sql.NullString
+			lookupErr := db.Get(&eventName, `SELECT event_name FROM public.event_logs WHERE tx_hash = $1 AND log_index = $2`,
+				log.TxHash.Hex(), log.Index)
+			Expect(lookupErr).NotTo(HaveOccurred())
+			Expect(eventName.Valid).To(BeFalse())
+		})
 	})
 
 	Describe("GetUntransformedEventLogs", func() {
+		const transformerName = "transformer"
+
 		Describe("when there are no logs", func() {
 			It("returns empty collection", func() {
-				result, err := repo.GetUntransformedEventLogs(0, 1)
+				result, err := repo.GetUntransformedEventLogs(transformerName, test_data.GenericTestLog().Topics[0], 0, 1)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(BeZero())
 			})
 		})
 
 		Describe("when there are logs", func() {
-			var log1, log2 types.Log
+			var (
+				log1, log2 types.Log
+				topic0     common.Hash
+			)
 
 			BeforeEach(func() {
 				log1 = test_data.GenericTestLog()
 				log2 = test_data.GenericTestLog()
+				topic0 = log1.Topics[0]
 				test_data.CreateMatchingTx(log1, headerID, headerRepository)
 				test_data.CreateMatchingTx(log2, headerID, headerRepository)
 
@@ -182,7 +287,7 @@ var _ = Describe("Event log repository", func() {
 			})
 
 			It("returns persisted logs", func() {
-				result, err := repo.GetUntransformedEventLogs(0, 2)
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(Equal(2))
@@ -191,40 +296,429 @@ var _ = Describe("Event log repository", func() {
 				Expect(result[0].Log).NotTo(Equal(result[1].Log))
 			})
 
-			It("excludes logs that have been transformed", func() {
-				_, insertErr := db.Exec(`UPDATE public.event_logs SET transformed = true WHERE tx_hash = $1`, log1.TxHash.Hex())
-				Expect(insertErr).NotTo(HaveOccurred())
+			It("excludes logs with a different topic0", func() {
+				result, err := repo.GetUntransformedEventLogs(transformerName, test_data.FakeHash(), 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(BeZero())
+			})
+
+			It("excludes logs the transformer has transformed", func() {
+				Expect(repo.MarkEventLogsTransformed(transformerName, []int64{getLogID(db, log1)})).To(Succeed())
 
-				result, err := repo.GetUntransformedEventLogs(0, 2)
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(Equal(1))
 				Expect(result[0].Log).To(Equal(log2))
 			})
 
+			It("includes logs with lower ids than logs the transformer has transformed", func() {
+				Expect(repo.MarkEventLogsTransformed(transformerName, []int64{getLogID(db, log2)})).To(Succeed())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+				Expect(result[0].Log).To(Equal(log1))
+			})
+
+			It("includes logs that have only been transformed by other transformers", func() {
+				Expect(repo.MarkEventLogsTransformed("otherTransformer", []int64{getLogID(db, log2)})).To(Succeed())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(2))
+			})
+
 			It("returns empty collection if all logs transformed", func() {
-				_, insertErr := db.Exec(`UPDATE public.event_logs SET transformed = true WHERE header_id = $1`, headerID)
-				Expect(insertErr).NotTo(HaveOccurred())
+				Expect(repo.MarkEventLogsTransformed(transformerName,
+					[]int64{getLogID(db, log1), getLogID(db, log2)})).To(Succeed())
 
-				result, err := repo.GetUntransformedEventLogs(0, 2)
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(result)).To(BeZero())
 			})
 
+			It("excludes logs transformed before transformed logs were tracked per transformer", func() {
+				insertLegacyTransformedLog(db, getLogID(db, log1))
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+				Expect(result[0].Log).To(Equal(log2))
+				Expect(getTransformedLogIDs(db, transformerName)).To(ConsistOf(getLogID(db, log1)))
+			})
+
+			It("only seeds a transformer's transformed logs once", func() {
+				_, seedErr := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+				Expect(seedErr).NotTo(HaveOccurred())
+				insertLegacyTransformedLog(db, getLogID(db, log1))
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(2))
+			})
+
+			It("includes logs transformed before transformed logs were tracked once the transformer is reset", func() {
+				insertLegacyTransformedLog(db, getLogID(db, log1))
+				Expect(repo.ResetTransformedEventLogs(transformerName)).To(Succeed())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(2))
+			})
+
+			It("includes logs the transformer failed on once they're due to be retried", func() {
+				_, recordErr := repo.RecordTransformError(getLogID(db, log1), transformerName, fakes.FakeError, 3, 0)
+				Expect(recordErr).NotTo(HaveOccurred())
+				Expect(repo.MarkEventLogsTransformed(transformerName, []int64{getLogID(db, log2)})).To(Succeed())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+				Expect(result[0].Log).To(Equal(log1))
+			})
+
 			It("enables seeking logs with greater ID", func() {
 				limit := 1
-				resultOne, errOne := repo.GetUntransformedEventLogs(0, limit)
+				resultOne, errOne := repo.GetUntransformedEventLogs(transformerName, topic0, 0, limit)
 				Expect(errOne).NotTo(HaveOccurred())
 				Expect(len(resultOne)).To(Equal(limit))
 
 				nextMinID := int(resultOne[0].ID)
-				resultTwo, errTwo := repo.GetUntransformedEventLogs(nextMinID, limit)
+				resultTwo, errTwo := repo.GetUntransformedEventLogs(transformerName, topic0, nextMinID, limit)
 				Expect(errTwo).NotTo(HaveOccurred())
 				Expect(len(resultTwo)).To(Equal(1))
 
 				Expect(resultTwo[0].ID > resultOne[0].ID).To(BeTrue())
 			})
+
+			It("excludes logs that have been quarantined for the transformer", func() {
+				_, recordErr := repo.RecordTransformError(getLogID(db, log1), transformerName, fakes.FakeError, 1, 0)
+				Expect(recordErr).NotTo(HaveOccurred())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(1))
+				Expect(result[0].Log).To(Equal(log2))
+			})
+
+			It("includes logs that have only been quarantined for other transformers", func() {
+				_, recordErr := repo.RecordTransformError(getLogID(db, log1), "otherTransformer", fakes.FakeError, 1, 0)
+				Expect(recordErr).NotTo(HaveOccurred())
+
+				result, err := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 2)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(result)).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("GetEventLogsInRange", func() {
+		const transformerName = "transformer"
+
+		var (
+			log1, log2  types.Log
+			topic0      common.Hash
+			blockNumber int64
+		)
+
+		BeforeEach(func() {
+			log1 = test_data.GenericTestLog()
+			log2 = test_data.GenericTestLog()
+			topic0 = log1.Topics[0]
+			blockNumber = int64(log1.BlockNumber)
+			test_data.CreateMatchingTx(log1, headerID, headerRepository)
+			test_data.CreateMatchingTx(log2, headerID, headerRepository)
+			Expect(repo.CreateEventLogs(headerID, []types.Log{log1, log2})).To(Succeed())
+		})
+
+		It("returns logs in the block range, including transformed ones", func() {
+			Expect(repo.MarkEventLogsTransformed(transformerName, []int64{getLogID(db, log1)})).To(Succeed())
+
+			result, err := repo.GetEventLogsInRange(transformerName, topic0, blockNumber, blockNumber, 0, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(Equal(2))
+		})
+
+		It("excludes logs outside the block range", func() {
+			result, err := repo.GetEventLogsInRange(transformerName, topic0, blockNumber+1, blockNumber+2, 0, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(BeZero())
+		})
+
+		It("excludes logs with a different topic0", func() {
+			result, err := repo.GetEventLogsInRange(transformerName, test_data.FakeHash(), blockNumber, blockNumber, 0, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(BeZero())
+		})
+
+		It("excludes logs that have been quarantined for the transformer", func() {
+			_, recordErr := repo.RecordTransformError(getLogID(db, log1), transformerName, fakes.FakeError, 1, 0)
+			Expect(recordErr).NotTo(HaveOccurred())
+
+			result, err := repo.GetEventLogsInRange(transformerName, topic0, blockNumber, blockNumber, 0, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(Equal(1))
+			Expect(result[0].Log).To(Equal(log2))
+		})
+
+		It("includes logs that have only been quarantined for other transformers", func() {
+			_, recordErr := repo.RecordTransformError(getLogID(db, log1), "otherTransformer", fakes.FakeError, 1, 0)
+			Expect(recordErr).NotTo(HaveOccurred())
+
+			result, err := repo.GetEventLogsInRange(transformerName, topic0, blockNumber, blockNumber, 0, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(result)).To(Equal(2))
+		})
+
+		It("enables seeking logs with greater ID", func() {
+			resultOne, errOne := repo.GetEventLogsInRange(transformerName, topic0, blockNumber, blockNumber, 0, 1)
+			Expect(errOne).NotTo(HaveOccurred())
+			Expect(len(resultOne)).To(Equal(1))
+
+			resultTwo, errTwo := repo.GetEventLogsInRange(transformerName, topic0, blockNumber, blockNumber, int(resultOne[0].ID), 1)
+			Expect(errTwo).NotTo(HaveOccurred())
+			Expect(len(resultTwo)).To(Equal(1))
+			Expect(resultTwo[0].ID > resultOne[0].ID).To(BeTrue())
+		})
+	})
+
+	Describe("transformed logs", func() {
+		var logID int64
+
+		BeforeEach(func() {
+			log := test_data.GenericTestLog()
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			Expect(repo.CreateEventLogs(headerID, []types.Log{log})).To(Succeed())
+			logID = getLogID(db, log)
+		})
+
+		Describe("MarkEventLogsTransformed", func() {
+			It("records the logs transformed by the transformer", func() {
+				err := repo.MarkEventLogsTransformed("transformer", []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getTransformedLogIDs(db, "transformer")).To(ConsistOf(logID))
+				Expect(getTransformedLogIDs(db, "otherTransformer")).To(BeEmpty())
+			})
+
+			It("is idempotent", func() {
+				Expect(repo.MarkEventLogsTransformed("transformer", []int64{logID})).To(Succeed())
+
+				err := repo.MarkEventLogsTransformed("transformer", []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getTransformedLogIDs(db, "transformer")).To(ConsistOf(logID))
+			})
+
+			It("sets the deprecated transformed flag on the logs", func() {
+				err := repo.MarkEventLogsTransformed("transformer", []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				var transformed bool
+				Expect(db.Get(&transformed, `SELECT transformed FROM public.event_logs WHERE id = $1`, logID)).To(Succeed())
+				Expect(transformed).To(BeTrue())
+			})
+
+			It("clears the transformer's errors on the given logs", func() {
+				_, recordErr := repo.RecordTransformError(logID, "transformer", fakes.FakeError, 3, 0)
+				Expect(recordErr).NotTo(HaveOccurred())
+				_, otherRecordErr := repo.RecordTransformError(logID, "otherTransformer", fakes.FakeError, 3, 0)
+				Expect(otherRecordErr).NotTo(HaveOccurred())
+
+				err := repo.MarkEventLogsTransformed("transformer", []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				var transformers []string
+				Expect(db.Select(&transformers, `SELECT transformer FROM public.transform_errors`)).To(Succeed())
+				Expect(transformers).To(ConsistOf("otherTransformer"))
+			})
+		})
+
+		Describe("ResetTransformedEventLogs", func() {
+			It("forgets only the transformer's transformed logs and errors", func() {
+				Expect(repo.MarkEventLogsTransformed("transformer", []int64{logID})).To(Succeed())
+				Expect(repo.MarkEventLogsTransformed("otherTransformer", []int64{logID})).To(Succeed())
+				_, recordErr := repo.RecordTransformError(logID, "transformer", fakes.FakeError, 1, 0)
+				Expect(recordErr).NotTo(HaveOccurred())
+				_, otherRecordErr := repo.RecordTransformError(logID, "otherTransformer", fakes.FakeError, 1, 0)
+				Expect(otherRecordErr).NotTo(HaveOccurred())
+
+				err := repo.ResetTransformedEventLogs("transformer")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(getTransformedLogIDs(db, "transformer")).To(BeEmpty())
+				Expect(getTransformedLogIDs(db, "otherTransformer")).To(ConsistOf(logID))
+				var transformers []string
+				Expect(db.Select(&transformers, `SELECT transformer FROM public.transform_errors`)).To(Succeed())
+				Expect(transformers).To(ConsistOf("otherTransformer"))
+			})
+		})
+	})
+
+	Describe("quarantining logs", func() {
+		const transformerName = "transformer"
+
+		var (
+			logID  int64
+			topic0 common.Hash
+		)
+
+		BeforeEach(func() {
+			log := test_data.GenericTestLog()
+			topic0 = log.Topics[0]
+			test_data.CreateMatchingTx(log, headerID, headerRepository)
+			Expect(repo.CreateEventLogs(headerID, []types.Log{log})).To(Succeed())
+			Expect(db.Get(&logID, `SELECT id FROM public.event_logs`)).To(Succeed())
+		})
+
+		Describe("RecordTransformError", func() {
+			It("increments the transformer's error count on the log and records the error", func() {
+				quarantined, err := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 3, time.Minute)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(quarantined).To(BeFalse())
+				var transformError struct {
+					ErrorCount  int64  `db:"error_count"`
+					LastError   string `db:"last_error"`
+					Quarantined bool
+				}
+				readErr := db.Get(&transformError, `SELECT error_count, last_error, quarantined
+					FROM public.transform_errors WHERE log_id = $1 AND transformer = $2`, logID, transformerName)
+				Expect(readErr).NotTo(HaveOccurred())
+				Expect(transformError.ErrorCount).To(Equal(int64(1)))
+				Expect(transformError.LastError).To(Equal(fakes.FakeError.Error()))
+				Expect(transformError.Quarantined).To(BeFalse())
+			})
+
+			It("doesn't return the log as untransformed until the backoff has passed", func() {
+				_, err := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 3, time.Minute)
+
+				Expect(err).NotTo(HaveOccurred())
+				untransformedLogs, getErr := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 1)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(untransformedLogs).To(BeEmpty())
+			})
+
+			It("returns the log as untransformed once the backoff has passed", func() {
+				_, err := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 3, 0)
+
+				Expect(err).NotTo(HaveOccurred())
+				untransformedLogs, getErr := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 1)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(len(untransformedLogs)).To(Equal(1))
+			})
+
+			It("doubles the backoff with each failure", func() {
+				_, errOne := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 3, time.Minute)
+				Expect(errOne).NotTo(HaveOccurred())
+
+				_, errTwo := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 3, time.Minute)
+
+				Expect(errTwo).NotTo(HaveOccurred())
+				var backoffSeconds float64
+				readErr := db.Get(&backoffSeconds, `SELECT EXTRACT(EPOCH FROM next_retry - NOW())
+					FROM public.transform_errors WHERE log_id = $1 AND transformer = $2`, logID, transformerName)
+				Expect(readErr).NotTo(HaveOccurred())
+				Expect(backoffSeconds).To(BeNumerically("~", 120, 5))
+			})
+
+			It("quarantines the log for the transformer once it has failed maxErrors times", func() {
+				_, errOne := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 2, time.Minute)
+				Expect(errOne).NotTo(HaveOccurred())
+
+				quarantined, errTwo := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 2, time.Minute)
+
+				Expect(errTwo).NotTo(HaveOccurred())
+				Expect(quarantined).To(BeTrue())
+				quarantinedLogs, getErr := repo.GetQuarantinedEventLogs(transformerName)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(len(quarantinedLogs)).To(Equal(1))
+				Expect(quarantinedLogs[0].ID).To(Equal(logID))
+				Expect(quarantinedLogs[0].TransformErrorCount).To(Equal(int64(2)))
+				Expect(quarantinedLogs[0].LastTransformError).To(Equal(fakes.FakeError.Error()))
+				otherQuarantinedLogs, otherGetErr := repo.GetQuarantinedEventLogs("otherTransformer")
+				Expect(otherGetErr).NotTo(HaveOccurred())
+				Expect(otherQuarantinedLogs).To(BeEmpty())
+			})
+		})
+
+		Describe("RetryQuarantinedEventLogs", func() {
+			BeforeEach(func() {
+				_, recordErr := repo.RecordTransformError(logID, transformerName, fakes.FakeError, 1, time.Minute)
+				Expect(recordErr).NotTo(HaveOccurred())
+			})
+
+			It("releases quarantined logs with the given ids", func() {
+				retried, err := repo.RetryQuarantinedEventLogs(transformerName, []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retried).To(Equal(int64(1)))
+				untransformedLogs, getErr := repo.GetUntransformedEventLogs(transformerName, topic0, 0, 1)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(len(untransformedLogs)).To(Equal(1))
+				var errorCount int64
+				Expect(db.Get(&errorCount, `SELECT error_count FROM public.transform_errors`)).To(Succeed())
+				Expect(errorCount).To(BeZero())
+			})
+
+			It("doesn't release quarantined logs with other ids", func() {
+				retried, err := repo.RetryQuarantinedEventLogs(transformerName, []int64{logID + 1})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retried).To(BeZero())
+			})
+
+			It("doesn't release logs quarantined for other transformers", func() {
+				retried, err := repo.RetryQuarantinedEventLogs("otherTransformer", []int64{logID})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retried).To(BeZero())
+			})
+
+			It("releases all the transformer's quarantined logs if no ids are given", func() {
+				retried, err := repo.RetryQuarantinedEventLogs(transformerName, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retried).To(Equal(int64(1)))
+				quarantinedLogs, getErr := repo.GetQuarantinedEventLogs(transformerName)
+				Expect(getErr).NotTo(HaveOccurred())
+				Expect(quarantinedLogs).To(BeEmpty())
+			})
 		})
 	})
 })
+
+func getTransformedLogIDs(db *postgres.DB, transformer string) []int64 {
+	var logIDs []int64
+	err := db.Select(&logIDs, `SELECT log_id FROM public.transformed_logs WHERE transformer = $1`, transformer)
+	Expect(err).NotTo(HaveOccurred())
+	return logIDs
+}
+
+func insertLegacyTransformedLog(db *postgres.DB, logID int64) {
+	_, err := db.Exec(`INSERT INTO public.legacy_transformed_logs (log_id) VALUES ($1)`, logID)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func getLogID(db *postgres.DB, log types.Log) int64 {
+	var logID int64
+	err := db.Get(&logID, `SELECT id FROM public.event_logs WHERE tx_index = $1 AND log_index = $2`, log.TxIndex, log.Index)
+	Expect(err).NotTo(HaveOccurred())
+	return logID
+}
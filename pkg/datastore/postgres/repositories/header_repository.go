@@ -20,23 +20,57 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
 	"github.com/sirupsen/logrus"
 )
 
+// Number of blocks from the most recent header within which a conflicting header is replaced
+const DefaultReorgWindow = 15
+
 type headerRepository struct {
-	db *postgres.DB
+	db          *postgres.DB
+	reorgWindow int64
 }
 
 func NewHeaderRepository(database *postgres.DB) headerRepository {
-	return headerRepository{db: database}
+	return NewHeaderRepositoryWithReorgWindow(database, DefaultReorgWindow)
+}
+
+func NewHeaderRepositoryWithReorgWindow(database *postgres.DB, reorgWindow int64) headerRepository {
+	return headerRepository{db: database, reorgWindow: reorgWindow}
 }
 
 func (repo headerRepository) CreateOrUpdateHeader(header core.Header) (int64, error) {
+	return repo.createOrUpdateHeader(repo.db, header)
+}
+
+// Persists the headers replacing a reorg's orphaned headers, ordered by block number, and records the reorg in one
+// transaction, so that a reorg is recorded if and only if its headers were replaced
+func (repo headerRepository) ReplaceOrphanedHeaders(headers []core.Header, reorg core.Reorg) error {
+	tx, txErr := repo.db.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	for _, header := range headers {
+		_, createErr := repo.createOrUpdateHeader(tx, header)
+		if createErr != nil {
+			return rollbackTransactions(tx, createErr)
+		}
+	}
+	_, reorgErr := insertReorg(tx, reorg, repo.db.NodeID)
+	if reorgErr != nil {
+		return rollbackTransactions(tx, reorgErr)
+	}
+	return tx.Commit()
+}
+
+func (repo headerRepository) createOrUpdateHeader(queryer sqlx.Queryer, header core.Header) (int64, error) {
 	var headerID int64
-	err := repo.db.QueryRowx("SELECT * FROM public.get_or_create_header($1, $2, $3, $4, $5)",
-		header.BlockNumber, header.Hash, header.Raw, header.Timestamp, repo.db.NodeID).Scan(&headerID)
+	err := queryer.QueryRowx("SELECT * FROM public.get_or_create_header($1, $2, $3, $4, $5, $6, NULLIF($7, '')::NUMERIC)",
+		header.BlockNumber, header.Hash, header.Raw, header.Timestamp, repo.db.NodeID, repo.reorgWindow,
+		header.BaseFee).Scan(&headerID)
 	if err != nil {
 		return headerID, fmt.Errorf("error inserting header for block %d: %w", header.BlockNumber, err)
 	}
@@ -46,11 +80,15 @@ func (repo headerRepository) CreateOrUpdateHeader(header core.Header) (int64, er
 func (repo headerRepository) CreateTransactions(headerID int64, transactions []core.TransactionModel) error {
 	for _, transaction := range transactions {
 		_, err := repo.db.Exec(`INSERT INTO public.transactions
-		(header_id, hash, gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value") 
-		VALUES ($1, $2, $3::NUMERIC, $4::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC)
+		(header_id, hash, gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value", tx_type,
+		 chain_id, max_fee_per_gas, max_priority_fee_per_gas, max_fee_per_blob_gas, access_list, blob_versioned_hashes)
+		VALUES ($1, $2, $3::NUMERIC, NULLIF($4, '')::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC, $12,
+		        NULLIF($13, '')::NUMERIC, NULLIF($14, '')::NUMERIC, NULLIF($15, '')::NUMERIC, NULLIF($16, '')::NUMERIC, $17, $18)
 		ON CONFLICT DO NOTHING`, headerID, transaction.Hash, transaction.GasLimit, transaction.GasPrice,
 			transaction.Data, transaction.Nonce, transaction.Raw, transaction.From, transaction.TxIndex, transaction.To,
-			transaction.Value)
+			transaction.Value, transaction.Type, transaction.ChainID, transaction.MaxFeePerGas,
+			transaction.MaxPriorityFeePerGas, transaction.MaxFeePerBlobGas, transaction.AccessList,
+			pq.Array(transaction.BlobVersionedHashes))
 		if err != nil {
 			return fmt.Errorf("error creating transactions: %w", err)
 		}
@@ -58,17 +96,52 @@ func (repo headerRepository) CreateTransactions(headerID int64, transactions []c
 	return nil
 }
 
+// Persists each transaction along with its embedded receipt in a single DB transaction
+func (repo headerRepository) CreateTransactionsWithReceipts(headerID int64, transactions []core.TransactionModel) error {
+	tx, txErr := repo.db.Beginx()
+	if txErr != nil {
+		return txErr
+	}
+	receiptRepository := ReceiptRepository{}
+	for _, transaction := range transactions {
+		transactionID, createTxErr := repo.CreateTransactionInTx(tx, headerID, transaction)
+		if createTxErr != nil {
+			return rollbackTransactions(tx, fmt.Errorf("error creating transaction %s: %w", transaction.Hash, createTxErr))
+		}
+		_, createReceiptErr := receiptRepository.CreateReceiptInTx(headerID, transactionID, transaction.Receipt, tx)
+		if createReceiptErr != nil {
+			return rollbackTransactions(tx, fmt.Errorf("error creating receipt for transaction %s: %w", transaction.Hash, createReceiptErr))
+		}
+	}
+	return tx.Commit()
+}
+
+func rollbackTransactions(tx *sqlx.Tx, err error) error {
+	rollbackErr := tx.Rollback()
+	if rollbackErr != nil {
+		logrus.Errorf("failed to rollback transactions insert: %s", rollbackErr.Error())
+	}
+	return err
+}
+
 func (repo headerRepository) CreateTransactionInTx(tx *sqlx.Tx, headerID int64, transaction core.TransactionModel) (int64, error) {
 	var txId int64
 	err := tx.QueryRowx(`INSERT INTO public.transactions
-		(header_id, hash, gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value")
-		VALUES ($1, $2, $3::NUMERIC, $4::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC)
+		(header_id, hash, gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value", tx_type,
+		 chain_id, max_fee_per_gas, max_priority_fee_per_gas, max_fee_per_blob_gas, access_list, blob_versioned_hashes)
+		VALUES ($1, $2, $3::NUMERIC, NULLIF($4, '')::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC, $12,
+		        NULLIF($13, '')::NUMERIC, NULLIF($14, '')::NUMERIC, NULLIF($15, '')::NUMERIC, NULLIF($16, '')::NUMERIC, $17, $18)
 		ON CONFLICT (hash) DO UPDATE
-		SET (gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value") = ($3::NUMERIC, $4::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC)
+		SET (gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value", tx_type, chain_id,
+		     max_fee_per_gas, max_priority_fee_per_gas, max_fee_per_blob_gas, access_list, blob_versioned_hashes) =
+		    ($3::NUMERIC, NULLIF($4, '')::NUMERIC, $5, $6::NUMERIC, $7, $8, $9::NUMERIC, $10, $11::NUMERIC, $12,
+		     NULLIF($13, '')::NUMERIC, NULLIF($14, '')::NUMERIC, NULLIF($15, '')::NUMERIC, NULLIF($16, '')::NUMERIC, $17, $18)
 		RETURNING id`,
 		headerID, transaction.Hash, transaction.GasLimit, transaction.GasPrice,
 		transaction.Data, transaction.Nonce, transaction.Raw, transaction.From,
-		transaction.TxIndex, transaction.To, transaction.Value).Scan(&txId)
+		transaction.TxIndex, transaction.To, transaction.Value, transaction.Type, transaction.ChainID,
+		transaction.MaxFeePerGas, transaction.MaxPriorityFeePerGas, transaction.MaxFeePerBlobGas,
+		transaction.AccessList, pq.Array(transaction.BlobVersionedHashes)).Scan(&txId)
 	if err != nil {
 		logrus.Error("header_repository: error inserting transaction: ", err)
 	}
@@ -78,20 +151,24 @@ func (repo headerRepository) CreateTransactionInTx(tx *sqlx.Tx, headerID int64,
 func (repo headerRepository) GetHeaderByBlockNumber(blockNumber int64) (core.Header, error) {
 	var header core.Header
 	err := repo.db.Get(&header,
-		`SELECT id, block_number, hash, raw, block_timestamp FROM headers WHERE block_number = $1`, blockNumber)
+		`SELECT id, block_number, hash, raw, block_timestamp, COALESCE(base_fee_per_gas::TEXT, '') AS base_fee_per_gas
+		FROM headers WHERE block_number = $1`, blockNumber)
 	return header, err
 }
 
 func (repo headerRepository) GetHeaderByID(id int64) (core.Header, error) {
 	var header core.Header
-	headerErr := repo.db.Get(&header, `SELECT id, block_number, hash, raw, block_timestamp FROM headers WHERE id = $1`, id)
+	headerErr := repo.db.Get(&header,
+		`SELECT id, block_number, hash, raw, block_timestamp, COALESCE(base_fee_per_gas::TEXT, '') AS base_fee_per_gas
+		FROM headers WHERE id = $1`, id)
 	return header, headerErr
 }
 
 func (repo headerRepository) GetHeadersInRange(startingBlock, endingBlock int64) ([]core.Header, error) {
 	var headers []core.Header
 	err := repo.db.Select(&headers,
-		`SELECT id, block_number, hash, raw, block_timestamp FROM headers WHERE block_number BETWEEN $1 AND $2 ORDER BY block_number ASC`,
+		`SELECT id, block_number, hash, raw, block_timestamp, COALESCE(base_fee_per_gas::TEXT, '') AS base_fee_per_gas
+		FROM headers WHERE block_number BETWEEN $1 AND $2 ORDER BY block_number ASC`,
 		startingBlock, endingBlock)
 	return headers, err
 }
@@ -118,3 +195,20 @@ func (repo headerRepository) GetMostRecentHeaderBlockNumber() (int64, error) {
 		`SELECT block_number FROM headers ORDER BY block_number DESC LIMIT 1`)
 	return blockNumber, err
 }
+
+// Returns headers at or above the starting block whose block bodies haven't been synced, in ascending order
+func (repo headerRepository) GetHeadersWithUnsyncedTransactions(startingBlockNumber int64, limit int) ([]core.Header, error) {
+	var headers []core.Header
+	err := repo.db.Select(&headers,
+		`SELECT id, block_number, hash, raw, block_timestamp, COALESCE(base_fee_per_gas::TEXT, '') AS base_fee_per_gas
+			FROM public.headers
+			WHERE transactions_synced = FALSE AND block_number >= $1
+			ORDER BY block_number ASC LIMIT $2`,
+		startingBlockNumber, limit)
+	return headers, err
+}
+
+func (repo headerRepository) MarkTransactionsSynced(headerID int64) error {
+	_, err := repo.db.Exec(`UPDATE public.headers SET transactions_synced = TRUE WHERE id = $1`, headerID)
+	return err
+}
@@ -61,6 +61,23 @@ var _ = Describe("Block header repository", func() {
 			Expect(dbHeader.Timestamp).To(Equal(header.Timestamp))
 		})
 
+		It("persists the base fee", func() {
+			londonHeader := fakes.GetFakeHeader(header.BlockNumber + 1)
+			londonHeader.BaseFee = "7"
+			_, createErr := repo.CreateOrUpdateHeader(londonHeader)
+			Expect(createErr).NotTo(HaveOccurred())
+
+			dbHeader, readErr := repo.GetHeaderByBlockNumber(londonHeader.BlockNumber)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbHeader.BaseFee).To(Equal("7"))
+		})
+
+		It("leaves base fee empty for headers without one", func() {
+			dbHeader, readErr := repo.GetHeaderByBlockNumber(header.BlockNumber)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbHeader.BaseFee).To(BeEmpty())
+		})
+
 		It("adds node data to header", func() {
 			var ethNodeId int64
 			readErr := db.Get(&ethNodeId, `SELECT eth_node_id FROM public.headers WHERE block_number = $1`, header.BlockNumber)
@@ -105,6 +122,22 @@ var _ = Describe("Block header repository", func() {
 			Expect(dbHeaderHash).To(Equal(header.Hash))
 		})
 
+		It("replaces header within a configured reorg window", func() {
+			windowedRepo := repositories.NewHeaderRepositoryWithReorgWindow(db, 30)
+			chainHeadHeader := fakes.GetFakeHeader(header.BlockNumber + 15)
+			_, createHeadErr := windowedRepo.CreateOrUpdateHeader(chainHeadHeader)
+			Expect(createHeadErr).NotTo(HaveOccurred())
+
+			conflictingHeader := fakes.GetFakeHeader(header.BlockNumber)
+			_, createConflictErr := windowedRepo.CreateOrUpdateHeader(conflictingHeader)
+			Expect(createConflictErr).NotTo(HaveOccurred())
+
+			var dbHeaderHash string
+			readErr := db.Get(&dbHeaderHash, `SELECT hash FROM public.headers WHERE block_number = $1`, header.BlockNumber)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbHeaderHash).To(Equal(conflictingHeader.Hash))
+		})
+
 		It("does not duplicate headers with different hashes", func() {
 			headerTwo := fakes.GetFakeHeader(header.BlockNumber)
 
@@ -135,6 +168,52 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("replacing orphaned headers", func() {
+		var (
+			orphan      core.Header
+			replacement core.Header
+			reorg       core.Reorg
+		)
+
+		BeforeEach(func() {
+			orphan = header
+			var createErr error
+			orphan.Id, createErr = repo.CreateOrUpdateHeader(orphan)
+			Expect(createErr).NotTo(HaveOccurred())
+			replacement = fakes.GetFakeHeader(header.BlockNumber)
+			reorg = core.Reorg{
+				BlockNumber:  header.BlockNumber - 1,
+				Depth:        1,
+				OldHashes:    []string{orphan.Hash},
+				NewHashes:    []string{replacement.Hash},
+				OldHeaderIDs: []int64{orphan.Id},
+			}
+		})
+
+		It("replaces the headers and records the reorg", func() {
+			err := repo.ReplaceOrphanedHeaders([]core.Header{replacement}, reorg)
+
+			Expect(err).NotTo(HaveOccurred())
+			dbHeader, readErr := repo.GetHeaderByBlockNumber(header.BlockNumber)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbHeader.Hash).To(Equal(replacement.Hash))
+			var reorgCount int
+			Expect(db.Get(&reorgCount, `SELECT count(*) FROM public.reorgs WHERE block_number = $1`, reorg.BlockNumber)).To(Succeed())
+			Expect(reorgCount).To(Equal(1))
+		})
+
+		It("keeps the orphaned headers if recording the reorg fails", func() {
+			reorg.NewHashes = nil // violates the reorgs table's NOT NULL constraint
+
+			err := repo.ReplaceOrphanedHeaders([]core.Header{replacement}, reorg)
+
+			Expect(err).To(HaveOccurred())
+			dbHeader, readErr := repo.GetHeaderByBlockNumber(header.BlockNumber)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbHeader.Hash).To(Equal(orphan.Hash))
+		})
+	})
+
 	Describe("creating a transaction", func() {
 		var (
 			headerID     int64
@@ -154,7 +233,7 @@ var _ = Describe("Block header repository", func() {
 				Data:     []byte{},
 				From:     fromAddress.Hex(),
 				GasLimit: 0,
-				GasPrice: 0,
+				GasPrice: "0",
 				Hash:     txHash.Hex(),
 				Nonce:    0,
 				Raw:      []byte{},
@@ -166,7 +245,7 @@ var _ = Describe("Block header repository", func() {
 				Data:     []byte{},
 				From:     fromAddress.Hex(),
 				GasLimit: 1,
-				GasPrice: 1,
+				GasPrice: "1",
 				Hash:     txHashTwo.Hex(),
 				Nonce:    1,
 				Raw:      []byte{},
@@ -188,6 +267,38 @@ var _ = Describe("Block header repository", func() {
 			Expect(dbTransactions).To(ConsistOf(transactions))
 		})
 
+		It("adds typed transaction fields", func() {
+			typedTransaction := core.TransactionModel{
+				AccessList:           []byte(`[]`),
+				ChainID:              "1",
+				Data:                 []byte{},
+				From:                 common.HexToAddress("0x1234").Hex(),
+				GasPrice:             "50",
+				Hash:                 common.HexToHash("0x1111").Hex(),
+				MaxFeePerGas:         "100",
+				MaxPriorityFeePerGas: "2",
+				Raw:                  []byte{2},
+				To:                   common.HexToAddress("0x5678").Hex(),
+				Type:                 core.DynamicFeeTxType,
+				Value:                "0",
+			}
+			insertErr := repo.CreateTransactions(headerID, []core.TransactionModel{typedTransaction})
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			var dbTransaction core.TransactionModel
+			readErr := db.Get(&dbTransaction,
+				`SELECT hash, gas_limit, gas_price, input_data, nonce, raw, tx_from, tx_index, tx_to, "value", tx_type,
+				chain_id, max_fee_per_gas, max_priority_fee_per_gas, COALESCE(max_fee_per_blob_gas::TEXT, '') AS max_fee_per_blob_gas,
+				access_list FROM public.transactions WHERE hash = $1`, typedTransaction.Hash)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbTransaction.Type).To(Equal(typedTransaction.Type))
+			Expect(dbTransaction.ChainID).To(Equal(typedTransaction.ChainID))
+			Expect(dbTransaction.MaxFeePerGas).To(Equal(typedTransaction.MaxFeePerGas))
+			Expect(dbTransaction.MaxPriorityFeePerGas).To(Equal(typedTransaction.MaxPriorityFeePerGas))
+			Expect(dbTransaction.MaxFeePerBlobGas).To(BeEmpty())
+			Expect(dbTransaction.AccessList).To(MatchJSON(typedTransaction.AccessList))
+		})
+
 		It("silently ignores duplicate inserts", func() {
 			insertTwoErr := repo.CreateTransactions(headerID, transactions)
 			Expect(insertTwoErr).NotTo(HaveOccurred())
@@ -201,6 +312,69 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("creating transactions with receipts", func() {
+		var (
+			headerID    int64
+			transaction core.TransactionModel
+		)
+
+		BeforeEach(func() {
+			var err error
+			headerID, err = repo.CreateOrUpdateHeader(header)
+			Expect(err).NotTo(HaveOccurred())
+			txHash := common.HexToHash("0x9876")
+			transaction = core.TransactionModel{
+				Data:    []byte{},
+				From:    common.HexToAddress("0x1234").Hex(),
+				Hash:    txHash.Hex(),
+				Raw:     []byte{},
+				To:      common.HexToAddress("0x5678").Hex(),
+				TxIndex: 1,
+				Value:   "0",
+				Receipt: core.Receipt{
+					ContractAddress:   common.Address{}.Hex(),
+					CumulativeGasUsed: 42000,
+					GasUsed:           21000,
+					Status:            1,
+					TxHash:            txHash.Hex(),
+					Rlp:               []byte{1, 2, 3},
+				},
+			}
+		})
+
+		It("adds transactions and their receipts", func() {
+			insertErr := repo.CreateTransactionsWithReceipts(headerID, []core.TransactionModel{transaction})
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			var transactionID int64
+			readTxErr := db.Get(&transactionID, `SELECT id FROM public.transactions WHERE hash = $1`, transaction.Hash)
+			Expect(readTxErr).NotTo(HaveOccurred())
+			var dbReceipt core.Receipt
+			readReceiptErr := db.Get(&dbReceipt,
+				`SELECT cumulative_gas_used, gas_used, status, tx_hash, rlp FROM public.receipts
+				WHERE header_id = $1 AND transaction_id = $2`, headerID, transactionID)
+			Expect(readReceiptErr).NotTo(HaveOccurred())
+			Expect(dbReceipt.CumulativeGasUsed).To(Equal(transaction.Receipt.CumulativeGasUsed))
+			Expect(dbReceipt.GasUsed).To(Equal(transaction.Receipt.GasUsed))
+			Expect(dbReceipt.Status).To(Equal(transaction.Receipt.Status))
+			Expect(dbReceipt.TxHash).To(Equal(transaction.Receipt.TxHash))
+			Expect(dbReceipt.Rlp).To(Equal(transaction.Receipt.Rlp))
+		})
+
+		It("upserts duplicate inserts", func() {
+			insertErr := repo.CreateTransactionsWithReceipts(headerID, []core.TransactionModel{transaction})
+			Expect(insertErr).NotTo(HaveOccurred())
+
+			insertTwoErr := repo.CreateTransactionsWithReceipts(headerID, []core.TransactionModel{transaction})
+			Expect(insertTwoErr).NotTo(HaveOccurred())
+
+			var receiptCount int
+			readErr := db.Get(&receiptCount, `SELECT COUNT(*) FROM public.receipts WHERE header_id = $1`, headerID)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(receiptCount).To(Equal(1))
+		})
+	})
+
 	Describe("creating a transaction in a sqlx tx", func() {
 		It("adds a transaction", func() {
 			headerID, err := repo.CreateOrUpdateHeader(header)
@@ -213,7 +387,7 @@ var _ = Describe("Block header repository", func() {
 				Data:     []byte{},
 				From:     fromAddress.Hex(),
 				GasLimit: 0,
-				GasPrice: 0,
+				GasPrice: "0",
 				Hash:     txHash.Hex(),
 				Nonce:    0,
 				Raw:      []byte{1, 2, 3},
@@ -248,7 +422,7 @@ var _ = Describe("Block header repository", func() {
 				Data:     []byte{},
 				From:     fromAddress.Hex(),
 				GasLimit: 0,
-				GasPrice: 0,
+				GasPrice: "0",
 				Hash:     txHash.Hex(),
 				Nonce:    0,
 				Raw:      []byte{},
@@ -362,6 +536,53 @@ var _ = Describe("Block header repository", func() {
 		})
 	})
 
+	Describe("Getting headers with unsynced transactions", func() {
+		var headerOneID, headerTwoID int64
+
+		BeforeEach(func() {
+			var createOneErr, createTwoErr error
+			headerOneID, createOneErr = repo.CreateOrUpdateHeader(fakes.GetFakeHeader(1))
+			Expect(createOneErr).NotTo(HaveOccurred())
+			headerTwoID, createTwoErr = repo.CreateOrUpdateHeader(fakes.GetFakeHeader(2))
+			Expect(createTwoErr).NotTo(HaveOccurred())
+		})
+
+		It("returns headers at or above the starting block in ascending order", func() {
+			headers, err := repo.GetHeadersWithUnsyncedTransactions(1, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(2))
+			Expect(headers[0].Id).To(Equal(headerOneID))
+			Expect(headers[1].Id).To(Equal(headerTwoID))
+		})
+
+		It("limits the number of headers returned", func() {
+			headers, err := repo.GetHeadersWithUnsyncedTransactions(1, 1)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(1))
+		})
+
+		It("does not return headers below the starting block", func() {
+			headers, err := repo.GetHeadersWithUnsyncedTransactions(2, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(1))
+			Expect(headers[0].Id).To(Equal(headerTwoID))
+		})
+
+		It("does not return headers marked synced", func() {
+			markErr := repo.MarkTransactionsSynced(headerOneID)
+			Expect(markErr).NotTo(HaveOccurred())
+
+			headers, err := repo.GetHeadersWithUnsyncedTransactions(1, 10)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(headers)).To(Equal(1))
+			Expect(headers[0].Id).To(Equal(headerTwoID))
+		})
+	})
+
 	Describe("Getting missing headers", func() {
 		It("returns block numbers for headers not in the db", func() {
 			_, createOneErr := repo.CreateOrUpdateHeader(fakes.GetFakeHeader(1))
@@ -57,7 +57,7 @@ var _ = Describe("Receipt Repository", func() {
 				Data:     []byte{},
 				From:     fromAddress.Hex(),
 				GasLimit: 0,
-				GasPrice: 0,
+				GasPrice: "0",
 				Hash:     txHash.Hex(),
 				Nonce:    0,
 				Raw:      []byte{},
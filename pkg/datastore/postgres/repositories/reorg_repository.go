@@ -0,0 +1,109 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+)
+
+type ReorgRepository struct {
+	db *postgres.DB
+}
+
+func NewReorgRepository(db *postgres.DB) ReorgRepository {
+	return ReorgRepository{db: db}
+}
+
+func (repo ReorgRepository) CreateReorg(reorg core.Reorg) (int64, error) {
+	return insertReorg(repo.db, reorg, repo.db.NodeID)
+}
+
+func insertReorg(queryer sqlx.Queryer, reorg core.Reorg, nodeID int64) (int64, error) {
+	var reorgID int64
+	err := queryer.QueryRowx(`INSERT INTO public.reorgs
+		(block_number, depth, old_hashes, new_hashes, old_header_ids, eth_node_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`, reorg.BlockNumber, reorg.Depth, pq.Array(reorg.OldHashes), pq.Array(reorg.NewHashes),
+		pq.Array(reorg.OldHeaderIDs), nodeID).Scan(&reorgID)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting reorg at block %d: %w", reorg.BlockNumber, err)
+	}
+	return reorgID, nil
+}
+
+// Returns 0 if no reorgs have been recorded
+func (repo ReorgRepository) GetMostRecentReorgID() (int64, error) {
+	var reorgID int64
+	err := repo.db.Get(&reorgID, `SELECT COALESCE(MAX(id), 0) FROM public.reorgs`)
+	return reorgID, err
+}
+
+// Returns reorgs recorded after the given ID, in the order they were recorded
+func (repo ReorgRepository) GetReorgsAfterID(id int64) ([]core.Reorg, error) {
+	rows, queryErr := repo.db.Queryx(`SELECT id, block_number, depth, old_hashes, new_hashes, old_header_ids
+		FROM public.reorgs WHERE id > $1 ORDER BY id`, id)
+	if queryErr != nil {
+		return nil, fmt.Errorf("error getting reorgs after id %d: %w", id, queryErr)
+	}
+	defer rows.Close()
+
+	var reorgs []core.Reorg
+	for rows.Next() {
+		var reorg core.Reorg
+		scanErr := rows.Scan(&reorg.ID, &reorg.BlockNumber, &reorg.Depth, pq.Array(&reorg.OldHashes),
+			pq.Array(&reorg.NewHashes), pq.Array(&reorg.OldHeaderIDs))
+		if scanErr != nil {
+			return nil, fmt.Errorf("error scanning reorg: %w", scanErr)
+		}
+		reorgs = append(reorgs, reorg)
+	}
+	return reorgs, rows.Err()
+}
+
+// Returns the ID of the last reorg the transformer was notified of. A transformer that has not been notified of any
+// reorgs starts from the most recent reorg, so it is only notified of reorgs recorded after it first checks.
+func (repo ReorgRepository) GetLastNotifiedReorgID(transformer string) (int64, error) {
+	var reorgID int64
+	err := repo.db.Get(&reorgID, `WITH inserted AS (
+			INSERT INTO public.reorg_notifications (transformer, last_reorg_id)
+			SELECT $1, COALESCE(MAX(id), 0) FROM public.reorgs
+			ON CONFLICT (transformer) DO NOTHING
+			RETURNING last_reorg_id
+		)
+		SELECT last_reorg_id FROM inserted
+		UNION ALL
+		SELECT last_reorg_id FROM public.reorg_notifications WHERE transformer = $1`, transformer)
+	if err != nil {
+		return 0, fmt.Errorf("error getting last reorg %s was notified of: %w", transformer, err)
+	}
+	return reorgID, nil
+}
+
+// Records that the transformer has handled every reorg up to and including the given ID
+func (repo ReorgRepository) MarkReorgNotified(transformer string, reorgID int64) error {
+	_, err := repo.db.Exec(`INSERT INTO public.reorg_notifications (transformer, last_reorg_id) VALUES ($1, $2)
+		ON CONFLICT (transformer) DO UPDATE SET last_reorg_id = $2, updated = NOW()`, transformer, reorgID)
+	if err != nil {
+		return fmt.Errorf("error marking reorg %d notified for %s: %w", reorgID, transformer, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package repositories_test
+
+import (
+	"github.com/lib/pq"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres/repositories"
+	"github.com/makerdao/vulcanizedb/test_config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg repository", func() {
+	var (
+		db   = test_config.NewTestDB(test_config.NewTestNode())
+		repo repositories.ReorgRepository
+	)
+
+	BeforeEach(func() {
+		test_config.CleanTestDB(db)
+		repo = repositories.NewReorgRepository(db)
+	})
+
+	Describe("CreateReorg", func() {
+		It("persists the reorg", func() {
+			reorg := core.Reorg{
+				BlockNumber:  100,
+				Depth:        2,
+				OldHashes:    []string{"0xa101", "0xa102"},
+				NewHashes:    []string{"0xb101", "0xb102", "0xb103"},
+				OldHeaderIDs: []int64{1, 2},
+			}
+
+			reorgID, createErr := repo.CreateReorg(reorg)
+
+			Expect(createErr).NotTo(HaveOccurred())
+			var dbReorg struct {
+				BlockNumber  int64          `db:"block_number"`
+				Depth        int64          `db:"depth"`
+				OldHashes    pq.StringArray `db:"old_hashes"`
+				NewHashes    pq.StringArray `db:"new_hashes"`
+				OldHeaderIDs pq.Int64Array  `db:"old_header_ids"`
+				EthNodeID    int64          `db:"eth_node_id"`
+			}
+			readErr := db.Get(&dbReorg, `SELECT block_number, depth, old_hashes, new_hashes, old_header_ids, eth_node_id
+				FROM public.reorgs WHERE id = $1`, reorgID)
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(dbReorg.BlockNumber).To(Equal(reorg.BlockNumber))
+			Expect(dbReorg.Depth).To(Equal(reorg.Depth))
+			Expect([]string(dbReorg.OldHashes)).To(Equal(reorg.OldHashes))
+			Expect([]string(dbReorg.NewHashes)).To(Equal(reorg.NewHashes))
+			Expect([]int64(dbReorg.OldHeaderIDs)).To(Equal(reorg.OldHeaderIDs))
+			Expect(dbReorg.EthNodeID).To(Equal(db.NodeID))
+		})
+	})
+
+	Describe("GetMostRecentReorgID", func() {
+		It("returns zero if no reorgs have been recorded", func() {
+			reorgID, err := repo.GetMostRecentReorgID()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgID).To(BeZero())
+		})
+
+		It("returns the ID of the most recently recorded reorg", func() {
+			_, createOneErr := repo.CreateReorg(core.Reorg{BlockNumber: 1, Depth: 1, OldHashes: []string{"0xa2"}, NewHashes: []string{"0xb2"}, OldHeaderIDs: []int64{2}})
+			Expect(createOneErr).NotTo(HaveOccurred())
+			reorgTwoID, createTwoErr := repo.CreateReorg(core.Reorg{BlockNumber: 5, Depth: 1, OldHashes: []string{"0xa6"}, NewHashes: []string{"0xb6"}, OldHeaderIDs: []int64{6}})
+			Expect(createTwoErr).NotTo(HaveOccurred())
+
+			reorgID, err := repo.GetMostRecentReorgID()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgID).To(Equal(reorgTwoID))
+		})
+	})
+
+	Describe("GetReorgsAfterID", func() {
+		It("returns reorgs recorded after the given ID in order", func() {
+			reorgOne := core.Reorg{BlockNumber: 1, Depth: 1, OldHashes: []string{"0xa2"}, NewHashes: []string{"0xb2"}, OldHeaderIDs: []int64{2}}
+			reorgOneID, createOneErr := repo.CreateReorg(reorgOne)
+			Expect(createOneErr).NotTo(HaveOccurred())
+			reorgTwo := core.Reorg{BlockNumber: 5, Depth: 1, OldHashes: []string{"0xa6"}, NewHashes: []string{"0xb6"}, OldHeaderIDs: []int64{6}}
+			reorgTwoID, createTwoErr := repo.CreateReorg(reorgTwo)
+			Expect(createTwoErr).NotTo(HaveOccurred())
+			reorgThree := core.Reorg{BlockNumber: 9, Depth: 1, OldHashes: []string{"0xa10"}, NewHashes: []string{"0xb10"}, OldHeaderIDs: []int64{10}}
+			reorgThreeID, createThreeErr := repo.CreateReorg(reorgThree)
+			Expect(createThreeErr).NotTo(HaveOccurred())
+			reorgTwo.ID = reorgTwoID
+			reorgThree.ID = reorgThreeID
+
+			reorgs, err := repo.GetReorgsAfterID(reorgOneID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reorgs).To(Equal([]core.Reorg{reorgTwo, reorgThree}))
+		})
+	})
+
+	Describe("GetLastNotifiedReorgID", func() {
+		It("starts a transformer that hasn't been notified at the most recent reorg", func() {
+			reorgID, createErr := repo.CreateReorg(core.Reorg{BlockNumber: 1, Depth: 1, OldHashes: []string{"0xa2"}, NewHashes: []string{"0xb2"}, OldHeaderIDs: []int64{2}})
+			Expect(createErr).NotTo(HaveOccurred())
+
+			lastNotifiedID, err := repo.GetLastNotifiedReorgID("transformer")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastNotifiedID).To(Equal(reorgID))
+		})
+
+		It("does not move the transformer past reorgs recorded after it first checks", func() {
+			firstID, firstErr := repo.GetLastNotifiedReorgID("transformer")
+			Expect(firstErr).NotTo(HaveOccurred())
+			_, createErr := repo.CreateReorg(core.Reorg{BlockNumber: 1, Depth: 1, OldHashes: []string{"0xa2"}, NewHashes: []string{"0xb2"}, OldHeaderIDs: []int64{2}})
+			Expect(createErr).NotTo(HaveOccurred())
+
+			lastNotifiedID, err := repo.GetLastNotifiedReorgID("transformer")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lastNotifiedID).To(Equal(firstID))
+		})
+	})
+
+	Describe("MarkReorgNotified", func() {
+		It("records the last reorg each transformer was notified of", func() {
+			Expect(repo.MarkReorgNotified("transformerOne", 3)).To(Succeed())
+			Expect(repo.MarkReorgNotified("transformerTwo", 5)).To(Succeed())
+			Expect(repo.MarkReorgNotified("transformerOne", 4)).To(Succeed())
+
+			transformerOneID, errOne := repo.GetLastNotifiedReorgID("transformerOne")
+			Expect(errOne).NotTo(HaveOccurred())
+			Expect(transformerOneID).To(Equal(int64(4)))
+			transformerTwoID, errTwo := repo.GetLastNotifiedReorgID("transformerTwo")
+			Expect(errTwo).NotTo(HaveOccurred())
+			Expect(transformerTwoID).To(Equal(int64(5)))
+		})
+	})
+})
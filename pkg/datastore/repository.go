@@ -17,15 +17,30 @@
 package datastore
 
 import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jmoiron/sqlx"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
+type AbiRepository interface {
+	GetAbi(address string) (string, error)
+	SaveAbi(address, contractAbi string) error
+}
+
 type AddressRepository interface {
 	GetOrCreateAddress(address string) (int, error)
 }
 
+type AddressRegistryRepository interface {
+	CountRegisteredAddresses(registry string) (int64, error)
+	GetRegisteredAddresses(registry string, afterID int64) ([]core.RegisteredAddress, error)
+	MarkRegisteredAddressesBackfilled(ids []int64) error
+	RegisterAddress(registeredAddress core.RegisteredAddress) error
+}
+
 type CheckedHeadersRepository interface {
 	MarkHeaderChecked(headerID int64) error
 	MarkSingleHeaderUnchecked(blockNumber int64) error
@@ -33,22 +48,43 @@ type CheckedHeadersRepository interface {
 }
 
 type CheckedLogsRepository interface {
-	AlreadyWatchingLog(addresses []string, topic0 string) (bool, error)
-	MarkLogWatched(addresses []string, topic0 string) error
+	MarkHeaderChecked(headerID int64, watchedLogIDs []int64) error
+	MarkSingleHeaderUnchecked(blockNumber int64) error
+	UncheckedHeaders(watchedLogs []core.WatchedLog, checkCount int64, limit int) ([]core.UncheckedHeader, error)
+	WatchLog(address, topic0 string, topicFilters [][]string) (int64, error)
+	WatchRegistryLog(registry, topic0 string, topicFilters [][]string) (int64, error)
 }
 
 type HeaderRepository interface {
 	CreateOrUpdateHeader(header core.Header) (int64, error)
 	CreateTransactions(headerID int64, transactions []core.TransactionModel) error
+	CreateTransactionsWithReceipts(headerID int64, transactions []core.TransactionModel) error
 	CreateTransactionInTx(tx *sqlx.Tx, headerID int64, transaction core.TransactionModel) (int64, error)
 	GetHeaderByBlockNumber(blockNumber int64) (core.Header, error)
 	GetHeaderByID(id int64) (core.Header, error)
 	GetHeadersInRange(startingBlock, endingBlock int64) ([]core.Header, error)
+	GetHeadersWithUnsyncedTransactions(startingBlockNumber int64, limit int) ([]core.Header, error)
+	MarkTransactionsSynced(headerID int64) error
 	MissingBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error)
 	GetMostRecentHeaderBlockNumber() (int64, error)
+	ReplaceOrphanedHeaders(headers []core.Header, reorg core.Reorg) error
+}
+
+type ReorgRepository interface {
+	CreateReorg(reorg core.Reorg) (int64, error)
+	GetMostRecentReorgID() (int64, error)
+	GetReorgsAfterID(id int64) ([]core.Reorg, error)
+	GetLastNotifiedReorgID(transformer string) (int64, error)
+	MarkReorgNotified(transformer string, reorgID int64) error
 }
 
 type EventLogRepository interface {
-	GetUntransformedEventLogs(minID, limit int) ([]core.EventLog, error)
+	GetUntransformedEventLogs(transformer string, topic0 common.Hash, minID, limit int) ([]core.EventLog, error)
 	CreateEventLogs(headerID int64, logs []types.Log) error
+	GetEventLogsInRange(transformer string, topic0 common.Hash, startingBlock, endingBlock int64, minID, limit int) ([]core.EventLog, error)
+	GetQuarantinedEventLogs(transformer string) ([]core.QuarantinedEventLog, error)
+	MarkEventLogsTransformed(transformer string, logIDs []int64) error
+	RecordTransformError(logID int64, transformer string, transformErr error, maxErrors int64, backoff time.Duration) (bool, error)
+	ResetTransformedEventLogs(transformer string) error
+	RetryQuarantinedEventLogs(transformer string, logIDs []int64) (int64, error)
 }
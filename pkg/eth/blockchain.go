@@ -18,6 +18,7 @@ package eth
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"strconv"
 
@@ -30,7 +31,11 @@ import (
 	"golang.org/x/net/context"
 )
 
-var ErrEmptyHeader = errors.New("empty header returned over RPC")
+var (
+	ErrEmptyBlock  = errors.New("empty block returned over RPC")
+	ErrEmptyHeader = errors.New("empty header returned over RPC")
+	ErrNoReceipt   = errors.New("no receipt returned over RPC")
+)
 
 const MAX_BATCH_SIZE = 100
 
@@ -38,6 +43,7 @@ type BlockChain struct {
 	ethClient            core.EthClient
 	headerConverter      converters.HeaderConverter
 	node                 core.Node
+	receiptConverter     converters.ReceiptConverter
 	rpcClient            core.RpcClient
 	transactionConverter converters.TransactionConverter
 }
@@ -47,34 +53,67 @@ func NewBlockChain(ethClient core.EthClient, rpcClient core.RpcClient, node core
 		ethClient:            ethClient,
 		headerConverter:      converters.HeaderConverter{},
 		node:                 node,
+		receiptConverter:     converters.ReceiptConverter{},
 		rpcClient:            rpcClient,
 		transactionConverter: converter,
 	}
 }
 
-func (blockChain *BlockChain) GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error) {
-	gethLogs, err := blockChain.ethClient.FilterLogs(context.Background(), query)
+func (blockChain *BlockChain) GetBlockWithTransactions(ctx context.Context, blockNumber int64) (core.Block, error) {
+	var rpcBlock core.RpcBlock
+	blockNumberArg := hexutil.EncodeBig(big.NewInt(blockNumber))
+	includeTransactions := true
+	err := blockChain.rpcClient.CallContext(ctx, &rpcBlock, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
+	if err != nil {
+		return core.Block{}, err
+	}
+	if rpcBlock.Hash == "" {
+		return core.Block{}, ErrEmptyBlock
+	}
+	transactions, convertErr := blockChain.transactionConverter.ConvertRpcTransactionsToModels(rpcBlock.Transactions)
+	if convertErr != nil {
+		return core.Block{}, fmt.Errorf("error converting transactions for block %d: %w", blockNumber, convertErr)
+	}
+	return core.Block{Hash: rpcBlock.Hash, Number: blockNumber, Transactions: transactions}, nil
+}
+
+func (blockChain *BlockChain) GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	gethLogs, err := blockChain.ethClient.FilterLogs(ctx, query)
 	if err != nil {
 		return []types.Log{}, err
 	}
 	return gethLogs, nil
 }
 
-func (blockChain *BlockChain) GetHeaderByNumber(blockNumber int64) (header core.Header, err error) {
+func (blockChain *BlockChain) GetHeaderByNumber(ctx context.Context, blockNumber int64) (header core.Header, err error) {
 	if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
-		return blockChain.getPOAHeader(blockNumber)
+		return blockChain.getPOAHeader(ctx, blockNumber)
 	}
-	return blockChain.getPOWHeader(blockNumber)
+	return blockChain.getPOWHeader(ctx, blockNumber)
 }
 
-func (blockChain *BlockChain) GetHeadersByNumbers(blockNumbers []int64) (header []core.Header, err error) {
-	if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
-		return blockChain.getPOAHeaders(blockNumbers)
+// Fetches headers in batch calls of at most MAX_BATCH_SIZE block numbers each
+func (blockChain *BlockChain) GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
+	for start := 0; start < len(blockNumbers); start += MAX_BATCH_SIZE {
+		end := start + MAX_BATCH_SIZE
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		var batchHeaders []core.Header
+		if blockChain.node.NetworkID == core.KOVAN_NETWORK_ID {
+			batchHeaders, err = blockChain.getPOAHeaders(ctx, blockNumbers[start:end])
+		} else {
+			batchHeaders, err = blockChain.getPOWHeaders(ctx, blockNumbers[start:end])
+		}
+		if err != nil {
+			return headers, err
+		}
+		headers = append(headers, batchHeaders...)
 	}
-	return blockChain.getPOWHeaders(blockNumbers)
+	return headers, nil
 }
 
-func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) ([]core.TransactionModel, error) {
+func (blockChain *BlockChain) GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]core.TransactionModel, error) {
 	numTransactions := len(transactionHashes)
 	var batch []core.BatchElem
 	transactions := make([]core.RpcTransaction, numTransactions)
@@ -88,7 +127,7 @@ func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) (
 		batch = append(batch, batchElem)
 	}
 
-	rpcErr := blockChain.rpcClient.BatchCall(batch)
+	rpcErr := blockChain.rpcClient.BatchCall(ctx, batch)
 	if rpcErr != nil {
 		return []core.TransactionModel{}, rpcErr
 	}
@@ -96,15 +135,53 @@ func (blockChain *BlockChain) GetTransactions(transactionHashes []common.Hash) (
 	return blockChain.transactionConverter.ConvertRpcTransactionsToModels(transactions)
 }
 
-func (blockChain *BlockChain) ChainHead() (*big.Int, error) {
-	block, err := blockChain.ethClient.HeaderByNumber(context.Background(), nil)
+// Fetches receipts in batch calls of at most MAX_BATCH_SIZE transactions each
+func (blockChain *BlockChain) GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]core.Receipt, error) {
+	gethReceipts := make([]*types.Receipt, len(transactionHashes))
+	var batch []core.BatchElem
+	for index, transactionHash := range transactionHashes {
+		batchElem := core.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Result: &gethReceipts[index],
+			Args:   []interface{}{transactionHash},
+		}
+		batch = append(batch, batchElem)
+	}
+
+	for start := 0; start < len(batch); start += MAX_BATCH_SIZE {
+		end := start + MAX_BATCH_SIZE
+		if end > len(batch) {
+			end = len(batch)
+		}
+		rpcErr := blockChain.rpcClient.BatchCall(ctx, batch[start:end])
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+
+	var receipts []core.Receipt
+	for index, gethReceipt := range gethReceipts {
+		if gethReceipt == nil {
+			return nil, fmt.Errorf("%w for transaction %s", ErrNoReceipt, transactionHashes[index].Hex())
+		}
+		receipt, convertErr := blockChain.receiptConverter.Convert(gethReceipt)
+		if convertErr != nil {
+			return nil, fmt.Errorf("error converting receipt for transaction %s: %w", transactionHashes[index].Hex(), convertErr)
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+func (blockChain *BlockChain) ChainHead(ctx context.Context) (*big.Int, error) {
+	block, err := blockChain.ethClient.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return big.NewInt(0), err
 	}
 	return block.Number, err
 }
 
-func (blockChain *BlockChain) BatchGetStorageAt(account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error) {
+func (blockChain *BlockChain) BatchGetStorageAt(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error) {
 	numStorageValues := len(keys)
 	var batch []core.BatchElem
 	storageValues := make([]hexutil.Bytes, numStorageValues)
@@ -119,7 +196,7 @@ func (blockChain *BlockChain) BatchGetStorageAt(account common.Address, keys []c
 		batch = append(batch, batchElem)
 	}
 
-	rpcErr := blockChain.rpcClient.BatchCall(batch)
+	rpcErr := blockChain.rpcClient.BatchCall(ctx, batch)
 	if rpcErr != nil {
 		return nil, rpcErr
 	}
@@ -135,18 +212,23 @@ func (blockChain *BlockChain) Node() core.Node {
 	return blockChain.node
 }
 
-func (blockChain *BlockChain) getPOAHeader(blockNumber int64) (header core.Header, err error) {
+// Subscribes to headers as they're added to the node's canonical chain; requires an IPC or websocket connection
+func (blockChain *BlockChain) SubscribeNewHeads(ctx context.Context, payloadChan chan *types.Header) (core.Subscription, error) {
+	return blockChain.rpcClient.Subscribe(ctx, "eth", payloadChan, "newHeads")
+}
+
+func (blockChain *BlockChain) getPOAHeader(ctx context.Context, blockNumber int64) (header core.Header, err error) {
 	var POAHeader core.POAHeader
 	blockNumberArg := hexutil.EncodeBig(big.NewInt(blockNumber))
 	includeTransactions := false
-	err = blockChain.rpcClient.CallContext(context.Background(), &POAHeader, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
+	err = blockChain.rpcClient.CallContext(ctx, &POAHeader, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
 	if err != nil {
 		return header, err
 	}
 	if POAHeader.Number == nil {
 		return header, ErrEmptyHeader
 	}
-	return blockChain.headerConverter.Convert(&types.Header{
+	header = blockChain.headerConverter.Convert(&types.Header{
 		ParentHash:  POAHeader.ParentHash,
 		UncleHash:   POAHeader.UncleHash,
 		Coinbase:    POAHeader.Coinbase,
@@ -160,10 +242,12 @@ func (blockChain *BlockChain) getPOAHeader(blockNumber int64) (header core.Heade
 		GasUsed:     uint64(POAHeader.GasUsed),
 		Time:        uint64(POAHeader.Time),
 		Extra:       POAHeader.Extra,
-	}, POAHeader.Hash.String()), nil
+	}, POAHeader.Hash.String())
+	header.BaseFee = baseFeeToString(POAHeader.BaseFee)
+	return header, nil
 }
 
-func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []core.Header, err error) {
+func (blockChain *BlockChain) getPOAHeaders(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
 
 	var batch []core.BatchElem
 	var POAHeaders [MAX_BATCH_SIZE]core.POAHeader
@@ -186,7 +270,7 @@ func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []cor
 		batch = append(batch, batchElem)
 	}
 
-	err = blockChain.rpcClient.BatchCall(batch)
+	err = blockChain.rpcClient.BatchCall(ctx, batch)
 	if err != nil {
 		return headers, err
 	}
@@ -210,6 +294,7 @@ func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []cor
 				Time:        uint64(POAHeader.Time),
 				Extra:       POAHeader.Extra,
 			}, POAHeader.Hash.String())
+			header.BaseFee = baseFeeToString(POAHeader.BaseFee)
 
 			headers = append(headers, header)
 		}
@@ -218,17 +303,23 @@ func (blockChain *BlockChain) getPOAHeaders(blockNumbers []int64) (headers []cor
 	return headers, err
 }
 
-func (blockChain *BlockChain) getPOWHeader(blockNumber int64) (header core.Header, err error) {
-	gethHeader, err := blockChain.ethClient.HeaderByNumber(context.Background(), big.NewInt(blockNumber))
+func (blockChain *BlockChain) getPOWHeader(ctx context.Context, blockNumber int64) (header core.Header, err error) {
+	var POWHeader core.POWHeader
+	blockNumberArg := hexutil.EncodeBig(big.NewInt(blockNumber))
+	includeTransactions := false
+	err = blockChain.rpcClient.CallContext(ctx, &POWHeader, "eth_getBlockByNumber", blockNumberArg, includeTransactions)
 	if err != nil {
 		return header, err
 	}
-	return blockChain.headerConverter.Convert(gethHeader, gethHeader.Hash().String()), nil
+	if POWHeader.Number == nil {
+		return header, ErrEmptyHeader
+	}
+	return blockChain.convertPOWHeader(POWHeader), nil
 }
 
-func (blockChain *BlockChain) getPOWHeaders(blockNumbers []int64) (headers []core.Header, err error) {
+func (blockChain *BlockChain) getPOWHeaders(ctx context.Context, blockNumbers []int64) (headers []core.Header, err error) {
 	var batch []core.BatchElem
-	var POWHeaders [MAX_BATCH_SIZE]types.Header
+	var POWHeaders [MAX_BATCH_SIZE]core.POWHeader
 	includeTransactions := false
 
 	for index, blockNumber := range blockNumbers {
@@ -248,17 +339,34 @@ func (blockChain *BlockChain) getPOWHeaders(blockNumbers []int64) (headers []cor
 		batch = append(batch, batchElem)
 	}
 
-	err = blockChain.rpcClient.BatchCall(batch)
+	err = blockChain.rpcClient.BatchCall(ctx, batch)
 	if err != nil {
 		return headers, err
 	}
 
 	for _, POWHeader := range POWHeaders {
 		if POWHeader.Number != nil {
-			header := blockChain.headerConverter.Convert(&POWHeader, POWHeader.Hash().String())
-			headers = append(headers, header)
+			headers = append(headers, blockChain.convertPOWHeader(POWHeader))
 		}
 	}
 
 	return headers, err
 }
+
+func (blockChain *BlockChain) convertPOWHeader(POWHeader core.POWHeader) core.Header {
+	blockHash := POWHeader.BlockHash
+	if blockHash == (common.Hash{}) {
+		blockHash = POWHeader.Header.Hash()
+	}
+	header := blockChain.headerConverter.Convert(&POWHeader.Header, blockHash.String())
+	header.BaseFee = baseFeeToString(POWHeader.BaseFee)
+	return header
+}
+
+// Base fee is only present on blocks after the London hard fork
+func baseFeeToString(baseFee *hexutil.Big) string {
+	if baseFee == nil {
+		return ""
+	}
+	return baseFee.ToInt().String()
+}
@@ -52,31 +52,74 @@ var _ = Describe("Geth blockchain", func() {
 
 	Describe("getting a header", func() {
 		Describe("default/mainnet", func() {
-			It("fetches header from ethClient", func() {
-				blockNumber := int64(100)
-				mockClient.SetHeaderByNumberReturnHeader(&types.Header{Number: big.NewInt(blockNumber)})
+			It("fetches header from rpcClient", func() {
+				mockRpcClient.SetReturnPOWHeader(core.POWHeader{Header: types.Header{Number: big.NewInt(100)}})
+
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
+
+				Expect(err).NotTo(HaveOccurred())
+				mockRpcClient.AssertCallContextCalledWith(context.Background(), &core.POWHeader{}, "eth_getBlockByNumber")
+			})
+
+			It("uses the node-reported block hash and base fee", func() {
+				baseFee := hexutil.Big(*big.NewInt(7))
+				mockRpcClient.SetReturnPOWHeader(core.POWHeader{
+					Header:    types.Header{Number: big.NewInt(100)},
+					BaseFee:   &baseFee,
+					BlockHash: fakes.FakeHash,
+				})
+
+				header, err := blockChain.GetHeaderByNumber(context.Background(), 100)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(header.Hash).To(Equal(fakes.FakeHash.Hex()))
+				Expect(header.BaseFee).To(Equal("7"))
+			})
 
-				_, err := blockChain.GetHeaderByNumber(blockNumber)
+			It("leaves base fee empty for blocks before London", func() {
+				mockRpcClient.SetReturnPOWHeader(core.POWHeader{Header: types.Header{Number: big.NewInt(100)}})
+
+				header, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).NotTo(HaveOccurred())
-				mockClient.AssertHeaderByNumberCalledWith(context.Background(), big.NewInt(blockNumber))
+				Expect(header.BaseFee).To(BeEmpty())
 			})
 
-			It("returns err if ethClient returns err", func() {
-				mockClient.SetHeaderByNumberErr(fakes.FakeError)
+			It("returns err if rpcClient returns err", func() {
+				mockRpcClient.SetCallContextErr(fakes.FakeError)
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
 			})
 
+			It("returns error if returned header is empty", func() {
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
+
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(MatchError(eth.ErrEmptyHeader))
+			})
+
 			It("fetches headers with multiple blocks", func() {
-				_, err := blockChain.GetHeadersByNumbers([]int64{100, 99})
+				_, err := blockChain.GetHeadersByNumbers(context.Background(), []int64{100, 99})
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 2)
 			})
+
+			It("fetches headers in batches of at most MAX_BATCH_SIZE blocks", func() {
+				var blockNumbers []int64
+				for i := int64(0); i < eth.MAX_BATCH_SIZE+50; i++ {
+					blockNumbers = append(blockNumbers, i)
+				}
+
+				headers, err := blockChain.GetHeadersByNumbers(context.Background(), blockNumbers)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(headers)).To(Equal(eth.MAX_BATCH_SIZE + 50))
+				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 50)
+			})
 		})
 
 		Describe("POA/Kovan", func() {
@@ -86,7 +129,7 @@ var _ = Describe("Geth blockchain", func() {
 				mockRpcClient.SetReturnPOAHeader(core.POAHeader{Number: &blockNumber})
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertCallContextCalledWith(context.Background(), &core.POAHeader{}, "eth_getBlockByNumber")
@@ -97,7 +140,7 @@ var _ = Describe("Geth blockchain", func() {
 				mockRpcClient.SetCallContextErr(fakes.FakeError)
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(fakes.FakeError))
@@ -107,7 +150,7 @@ var _ = Describe("Geth blockchain", func() {
 				node.NetworkID = core.KOVAN_NETWORK_ID
 				blockChain = eth.NewBlockChain(mockClient, mockRpcClient, node, fakes.NewMockTransactionConverter())
 
-				_, err := blockChain.GetHeaderByNumber(100)
+				_, err := blockChain.GetHeaderByNumber(context.Background(), 100)
 
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(MatchError(eth.ErrEmptyHeader))
@@ -118,7 +161,7 @@ var _ = Describe("Geth blockchain", func() {
 				blockNumber := hexutil.Big(*big.NewInt(100))
 				mockRpcClient.SetReturnPOAHeaders([]core.POAHeader{{Number: &blockNumber}})
 
-				_, err := blockChain.GetHeadersByNumbers([]int64{100, 99})
+				_, err := blockChain.GetHeadersByNumbers(context.Background(), []int64{100, 99})
 
 				Expect(err).NotTo(HaveOccurred())
 				mockRpcClient.AssertBatchCalledWith("eth_getBlockByNumber", 2)
@@ -140,7 +183,7 @@ var _ = Describe("Geth blockchain", func() {
 				Topics:    [][]common.Hash{{topic}},
 			}
 
-			_, err := blockChain.GetEthLogsWithCustomQuery(query)
+			_, err := blockChain.GetEthLogsWithCustomQuery(context.Background(), query)
 
 			Expect(err).NotTo(HaveOccurred())
 			mockClient.AssertFilterLogsCalledWith(context.Background(), query)
@@ -158,7 +201,7 @@ var _ = Describe("Geth blockchain", func() {
 				Topics:    nil,
 			}
 
-			_, err := blockChain.GetEthLogsWithCustomQuery(query)
+			_, err := blockChain.GetEthLogsWithCustomQuery(context.Background(), query)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err).To(MatchError(fakes.FakeError))
@@ -167,26 +210,101 @@ var _ = Describe("Geth blockchain", func() {
 
 	Describe("getting transactions", func() {
 		It("fetches transaction for each hash", func() {
-			_, err := blockChain.GetTransactions([]common.Hash{{}, {}})
+			_, err := blockChain.GetTransactions(context.Background(), []common.Hash{{}, {}})
 
 			Expect(err).NotTo(HaveOccurred())
 			mockRpcClient.AssertBatchCalledWith("eth_getTransactionByHash", 2)
 		})
 
 		It("converts rpc transaction to models", func() {
-			_, err := blockChain.GetTransactions([]common.Hash{{}, {}})
+			_, err := blockChain.GetTransactions(context.Background(), []common.Hash{{}, {}})
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(mockTransactionConverter.ConvertRpcTransactionsToModelsCalled).To(BeTrue())
 		})
 	})
 
+	Describe("getting a block with transactions", func() {
+		It("fetches the block with full transactions from rpcClient", func() {
+			mockRpcClient.SetReturnRpcBlock(core.RpcBlock{Hash: fakes.FakeHash.Hex()})
+
+			block, err := blockChain.GetBlockWithTransactions(context.Background(), 100)
+
+			Expect(err).NotTo(HaveOccurred())
+			mockRpcClient.AssertCallContextCalledWith(context.Background(), &core.RpcBlock{}, "eth_getBlockByNumber")
+			Expect(block.Hash).To(Equal(fakes.FakeHash.Hex()))
+			Expect(block.Number).To(Equal(int64(100)))
+		})
+
+		It("converts rpc transactions to models", func() {
+			mockRpcClient.SetReturnRpcBlock(core.RpcBlock{Hash: fakes.FakeHash.Hex()})
+
+			_, err := blockChain.GetBlockWithTransactions(context.Background(), 100)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockTransactionConverter.ConvertRpcTransactionsToModelsCalled).To(BeTrue())
+		})
+
+		It("returns err if rpcClient returns err", func() {
+			mockRpcClient.SetCallContextErr(fakes.FakeError)
+
+			_, err := blockChain.GetBlockWithTransactions(context.Background(), 100)
+
+			Expect(err).To(MatchError(fakes.FakeError))
+		})
+
+		It("returns error if returned block is empty", func() {
+			_, err := blockChain.GetBlockWithTransactions(context.Background(), 100)
+
+			Expect(err).To(MatchError(eth.ErrEmptyBlock))
+		})
+	})
+
+	Describe("getting transaction receipts", func() {
+		It("fetches receipt for each hash", func() {
+			mockRpcClient.ReceiptToReturn = &types.Receipt{}
+
+			_, err := blockChain.GetTransactionReceipts(context.Background(), []common.Hash{{}, {}})
+
+			Expect(err).NotTo(HaveOccurred())
+			mockRpcClient.AssertBatchCalledWith("eth_getTransactionReceipt", 2)
+		})
+
+		It("fetches receipts in batches of at most MAX_BATCH_SIZE transactions", func() {
+			mockRpcClient.ReceiptToReturn = &types.Receipt{}
+			hashes := make([]common.Hash, eth.MAX_BATCH_SIZE+50)
+
+			receipts, err := blockChain.GetTransactionReceipts(context.Background(), hashes)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(receipts)).To(Equal(eth.MAX_BATCH_SIZE + 50))
+			mockRpcClient.AssertBatchCalledWith("eth_getTransactionReceipt", 50)
+		})
+
+		It("converts receipts to core receipts", func() {
+			mockRpcClient.ReceiptToReturn = &types.Receipt{GasUsed: 21000, TxHash: fakes.FakeHash}
+
+			receipts, err := blockChain.GetTransactionReceipts(context.Background(), []common.Hash{fakes.FakeHash})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(receipts)).To(Equal(1))
+			Expect(receipts[0].GasUsed).To(Equal(uint64(21000)))
+			Expect(receipts[0].TxHash).To(Equal(fakes.FakeHash.Hex()))
+		})
+
+		It("returns error if a receipt is missing", func() {
+			_, err := blockChain.GetTransactionReceipts(context.Background(), []common.Hash{fakes.FakeHash})
+
+			Expect(err).To(MatchError(eth.ErrNoReceipt))
+		})
+	})
+
 	Describe("getting the most recent block number", func() {
 		It("fetches latest header from ethClient", func() {
 			blockNumber := int64(100)
 			mockClient.SetHeaderByNumberReturnHeader(&types.Header{Number: big.NewInt(blockNumber)})
 
-			result, err := blockChain.ChainHead()
+			result, err := blockChain.ChainHead(context.Background())
 			Expect(err).NotTo(HaveOccurred())
 
 			mockClient.AssertHeaderByNumberCalledWith(context.Background(), nil)
@@ -201,7 +319,7 @@ var _ = Describe("Geth blockchain", func() {
 		)
 
 		It("fetches storage for each key", func() {
-			_, err := blockChain.BatchGetStorageAt(account, []common.Hash{{}, {}}, blockNumber)
+			_, err := blockChain.BatchGetStorageAt(context.Background(), account, []common.Hash{{}, {}}, blockNumber)
 			Expect(err).NotTo(HaveOccurred())
 
 			mockRpcClient.AssertBatchCalledWith("eth_getStorageAt", 2)
@@ -212,7 +330,7 @@ var _ = Describe("Geth blockchain", func() {
 			fakeStorageValue := test_data.FakeHash().Bytes()
 			mockRpcClient.StorageValueToReturn = fakeStorageValue
 
-			result, err := blockChain.BatchGetStorageAt(account, []common.Hash{fakeKey}, blockNumber)
+			result, err := blockChain.BatchGetStorageAt(context.Background(), account, []common.Hash{fakeKey}, blockNumber)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(map[common.Hash][]byte{fakeKey: fakeStorageValue}))
@@ -19,12 +19,14 @@ package client
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 )
 
 type EthClient struct {
@@ -36,19 +38,31 @@ func NewEthClient(client *ethclient.Client) EthClient {
 }
 
 func (client EthClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
-	return client.client.BlockByNumber(ctx, number)
+	start := time.Now()
+	block, err := client.client.BlockByNumber(ctx, number)
+	metrics.ObserveRPC("eth_getBlockByNumber", start, err)
+	return block, err
 }
 
 func (client EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
-	return client.client.CallContract(ctx, msg, blockNumber)
+	start := time.Now()
+	result, err := client.client.CallContract(ctx, msg, blockNumber)
+	metrics.ObserveRPC("eth_call", start, err)
+	return result, err
 }
 
 func (client EthClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
-	return client.client.FilterLogs(ctx, q)
+	start := time.Now()
+	logs, err := client.client.FilterLogs(ctx, q)
+	metrics.ObserveRPC("eth_getLogs", start, err)
+	return logs, err
 }
 
 func (client EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
-	return client.client.HeaderByNumber(ctx, number)
+	start := time.Now()
+	header, err := client.client.HeaderByNumber(ctx, number)
+	metrics.ObserveRPC("eth_getBlockByNumber", start, err)
+	return header, err
 }
 
 func (client EthClient) SubscribeNewStateChanges(ctx context.Context, q ethereum.FilterQuery, ch chan<- filters.Payload) (ethereum.Subscription, error) {
@@ -56,9 +70,15 @@ func (client EthClient) SubscribeNewStateChanges(ctx context.Context, q ethereum
 }
 
 func (client EthClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
-	return client.client.TransactionSender(ctx, tx, block, index)
+	start := time.Now()
+	sender, err := client.client.TransactionSender(ctx, tx, block, index)
+	metrics.ObserveRPC("eth_getTransactionByBlockHashAndIndex", start, err)
+	return sender, err
 }
 
 func (client EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
-	return client.client.TransactionReceipt(ctx, txHash)
+	start := time.Now()
+	receipt, err := client.client.TransactionReceipt(ctx, txHash)
+	metrics.ObserveRPC("eth_getTransactionReceipt", start, err)
+	return receipt, err
 }
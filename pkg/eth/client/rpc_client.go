@@ -20,9 +20,11 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 )
 
 type RpcClient struct {
@@ -38,21 +40,25 @@ func NewRpcClient(client *rpc.Client, ipcPath string) RpcClient {
 }
 
 func (client RpcClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := time.Now()
+	var err error
 	//If an empty interface (or other nil object) is passed to CallContext, when the JSONRPC message is created the params will
 	//be interpreted as [null]. This seems to work fine for most of the ethereum clients (which presumably ignore a null parameter.
 	//Ganache however does not ignore it, and throws an 'Incorrect number of arguments' error.
 	if args == nil {
-		return client.client.CallContext(ctx, result, method)
+		err = client.client.CallContext(ctx, result, method)
 	} else {
-		return client.client.CallContext(ctx, result, method, args...)
+		err = client.client.CallContext(ctx, result, method, args...)
 	}
+	metrics.ObserveRPC(method, start, err)
+	return err
 }
 
 func (client RpcClient) IpcPath() string {
 	return client.ipcPath
 }
 
-func (client RpcClient) BatchCall(batch []core.BatchElem) error {
+func (client RpcClient) BatchCall(ctx context.Context, batch []core.BatchElem) error {
 	var rpcBatch []rpc.BatchElem
 	for _, batchElem := range batch {
 		var newBatchElem = rpc.BatchElem{
@@ -64,12 +70,23 @@ func (client RpcClient) BatchCall(batch []core.BatchElem) error {
 
 		rpcBatch = append(rpcBatch, newBatchElem)
 	}
-	return client.client.BatchCall(rpcBatch)
+	start := time.Now()
+	err := client.client.BatchCallContext(ctx, rpcBatch)
+	metrics.ObserveRPC(batchMethod(batch), start, err)
+	return err
+}
+
+// Batches are made up of requests for a single method, so they're labelled with that method
+func batchMethod(batch []core.BatchElem) string {
+	if len(batch) == 0 {
+		return "batch"
+	}
+	return "batch_" + batch[0].Method
 }
 
 // Subscribe subscribes to an rpc "namespace_subscribe" subscription with the given channel
 // The first argument needs to be the method we wish to invoke
-func (client RpcClient) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (core.Subscription, error) {
+func (client RpcClient) Subscribe(ctx context.Context, namespace string, payloadChan interface{}, args ...interface{}) (core.Subscription, error) {
 	chanVal := reflect.ValueOf(payloadChan)
 	if chanVal.Kind() != reflect.Chan || chanVal.Type().ChanDir()&reflect.SendDir == 0 {
 		return nil, errors.New("second argument to Subscribe must be a writable channel")
@@ -77,6 +94,6 @@ func (client RpcClient) Subscribe(namespace string, payloadChan interface{}, arg
 	if chanVal.IsNil() {
 		return nil, errors.New("channel given to Subscribe must not be nil")
 	}
-	rpcSubscription, err := client.client.Subscribe(context.Background(), namespace, payloadChan, args...)
+	rpcSubscription, err := client.client.Subscribe(ctx, namespace, payloadChan, args...)
 	return Subscription{RpcSubscription: rpcSubscription}, err
 }
@@ -0,0 +1,51 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package converters
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
+type ReceiptConverter struct{}
+
+func (converter ReceiptConverter) Convert(gethReceipt *types.Receipt) (core.Receipt, error) {
+	receiptRLP, rlpErr := rlp.EncodeToBytes(gethReceipt)
+	if rlpErr != nil {
+		return core.Receipt{}, rlpErr
+	}
+	return core.Receipt{
+		Bloom:             hexutil.Encode(gethReceipt.Bloom.Bytes()),
+		ContractAddress:   gethReceipt.ContractAddress.Hex(),
+		CumulativeGasUsed: gethReceipt.CumulativeGasUsed,
+		GasUsed:           gethReceipt.GasUsed,
+		StateRoot:         stateRoot(gethReceipt),
+		Status:            int(gethReceipt.Status),
+		TxHash:            gethReceipt.TxHash.Hex(),
+		Rlp:               receiptRLP,
+	}, nil
+}
+
+// Pre-Byzantium receipts include an intermediate state root instead of a status
+func stateRoot(gethReceipt *types.Receipt) string {
+	if len(gethReceipt.PostState) == 0 {
+		return ""
+	}
+	return hexutil.Encode(gethReceipt.PostState)
+}
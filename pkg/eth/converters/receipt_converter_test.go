@@ -0,0 +1,73 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package converters_test
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/makerdao/vulcanizedb/pkg/eth/converters"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Receipt converter", func() {
+	var gethReceipt *types.Receipt
+
+	BeforeEach(func() {
+		gethReceipt = &types.Receipt{
+			ContractAddress:   common.HexToAddress("0x123"),
+			CumulativeGasUsed: 21000,
+			GasUsed:           7000,
+			Status:            types.ReceiptStatusSuccessful,
+			TxHash:            common.HexToHash("0x456"),
+		}
+	})
+
+	It("converts geth receipt to core receipt", func() {
+		receipt, err := converters.ReceiptConverter{}.Convert(gethReceipt)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(receipt.ContractAddress).To(Equal(gethReceipt.ContractAddress.Hex()))
+		Expect(receipt.CumulativeGasUsed).To(Equal(gethReceipt.CumulativeGasUsed))
+		Expect(receipt.GasUsed).To(Equal(gethReceipt.GasUsed))
+		Expect(receipt.Status).To(Equal(1))
+		Expect(receipt.StateRoot).To(BeEmpty())
+		Expect(receipt.TxHash).To(Equal(gethReceipt.TxHash.Hex()))
+		Expect(receipt.Bloom).To(Equal(hexutil.Encode(gethReceipt.Bloom.Bytes())))
+	})
+
+	It("includes the consensus encoding of the receipt", func() {
+		expectedRLP, rlpErr := rlp.EncodeToBytes(gethReceipt)
+		Expect(rlpErr).NotTo(HaveOccurred())
+
+		receipt, err := converters.ReceiptConverter{}.Convert(gethReceipt)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(receipt.Rlp).To(Equal(expectedRLP))
+	})
+
+	It("includes the state root of pre-Byzantium receipts", func() {
+		gethReceipt.PostState = common.HexToHash("0x789").Bytes()
+
+		receipt, err := converters.ReceiptConverter{}.Convert(gethReceipt)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(receipt.StateRoot).To(Equal(common.HexToHash("0x789").Hex()))
+	})
+})
@@ -18,6 +18,8 @@ package converters
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -25,8 +27,11 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/sirupsen/logrus"
 )
 
+var ErrBlobTransactionWithoutRecipient = errors.New("blob transaction missing recipient")
+
 type TransactionConverter interface {
 	ConvertRpcTransactionsToModels(transactions []core.RpcTransaction) ([]core.TransactionModel, error)
 }
@@ -48,6 +53,65 @@ type transactionData struct {
 	S            *big.Int
 }
 
+// EIP-2930 access list transaction payload, encoded after the type byte
+type accessListTransactionData struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	AccessList   []core.AccessTuple
+	V            *big.Int
+	R            *big.Int
+	S            *big.Int
+}
+
+// EIP-1559 dynamic fee transaction payload, encoded after the type byte
+type dynamicFeeTransactionData struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	GasTipCap    *big.Int
+	GasFeeCap    *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	AccessList   []core.AccessTuple
+	V            *big.Int
+	R            *big.Int
+	S            *big.Int
+}
+
+// EIP-4844 blob transaction payload, encoded after the type byte; blob transactions can't create contracts
+type blobTransactionData struct {
+	ChainID             *big.Int
+	AccountNonce        uint64
+	GasTipCap           *big.Int
+	GasFeeCap           *big.Int
+	GasLimit            uint64
+	Recipient           common.Address
+	Amount              *big.Int
+	Payload             []byte
+	AccessList          []core.AccessTuple
+	BlobFeeCap          *big.Int
+	BlobVersionedHashes []common.Hash
+	V                   *big.Int
+	R                   *big.Int
+	S                   *big.Int
+}
+
+// fields shared by every transaction type
+type commonTransactionData struct {
+	nonce     uint64
+	gasLimit  uint64
+	recipient *common.Address
+	amount    *big.Int
+	payload   []byte
+	v, r, s   *big.Int
+}
+
 func NewTransactionConverter(client core.EthClient) TransactionConverter {
 	return &transactionConverter{client: client}
 }
@@ -55,80 +119,214 @@ func NewTransactionConverter(client core.EthClient) TransactionConverter {
 func (converter *transactionConverter) ConvertRpcTransactionsToModels(transactions []core.RpcTransaction) ([]core.TransactionModel, error) {
 	var results []core.TransactionModel
 	for _, transaction := range transactions {
-		txData, convertErr := getTransactionData(transaction)
+		transactionModel, convertErr := convertTransaction(transaction)
 		if convertErr != nil {
-			return nil, convertErr
-		}
-		txRLP, rlpErr := getTransactionRLP(txData)
-		if rlpErr != nil {
-			return nil, rlpErr
-		}
-		txIndex, txIndexErr := hexToBigInt(transaction.TransactionIndex)
-		if txIndexErr != nil {
-			return nil, txIndexErr
-		}
-		transactionModel := core.TransactionModel{
-			Data:     txData.Payload,
-			From:     transaction.From,
-			GasLimit: txData.GasLimit,
-			GasPrice: txData.Price.Int64(),
-			Hash:     transaction.Hash,
-			Nonce:    txData.AccountNonce,
-			Raw:      txRLP,
-			// NOTE: Header Sync transactions don't include receipt; would require separate RPC call
-			To:      transaction.Recipient,
-			TxIndex: txIndex.Int64(),
-			Value:   txData.Amount.String(),
+			return nil, fmt.Errorf("error converting transaction %s: %w", transaction.Hash, convertErr)
 		}
 		results = append(results, transactionModel)
 	}
 	return results, nil
 }
 
-func getTransactionData(transaction core.RpcTransaction) (transactionData, error) {
+func convertTransaction(transaction core.RpcTransaction) (core.TransactionModel, error) {
+	txType, txTypeErr := optionalHexToBigInt(transaction.Type)
+	if txTypeErr != nil {
+		return core.TransactionModel{}, txTypeErr
+	}
+	txData, txDataErr := getCommonTransactionData(transaction)
+	if txDataErr != nil {
+		return core.TransactionModel{}, txDataErr
+	}
+	txIndex, txIndexErr := hexToBigInt(transaction.TransactionIndex)
+	if txIndexErr != nil {
+		return core.TransactionModel{}, txIndexErr
+	}
+	accessList, accessListErr := getAccessListJSON(txType.Int64(), transaction.AccessList)
+	if accessListErr != nil {
+		return core.TransactionModel{}, accessListErr
+	}
+	transactionModel := core.TransactionModel{
+		AccessList:          accessList,
+		BlobVersionedHashes: transaction.BlobVersionedHashes,
+		Data:                txData.payload,
+		From:                transaction.From,
+		GasLimit:            txData.gasLimit,
+		Hash:                transaction.Hash,
+		Nonce:               txData.nonce,
+		// NOTE: Header Sync transactions don't include receipt; would require separate RPC call
+		To:      transaction.Recipient,
+		TxIndex: txIndex.Int64(),
+		Type:    txType.Int64(),
+		Value:   txData.amount.String(),
+	}
+	// fee fields are optional for some transaction types, so they're persisted as NULL when absent
+	for _, field := range []struct {
+		hex   string
+		model *string
+	}{
+		{transaction.ChainID, &transactionModel.ChainID},
+		{transaction.GasPrice, &transactionModel.GasPrice},
+		{transaction.MaxFeePerGas, &transactionModel.MaxFeePerGas},
+		{transaction.MaxPriorityFeePerGas, &transactionModel.MaxPriorityFeePerGas},
+		{transaction.MaxFeePerBlobGas, &transactionModel.MaxFeePerBlobGas},
+	} {
+		value, valueErr := optionalHexToDecimalString(field.hex)
+		if valueErr != nil {
+			return core.TransactionModel{}, valueErr
+		}
+		*field.model = value
+	}
+
+	raw, rawErr := getTransactionRaw(txType.Int64(), txData, transaction)
+	if rawErr != nil {
+		return core.TransactionModel{}, rawErr
+	}
+	transactionModel.Raw = raw
+	return transactionModel, nil
+}
+
+func getCommonTransactionData(transaction core.RpcTransaction) (commonTransactionData, error) {
 	nonce, nonceErr := hexToBigInt(transaction.Nonce)
 	if nonceErr != nil {
-		return transactionData{}, nonceErr
-	}
-	gasPrice, gasPriceErr := hexToBigInt(transaction.GasPrice)
-	if gasPriceErr != nil {
-		return transactionData{}, gasPriceErr
+		return commonTransactionData{}, nonceErr
 	}
 	gasLimit, gasLimitErr := hexToBigInt(transaction.GasLimit)
 	if gasLimitErr != nil {
-		return transactionData{}, gasLimitErr
+		return commonTransactionData{}, gasLimitErr
+	}
+	var recipient *common.Address
+	if transaction.Recipient != "" {
+		address := common.HexToAddress(transaction.Recipient)
+		recipient = &address
 	}
-	recipient := common.HexToAddress(transaction.Recipient)
 	amount, amountErr := hexToBigInt(transaction.Amount)
 	if amountErr != nil {
-		return transactionData{}, amountErr
+		return commonTransactionData{}, amountErr
+	}
+	payload, payloadErr := hexutil.Decode(transaction.Payload)
+	if payloadErr != nil {
+		return commonTransactionData{}, payloadErr
 	}
 	v, vErr := hexToBigInt(transaction.V)
 	if vErr != nil {
-		return transactionData{}, vErr
+		return commonTransactionData{}, vErr
 	}
 	r, rErr := hexToBigInt(transaction.R)
 	if rErr != nil {
-		return transactionData{}, rErr
+		return commonTransactionData{}, rErr
 	}
 	s, sErr := hexToBigInt(transaction.S)
 	if sErr != nil {
-		return transactionData{}, sErr
-	}
-	return transactionData{
-		AccountNonce: nonce.Uint64(),
-		Price:        gasPrice,
-		GasLimit:     gasLimit.Uint64(),
-		Recipient:    &recipient,
-		Amount:       amount,
-		Payload:      hexutil.MustDecode(transaction.Payload),
-		V:            v,
-		R:            r,
-		S:            s,
+		return commonTransactionData{}, sErr
+	}
+	return commonTransactionData{
+		nonce:     nonce.Uint64(),
+		gasLimit:  gasLimit.Uint64(),
+		recipient: recipient,
+		amount:    amount,
+		payload:   payload,
+		v:         v,
+		r:         r,
+		s:         s,
 	}, nil
 }
 
-func getTransactionRLP(txData transactionData) ([]byte, error) {
+// Returns the canonical encoding of the transaction: plain RLP for legacy transactions and the
+// EIP-2718 envelope (type byte followed by the RLP payload) for typed transactions
+func getTransactionRaw(txType int64, txData commonTransactionData, transaction core.RpcTransaction) ([]byte, error) {
+	switch txType {
+	case core.LegacyTxType:
+		gasPrice, gasPriceErr := hexToBigInt(transaction.GasPrice)
+		if gasPriceErr != nil {
+			return nil, gasPriceErr
+		}
+		return getTransactionRLP(transactionData{
+			AccountNonce: txData.nonce,
+			Price:        gasPrice,
+			GasLimit:     txData.gasLimit,
+			Recipient:    txData.recipient,
+			Amount:       txData.amount,
+			Payload:      txData.payload,
+			V:            txData.v,
+			R:            txData.r,
+			S:            txData.s,
+		})
+	case core.AccessListTxType:
+		values, err := hexesToBigInts(transaction.ChainID, transaction.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		chainID, gasPrice := values[0], values[1]
+		return getTypedTransactionEnvelope(txType, accessListTransactionData{
+			ChainID:      chainID,
+			AccountNonce: txData.nonce,
+			Price:        gasPrice,
+			GasLimit:     txData.gasLimit,
+			Recipient:    txData.recipient,
+			Amount:       txData.amount,
+			Payload:      txData.payload,
+			AccessList:   transaction.AccessList,
+			V:            txData.v,
+			R:            txData.r,
+			S:            txData.s,
+		})
+	case core.DynamicFeeTxType:
+		values, err := hexesToBigInts(transaction.ChainID, transaction.MaxPriorityFeePerGas, transaction.MaxFeePerGas)
+		if err != nil {
+			return nil, err
+		}
+		chainID, tipCap, feeCap := values[0], values[1], values[2]
+		return getTypedTransactionEnvelope(txType, dynamicFeeTransactionData{
+			ChainID:      chainID,
+			AccountNonce: txData.nonce,
+			GasTipCap:    tipCap,
+			GasFeeCap:    feeCap,
+			GasLimit:     txData.gasLimit,
+			Recipient:    txData.recipient,
+			Amount:       txData.amount,
+			Payload:      txData.payload,
+			AccessList:   transaction.AccessList,
+			V:            txData.v,
+			R:            txData.r,
+			S:            txData.s,
+		})
+	case core.BlobTxType:
+		values, err := hexesToBigInts(transaction.ChainID, transaction.MaxPriorityFeePerGas, transaction.MaxFeePerGas,
+			transaction.MaxFeePerBlobGas)
+		if err != nil {
+			return nil, err
+		}
+		chainID, tipCap, feeCap, blobFeeCap := values[0], values[1], values[2], values[3]
+		if txData.recipient == nil {
+			return nil, ErrBlobTransactionWithoutRecipient
+		}
+		var blobHashes []common.Hash
+		for _, blobHash := range transaction.BlobVersionedHashes {
+			blobHashes = append(blobHashes, common.HexToHash(blobHash))
+		}
+		return getTypedTransactionEnvelope(txType, blobTransactionData{
+			ChainID:             chainID,
+			AccountNonce:        txData.nonce,
+			GasTipCap:           tipCap,
+			GasFeeCap:           feeCap,
+			GasLimit:            txData.gasLimit,
+			Recipient:           *txData.recipient,
+			Amount:              txData.amount,
+			Payload:             txData.payload,
+			AccessList:          transaction.AccessList,
+			BlobFeeCap:          blobFeeCap,
+			BlobVersionedHashes: blobHashes,
+			V:                   txData.v,
+			R:                   txData.r,
+			S:                   txData.s,
+		})
+	default:
+		logrus.Warnf("unknown transaction type %d for transaction %s; persisting without raw encoding", txType, transaction.Hash)
+		return nil, nil
+	}
+}
+
+func getTransactionRLP(txData interface{}) ([]byte, error) {
 	transactionRlp := bytes.Buffer{}
 	encodeErr := rlp.Encode(&transactionRlp, txData)
 	if encodeErr != nil {
@@ -137,6 +335,37 @@ func getTransactionRLP(txData transactionData) ([]byte, error) {
 	return transactionRlp.Bytes(), nil
 }
 
+func getTypedTransactionEnvelope(txType int64, txData interface{}) ([]byte, error) {
+	payload, rlpErr := getTransactionRLP(txData)
+	if rlpErr != nil {
+		return nil, rlpErr
+	}
+	return append([]byte{byte(txType)}, payload...), nil
+}
+
+// Legacy transactions have no access list, so it's persisted as NULL rather than an empty list
+func getAccessListJSON(txType int64, accessList []core.AccessTuple) ([]byte, error) {
+	if txType == core.LegacyTxType {
+		return nil, nil
+	}
+	if accessList == nil {
+		accessList = []core.AccessTuple{}
+	}
+	return json.Marshal(accessList)
+}
+
+func hexesToBigInts(hexes ...string) ([]*big.Int, error) {
+	var results []*big.Int
+	for _, hex := range hexes {
+		result, err := hexToBigInt(hex)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 func hexToBigInt(hex string) (*big.Int, error) {
 	result := big.NewInt(0)
 	_, scanErr := fmt.Sscan(hex, result)
@@ -145,3 +374,23 @@ func hexToBigInt(hex string) (*big.Int, error) {
 	}
 	return result, nil
 }
+
+// Treats an absent field as zero
+func optionalHexToBigInt(hex string) (*big.Int, error) {
+	if hex == "" {
+		return big.NewInt(0), nil
+	}
+	return hexToBigInt(hex)
+}
+
+// Converts a hex quantity to a base 10 string, leaving absent fields empty
+func optionalHexToDecimalString(hex string) (string, error) {
+	if hex == "" {
+		return "", nil
+	}
+	result, err := hexToBigInt(hex)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
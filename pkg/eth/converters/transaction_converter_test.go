@@ -17,6 +17,10 @@
 package converters_test
 
 import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/eth/converters"
 	"github.com/makerdao/vulcanizedb/pkg/fakes"
@@ -39,7 +43,7 @@ var _ = Describe("RPC transaction converter", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(len(transactionModels)).To(Equal(1))
 		Expect(transactionModels[0].GasLimit).To(Equal(uint64(1)))
-		Expect(transactionModels[0].GasPrice).To(Equal(int64(1)))
+		Expect(transactionModels[0].GasPrice).To(Equal("1"))
 		Expect(transactionModels[0].Nonce).To(Equal(uint64(1)))
 		Expect(transactionModels[0].TxIndex).To(Equal(int64(1)))
 		Expect(transactionModels[0].Value).To(Equal("1"))
@@ -95,6 +99,133 @@ var _ = Describe("RPC transaction converter", func() {
 		Expect(model.Raw).To(Equal(expectedRLP))
 	})
 
+	It("encodes a nil recipient for contract creation", func() {
+		rpcTransaction := getFakeRpcTransaction("0x1")
+		rpcTransaction.Recipient = ""
+
+		transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+		Expect(err).NotTo(HaveOccurred())
+		var fields []rlp.RawValue
+		decodeErr := rlp.DecodeBytes(transactionModels[0].Raw, &fields)
+		Expect(decodeErr).NotTo(HaveOccurred())
+		Expect(fields[3]).To(Equal(rlp.RawValue{rlp.EmptyString[0]}))
+	})
+
+	Describe("typed transactions", func() {
+		It("treats a missing type as a legacy transaction", func() {
+			rpcTransaction := getFakeRpcTransaction("0x1")
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transactionModels[0].Type).To(Equal(int64(core.LegacyTxType)))
+			Expect(transactionModels[0].AccessList).To(BeNil())
+			Expect(transactionModels[0].MaxFeePerGas).To(BeEmpty())
+		})
+
+		It("copies access list transaction fields to model", func() {
+			rpcTransaction := getFakeAccessListRpcTransaction()
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			model := transactionModels[0]
+			Expect(model.Type).To(Equal(int64(core.AccessListTxType)))
+			Expect(model.ChainID).To(Equal("1"))
+			Expect(model.GasPrice).To(Equal("1"))
+			expectedAccessList, marshalErr := json.Marshal(rpcTransaction.AccessList)
+			Expect(marshalErr).NotTo(HaveOccurred())
+			Expect(model.AccessList).To(MatchJSON(expectedAccessList))
+		})
+
+		It("derives the typed envelope for an access list transaction", func() {
+			rpcTransaction := getFakeAccessListRpcTransaction()
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			raw := transactionModels[0].Raw
+			Expect(raw[0]).To(Equal(byte(core.AccessListTxType)))
+			var fields []rlp.RawValue
+			decodeErr := rlp.DecodeBytes(raw[1:], &fields)
+			Expect(decodeErr).NotTo(HaveOccurred())
+			Expect(len(fields)).To(Equal(11))
+			var accessList []core.AccessTuple
+			accessListErr := rlp.DecodeBytes(fields[7], &accessList)
+			Expect(accessListErr).NotTo(HaveOccurred())
+			Expect(accessList).To(Equal(rpcTransaction.AccessList))
+		})
+
+		It("copies dynamic fee transaction fields to model", func() {
+			rpcTransaction := getFakeDynamicFeeRpcTransaction()
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			model := transactionModels[0]
+			Expect(model.Type).To(Equal(int64(core.DynamicFeeTxType)))
+			Expect(model.ChainID).To(Equal("1"))
+			Expect(model.MaxFeePerGas).To(Equal("100"))
+			Expect(model.MaxPriorityFeePerGas).To(Equal("2"))
+			Expect(model.GasPrice).To(Equal("50"))
+			Expect(model.AccessList).To(MatchJSON("[]"))
+		})
+
+		It("derives the typed envelope for a dynamic fee transaction", func() {
+			rpcTransaction := getFakeDynamicFeeRpcTransaction()
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			raw := transactionModels[0].Raw
+			Expect(raw[0]).To(Equal(byte(core.DynamicFeeTxType)))
+			var fields struct {
+				ChainID              uint64
+				Nonce                uint64
+				MaxPriorityFeePerGas uint64
+				MaxFeePerGas         uint64
+				Gas                  uint64
+				To                   common.Address
+				Value                uint64
+				Data                 []byte
+				AccessList           []core.AccessTuple
+				V, R, S              uint64
+			}
+			decodeErr := rlp.DecodeBytes(raw[1:], &fields)
+			Expect(decodeErr).NotTo(HaveOccurred())
+			Expect(fields.ChainID).To(Equal(uint64(1)))
+			Expect(fields.MaxPriorityFeePerGas).To(Equal(uint64(2)))
+			Expect(fields.MaxFeePerGas).To(Equal(uint64(100)))
+			Expect(fields.To).To(Equal(fakes.FakeAddress))
+			Expect(fields.Data).To(Equal([]byte{0x12}))
+		})
+
+		It("requires a recipient for blob transactions", func() {
+			rpcTransaction := getFakeDynamicFeeRpcTransaction()
+			rpcTransaction.Type = "0x3"
+			rpcTransaction.MaxFeePerBlobGas = "0x1"
+			rpcTransaction.Recipient = ""
+
+			_, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(converters.ErrBlobTransactionWithoutRecipient))
+		})
+
+		It("persists unknown transaction types without a raw encoding", func() {
+			rpcTransaction := getFakeDynamicFeeRpcTransaction()
+			rpcTransaction.Type = "0x7e"
+
+			transactionModels, err := converter.ConvertRpcTransactionsToModels([]core.RpcTransaction{rpcTransaction})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transactionModels[0].Type).To(Equal(int64(0x7e)))
+			Expect(transactionModels[0].Raw).To(BeNil())
+			Expect(transactionModels[0].Hash).To(Equal(rpcTransaction.Hash))
+		})
+	})
+
 	It("does not include transaction receipt", func() {
 		rpcTransaction := getFakeRpcTransaction("0x1")
 
@@ -122,3 +253,26 @@ func getFakeRpcTransaction(hex string) core.RpcTransaction {
 		TransactionIndex: hex,
 	}
 }
+
+func getFakeAccessListRpcTransaction() core.RpcTransaction {
+	rpcTransaction := getFakeRpcTransaction("0x1")
+	rpcTransaction.Type = "0x1"
+	rpcTransaction.ChainID = "0x1"
+	rpcTransaction.V = "0x1"
+	rpcTransaction.AccessList = []core.AccessTuple{{
+		Address:     fakes.FakeAddress,
+		StorageKeys: []common.Hash{fakes.FakeHash},
+	}}
+	return rpcTransaction
+}
+
+func getFakeDynamicFeeRpcTransaction() core.RpcTransaction {
+	rpcTransaction := getFakeRpcTransaction("0x1")
+	rpcTransaction.Type = "0x2"
+	rpcTransaction.ChainID = "0x1"
+	rpcTransaction.GasPrice = "0x32"
+	rpcTransaction.MaxFeePerGas = "0x64"
+	rpcTransaction.MaxPriorityFeePerGas = "0x2"
+	rpcTransaction.V = "0x1"
+	return rpcTransaction
+}
@@ -0,0 +1,158 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	ErrUnknownEvent       = errors.New("log topic0 doesn't match an event in the abi")
+	ErrTopicCountMismatch = errors.New("log topics don't match the event's indexed arguments")
+)
+
+// DecodeLog decodes a log with the ABI of the contract that emitted it, returning the event name and its arguments
+// keyed by name, or arg<position> for unnamed arguments. Indexed strings, bytes, arrays and tuples are returned as the
+// keccak256 hash in their topic.
+func DecodeLog(contractAbi abi.ABI, log types.Log) (string, map[string]interface{}, error) {
+	if len(log.Topics) == 0 {
+		return "", nil, ErrUnknownEvent
+	}
+	event, eventErr := contractAbi.EventByID(log.Topics[0])
+	if eventErr != nil {
+		return "", nil, ErrUnknownEvent
+	}
+
+	var indexedCount int
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexedCount++
+		}
+	}
+	if len(log.Topics) != indexedCount+1 {
+		return "", nil, fmt.Errorf("%w: got %d topics for %d indexed arguments", ErrTopicCountMismatch, len(log.Topics), indexedCount)
+	}
+
+	nonIndexed := event.Inputs.NonIndexed()
+	var dataValues []interface{}
+	if len(nonIndexed) > 0 {
+		var unpackErr error
+		dataValues, unpackErr = nonIndexed.UnpackValues(log.Data)
+		if unpackErr != nil {
+			return "", nil, fmt.Errorf("error unpacking log data: %w", unpackErr)
+		}
+	}
+
+	args := make(map[string]interface{}, len(event.Inputs))
+	topicIndex, dataIndex := 1, 0
+	for i, arg := range event.Inputs {
+		name := arg.Name
+		if _, taken := args[name]; name == "" || taken {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		if arg.Indexed {
+			value, topicErr := UnpackTopic(arg, log.Topics[topicIndex])
+			if topicErr != nil {
+				return "", nil, fmt.Errorf("error unpacking topic %d: %w", topicIndex, topicErr)
+			}
+			if IsHashedInTopic(arg.Type) {
+				args[name] = value.(common.Hash).Hex()
+			} else {
+				args[name] = AbiValueToJSON(arg.Type, value)
+			}
+			topicIndex++
+		} else {
+			args[name] = AbiValueToJSON(arg.Type, dataValues[dataIndex])
+			dataIndex++
+		}
+	}
+	return event.RawName, args, nil
+}
+
+// UnpackTopic unpacks an indexed argument from its topic, returning the topic itself for arguments stored as a hash
+func UnpackTopic(arg abi.Argument, topic common.Hash) (interface{}, error) {
+	if IsHashedInTopic(arg.Type) {
+		return topic, nil
+	}
+	// Static types are encoded in topics as they are in data
+	arg.Indexed = false
+	values, unpackErr := abi.Arguments{arg}.UnpackValues(topic.Bytes())
+	if unpackErr != nil {
+		return nil, unpackErr
+	}
+	return values[0], nil
+}
+
+// IsHashedInTopic reports whether indexed arguments of the type are stored as the keccak256 hash of their value
+func IsHashedInTopic(typ abi.Type) bool {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
+
+// AbiValueToJSON converts an unpacked argument to a value that encodes to JSON without losing precision: numbers as
+// JSON numbers, bytes and addresses as hex strings, and tuples as objects keyed by field name
+func AbiValueToJSON(typ abi.Type, value interface{}) interface{} {
+	return toJSONValue(typ, reflect.ValueOf(value))
+}
+
+func toJSONValue(typ abi.Type, value reflect.Value) interface{} {
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		elements := make([]interface{}, value.Len())
+		for i := range elements {
+			elements[i] = toJSONValue(*typ.Elem, value.Index(i))
+		}
+		return elements
+	case abi.TupleTy:
+		fields := make(map[string]interface{}, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			fields[typ.TupleRawNames[i]] = toJSONValue(*elem, value.Field(i))
+		}
+		return fields
+	case abi.IntTy, abi.UintTy:
+		return json.Number(fmt.Sprint(value.Interface()))
+	case abi.AddressTy:
+		return value.Interface().(common.Address).Hex()
+	case abi.FixedBytesTy, abi.BytesTy, abi.HashTy, abi.FunctionTy:
+		return hexutil.Encode(AbiBytes(value.Interface()))
+	default:
+		return value.Interface()
+	}
+}
+
+// AbiBytes returns the bytes of an unpacked bytes, fixed size bytes, hash or function argument
+func AbiBytes(value interface{}) []byte {
+	if bytes, ok := value.([]byte); ok {
+		return bytes
+	}
+	array := reflect.ValueOf(value)
+	bytes := make([]byte, array.Len())
+	reflect.Copy(reflect.ValueOf(bytes), array)
+	return bytes
+}
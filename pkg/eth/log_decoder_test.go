@@ -0,0 +1,101 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package eth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/makerdao/vulcanizedb/pkg/eth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const decoderTestAbi = `[
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"name","type":"string"},{"indexed":false,"name":"","type":"bytes32"},{"indexed":false,"name":"amounts","type":"uint256[]"}],"name":"NameSet","type":"event"}
+]`
+
+var _ = Describe("DecodeLog", func() {
+	var (
+		from = common.HexToAddress("0x1")
+		to   = common.HexToAddress("0x2")
+	)
+
+	It("decodes indexed and non-indexed arguments keyed by name", func() {
+		contractAbi, parseErr := eth.ParseAbi(decoderTestAbi)
+		Expect(parseErr).NotTo(HaveOccurred())
+		log := types.Log{
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+			},
+			Data: common.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+		}
+
+		eventName, args, err := eth.DecodeLog(contractAbi, log)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventName).To(Equal("Transfer"))
+		encoded, jsonErr := json.Marshal(args)
+		Expect(jsonErr).NotTo(HaveOccurred())
+		Expect(encoded).To(MatchJSON(`{"from": "` + from.Hex() + `", "to": "` + to.Hex() + `", "value": 1000}`))
+	})
+
+	It("names unnamed arguments by position and returns hashes of indexed dynamic arguments", func() {
+		contractAbi, parseErr := eth.ParseAbi(decoderTestAbi)
+		Expect(parseErr).NotTo(HaveOccurred())
+		nameHash := crypto.Keccak256Hash([]byte("name"))
+		data, packErr := contractAbi.Events["NameSet"].Inputs.NonIndexed().Pack(common.HexToHash("0xabc"), []*big.Int{big.NewInt(1), big.NewInt(2)})
+		Expect(packErr).NotTo(HaveOccurred())
+		log := types.Log{
+			Topics: []common.Hash{contractAbi.Events["NameSet"].ID, nameHash},
+			Data:   data,
+		}
+
+		eventName, args, err := eth.DecodeLog(contractAbi, log)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventName).To(Equal("NameSet"))
+		encoded, jsonErr := json.Marshal(args)
+		Expect(jsonErr).NotTo(HaveOccurred())
+		Expect(encoded).To(MatchJSON(`{"name": "` + nameHash.Hex() + `", "arg1": "` + common.HexToHash("0xabc").Hex() + `", "amounts": [1, 2]}`))
+	})
+
+	It("returns an error if the log's topic0 isn't an event in the abi", func() {
+		contractAbi, parseErr := eth.ParseAbi(decoderTestAbi)
+		Expect(parseErr).NotTo(HaveOccurred())
+
+		_, _, err := eth.DecodeLog(contractAbi, types.Log{Topics: []common.Hash{common.HexToHash("0x123")}})
+
+		Expect(err).To(MatchError(eth.ErrUnknownEvent))
+	})
+
+	It("returns an error if the log's topics don't match the indexed arguments", func() {
+		contractAbi, parseErr := eth.ParseAbi(decoderTestAbi)
+		Expect(parseErr).NotTo(HaveOccurred())
+
+		_, _, err := eth.DecodeLog(contractAbi, types.Log{Topics: []common.Hash{contractAbi.Events["Transfer"].ID}})
+
+		Expect(errors.Is(err, eth.ErrTopicCountMismatch)).To(BeTrue())
+	})
+})
@@ -16,24 +16,70 @@
 
 package fakes
 
+import (
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+)
+
 type MockCheckedLogsRepository struct {
-	AlreadyWatchingLogAddresses []string
-	AlreadyWatchingLogError     error
-	AlreadyWatchingLogReturn    bool
-	AlreadyWatchingLogTopicZero string
-	MarkLogWatchedAddresses     []string
-	MarkLogWatchedError         error
-	MarkLogWatchedTopicZero     string
+	MarkHeaderCheckedHeaderIDs     []int64
+	MarkHeaderCheckedReturnError   error
+	MarkHeaderCheckedWatchedLogIDs [][]int64
+	UncheckedHeadersCheckCount     int64
+	UncheckedHeadersLimit          int
+	UncheckedHeadersReturnError    error
+	UncheckedHeadersReturnHeaders  []core.UncheckedHeader
+	UncheckedHeadersWatchedLogs    []core.WatchedLog
+	WatchLogAddresses              []string
+	WatchLogError                  error
+	WatchLogTopicFilters           [][][]string
+	WatchLogTopicZeros             []string
+	WatchRegistryLogRegistries     []string
+	watchedLogIDs                  map[string]int64
+}
+
+func (repository *MockCheckedLogsRepository) MarkHeaderChecked(headerID int64, watchedLogIDs []int64) error {
+	repository.MarkHeaderCheckedHeaderIDs = append(repository.MarkHeaderCheckedHeaderIDs, headerID)
+	repository.MarkHeaderCheckedWatchedLogIDs = append(repository.MarkHeaderCheckedWatchedLogIDs, watchedLogIDs)
+	return repository.MarkHeaderCheckedReturnError
+}
+
+func (repository *MockCheckedLogsRepository) MarkSingleHeaderUnchecked(blockNumber int64) error {
+	panic("implement me")
+}
+
+func (repository *MockCheckedLogsRepository) UncheckedHeaders(watchedLogs []core.WatchedLog, checkCount int64, limit int) ([]core.UncheckedHeader, error) {
+	repository.UncheckedHeadersWatchedLogs = watchedLogs
+	repository.UncheckedHeadersCheckCount = checkCount
+	repository.UncheckedHeadersLimit = limit
+	return repository.UncheckedHeadersReturnHeaders, repository.UncheckedHeadersReturnError
 }
 
-func (repository *MockCheckedLogsRepository) AlreadyWatchingLog(addresses []string, topic0 string) (bool, error) {
-	repository.AlreadyWatchingLogAddresses = addresses
-	repository.AlreadyWatchingLogTopicZero = topic0
-	return repository.AlreadyWatchingLogReturn, repository.AlreadyWatchingLogError
+// WatchLog returns sequential ids, reusing the id of an address + topics that are already watched
+func (repository *MockCheckedLogsRepository) WatchLog(address, topic0 string, topicFilters [][]string) (int64, error) {
+	repository.WatchLogAddresses = append(repository.WatchLogAddresses, address)
+	repository.WatchLogTopicZeros = append(repository.WatchLogTopicZeros, topic0)
+	repository.WatchLogTopicFilters = append(repository.WatchLogTopicFilters, topicFilters)
+	if repository.watchedLogIDs == nil {
+		repository.watchedLogIDs = make(map[string]int64)
+	}
+	key := fmt.Sprint(address, topic0, topicFilters)
+	if _, ok := repository.watchedLogIDs[key]; !ok {
+		repository.watchedLogIDs[key] = int64(len(repository.watchedLogIDs) + 1)
+	}
+	return repository.watchedLogIDs[key], repository.WatchLogError
 }
 
-func (repository *MockCheckedLogsRepository) MarkLogWatched(addresses []string, topic0 string) error {
-	repository.MarkLogWatchedAddresses = addresses
-	repository.MarkLogWatchedTopicZero = topic0
-	return repository.MarkLogWatchedError
+// WatchRegistryLog shares WatchLog's ids, keyed on the registry in place of the address
+func (repository *MockCheckedLogsRepository) WatchRegistryLog(registry, topic0 string, topicFilters [][]string) (int64, error) {
+	repository.WatchRegistryLogRegistries = append(repository.WatchRegistryLogRegistries, registry)
+	if repository.watchedLogIDs == nil {
+		repository.watchedLogIDs = make(map[string]int64)
+	}
+	key := fmt.Sprint("registry", registry, topic0, topicFilters)
+	if _, ok := repository.watchedLogIDs[key]; !ok {
+		repository.watchedLogIDs[key] = int64(len(repository.watchedLogIDs) + 1)
+	}
+	return repository.watchedLogIDs[key], repository.WatchLogError
 }
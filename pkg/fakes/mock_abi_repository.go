@@ -0,0 +1,38 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fakes
+
+type MockAbiRepository struct {
+	GetAbiError  error
+	SaveAbiError error
+	SavedAbis    map[string]string
+}
+
+func (mock *MockAbiRepository) GetAbi(address string) (string, error) {
+	return mock.SavedAbis[address], mock.GetAbiError
+}
+
+func (mock *MockAbiRepository) SaveAbi(address, contractAbi string) error {
+	if mock.SaveAbiError != nil {
+		return mock.SaveAbiError
+	}
+	if mock.SavedAbis == nil {
+		mock.SavedAbis = make(map[string]string)
+	}
+	mock.SavedAbis[address] = contractAbi
+	return nil
+}
@@ -0,0 +1,74 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fakes
+
+import "github.com/makerdao/vulcanizedb/pkg/core"
+
+type MockAddressRegistryRepository struct {
+	BackfilledIDs                    []int64
+	CountRegisteredAddressesError    error
+	GetRegisteredAddressesAfterIDs   []int64
+	GetRegisteredAddressesError      error
+	GetRegisteredAddressesRegistries []string
+	MarkBackfilledError              error
+	RegisterAddressError             error
+	RegisteredAddresses              []core.RegisteredAddress
+}
+
+func (mock *MockAddressRegistryRepository) CountRegisteredAddresses(registry string) (int64, error) {
+	if mock.CountRegisteredAddressesError != nil {
+		return 0, mock.CountRegisteredAddressesError
+	}
+	var count int64
+	for _, registeredAddress := range mock.RegisteredAddresses {
+		if registeredAddress.Registry == registry {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Returns the addresses registered to the registry with an id greater than afterID; addresses are expected to be
+// registered with ascending ids
+func (mock *MockAddressRegistryRepository) GetRegisteredAddresses(registry string, afterID int64) ([]core.RegisteredAddress, error) {
+	mock.GetRegisteredAddressesRegistries = append(mock.GetRegisteredAddressesRegistries, registry)
+	mock.GetRegisteredAddressesAfterIDs = append(mock.GetRegisteredAddressesAfterIDs, afterID)
+	if mock.GetRegisteredAddressesError != nil {
+		return nil, mock.GetRegisteredAddressesError
+	}
+	var registeredAddresses []core.RegisteredAddress
+	for _, registeredAddress := range mock.RegisteredAddresses {
+		if registeredAddress.Registry == registry && registeredAddress.ID > afterID {
+			registeredAddresses = append(registeredAddresses, registeredAddress)
+		}
+	}
+	return registeredAddresses, nil
+}
+
+func (mock *MockAddressRegistryRepository) MarkRegisteredAddressesBackfilled(ids []int64) error {
+	mock.BackfilledIDs = append(mock.BackfilledIDs, ids...)
+	return mock.MarkBackfilledError
+}
+
+func (mock *MockAddressRegistryRepository) RegisterAddress(registeredAddress core.RegisteredAddress) error {
+	if mock.RegisterAddressError != nil {
+		return mock.RegisterAddressError
+	}
+	registeredAddress.ID = int64(len(mock.RegisteredAddresses) + 1)
+	mock.RegisteredAddresses = append(mock.RegisteredAddresses, registeredAddress)
+	return nil
+}
@@ -17,6 +17,7 @@
 package fakes
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
@@ -29,9 +30,15 @@ import (
 type MockBlockChain struct {
 	BatchGetStorageAtCalls             []BatchGetStorageAtCall
 	BatchGetStorageAtError             error
+	BlocksByNumber                     map[int64]core.Block
+	GetBlockWithTransactionsError      error
+	GetBlockWithTransactionsPassed     []int64
 	GetTransactionsCalled              bool
 	GetTransactionsError               error
 	GetTransactionsPassedHashes        []common.Hash
+	GetReceiptsError                   error
+	GetReceiptsPassedHashes            []common.Hash
+	Receipts                           []core.Receipt
 	Transactions                       []core.TransactionModel
 	fetchContractDataErr               error
 	fetchContractDataPassedAbi         string
@@ -42,6 +49,11 @@ type MockBlockChain struct {
 	fetchContractDataPassedResult      interface{}
 	chainHead                          *big.Int
 	chainHeadErr                       error
+	getHeadersByNumbersErr             error
+	HeadersByNumber                    map[int64]core.Header
+	NewHeadsToSend                     []*types.Header
+	NewHeadsSubscription               *MockSubscription
+	NewHeadsSubscribeErr               error
 	logQuery                           ethereum.FilterQuery
 	logQueryErr                        error
 	logQueryReturnLogs                 []types.Log
@@ -86,35 +98,73 @@ func (blockChain *MockBlockChain) FetchContractData(abiJSON string, address stri
 	return blockChain.fetchContractDataErr
 }
 
-func (blockChain *MockBlockChain) GetEthLogsWithCustomQuery(query ethereum.FilterQuery) ([]types.Log, error) {
+func (blockChain *MockBlockChain) GetBlockWithTransactions(ctx context.Context, blockNumber int64) (core.Block, error) {
+	blockChain.GetBlockWithTransactionsPassed = append(blockChain.GetBlockWithTransactionsPassed, blockNumber)
+	if blockChain.GetBlockWithTransactionsError != nil {
+		return core.Block{}, blockChain.GetBlockWithTransactionsError
+	}
+	return blockChain.BlocksByNumber[blockNumber], nil
+}
+
+func (blockChain *MockBlockChain) GetEthLogsWithCustomQuery(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
 	blockChain.logQuery = query
 	return blockChain.logQueryReturnLogs, blockChain.logQueryErr
 }
 
-func (blockChain *MockBlockChain) GetHeaderByNumber(blockNumber int64) (core.Header, error) {
+func (blockChain *MockBlockChain) GetHeaderByNumber(ctx context.Context, blockNumber int64) (core.Header, error) {
+	if header, ok := blockChain.HeadersByNumber[blockNumber]; ok {
+		return header, nil
+	}
 	return core.Header{BlockNumber: blockNumber}, nil
 }
 
-func (blockChain *MockBlockChain) GetHeadersByNumbers(blockNumbers []int64) ([]core.Header, error) {
+func (blockChain *MockBlockChain) SetGetHeadersByNumbersErr(err error) {
+	blockChain.getHeadersByNumbersErr = err
+}
+
+func (blockChain *MockBlockChain) GetHeadersByNumbers(ctx context.Context, blockNumbers []int64) ([]core.Header, error) {
+	if blockChain.getHeadersByNumbersErr != nil {
+		return nil, blockChain.getHeadersByNumbersErr
+	}
 	var headers []core.Header
 	for _, blockNumber := range blockNumbers {
-		var header = core.Header{BlockNumber: blockNumber}
+		header, _ := blockChain.GetHeaderByNumber(ctx, blockNumber)
 		headers = append(headers, header)
 	}
 	return headers, nil
 }
 
-func (blockChain *MockBlockChain) GetTransactions(transactionHashes []common.Hash) ([]core.TransactionModel, error) {
+func (blockChain *MockBlockChain) GetTransactions(ctx context.Context, transactionHashes []common.Hash) ([]core.TransactionModel, error) {
 	blockChain.GetTransactionsCalled = true
 	blockChain.GetTransactionsPassedHashes = transactionHashes
 	return blockChain.Transactions, blockChain.GetTransactionsError
 }
 
+func (blockChain *MockBlockChain) GetTransactionReceipts(ctx context.Context, transactionHashes []common.Hash) ([]core.Receipt, error) {
+	blockChain.GetReceiptsPassedHashes = transactionHashes
+	return blockChain.Receipts, blockChain.GetReceiptsError
+}
+
 func (blockChain *MockBlockChain) CallContract(contractHash string, input []byte, blockNumber *big.Int) ([]byte, error) {
 	return []byte{}, nil
 }
 
-func (blockChain *MockBlockChain) ChainHead() (*big.Int, error) {
+// Sends NewHeadsToSend on the passed channel, followed by an error on the subscription's error channel
+func (blockChain *MockBlockChain) SubscribeNewHeads(ctx context.Context, payloadChan chan *types.Header) (core.Subscription, error) {
+	if blockChain.NewHeadsSubscribeErr != nil {
+		return nil, blockChain.NewHeadsSubscribeErr
+	}
+	blockChain.NewHeadsSubscription = &MockSubscription{Errs: make(chan error, 1)}
+	go func(subscription *MockSubscription, headers []*types.Header) {
+		for _, header := range headers {
+			payloadChan <- header
+		}
+		subscription.Errs <- FakeError
+	}(blockChain.NewHeadsSubscription, blockChain.NewHeadsToSend)
+	return blockChain.NewHeadsSubscription, nil
+}
+
+func (blockChain *MockBlockChain) ChainHead(ctx context.Context) (*big.Int, error) {
 	return blockChain.chainHead, blockChain.chainHeadErr
 }
 
@@ -124,7 +174,7 @@ type BatchGetStorageAtCall struct {
 	BlockNumber *big.Int
 }
 
-func (blockChain *MockBlockChain) BatchGetStorageAt(account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error) {
+func (blockChain *MockBlockChain) BatchGetStorageAt(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (map[common.Hash][]byte, error) {
 	var storageToReturn = make(map[common.Hash][]byte)
 	blockChain.BatchGetStorageAtCalls = append(blockChain.BatchGetStorageAtCalls, BatchGetStorageAtCall{
 		Account:     account,
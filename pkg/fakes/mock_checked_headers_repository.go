@@ -22,6 +22,7 @@ import (
 
 type MockCheckedHeadersRepository struct {
 	MarkHeaderCheckedHeaderID           int64
+	MarkHeaderCheckedHeaderIDs          []int64
 	MarkHeaderCheckedReturnError        error
 	UncheckedHeadersCheckCount          int64
 	UncheckedHeadersEndingBlockNumber   int64
@@ -36,6 +37,7 @@ func (repository *MockCheckedHeadersRepository) MarkSingleHeaderUnchecked(blockN
 
 func (repository *MockCheckedHeadersRepository) MarkHeaderChecked(headerID int64) error {
 	repository.MarkHeaderCheckedHeaderID = headerID
+	repository.MarkHeaderCheckedHeaderIDs = append(repository.MarkHeaderCheckedHeaderIDs, headerID)
 	return repository.MarkHeaderCheckedReturnError
 }
 
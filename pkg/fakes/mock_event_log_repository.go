@@ -17,42 +17,127 @@
 package fakes
 
 import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 )
 
 type MockEventLogRepository struct {
-	CreateError    error
-	GetCalled      bool
-	GetError       error
-	PassedMinIDs   []int
-	PassedLimits   []int
-	PassedHeaderID int64
-	PassedLogs     []types.Log
-	ReturnLogs     []core.EventLog
+	CreateError                    error
+	GetCalled                      bool
+	GetError                       error
+	GetQuarantinedError            error
+	MarkTransformedError           error
+	PassedEndingBlock              int64
+	PassedMinIDs                   []int
+	PassedLimits                   []int
+	PassedHeaderID                 int64
+	PassedHeaderIDs                []int64
+	PassedLogs                     []types.Log
+	PassedQuarantinedTransformer   string
+	PassedResetTransformer         string
+	PassedRetryTransformer         string
+	PassedRetryLogIDs              []int64
+	PassedStartingBlock            int64
+	PassedTopic0s                  []common.Hash
+	PassedTransformerNames         []string
+	QuarantinedLogIDs              []int64
+	RecordTransformErrorError      error
+	RecordedTransformErrorBackoff  time.Duration
+	RecordedTransformErrors        map[int64][]error
+	RecordedTransformErrorMaxCount int64
+	RecordedTransformErrorNames    []string
+	ResetError                     error
+	RetryError                     error
+	ReturnLogs                     []core.EventLog
+	ReturnQuarantinedLogs          []core.QuarantinedEventLog
+	TransformedLogIDs              map[string][]int64
+	returnedLogCounts              map[string]int
 }
 
-func (repository *MockEventLogRepository) GetUntransformedEventLogs(minID, limit int) ([]core.EventLog, error) {
+// Returns ReturnLogs to each transformer in turn, paging through them with limit
+func (repository *MockEventLogRepository) GetUntransformedEventLogs(transformer string, topic0 common.Hash, minID, limit int) ([]core.EventLog, error) {
 	repository.GetCalled = true
+	repository.PassedTransformerNames = append(repository.PassedTransformerNames, transformer)
+	repository.PassedTopic0s = append(repository.PassedTopic0s, topic0)
 	repository.PassedMinIDs = append(repository.PassedMinIDs, minID)
 	repository.PassedLimits = append(repository.PassedLimits, limit)
+	return repository.pageReturnLogs(transformer, limit), repository.GetError
+}
 
-	var returnLogs []core.EventLog
-	if limit >= len(repository.ReturnLogs) {
-		returnLogs = repository.ReturnLogs
-		repository.ReturnLogs = []core.EventLog{}
-	} else {
-		for i := 0; i < limit; i++ {
-			returnLogs = append(returnLogs, repository.ReturnLogs[i])
-		}
-		repository.ReturnLogs = repository.ReturnLogs[limit:]
+// Returns ReturnLogs for each topic0, paging through them with limit
+func (repository *MockEventLogRepository) GetEventLogsInRange(transformer string, topic0 common.Hash, startingBlock, endingBlock int64, minID, limit int) ([]core.EventLog, error) {
+	repository.GetCalled = true
+	repository.PassedTransformerNames = append(repository.PassedTransformerNames, transformer)
+	repository.PassedTopic0s = append(repository.PassedTopic0s, topic0)
+	repository.PassedStartingBlock = startingBlock
+	repository.PassedEndingBlock = endingBlock
+	repository.PassedMinIDs = append(repository.PassedMinIDs, minID)
+	repository.PassedLimits = append(repository.PassedLimits, limit)
+	return repository.pageReturnLogs(topic0.Hex(), limit), repository.GetError
+}
+
+func (repository *MockEventLogRepository) pageReturnLogs(key string, limit int) []core.EventLog {
+	if repository.returnedLogCounts == nil {
+		repository.returnedLogCounts = make(map[string]int)
 	}
 
-	return returnLogs, repository.GetError
+	offset := repository.returnedLogCounts[key]
+	end := offset + limit
+	if end > len(repository.ReturnLogs) {
+		end = len(repository.ReturnLogs)
+	}
+	repository.returnedLogCounts[key] = end
+	return repository.ReturnLogs[offset:end]
 }
 
 func (repository *MockEventLogRepository) CreateEventLogs(headerID int64, logs []types.Log) error {
 	repository.PassedHeaderID = headerID
+	repository.PassedHeaderIDs = append(repository.PassedHeaderIDs, headerID)
 	repository.PassedLogs = logs
 	return repository.CreateError
 }
+
+func (repository *MockEventLogRepository) GetQuarantinedEventLogs(transformer string) ([]core.QuarantinedEventLog, error) {
+	repository.PassedQuarantinedTransformer = transformer
+	return repository.ReturnQuarantinedLogs, repository.GetQuarantinedError
+}
+
+func (repository *MockEventLogRepository) MarkEventLogsTransformed(transformer string, logIDs []int64) error {
+	if repository.TransformedLogIDs == nil {
+		repository.TransformedLogIDs = make(map[string][]int64)
+	}
+	repository.TransformedLogIDs[transformer] = append(repository.TransformedLogIDs[transformer], logIDs...)
+	return repository.MarkTransformedError
+}
+
+func (repository *MockEventLogRepository) RecordTransformError(logID int64, transformer string, transformErr error, maxErrors int64, backoff time.Duration) (bool, error) {
+	if repository.RecordTransformErrorError != nil {
+		return false, repository.RecordTransformErrorError
+	}
+	if repository.RecordedTransformErrors == nil {
+		repository.RecordedTransformErrors = make(map[int64][]error)
+	}
+	repository.RecordedTransformErrors[logID] = append(repository.RecordedTransformErrors[logID], transformErr)
+	repository.RecordedTransformErrorNames = append(repository.RecordedTransformErrorNames, transformer)
+	repository.RecordedTransformErrorMaxCount = maxErrors
+	repository.RecordedTransformErrorBackoff = backoff
+	quarantined := int64(len(repository.RecordedTransformErrors[logID])) >= maxErrors
+	if quarantined {
+		repository.QuarantinedLogIDs = append(repository.QuarantinedLogIDs, logID)
+	}
+	return quarantined, nil
+}
+
+func (repository *MockEventLogRepository) RetryQuarantinedEventLogs(transformer string, logIDs []int64) (int64, error) {
+	repository.PassedRetryTransformer = transformer
+	repository.PassedRetryLogIDs = logIDs
+	return int64(len(repository.ReturnQuarantinedLogs)), repository.RetryError
+}
+
+func (repository *MockEventLogRepository) ResetTransformedEventLogs(transformer string) error {
+	repository.PassedResetTransformer = transformer
+	return repository.ResetError
+}
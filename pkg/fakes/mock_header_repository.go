@@ -17,6 +17,9 @@
 package fakes
 
 import (
+	"database/sql"
+	"sync"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	. "github.com/onsi/gomega"
@@ -26,6 +29,10 @@ type MockHeaderRepository struct {
 	AllHeaders                             []core.Header
 	CreateTransactionsCalled               bool
 	CreateTransactionsError                error
+	CreateTransactionsPassedHeaderIDs      []int64
+	CreateTransactionsPassedTransactions   [][]core.TransactionModel
+	CreateWithReceiptsError                error
+	CreateWithReceiptsPassedTransactions   []core.TransactionModel
 	GetHeaderByBlockNumberError            error
 	GetHeaderByBlockNumberReturnHash       string
 	GetHeaderByBlockNumberReturnID         int64
@@ -35,16 +42,27 @@ type MockHeaderRepository struct {
 	GetHeadersInRangeEndingBlocks          []int64
 	GetHeadersInRangeError                 error
 	GetHeadersInRangeStartingBlocks        []int64
+	GetUnsyncedTransactionsError           error
+	GetUnsyncedTransactionsPassedLimit     int
+	GetUnsyncedTransactionsPassedStart     int64
+	MarkTransactionsSyncedError            error
+	MarkTransactionsSyncedHeaderIDs        []int64
+	UnsyncedTransactionsHeaders            []core.Header
 	MissingBlockNumbersPassedEndingBlock   int64
 	MissingBlockNumbersPassedStartingBlock int64
 	MostRecentHeaderBlockNumber            int64
 	MostRecentHeaderBlockNumberErr         error
+	ReplaceOrphanedHeadersError            error
+	ReplacedHeaderBlockNumbers             []int64
+	ReplacedReorgs                         []core.Reorg
+	StoredHeaders                          map[int64]core.Header
 	createOrUpdateHeaderCallCount          int
 	createOrUpdateHeaderErr                error
 	createOrUpdateHeaderPassedBlockNumbers []int64
 	createOrUpdateHeaderReturnID           int64
 	headerExists                           bool
 	missingBlockNumbers                    []int64
+	mutex                                  sync.Mutex
 }
 
 func NewMockHeaderRepository() *MockHeaderRepository {
@@ -64,22 +82,41 @@ func (mock *MockHeaderRepository) SetMissingBlockNumbers(blockNumbers []int64) {
 }
 
 func (mock *MockHeaderRepository) CreateOrUpdateHeader(header core.Header) (int64, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
 	mock.createOrUpdateHeaderCallCount++
 	mock.createOrUpdateHeaderPassedBlockNumbers = append(mock.createOrUpdateHeaderPassedBlockNumbers, header.BlockNumber)
+	if mock.StoredHeaders != nil && mock.createOrUpdateHeaderErr == nil {
+		mock.StoredHeaders[header.BlockNumber] = header
+	}
 	return mock.createOrUpdateHeaderReturnID, mock.createOrUpdateHeaderErr
 }
 
 func (mock *MockHeaderRepository) CreateTransactions(headerID int64, transactions []core.TransactionModel) error {
 	mock.CreateTransactionsCalled = true
+	mock.CreateTransactionsPassedHeaderIDs = append(mock.CreateTransactionsPassedHeaderIDs, headerID)
+	mock.CreateTransactionsPassedTransactions = append(mock.CreateTransactionsPassedTransactions, transactions)
 	return mock.CreateTransactionsError
 }
 
+func (mock *MockHeaderRepository) CreateTransactionsWithReceipts(headerID int64, transactions []core.TransactionModel) error {
+	mock.CreateWithReceiptsPassedTransactions = append(mock.CreateWithReceiptsPassedTransactions, transactions...)
+	return mock.CreateWithReceiptsError
+}
+
 func (mock *MockHeaderRepository) CreateTransactionInTx(tx *sqlx.Tx, headerID int64, transaction core.TransactionModel) (int64, error) {
 	panic("implement me")
 }
 
 func (mock *MockHeaderRepository) GetHeaderByBlockNumber(blockNumber int64) (core.Header, error) {
 	mock.GetHeaderPassedBlockNumber = blockNumber
+	if mock.StoredHeaders != nil {
+		header, ok := mock.StoredHeaders[blockNumber]
+		if !ok {
+			return core.Header{}, sql.ErrNoRows
+		}
+		return header, nil
+	}
 	return core.Header{
 		Id:          mock.GetHeaderByBlockNumberReturnID,
 		BlockNumber: blockNumber,
@@ -97,6 +134,17 @@ func (mock *MockHeaderRepository) GetHeadersInRange(startingBlock, endingBlock i
 	return mock.AllHeaders, mock.GetHeadersInRangeError
 }
 
+func (mock *MockHeaderRepository) GetHeadersWithUnsyncedTransactions(startingBlockNumber int64, limit int) ([]core.Header, error) {
+	mock.GetUnsyncedTransactionsPassedStart = startingBlockNumber
+	mock.GetUnsyncedTransactionsPassedLimit = limit
+	return mock.UnsyncedTransactionsHeaders, mock.GetUnsyncedTransactionsError
+}
+
+func (mock *MockHeaderRepository) MarkTransactionsSynced(headerID int64) error {
+	mock.MarkTransactionsSyncedHeaderIDs = append(mock.MarkTransactionsSyncedHeaderIDs, headerID)
+	return mock.MarkTransactionsSyncedError
+}
+
 func (mock *MockHeaderRepository) MissingBlockNumbers(startingBlockNumber, endingBlockNumber int64) ([]int64, error) {
 	mock.MissingBlockNumbersPassedStartingBlock = startingBlockNumber
 	mock.MissingBlockNumbersPassedEndingBlock = endingBlockNumber
@@ -107,6 +155,24 @@ func (mock *MockHeaderRepository) GetMostRecentHeaderBlockNumber() (int64, error
 	return mock.MostRecentHeaderBlockNumber, mock.MostRecentHeaderBlockNumberErr
 }
 
+func (mock *MockHeaderRepository) ReplaceOrphanedHeaders(headers []core.Header, reorg core.Reorg) error {
+	if mock.ReplaceOrphanedHeadersError != nil {
+		return mock.ReplaceOrphanedHeadersError
+	}
+	for _, header := range headers {
+		mock.ReplacedHeaderBlockNumbers = append(mock.ReplacedHeaderBlockNumbers, header.BlockNumber)
+		if mock.StoredHeaders != nil {
+			mock.StoredHeaders[header.BlockNumber] = header
+		}
+	}
+	mock.ReplacedReorgs = append(mock.ReplacedReorgs, reorg)
+	return nil
+}
+
+func (mock *MockHeaderRepository) CreateOrUpdateHeaderPassedBlockNumbers() []int64 {
+	return mock.createOrUpdateHeaderPassedBlockNumbers
+}
+
 func (mock *MockHeaderRepository) AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(times int, blockNumbers []int64) {
 	Expect(mock.createOrUpdateHeaderCallCount).To(Equal(times))
 	Expect(mock.createOrUpdateHeaderPassedBlockNumbers).To(Equal(blockNumbers))
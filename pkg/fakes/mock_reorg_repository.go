@@ -0,0 +1,83 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fakes
+
+import "github.com/makerdao/vulcanizedb/pkg/core"
+
+type MockReorgRepository struct {
+	CreateReorgError            error
+	CreateReorgPassed           []core.Reorg
+	GetLastNotifiedReorgIDError error
+	GetMostRecentReorgIDCalls   int
+	GetMostRecentReorgIDError   error
+	GetReorgsAfterIDError       error
+	GetReorgsAfterIDPassedIDs   []int64
+	LastNotifiedReorgIDs        map[string]int64
+	MarkReorgNotifiedError      error
+	MostRecentReorgID           int64
+	ReorgsToReturn              []core.Reorg
+}
+
+func (mock *MockReorgRepository) CreateReorg(reorg core.Reorg) (int64, error) {
+	mock.CreateReorgPassed = append(mock.CreateReorgPassed, reorg)
+	return int64(len(mock.CreateReorgPassed)), mock.CreateReorgError
+}
+
+func (mock *MockReorgRepository) GetMostRecentReorgID() (int64, error) {
+	mock.GetMostRecentReorgIDCalls++
+	return mock.MostRecentReorgID, mock.GetMostRecentReorgIDError
+}
+
+func (mock *MockReorgRepository) GetReorgsAfterID(id int64) ([]core.Reorg, error) {
+	mock.GetReorgsAfterIDPassedIDs = append(mock.GetReorgsAfterIDPassedIDs, id)
+	if mock.GetReorgsAfterIDError != nil {
+		return nil, mock.GetReorgsAfterIDError
+	}
+	var reorgs []core.Reorg
+	for _, reorg := range mock.ReorgsToReturn {
+		if reorg.ID > id {
+			reorgs = append(reorgs, reorg)
+		}
+	}
+	return reorgs, nil
+}
+
+func (mock *MockReorgRepository) GetLastNotifiedReorgID(transformer string) (int64, error) {
+	if mock.GetLastNotifiedReorgIDError != nil {
+		return 0, mock.GetLastNotifiedReorgIDError
+	}
+	if mock.LastNotifiedReorgIDs == nil {
+		mock.LastNotifiedReorgIDs = map[string]int64{}
+	}
+	reorgID, ok := mock.LastNotifiedReorgIDs[transformer]
+	if !ok {
+		reorgID = mock.MostRecentReorgID
+		mock.LastNotifiedReorgIDs[transformer] = reorgID
+	}
+	return reorgID, nil
+}
+
+func (mock *MockReorgRepository) MarkReorgNotified(transformer string, reorgID int64) error {
+	if mock.MarkReorgNotifiedError != nil {
+		return mock.MarkReorgNotifiedError
+	}
+	if mock.LastNotifiedReorgIDs == nil {
+		mock.LastNotifiedReorgIDs = map[string]int64{}
+	}
+	mock.LastNotifiedReorgIDs[transformer] = reorgID
+	return nil
+}
@@ -18,12 +18,10 @@ package fakes
 
 import (
 	"context"
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/makerdao/vulcanizedb/pkg/core"
@@ -45,12 +43,15 @@ type MockRpcClient struct {
 	passedResult         interface{}
 	passedBatch          []core.BatchElem
 	passedNamespace      string
-	passedPayloadChan    chan filters.Payload
+	passedPayloadChan    interface{}
 	passedSubscribeArgs  []interface{}
 	lengthOfBatch        int
 	returnPOAHeader      core.POAHeader
 	returnPOAHeaders     []core.POAHeader
+	returnPOWHeader      core.POWHeader
 	returnPOWHeaders     []*types.Header
+	returnRpcBlock       core.RpcBlock
+	ReceiptToReturn      *types.Receipt
 	StorageValueToReturn []byte
 }
 
@@ -58,14 +59,10 @@ func NewMockRpcClient() *MockRpcClient {
 	return &MockRpcClient{}
 }
 
-func (c *MockRpcClient) Subscribe(namespace string, payloadChan interface{}, args ...interface{}) (core.Subscription, error) {
+func (c *MockRpcClient) Subscribe(ctx context.Context, namespace string, payloadChan interface{}, args ...interface{}) (core.Subscription, error) {
 	c.passedNamespace = namespace
 
-	passedPayloadChan, ok := payloadChan.(chan filters.Payload)
-	if !ok {
-		return nil, errors.New("passed in channel is not of the correct type")
-	}
-	c.passedPayloadChan = passedPayloadChan
+	c.passedPayloadChan = payloadChan
 
 	for _, arg := range args {
 		c.passedSubscribeArgs = append(c.passedSubscribeArgs, arg)
@@ -75,7 +72,7 @@ func (c *MockRpcClient) Subscribe(namespace string, payloadChan interface{}, arg
 	return client.Subscription{RpcSubscription: &subscription}, nil
 }
 
-func (c *MockRpcClient) AssertSubscribeCalledWith(namespace string, payloadChan chan filters.Payload, args []interface{}) {
+func (c *MockRpcClient) AssertSubscribeCalledWith(namespace string, payloadChan interface{}, args []interface{}) {
 	Expect(c.passedNamespace).To(Equal(namespace))
 	Expect(c.passedPayloadChan).To(Equal(payloadChan))
 	Expect(c.passedSubscribeArgs).To(Equal(args))
@@ -85,17 +82,17 @@ func (c *MockRpcClient) SetIpcPath(ipcPath string) {
 	c.ipcPath = ipcPath
 }
 
-func (c *MockRpcClient) BatchCall(batch []core.BatchElem) error {
+func (c *MockRpcClient) BatchCall(ctx context.Context, batch []core.BatchElem) error {
 	c.passedBatch = batch
 	c.passedMethod = batch[0].Method
 	c.lengthOfBatch = len(batch)
 
 	for _, batchElem := range batch {
-		c.passedContext = context.Background()
+		c.passedContext = ctx
 		c.passedResult = &batchElem.Result
 		c.passedMethod = batchElem.Method
-		if p, ok := batchElem.Result.(*types.Header); ok {
-			*p = types.Header{Number: big.NewInt(100)}
+		if p, ok := batchElem.Result.(*core.POWHeader); ok {
+			*p = core.POWHeader{Header: types.Header{Number: big.NewInt(100)}}
 		}
 		if p, ok := batchElem.Result.(*core.POAHeader); ok {
 			*p = c.returnPOAHeader
@@ -103,6 +100,9 @@ func (c *MockRpcClient) BatchCall(batch []core.BatchElem) error {
 		if p, ok := batchElem.Result.(*hexutil.Bytes); ok {
 			*p = c.StorageValueToReturn
 		}
+		if p, ok := batchElem.Result.(**types.Receipt); ok {
+			*p = c.ReceiptToReturn
+		}
 	}
 
 	return nil
@@ -114,13 +114,16 @@ func (c *MockRpcClient) CallContext(ctx context.Context, result interface{}, met
 	c.passedMethod = method
 	switch method {
 	case "eth_getBlockByNumber":
-		if p, ok := result.(*types.Header); ok {
-			*p = types.Header{Number: big.NewInt(100)}
+		if p, ok := result.(*core.POWHeader); ok {
+			*p = c.returnPOWHeader
 		}
 		if p, ok := result.(*core.POAHeader); ok {
 
 			*p = c.returnPOAHeader
 		}
+		if p, ok := result.(*core.RpcBlock); ok {
+			*p = c.returnRpcBlock
+		}
 		if c.callContextErr != nil {
 			return c.callContextErr
 		}
@@ -156,6 +159,14 @@ func (c *MockRpcClient) SetReturnPOAHeader(header core.POAHeader) {
 	c.returnPOAHeader = header
 }
 
+func (c *MockRpcClient) SetReturnRpcBlock(block core.RpcBlock) {
+	c.returnRpcBlock = block
+}
+
+func (c *MockRpcClient) SetReturnPOWHeader(header core.POWHeader) {
+	c.returnPOWHeader = header
+}
+
 func (c *MockRpcClient) SetReturnPOWHeaders(headers []*types.Header) {
 	c.returnPOWHeaders = headers
 }
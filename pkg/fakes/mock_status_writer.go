@@ -2,9 +2,11 @@ package fakes
 
 type MockStatusWriter struct {
 	WriteCalled bool
+	WriteCount  int
 }
 
 func (w *MockStatusWriter) Write() error {
 	w.WriteCalled = true
+	w.WriteCount++
 	return nil
 }
@@ -0,0 +1,143 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/fs"
+)
+
+// Check reports whether a dependency is ready, returning an error describing why it is not
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Healthy     bool       `json:"healthy"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+type Report struct {
+	Healthy bool              `json:"healthy"`
+	Checks  map[string]Result `json:"checks"`
+}
+
+// Checker tracks the last successful loop iteration of each long-running component for liveness, and runs
+// dependency checks for readiness. A component is unhealthy if it has not reported success within MaxStaleness;
+// a MaxStaleness of zero only requires that each component has reported success once.
+type Checker struct {
+	MaxStaleness    time.Duration
+	mutex           sync.RWMutex
+	lastSuccesses   map[string]time.Time
+	readinessChecks map[string]Check
+}
+
+func NewChecker(maxStaleness time.Duration) *Checker {
+	return &Checker{
+		MaxStaleness:    maxStaleness,
+		lastSuccesses:   make(map[string]time.Time),
+		readinessChecks: make(map[string]Check),
+	}
+}
+
+// Registers a component with the checker, returning a writer the component calls after each successful loop
+// iteration. Registered components are unhealthy until their first write.
+func (checker *Checker) NewStatusWriter(component string) fs.StatusWriter {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	if _, ok := checker.lastSuccesses[component]; !ok {
+		checker.lastSuccesses[component] = time.Time{}
+	}
+	return statusWriter{component: component, checker: checker}
+}
+
+func (checker *Checker) AddReadinessCheck(name string, check Check) {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	checker.readinessChecks[name] = check
+}
+
+func (checker *Checker) Liveness() Report {
+	checker.mutex.RLock()
+	defer checker.mutex.RUnlock()
+	report := Report{Healthy: true, Checks: make(map[string]Result)}
+	now := time.Now()
+	for component, lastSuccess := range checker.lastSuccesses {
+		result := checker.livenessResult(lastSuccess, now)
+		report.Healthy = report.Healthy && result.Healthy
+		report.Checks[component] = result
+	}
+	return report
+}
+
+func (checker *Checker) livenessResult(lastSuccess, now time.Time) Result {
+	if lastSuccess.IsZero() {
+		return Result{Healthy: false, Error: "no successful iteration yet"}
+	}
+	success := lastSuccess
+	result := Result{Healthy: true, LastSuccess: &success}
+	if checker.MaxStaleness > 0 && now.Sub(lastSuccess) > checker.MaxStaleness {
+		result.Healthy = false
+		result.Error = fmt.Sprintf("last successful iteration older than %s", checker.MaxStaleness)
+	}
+	return result
+}
+
+// Runs each readiness check in name order
+func (checker *Checker) Readiness(ctx context.Context) Report {
+	checker.mutex.RLock()
+	checks := make(map[string]Check, len(checker.readinessChecks))
+	names := make([]string, 0, len(checker.readinessChecks))
+	for name, check := range checker.readinessChecks {
+		checks[name] = check
+		names = append(names, name)
+	}
+	checker.mutex.RUnlock()
+	sort.Strings(names)
+
+	report := Report{Healthy: true, Checks: make(map[string]Result)}
+	for _, name := range names {
+		err := checks[name](ctx)
+		if err != nil {
+			report.Healthy = false
+			report.Checks[name] = Result{Healthy: false, Error: err.Error()}
+		} else {
+			report.Checks[name] = Result{Healthy: true}
+		}
+	}
+	return report
+}
+
+func (checker *Checker) recordSuccess(component string) {
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	checker.lastSuccesses[component] = time.Now()
+}
+
+type statusWriter struct {
+	component string
+	checker   *Checker
+}
+
+func (w statusWriter) Write() error {
+	w.checker.recordSuccess(w.component)
+	return nil
+}
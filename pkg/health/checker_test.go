@@ -0,0 +1,180 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/health"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var checker *health.Checker
+
+	BeforeEach(func() {
+		checker = health.NewChecker(time.Hour)
+	})
+
+	Describe("Liveness", func() {
+		It("is healthy when every component has recently reported success", func() {
+			writerOne := checker.NewStatusWriter("one")
+			writerTwo := checker.NewStatusWriter("two")
+			Expect(writerOne.Write()).To(Succeed())
+			Expect(writerTwo.Write()).To(Succeed())
+
+			report := checker.Liveness()
+
+			Expect(report.Healthy).To(BeTrue())
+			Expect(report.Checks).To(HaveLen(2))
+			Expect(report.Checks["one"].LastSuccess).NotTo(BeNil())
+		})
+
+		It("is unhealthy when a component has never reported success", func() {
+			checker.NewStatusWriter("one")
+
+			report := checker.Liveness()
+
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.Checks["one"].Healthy).To(BeFalse())
+		})
+
+		It("is unhealthy when a component's last success is older than the max staleness", func() {
+			checker.MaxStaleness = time.Nanosecond
+			writer := checker.NewStatusWriter("one")
+			Expect(writer.Write()).To(Succeed())
+			time.Sleep(time.Millisecond)
+
+			report := checker.Liveness()
+
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.Checks["one"].Error).To(ContainSubstring("older than"))
+		})
+
+		It("does not check staleness when max staleness is zero", func() {
+			checker.MaxStaleness = 0
+			writer := checker.NewStatusWriter("one")
+			Expect(writer.Write()).To(Succeed())
+			time.Sleep(time.Millisecond)
+
+			Expect(checker.Liveness().Healthy).To(BeTrue())
+		})
+	})
+
+	Describe("Readiness", func() {
+		It("is healthy when every check passes", func() {
+			checker.AddReadinessCheck("passing", func(context.Context) error { return nil })
+
+			Expect(checker.Readiness(context.Background()).Healthy).To(BeTrue())
+		})
+
+		It("reports failing checks", func() {
+			checker.AddReadinessCheck("passing", func(context.Context) error { return nil })
+			checker.AddReadinessCheck("failing", func(context.Context) error { return fakes.FakeError })
+
+			report := checker.Readiness(context.Background())
+
+			Expect(report.Healthy).To(BeFalse())
+			Expect(report.Checks["passing"].Healthy).To(BeTrue())
+			Expect(report.Checks["failing"].Error).To(Equal(fakes.FakeError.Error()))
+		})
+	})
+
+	Describe("HeaderSyncLagCheck", func() {
+		var (
+			blockChain       *fakes.MockBlockChain
+			headerRepository *fakes.MockHeaderRepository
+		)
+
+		BeforeEach(func() {
+			blockChain = fakes.NewMockBlockChain()
+			blockChain.SetChainHead(big.NewInt(100))
+			headerRepository = fakes.NewMockHeaderRepository()
+		})
+
+		It("passes when header sync is within the max lag", func() {
+			headerRepository.MostRecentHeaderBlockNumber = 90
+
+			Expect(health.HeaderSyncLagCheck(blockChain, headerRepository, 10)(context.Background())).To(Succeed())
+		})
+
+		It("fails when header sync trails by more than the max lag", func() {
+			headerRepository.MostRecentHeaderBlockNumber = 89
+
+			err := health.HeaderSyncLagCheck(blockChain, headerRepository, 10)(context.Background())
+
+			Expect(err).To(MatchError(ContainSubstring("11 blocks behind")))
+		})
+	})
+
+	Describe("server", func() {
+		It("returns service unavailable with a report when a probe fails", func() {
+			checker.AddReadinessCheck("failing", func(context.Context) error { return fakes.FakeError })
+			server := httptest.NewServer(health.NewServer("", checker).Handler)
+			defer server.Close()
+
+			res, err := server.Client().Get(server.URL + health.ReadinessPath)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			var report health.Report
+			Expect(json.NewDecoder(res.Body).Decode(&report)).To(Succeed())
+			Expect(report.Checks["failing"].Healthy).To(BeFalse())
+		})
+
+		It("returns ok when components are live", func() {
+			Expect(checker.NewStatusWriter("one").Write()).To(Succeed())
+			server := httptest.NewServer(health.NewServer("", checker).Handler)
+			defer server.Close()
+
+			res, err := server.Client().Get(server.URL + health.LivenessPath)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("Probe", func() {
+		It("succeeds when the probe is healthy", func() {
+			Expect(checker.NewStatusWriter("one").Write()).To(Succeed())
+			server := httptest.NewServer(health.NewServer("", checker).Handler)
+			defer server.Close()
+
+			Expect(health.Probe(context.Background(), server.URL+health.LivenessPath)).To(Succeed())
+		})
+
+		It("returns an error when the probe is unhealthy", func() {
+			checker.NewStatusWriter("one")
+			server := httptest.NewServer(health.NewServer("", checker).Handler)
+			defer server.Close()
+
+			err := health.Probe(context.Background(), server.URL+health.LivenessPath)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("503"))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+)
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+func DatabaseCheck(db Pinger) Check {
+	return func(ctx context.Context) error {
+		err := db.PingContext(ctx)
+		if err != nil {
+			return fmt.Errorf("error pinging database: %w", err)
+		}
+		return nil
+	}
+}
+
+func NodeCheck(blockChain core.BlockChain) Check {
+	return func(ctx context.Context) error {
+		_, err := blockChain.ChainHead(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting chain head: %w", err)
+		}
+		return nil
+	}
+}
+
+// Fails if the most recent persisted header is more than maxLag blocks behind the node's chain head
+func HeaderSyncLagCheck(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, maxLag int64) Check {
+	return func(ctx context.Context) error {
+		chainHead, chainHeadErr := blockChain.ChainHead(ctx)
+		if chainHeadErr != nil {
+			return fmt.Errorf("error getting chain head: %w", chainHeadErr)
+		}
+		dbHead, dbHeadErr := headerRepository.GetMostRecentHeaderBlockNumber()
+		if dbHeadErr != nil {
+			return fmt.Errorf("error getting most recent header block number: %w", dbHeadErr)
+		}
+		lag := chainHead.Int64() - dbHead
+		if lag > maxLag {
+			return fmt.Errorf("header sync is %d blocks behind chain head, more than the allowed %d", lag, maxLag)
+		}
+		return nil
+	}
+}
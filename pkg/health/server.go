@@ -0,0 +1,87 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+func NewServer(address string, checker *Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, checker.Liveness())
+	})
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, checker.Readiness(r.Context()))
+	})
+	return &http.Server{Addr: address, Handler: mux}
+}
+
+// Serves health and readiness probes at the given address in the background
+func StartServer(address string, checker *Checker) *http.Server {
+	server := NewServer(address, checker)
+	go func() {
+		logrus.Infof("serving health checks at %s%s and %s%s", address, LivenessPath, address, ReadinessPath)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("error serving health checks: %s", err.Error())
+		}
+	}()
+	return server
+}
+
+// Requests a probe served by StartServer, returning an error unless it reports healthy. Lets container health
+// checks use the vulcanizedb binary rather than depending on an http client in the image.
+func Probe(ctx context.Context, url string) error {
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if reqErr != nil {
+		return fmt.Errorf("error creating request for %s: %w", url, reqErr)
+	}
+	res, getErr := http.DefaultClient.Do(req)
+	if getErr != nil {
+		return fmt.Errorf("error requesting %s: %w", url, getErr)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, res.Status)
+	}
+	return nil
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		logrus.Errorf("error writing health report: %s", err.Error())
+	}
+}
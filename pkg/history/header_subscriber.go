@@ -0,0 +1,143 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultResubscribeBackoff = 3 * time.Second
+	MaxResubscribeBackoff     = time.Minute
+)
+
+var ErrNilSubscriptionHeader = errors.New("received header without a block number from newHeads subscription")
+
+type HeaderSubscriber struct {
+	blockChain    core.BlockChain
+	reorgDetector ReorgDetector
+	// Time to wait before resubscribing after the first failure, doubling with each consecutive failure
+	ResubscribeBackoff time.Duration
+}
+
+func NewHeaderSubscriber(blockChain core.BlockChain, reorgDetector ReorgDetector) HeaderSubscriber {
+	return HeaderSubscriber{
+		blockChain:         blockChain,
+		reorgDetector:      reorgDetector,
+		ResubscribeBackoff: DefaultResubscribeBackoff,
+	}
+}
+
+// Subscribes to the node's newHeads until the context is cancelled, resubscribing whenever the subscription fails
+// after a backoff that doubles with each consecutive failure, up to MaxResubscribeBackoff. Sends true on statusChan
+// once subscribed and false when the subscription fails, so that headers can be polled in the meantime. Returns the
+// context's error.
+func (subscriber HeaderSubscriber) SubscribeWithRetry(ctx context.Context, statusChan chan<- bool) error {
+	backoff := subscriber.ResubscribeBackoff
+	for {
+		subscribed := false
+		err := subscriber.subscribe(ctx, func() {
+			subscribed = true
+			sendStatus(ctx, statusChan, true)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if subscribed {
+			backoff = subscriber.ResubscribeBackoff
+			sendStatus(ctx, statusChan, false)
+		}
+		logrus.Errorf("new heads subscription failed, resubscribing in %s: %s", backoff, err.Error())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > MaxResubscribeBackoff {
+			backoff = MaxResubscribeBackoff
+		}
+	}
+}
+
+func sendStatus(ctx context.Context, statusChan chan<- bool, subscribed bool) {
+	select {
+	case <-ctx.Done():
+	case statusChan <- subscribed:
+	}
+}
+
+// Subscribes to the node's newHeads and persists each announced header.
+// Blocks until the subscription or persisting a header fails, returning the error, or until the context is
+// cancelled, unsubscribing and returning the context's error.
+func (subscriber HeaderSubscriber) Subscribe(ctx context.Context) error {
+	return subscriber.subscribe(ctx, nil)
+}
+
+// Calls onSubscribed, if it's not nil, once subscribed
+func (subscriber HeaderSubscriber) subscribe(ctx context.Context, onSubscribed func()) error {
+	headersChan := make(chan *types.Header)
+	subscription, subscribeErr := subscriber.blockChain.SubscribeNewHeads(ctx, headersChan)
+	if subscribeErr != nil {
+		return fmt.Errorf("error subscribing to new heads: %w", subscribeErr)
+	}
+	defer subscription.Unsubscribe()
+	logrus.Info("subscribed to new heads")
+	if onSubscribed != nil {
+		onSubscribed()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-subscription.Err():
+			return fmt.Errorf("error with new heads subscription: %w", err)
+		case gethHeader := <-headersChan:
+			persistErr := subscriber.persistHeader(ctx, gethHeader)
+			if persistErr != nil {
+				return persistErr
+			}
+		}
+	}
+}
+
+// Re-fetches the announced header by number so that POA and POW headers are converted consistently
+func (subscriber HeaderSubscriber) persistHeader(ctx context.Context, gethHeader *types.Header) error {
+	if gethHeader == nil || gethHeader.Number == nil {
+		return ErrNilSubscriptionHeader
+	}
+	blockNumber := gethHeader.Number.Int64()
+	header, getHeaderErr := subscriber.blockChain.GetHeaderByNumber(ctx, blockNumber)
+	if getHeaderErr != nil {
+		return fmt.Errorf("error getting header for block %d: %w", blockNumber, getHeaderErr)
+	}
+	reconcileErr := subscriber.reorgDetector.Reconcile(ctx, header)
+	if reconcileErr != nil {
+		return fmt.Errorf("error persisting header for block %d: %w", blockNumber, reconcileErr)
+	}
+	logrus.Debugf("persisted header for block %d from new heads subscription", blockNumber)
+	return nil
+}
@@ -0,0 +1,124 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header subscriber", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		subscriber       history.HeaderSubscriber
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		headerRepository = fakes.NewMockHeaderRepository()
+		headerRepository.GetHeaderByBlockNumberError = sql.ErrNoRows
+		reorgDetector := history.NewReorgDetector(blockChain, headerRepository, history.DefaultMaxReorgDepth)
+		subscriber = history.NewHeaderSubscriber(blockChain, reorgDetector)
+	})
+
+	It("persists headers received from the subscription", func() {
+		blockChain.NewHeadsToSend = []*types.Header{{Number: big.NewInt(10)}, {Number: big.NewInt(11)}}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(2, []int64{10, 11})
+	})
+
+	It("unsubscribes when the subscription errors", func() {
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+		Expect(blockChain.NewHeadsSubscription.UnsubscribeCalled).To(BeTrue())
+	})
+
+	It("returns error if subscribing fails", func() {
+		blockChain.NewHeadsSubscribeErr = fakes.FakeError
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns error if persisting a header fails", func() {
+		blockChain.NewHeadsToSend = []*types.Header{{Number: big.NewInt(10)}}
+		createErr := errors.New("create failed")
+		headerRepository.SetCreateOrUpdateHeaderReturnErr(createErr)
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(createErr))
+	})
+
+	It("returns error if a header without a block number is received", func() {
+		blockChain.NewHeadsToSend = []*types.Header{{}}
+
+		err := subscriber.Subscribe(context.Background())
+
+		Expect(err).To(MatchError(history.ErrNilSubscriptionHeader))
+	})
+
+	Describe("SubscribeWithRetry", func() {
+		It("resubscribes after the subscription fails, reporting the subscription status", func() {
+			subscriber.ResubscribeBackoff = time.Millisecond
+			ctx, cancel := context.WithCancel(context.Background())
+			statusChan := make(chan bool)
+			errChan := make(chan error)
+			go func() {
+				errChan <- subscriber.SubscribeWithRetry(ctx, statusChan)
+			}()
+
+			Expect(<-statusChan).To(BeTrue())
+			Expect(<-statusChan).To(BeFalse())
+			Expect(<-statusChan).To(BeTrue())
+			cancel()
+
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+		})
+
+		It("returns the context's error if cancelled while waiting to resubscribe", func() {
+			blockChain.NewHeadsSubscribeErr = fakes.FakeError
+			subscriber.ResubscribeBackoff = time.Hour
+			ctx, cancel := context.WithCancel(context.Background())
+			statusChan := make(chan bool, 1)
+			errChan := make(chan error)
+			go func() {
+				errChan <- subscriber.SubscribeWithRetry(ctx, statusChan)
+			}()
+
+			cancel()
+
+			Eventually(errChan).Should(Receive(MatchError(context.Canceled)))
+			Expect(statusChan).NotTo(Receive())
+		})
+	})
+})
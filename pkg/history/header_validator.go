@@ -17,35 +17,41 @@
 package history
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/makerdao/vulcanizedb/pkg/core"
-	"github.com/makerdao/vulcanizedb/pkg/datastore"
 )
 
 type HeaderValidator struct {
-	blockChain       core.BlockChain
-	headerRepository datastore.HeaderRepository
-	windowSize       int
+	blockChain    core.BlockChain
+	reorgDetector ReorgDetector
+	windowSize    int
 }
 
-func NewHeaderValidator(blockChain core.BlockChain, repository datastore.HeaderRepository, windowSize int) HeaderValidator {
+func NewHeaderValidator(blockChain core.BlockChain, reorgDetector ReorgDetector, windowSize int) HeaderValidator {
 	return HeaderValidator{
-		blockChain:       blockChain,
-		headerRepository: repository,
-		windowSize:       windowSize,
+		blockChain:    blockChain,
+		reorgDetector: reorgDetector,
+		windowSize:    windowSize,
 	}
 }
 
-func (validator HeaderValidator) ValidateHeaders() (ValidationWindow, error) {
-	window, err := MakeValidationWindow(validator.blockChain, validator.windowSize)
+func (validator HeaderValidator) ValidateHeaders(ctx context.Context) (ValidationWindow, error) {
+	window, err := MakeValidationWindow(ctx, validator.blockChain, validator.windowSize)
 	if err != nil {
 		return ValidationWindow{}, fmt.Errorf("error creating validation window: %s", err.Error())
 	}
 	blockNumbers := MakeRange(window.LowerBound, window.UpperBound)
-	_, err = RetrieveAndUpdateHeaders(validator.blockChain, validator.headerRepository, blockNumbers)
+	headers, err := validator.blockChain.GetHeadersByNumbers(ctx, blockNumbers)
 	if err != nil {
-		return ValidationWindow{}, fmt.Errorf("error getting/updating headers: %s", err.Error())
+		return ValidationWindow{}, fmt.Errorf("error getting headers: %s", err.Error())
+	}
+	for _, header := range headers {
+		err = validator.reorgDetector.Reconcile(ctx, header)
+		if err != nil {
+			return ValidationWindow{}, fmt.Errorf("error validating header for block %d: %w", header.BlockNumber, err)
+		}
 	}
 	return window, nil
 }
@@ -17,6 +17,8 @@
 package history_test
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"math/big"
 
@@ -31,19 +33,22 @@ var _ = Describe("Header validator", func() {
 	var (
 		headerRepository *fakes.MockHeaderRepository
 		blockChain       *fakes.MockBlockChain
+		reorgDetector    history.ReorgDetector
 	)
 
 	BeforeEach(func() {
 		headerRepository = fakes.NewMockHeaderRepository()
+		headerRepository.GetHeaderByBlockNumberError = sql.ErrNoRows
 		blockChain = fakes.NewMockBlockChain()
+		reorgDetector = history.NewReorgDetector(blockChain, headerRepository, history.DefaultMaxReorgDepth)
 	})
 
 	It("attempts to create every header in the validation window", func() {
 		headerRepository.SetMissingBlockNumbers([]int64{})
 		blockChain.SetChainHead(big.NewInt(3))
-		validator := history.NewHeaderValidator(blockChain, headerRepository, 2)
+		validator := history.NewHeaderValidator(blockChain, reorgDetector, 2)
 
-		_, err := validator.ValidateHeaders()
+		_, err := validator.ValidateHeaders(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 
 		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(3, []int64{1, 2, 3})
@@ -53,9 +58,19 @@ var _ = Describe("Header validator", func() {
 		blockChain.SetChainHead(big.NewInt(3))
 		headerRepositoryError := errors.New("CreateOrUpdate")
 		headerRepository.SetCreateOrUpdateHeaderReturnErr(headerRepositoryError)
-		validator := history.NewHeaderValidator(blockChain, headerRepository, 2)
+		validator := history.NewHeaderValidator(blockChain, reorgDetector, 2)
 
-		_, err := validator.ValidateHeaders()
+		_, err := validator.ValidateHeaders(context.Background())
 		Expect(err.Error()).To(ContainSubstring(headerRepositoryError.Error()))
 	})
+
+	It("propagates errors getting headers from the chain", func() {
+		blockChain.SetChainHead(big.NewInt(3))
+		blockChain.SetGetHeadersByNumbersErr(fakes.FakeError)
+		validator := history.NewHeaderValidator(blockChain, reorgDetector, 2)
+
+		_, err := validator.ValidateHeaders(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(fakes.FakeError.Error()))
+	})
 })
@@ -17,15 +17,30 @@
 package history
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
 	"github.com/sirupsen/logrus"
 )
 
-func PopulateMissingHeaders(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, startingBlockNumber, validationWindowSize int64) (int, error) {
-	chainHead, err := blockChain.ChainHead()
+const (
+	DefaultHeaderBatchSize = 100
+	DefaultHeaderWorkers   = 1
+)
+
+type batchResult struct {
+	populated int
+	err       error
+}
+
+// Splits missing block numbers into batches of batchSize and fetches/persists them with the given number of workers.
+// If onBatch is not nil, it is called with the number of headers populated so far after each batch is persisted.
+func PopulateMissingHeaders(ctx context.Context, blockChain core.BlockChain, headerRepository datastore.HeaderRepository, startingBlockNumber, validationWindowSize int64, workers, batchSize int, onBatch func(populated int)) (int, error) {
+	chainHead, err := blockChain.ChainHead(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("error getting last block: %w", err)
 	}
@@ -39,22 +54,100 @@ func PopulateMissingHeaders(blockChain core.BlockChain, headerRepository datasto
 	}
 
 	logrus.Debug(getBlockRangeString(blockNumbers))
-	_, err = RetrieveAndUpdateHeaders(blockChain, headerRepository, blockNumbers)
+	populated, err := retrieveAndUpdateHeadersConcurrently(ctx, blockChain, headerRepository, blockNumbers, workers, batchSize, onBatch)
 	if err != nil {
-		return 0, fmt.Errorf("error getting/updating headers: %s", err.Error())
+		return populated, fmt.Errorf("error getting/updating headers: %s", err.Error())
 	}
-	return len(blockNumbers), nil
+	return populated, nil
 }
 
-func RetrieveAndUpdateHeaders(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, blockNumbers []int64) (int, error) {
-	headers, err := blockChain.GetHeadersByNumbers(blockNumbers)
+// Fetched headers are persisted even if the context is cancelled while doing so, so that a batch is never left half-written
+func RetrieveAndUpdateHeaders(ctx context.Context, blockChain core.BlockChain, headerRepository datastore.HeaderRepository, blockNumbers []int64) (int, error) {
+	headers, err := blockChain.GetHeadersByNumbers(ctx, blockNumbers)
+	if err != nil {
+		return 0, err
+	}
 	for _, header := range headers {
 		_, err = headerRepository.CreateOrUpdateHeader(header)
 		if err != nil {
 			return 0, err
 		}
+		metrics.HeadersSynced.Inc()
+	}
+	return len(headers), nil
+}
+
+func retrieveAndUpdateHeadersConcurrently(ctx context.Context, blockChain core.BlockChain, headerRepository datastore.HeaderRepository, blockNumbers []int64, workers, batchSize int, onBatch func(populated int)) (int, error) {
+	if workers < 1 {
+		workers = DefaultHeaderWorkers
+	}
+	if batchSize < 1 {
+		batchSize = DefaultHeaderBatchSize
+	}
+	batches := chunkBlockNumbers(blockNumbers, batchSize)
+
+	batchesChan := make(chan []int64)
+	resultsChan := make(chan batchResult)
+	quitChan := make(chan bool)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchesChan {
+				populated, err := RetrieveAndUpdateHeaders(ctx, blockChain, headerRepository, batch)
+				resultsChan <- batchResult{populated: populated, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(batchesChan)
+		for _, batch := range batches {
+			select {
+			case batchesChan <- batch:
+			case <-quitChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	var (
+		populated int
+		firstErr  error
+	)
+	for result := range resultsChan {
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+				close(quitChan)
+			}
+			continue
+		}
+		populated += result.populated
+		logrus.Infof("back-filled %d of %d missing headers", populated, len(blockNumbers))
+		if onBatch != nil {
+			onBatch(populated)
+		}
+	}
+	return populated, firstErr
+}
+
+func chunkBlockNumbers(blockNumbers []int64, batchSize int) [][]int64 {
+	var batches [][]int64
+	for start := 0; start < len(blockNumbers); start += batchSize {
+		end := start + batchSize
+		if end > len(blockNumbers) {
+			end = len(blockNumbers)
+		}
+		batches = append(batches, blockNumbers[start:end])
 	}
-	return len(blockNumbers), nil
+	return batches
 }
 
 func getBlockRangeString(blockRange []int64) string {
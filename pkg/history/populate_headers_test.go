@@ -17,6 +17,7 @@
 package history_test
 
 import (
+	"context"
 	"math/big"
 
 	. "github.com/onsi/ginkgo"
@@ -46,7 +47,7 @@ var _ = Describe("Populating headers", func() {
 		blockChain.SetChainHead(big.NewInt(startingBlock + 1))
 		headerRepository.SetMissingBlockNumbers([]int64{startingBlock + 1})
 
-		numHeadersAdded, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		numHeadersAdded, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(numHeadersAdded).To(Equal(1))
@@ -57,16 +58,66 @@ var _ = Describe("Populating headers", func() {
 		blockChain.SetChainHead(big.NewInt(startingBlock + 1))
 		headerRepository.SetMissingBlockNumbers([]int64{startingBlock + 1})
 
-		_, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).NotTo(HaveOccurred())
 		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(1, []int64{2})
 	})
 
+	It("fetches missing headers in batches across workers", func() {
+		blockChain := fakes.NewMockBlockChain()
+		blockChain.SetChainHead(big.NewInt(startingBlock + validationWindowSize + 5))
+		missingBlockNumbers := []int64{2, 3, 4, 5, 6}
+		headerRepository.SetMissingBlockNumbers(missingBlockNumbers)
+
+		numHeadersAdded, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, 3, 2, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(numHeadersAdded).To(Equal(len(missingBlockNumbers)))
+		Expect(headerRepository.CreateOrUpdateHeaderPassedBlockNumbers()).To(ConsistOf(missingBlockNumbers))
+	})
+
+	It("reports the number of headers populated after each batch", func() {
+		blockChain := fakes.NewMockBlockChain()
+		blockChain.SetChainHead(big.NewInt(startingBlock + validationWindowSize + 5))
+		headerRepository.SetMissingBlockNumbers([]int64{2, 3, 4, 5, 6})
+		var progress []int
+
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, 1, 2, func(populated int) {
+			progress = append(progress, populated)
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress).To(Equal([]int{2, 4, 5}))
+	})
+
+	It("returns error if fetching headers fails", func() {
+		blockChain := fakes.NewMockBlockChain()
+		blockChain.SetChainHead(big.NewInt(startingBlock + 1))
+		blockChain.SetGetHeadersByNumbersErr(fakes.FakeError)
+		headerRepository.SetMissingBlockNumbers([]int64{startingBlock + 1})
+
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(fakes.FakeError.Error()))
+	})
+
+	It("returns error if persisting headers fails", func() {
+		blockChain := fakes.NewMockBlockChain()
+		blockChain.SetChainHead(big.NewInt(startingBlock + 1))
+		headerRepository.SetMissingBlockNumbers([]int64{startingBlock + 1})
+		headerRepository.SetCreateOrUpdateHeaderReturnErr(fakes.FakeError)
+
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("queries headers table for missing headers until beginning validation window (not chain head)", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetChainHead(big.NewInt(startingBlock + validationWindowSize))
-		_, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(headerRepository.MissingBlockNumbersPassedStartingBlock).To(Equal(startingBlock))
@@ -76,7 +127,7 @@ var _ = Describe("Populating headers", func() {
 	It("doesn't query for numbers less than starting block", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetChainHead(big.NewInt(startingBlock))
-		_, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(headerRepository.MissingBlockNumbersPassedStartingBlock).To(Equal(startingBlock))
@@ -86,7 +137,7 @@ var _ = Describe("Populating headers", func() {
 	It("returns early if the db is already synced up to the beginning of the validation window", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetChainHead(big.NewInt(startingBlock))
-		headersAdded, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		headersAdded, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(headersAdded).To(Equal(0))
@@ -96,7 +147,7 @@ var _ = Describe("Populating headers", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetChainHeadError(fakes.FakeError)
 
-		_, err := history.PopulateMissingHeaders(blockChain, headerRepository, startingBlock, validationWindowSize)
+		_, err := history.PopulateMissingHeaders(context.Background(), blockChain, headerRepository, startingBlock, validationWindowSize, history.DefaultHeaderWorkers, history.DefaultHeaderBatchSize, nil)
 
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(MatchError(fakes.FakeError))
@@ -0,0 +1,158 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultMaxReorgDepth = 15
+
+var (
+	ErrReorgTooDeep       = errors.New("reorg exceeds maximum reorg depth")
+	ErrParentHashMismatch = errors.New("parent header from node does not match child's parent hash")
+)
+
+type ReorgDetector struct {
+	blockChain       core.BlockChain
+	headerRepository datastore.HeaderRepository
+	maxDepth         int64
+}
+
+func NewReorgDetector(blockChain core.BlockChain, headerRepository datastore.HeaderRepository, maxDepth int64) ReorgDetector {
+	return ReorgDetector{
+		blockChain:       blockChain,
+		headerRepository: headerRepository,
+		maxDepth:         maxDepth,
+	}
+}
+
+// Persists the header after walking its parent hashes back to the common ancestor with the stored chain,
+// replacing every orphaned header along the way and recording the reorg if any were replaced.
+func (detector ReorgDetector) Reconcile(ctx context.Context, header core.Header) error {
+	var orphans, replacements []core.Header
+	current := header
+	for {
+		stored, found, getErr := detector.getStoredHeader(current.BlockNumber)
+		if getErr != nil {
+			return getErr
+		}
+		if found && stored.Hash == current.Hash {
+			break
+		}
+		if found {
+			orphans = append(orphans, stored)
+		}
+		replacements = append(replacements, current)
+
+		parentHash, parentHashErr := getParentHash(current)
+		if parentHashErr != nil {
+			return parentHashErr
+		}
+		storedParent, parentFound, getParentErr := detector.getStoredHeader(current.BlockNumber - 1)
+		if getParentErr != nil {
+			return getParentErr
+		}
+		if !parentFound || parentHash == "" || storedParent.Hash == parentHash {
+			break
+		}
+		if int64(len(orphans)) >= detector.maxDepth {
+			return fmt.Errorf("%w: more than %d headers orphaned below block %d", ErrReorgTooDeep, detector.maxDepth, header.BlockNumber)
+		}
+
+		parent, fetchErr := detector.blockChain.GetHeaderByNumber(ctx, current.BlockNumber-1)
+		if fetchErr != nil {
+			return fmt.Errorf("error getting header for block %d: %w", current.BlockNumber-1, fetchErr)
+		}
+		if parent.Hash != parentHash {
+			return fmt.Errorf("%w: block %d", ErrParentHashMismatch, parent.BlockNumber)
+		}
+		current = parent
+	}
+
+	if len(orphans) > 0 {
+		return detector.replaceOrphans(orphans, replacements)
+	}
+	for i := len(replacements) - 1; i >= 0; i-- {
+		_, createErr := detector.headerRepository.CreateOrUpdateHeader(replacements[i])
+		if createErr != nil {
+			return fmt.Errorf("error persisting header for block %d: %w", replacements[i].BlockNumber, createErr)
+		}
+		metrics.HeadersSynced.Inc()
+	}
+	return nil
+}
+
+func (detector ReorgDetector) getStoredHeader(blockNumber int64) (core.Header, bool, error) {
+	stored, err := detector.headerRepository.GetHeaderByBlockNumber(blockNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Header{}, false, nil
+	}
+	if err != nil {
+		return core.Header{}, false, fmt.Errorf("error getting stored header for block %d: %w", blockNumber, err)
+	}
+	return stored, true, nil
+}
+
+// Replaces the orphans and records the reorg together. Orphans and replacements are ordered from the highest block down.
+func (detector ReorgDetector) replaceOrphans(orphans, replacements []core.Header) error {
+	depth := len(orphans)
+	reorg := core.Reorg{
+		BlockNumber: orphans[depth-1].BlockNumber - 1,
+		Depth:       int64(depth),
+	}
+	for i := depth - 1; i >= 0; i-- {
+		reorg.OldHashes = append(reorg.OldHashes, orphans[i].Hash)
+		reorg.OldHeaderIDs = append(reorg.OldHeaderIDs, orphans[i].Id)
+	}
+	var headers []core.Header
+	for i := len(replacements) - 1; i >= 0; i-- {
+		reorg.NewHashes = append(reorg.NewHashes, replacements[i].Hash)
+		headers = append(headers, replacements[i])
+	}
+	replaceErr := detector.headerRepository.ReplaceOrphanedHeaders(headers, reorg)
+	if replaceErr != nil {
+		return fmt.Errorf("error replacing headers orphaned by reorg at block %d: %w", reorg.BlockNumber, replaceErr)
+	}
+	metrics.HeadersSynced.Add(float64(len(headers)))
+	logrus.Warnf("reorg detected: replaced %d headers after common ancestor at block %d", depth, reorg.BlockNumber)
+	return nil
+}
+
+// Returns an empty hash if the header has no raw data to read a parent hash from
+func getParentHash(header core.Header) (string, error) {
+	if len(header.Raw) == 0 {
+		return "", nil
+	}
+	var raw struct {
+		ParentHash string `json:"parentHash"`
+	}
+	unmarshalErr := json.Unmarshal(header.Raw, &raw)
+	if unmarshalErr != nil {
+		return "", fmt.Errorf("error reading parent hash of header for block %d: %w", header.BlockNumber, unmarshalErr)
+	}
+	return raw.ParentHash, nil
+}
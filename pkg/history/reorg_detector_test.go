@@ -0,0 +1,159 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reorg detector", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+		detector         history.ReorgDetector
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		blockChain.HeadersByNumber = make(map[int64]core.Header)
+		headerRepository = fakes.NewMockHeaderRepository()
+		headerRepository.StoredHeaders = make(map[int64]core.Header)
+		detector = history.NewReorgDetector(blockChain, headerRepository, 2)
+	})
+
+	It("persists a header that links to the stored chain", func() {
+		headerRepository.StoredHeaders[9] = headerOnFork(9, "a")
+
+		err := detector.Reconcile(context.Background(), headerOnFork(10, "a"))
+
+		Expect(err).NotTo(HaveOccurred())
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(1, []int64{10})
+		Expect(headerRepository.ReplacedReorgs).To(BeEmpty())
+	})
+
+	It("does nothing if the header is already stored", func() {
+		headerRepository.StoredHeaders[10] = headerOnFork(10, "a")
+
+		err := detector.Reconcile(context.Background(), headerOnFork(10, "a"))
+
+		Expect(err).NotTo(HaveOccurred())
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+	})
+
+	It("replaces orphaned ancestors back to the common ancestor and records the reorg together", func() {
+		headerRepository.StoredHeaders[8] = headerOnFork(8, "a")
+		headerRepository.StoredHeaders[9] = headerOnFork(9, "a")
+		headerRepository.StoredHeaders[10] = headerOnFork(10, "a")
+		blockChain.HeadersByNumber[10] = forkedHeader(10, "b")
+		newHeader := headerOnFork(11, "b")
+
+		err := detector.Reconcile(context.Background(), newHeader)
+
+		Expect(err).NotTo(HaveOccurred())
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+		Expect(headerRepository.ReplacedHeaderBlockNumbers).To(Equal([]int64{10, 11}))
+		Expect(headerRepository.StoredHeaders[10].Hash).To(Equal(hashOnFork(10, "b")))
+		Expect(headerRepository.ReplacedReorgs).To(ConsistOf(core.Reorg{
+			BlockNumber:  9,
+			Depth:        1,
+			OldHashes:    []string{hashOnFork(10, "a")},
+			NewHashes:    []string{hashOnFork(10, "b"), hashOnFork(11, "b")},
+			OldHeaderIDs: []int64{10},
+		}))
+	})
+
+	It("replaces a stored header with a different hash", func() {
+		headerRepository.StoredHeaders[9] = headerOnFork(9, "a")
+		headerRepository.StoredHeaders[10] = headerOnFork(10, "a")
+
+		err := detector.Reconcile(context.Background(), forkedHeader(10, "b"))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headerRepository.ReplacedReorgs).To(HaveLen(1))
+		Expect(headerRepository.ReplacedReorgs[0].BlockNumber).To(Equal(int64(9)))
+		Expect(headerRepository.ReplacedReorgs[0].OldHashes).To(Equal([]string{hashOnFork(10, "a")}))
+	})
+
+	It("returns an error if the reorg exceeds the maximum depth", func() {
+		for blockNumber := int64(7); blockNumber <= 10; blockNumber++ {
+			headerRepository.StoredHeaders[blockNumber] = headerOnFork(blockNumber, "a")
+			blockChain.HeadersByNumber[blockNumber] = headerOnFork(blockNumber, "b")
+		}
+
+		err := detector.Reconcile(context.Background(), headerOnFork(11, "b"))
+
+		Expect(err).To(MatchError(history.ErrReorgTooDeep))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+		Expect(headerRepository.ReplacedReorgs).To(BeEmpty())
+	})
+
+	It("returns an error if the node's parent header doesn't match the child's parent hash", func() {
+		headerRepository.StoredHeaders[9] = headerOnFork(9, "a")
+		headerRepository.StoredHeaders[10] = headerOnFork(10, "a")
+		blockChain.HeadersByNumber[10] = headerOnFork(10, "c")
+
+		err := detector.Reconcile(context.Background(), headerOnFork(11, "b"))
+
+		Expect(err).To(MatchError(history.ErrParentHashMismatch))
+		headerRepository.AssertCreateOrUpdateHeaderCallCountAndPassedBlockNumbers(0, nil)
+	})
+
+	It("returns an error if replacing the orphaned headers fails", func() {
+		headerRepository.StoredHeaders[9] = headerOnFork(9, "a")
+		headerRepository.StoredHeaders[10] = headerOnFork(10, "a")
+		headerRepository.ReplaceOrphanedHeadersError = fakes.FakeError
+
+		err := detector.Reconcile(context.Background(), forkedHeader(10, "b"))
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns an error if persisting a header fails", func() {
+		headerRepository.SetCreateOrUpdateHeaderReturnErr(fakes.FakeError)
+
+		err := detector.Reconcile(context.Background(), headerOnFork(10, "a"))
+
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+})
+
+func hashOnFork(blockNumber int64, fork string) string {
+	return fmt.Sprintf("0x%s%d", fork, blockNumber)
+}
+
+func headerOnFork(blockNumber int64, fork string) core.Header {
+	return core.Header{
+		Id:          blockNumber,
+		BlockNumber: blockNumber,
+		Hash:        hashOnFork(blockNumber, fork),
+		Raw:         []byte(fmt.Sprintf(`{"parentHash": "%s"}`, hashOnFork(blockNumber-1, fork))),
+	}
+}
+
+// Returns a header on the given fork whose parent is on fork "a"
+func forkedHeader(blockNumber int64, fork string) core.Header {
+	header := headerOnFork(blockNumber, fork)
+	header.Raw = []byte(fmt.Sprintf(`{"parentHash": "%s"}`, hashOnFork(blockNumber-1, "a")))
+	return header
+}
@@ -0,0 +1,42 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/makerdao/vulcanizedb/pkg/core"
+	"github.com/makerdao/vulcanizedb/pkg/datastore"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+)
+
+// Records how far the most recent persisted header trails the node's chain head
+func RecordHeaderSyncLag(ctx context.Context, blockChain core.BlockChain, headerRepository datastore.HeaderRepository) error {
+	chainHead, chainHeadErr := blockChain.ChainHead(ctx)
+	if chainHeadErr != nil {
+		return fmt.Errorf("error getting chain head: %w", chainHeadErr)
+	}
+	dbHead, dbHeadErr := headerRepository.GetMostRecentHeaderBlockNumber()
+	if dbHeadErr != nil {
+		return fmt.Errorf("error getting most recent header block number: %w", dbHeadErr)
+	}
+	metrics.ChainHeadBlockNumber.Set(float64(chainHead.Int64()))
+	metrics.DBHeadBlockNumber.Set(float64(dbHead))
+	metrics.HeaderSyncLag.Set(float64(chainHead.Int64() - dbHead))
+	return nil
+}
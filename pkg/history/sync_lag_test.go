@@ -0,0 +1,72 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history_test
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/makerdao/vulcanizedb/pkg/fakes"
+	"github.com/makerdao/vulcanizedb/pkg/history"
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Recording header sync lag", func() {
+	var (
+		blockChain       *fakes.MockBlockChain
+		headerRepository *fakes.MockHeaderRepository
+	)
+
+	BeforeEach(func() {
+		blockChain = fakes.NewMockBlockChain()
+		headerRepository = fakes.NewMockHeaderRepository()
+	})
+
+	It("records chain head, db head and the difference between them", func() {
+		blockChain.SetChainHead(big.NewInt(110))
+		headerRepository.MostRecentHeaderBlockNumber = 100
+
+		err := history.RecordHeaderSyncLag(context.Background(), blockChain, headerRepository)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(testutil.ToFloat64(metrics.ChainHeadBlockNumber)).To(Equal(float64(110)))
+		Expect(testutil.ToFloat64(metrics.DBHeadBlockNumber)).To(Equal(float64(100)))
+		Expect(testutil.ToFloat64(metrics.HeaderSyncLag)).To(Equal(float64(10)))
+	})
+
+	It("returns error if getting chain head fails", func() {
+		blockChain.SetChainHeadError(fakes.FakeError)
+
+		err := history.RecordHeaderSyncLag(context.Background(), blockChain, headerRepository)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+
+	It("returns error if getting most recent header fails", func() {
+		blockChain.SetChainHead(big.NewInt(110))
+		headerRepository.MostRecentHeaderBlockNumberErr = fakes.FakeError
+
+		err := history.RecordHeaderSyncLag(context.Background(), blockChain, headerRepository)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(MatchError(fakes.FakeError))
+	})
+})
@@ -17,7 +17,9 @@
 package history
 
 import (
+	"context"
 	"fmt"
+
 	"github.com/makerdao/vulcanizedb/pkg/core"
 	log "github.com/sirupsen/logrus"
 )
@@ -31,8 +33,8 @@ func (window ValidationWindow) Size() int {
 	return int(window.UpperBound - window.LowerBound)
 }
 
-func MakeValidationWindow(blockchain core.BlockChain, windowSize int) (ValidationWindow, error) {
-	upperBound, err := blockchain.ChainHead()
+func MakeValidationWindow(ctx context.Context, blockchain core.BlockChain, windowSize int) (ValidationWindow, error) {
+	upperBound, err := blockchain.ChainHead(ctx)
 	if err != nil {
 		log.Error("MakeValidationWindow: error getting ChainHead: ", err)
 		return ValidationWindow{}, err
@@ -17,6 +17,8 @@
 package history_test
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -30,7 +32,7 @@ var _ = Describe("Validation window", func() {
 		blockChain := fakes.NewMockBlockChain()
 		blockChain.SetChainHead(big.NewInt(5))
 
-		validationWindow, err := history.MakeValidationWindow(blockChain, 2)
+		validationWindow, err := history.MakeValidationWindow(context.Background(), blockChain, 2)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(validationWindow.LowerBound).To(Equal(int64(3)))
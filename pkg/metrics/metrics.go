@@ -0,0 +1,88 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "vulcanizedb"
+
+var (
+	HeadersSynced = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "headers_synced_total",
+		Help:      "Number of headers fetched from the node and persisted, including headers replaced by a reorg",
+	})
+	ChainHeadBlockNumber = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_head_block_number",
+		Help:      "Most recent block number reported by the node",
+	})
+	DBHeadBlockNumber = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "db_head_block_number",
+		Help:      "Most recent block number with a persisted header",
+	})
+	HeaderSyncLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "header_sync_lag_blocks",
+		Help:      "Number of blocks between the node's chain head and the most recent persisted header",
+	})
+	UncheckedHeaders = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unchecked_headers",
+		Help:      "Number of headers waiting to be checked for watched logs as of the last extraction",
+	})
+	LogsExtracted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_extracted_total",
+		Help:      "Number of watched logs persisted by the log extractor, by transformer",
+	}, []string{"transformer"})
+	LogsDelegated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_delegated_total",
+		Help:      "Number of logs successfully executed by event transformers, by transformer",
+	}, []string{"transformer"})
+	LogsQuarantined = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_quarantined_total",
+		Help:      "Number of logs quarantined after repeatedly failing to transform, by transformer",
+	}, []string{"transformer"})
+	RPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Latency of requests to the Ethereum node, by method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	RPCRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_errors_total",
+		Help:      "Number of failed requests to the Ethereum node, by method",
+	}, []string{"method"})
+	Retries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retries_total",
+		Help:      "Number of times a watcher operation was retried after an error, by operation and whether the error was expected",
+	}, []string{"operation", "error_type"})
+)
+
+const (
+	ExpectedError   = "expected"
+	UnexpectedError = "unexpected"
+)
@@ -0,0 +1,66 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"time"
+
+	"github.com/makerdao/vulcanizedb/pkg/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Metrics", func() {
+	Describe("ObserveRPC", func() {
+		It("counts an error for the method when the call fails", func() {
+			method := "eth_fakeFailingMethod"
+			before := testutil.ToFloat64(metrics.RPCRequestErrors.WithLabelValues(method))
+
+			metrics.ObserveRPC(method, time.Now(), errors.New("rpc failed"))
+
+			Expect(testutil.ToFloat64(metrics.RPCRequestErrors.WithLabelValues(method))).To(Equal(before + 1))
+		})
+
+		It("does not count an error for the method when the call succeeds", func() {
+			method := "eth_fakeSucceedingMethod"
+
+			metrics.ObserveRPC(method, time.Now(), nil)
+
+			Expect(testutil.ToFloat64(metrics.RPCRequestErrors.WithLabelValues(method))).To(BeZero())
+		})
+	})
+
+	Describe("server", func() {
+		It("serves registered metrics", func() {
+			metrics.HeadersSynced.Inc()
+			server := httptest.NewServer(metrics.NewServer("").Handler)
+			defer server.Close()
+
+			res, err := server.Client().Get(server.URL + metrics.Path)
+			Expect(err).NotTo(HaveOccurred())
+			defer res.Body.Close()
+			body, readErr := ioutil.ReadAll(res.Body)
+
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("vulcanizedb_headers_synced_total"))
+		})
+	})
+})
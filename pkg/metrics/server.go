@@ -0,0 +1,47 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const Path = "/metrics"
+
+func NewServer(address string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.Handler())
+	return &http.Server{Addr: address, Handler: mux}
+}
+
+// Serves metrics at the given address in the background; failing to serve is logged rather than fatal
+// since metrics aren't required for syncing
+func StartServer(address string) *http.Server {
+	server := NewServer(address)
+	go func() {
+		logrus.Infof("serving metrics at %s%s", address, Path)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("error serving metrics: %s", err.Error())
+		}
+	}()
+	return server
+}
@@ -0,0 +1,61 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"github.com/makerdao/vulcanizedb/pkg/datastore/postgres"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var storageDiffsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "storage_diffs"),
+	"Number of persisted storage diffs, by status",
+	[]string{"status"}, nil,
+)
+
+type diffStatusCount struct {
+	Status string
+	Count  int64
+}
+
+// StorageDiffsCollector counts storage diffs by status when scraped, so the count reflects every
+// process writing to or transforming diffs
+type StorageDiffsCollector struct {
+	db *postgres.DB
+}
+
+func NewStorageDiffsCollector(db *postgres.DB) StorageDiffsCollector {
+	return StorageDiffsCollector{db: db}
+}
+
+func (collector StorageDiffsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storageDiffsDesc
+}
+
+func (collector StorageDiffsCollector) Collect(ch chan<- prometheus.Metric) {
+	var counts []diffStatusCount
+	err := collector.db.Select(&counts, `SELECT status, COUNT(*) AS count FROM public.storage_diff GROUP BY status`)
+	if err != nil {
+		logrus.Errorf("error counting storage diffs for metrics: %s", err.Error())
+		ch <- prometheus.NewInvalidMetric(storageDiffsDesc, err)
+		return
+	}
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(storageDiffsDesc, prometheus.GaugeValue, float64(count.Count), count.Status)
+	}
+}
@@ -0,0 +1,174 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/makerdao/vulcanizedb/db"
+	"github.com/pressly/goose"
+)
+
+// CoreVersionTable records which core migrations have been applied
+const CoreVersionTable = "public.goose_db_version"
+
+// Migrator runs the goose migrations in a directory, recording which have been applied in a version table
+type Migrator struct {
+	DB        *sql.DB
+	Dir       string
+	TableName string
+}
+
+func NewMigrator(database *sql.DB, dir, tableName string) Migrator {
+	return Migrator{
+		DB:        database,
+		Dir:       dir,
+		TableName: tableName,
+	}
+}
+
+// Applies all migrations that haven't been applied yet
+func (m Migrator) Up() error {
+	goose.SetTableName(m.TableName)
+	err := goose.Up(m.DB, m.Dir)
+	if err != nil {
+		return fmt.Errorf("error applying migrations in %s: %w", m.Dir, err)
+	}
+	return nil
+}
+
+// Rolls back the most recently applied migration
+func (m Migrator) Down() error {
+	goose.SetTableName(m.TableName)
+	err := goose.Down(m.DB, m.Dir)
+	if err != nil {
+		return fmt.Errorf("error rolling back migration in %s: %w", m.Dir, err)
+	}
+	return nil
+}
+
+// Logs whether each migration has been applied
+func (m Migrator) Status() error {
+	goose.SetTableName(m.TableName)
+	err := goose.Status(m.DB, m.Dir)
+	if err != nil {
+		return fmt.Errorf("error getting status of migrations in %s: %w", m.Dir, err)
+	}
+	return nil
+}
+
+// Applies or rolls back migrations until the given version is the most recently applied
+func (m Migrator) To(version int64) error {
+	goose.SetTableName(m.TableName)
+	current, versionErr := goose.GetDBVersion(m.DB)
+	if versionErr != nil {
+		return fmt.Errorf("error getting current migration version: %w", versionErr)
+	}
+	var err error
+	if version >= current {
+		err = goose.UpTo(m.DB, m.Dir, version)
+	} else {
+		err = goose.DownTo(m.DB, m.Dir, version)
+	}
+	if err != nil {
+		return fmt.Errorf("error migrating to version %d in %s: %w", version, m.Dir, err)
+	}
+	return nil
+}
+
+// ExtractCoreMigrations writes the core migrations embedded in the binary to a new temporary directory, since goose
+// only reads migrations from disk. Returns the directory, which callers remove once done.
+func ExtractCoreMigrations() (string, error) {
+	dir, dirErr := ioutil.TempDir("", "vulcanizedb_migrations")
+	if dirErr != nil {
+		return "", fmt.Errorf("error creating directory for core migrations: %w", dirErr)
+	}
+	return dir, ExtractMigrations(db.Migrations, "migrations", dir)
+}
+
+// ExtractMigrations writes the .sql migrations in the root directory of fsys (e.g. migrations embedded in a binary or
+// plugin) to dir. A missing root directory is treated as having no migrations.
+func ExtractMigrations(fsys fs.FS, root, dir string) error {
+	files, readErr := fs.ReadDir(fsys, root)
+	if errors.Is(readErr, fs.ErrNotExist) {
+		return nil
+	}
+	if readErr != nil {
+		return fmt.Errorf("error reading embedded migrations: %w", readErr)
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
+			continue
+		}
+		contents, fileErr := fs.ReadFile(fsys, path.Join(root, file.Name()))
+		if fileErr != nil {
+			return fmt.Errorf("error reading embedded migration %s: %w", file.Name(), fileErr)
+		}
+		writeErr := ioutil.WriteFile(filepath.Join(dir, file.Name()), contents, 0644)
+		if writeErr != nil {
+			return fmt.Errorf("error writing migration %s: %w", file.Name(), writeErr)
+		}
+	}
+	return nil
+}
+
+// CopyRankedMigrations copies the .sql migrations in each of the paths into dir, in order, fixing timestamped
+// migrations into versioned ones after each path so that they're numbered after the migrations of the paths before them
+func CopyRankedMigrations(paths []string, dir string) error {
+	for _, migrationsPath := range paths {
+		files, readErr := ioutil.ReadDir(migrationsPath)
+		if readErr != nil {
+			return readErr
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
+				continue
+			}
+			contents, fileErr := ioutil.ReadFile(filepath.Join(migrationsPath, file.Name()))
+			if fileErr != nil {
+				return fileErr
+			}
+			writeErr := ioutil.WriteFile(filepath.Join(dir, file.Name()), contents, 0644)
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		fixErr := goose.Fix(dir)
+		if fixErr != nil {
+			return fmt.Errorf("version fixing for migrations at %s failed: %w", migrationsPath, fixErr)
+		}
+	}
+	return nil
+}
+
+// NewCoreMigrator extracts the embedded core migrations and returns a migrator for them, along with a function
+// removing the extracted files
+func NewCoreMigrator(database *sql.DB) (Migrator, func() error, error) {
+	dir, extractErr := ExtractCoreMigrations()
+	if extractErr != nil {
+		return Migrator{}, nil, extractErr
+	}
+	cleanUp := func() error { return os.RemoveAll(dir) }
+	return NewMigrator(database, dir, CoreVersionTable), cleanUp, nil
+}
@@ -0,0 +1,123 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package migrations_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/makerdao/vulcanizedb/pkg/migrations"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Core migrations", func() {
+	It("extracts every migration in db/migrations", func() {
+		dir, err := migrations.ExtractCoreMigrations()
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		expectedFiles, globErr := filepath.Glob(filepath.Join("..", "..", "db", "migrations", "*.sql"))
+		Expect(globErr).NotTo(HaveOccurred())
+		Expect(expectedFiles).NotTo(BeEmpty())
+		extractedFiles, readErr := ioutil.ReadDir(dir)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(extractedFiles).To(HaveLen(len(expectedFiles)))
+		for _, expectedFile := range expectedFiles {
+			expectedContents, expectedErr := ioutil.ReadFile(expectedFile)
+			Expect(expectedErr).NotTo(HaveOccurred())
+			contents, contentsErr := ioutil.ReadFile(filepath.Join(dir, filepath.Base(expectedFile)))
+			Expect(contentsErr).NotTo(HaveOccurred())
+			Expect(contents).To(Equal(expectedContents))
+		}
+	})
+
+	It("removes the extracted migrations on clean up", func() {
+		migrator, cleanUp, err := migrations.NewCoreMigrator(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrator.TableName).To(Equal(migrations.CoreVersionTable))
+
+		Expect(cleanUp()).To(Succeed())
+
+		_, statErr := os.Stat(migrator.Dir)
+		Expect(os.IsNotExist(statErr)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Plugin migrations", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var dirErr error
+		dir, dirErr = ioutil.TempDir("", "migrations_test")
+		Expect(dirErr).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("extracts the migrations in a directory of a file system", func() {
+		fsys := fstest.MapFS{
+			"migrations/00001_create_table.sql": {Data: []byte("-- +goose Up")},
+			"migrations/README.md":              {Data: []byte("not a migration")},
+		}
+
+		err := migrations.ExtractMigrations(fsys, "migrations", dir)
+
+		Expect(err).NotTo(HaveOccurred())
+		extractedFiles, readErr := ioutil.ReadDir(dir)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(extractedFiles).To(HaveLen(1))
+		contents, contentsErr := ioutil.ReadFile(filepath.Join(dir, "00001_create_table.sql"))
+		Expect(contentsErr).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("-- +goose Up"))
+	})
+
+	It("extracts no migrations if the directory is missing", func() {
+		err := migrations.ExtractMigrations(fstest.MapFS{}, "migrations", dir)
+
+		Expect(err).NotTo(HaveOccurred())
+		extractedFiles, readErr := ioutil.ReadDir(dir)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(extractedFiles).To(BeEmpty())
+	})
+
+	It("copies migrations from each path, numbering them in order", func() {
+		firstPath := filepath.Join(dir, "first")
+		secondPath := filepath.Join(dir, "second")
+		copyDir := filepath.Join(dir, "copies")
+		for _, path := range []string{firstPath, secondPath, copyDir} {
+			Expect(os.Mkdir(path, 0755)).To(Succeed())
+		}
+		Expect(ioutil.WriteFile(filepath.Join(firstPath, "20200102000000_second.sql"), []byte("-- +goose Up"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(secondPath, "20200101000000_first.sql"), []byte("-- +goose Up"), 0644)).To(Succeed())
+
+		err := migrations.CopyRankedMigrations([]string{firstPath, secondPath}, copyDir)
+
+		Expect(err).NotTo(HaveOccurred())
+		copiedFiles, readErr := ioutil.ReadDir(copyDir)
+		Expect(readErr).NotTo(HaveOccurred())
+		var names []string
+		for _, file := range copiedFiles {
+			names = append(names, file.Name())
+		}
+		Expect(names).To(Equal([]string{"00001_second.sql", "00002_first.sql"}))
+	})
+})
@@ -17,36 +17,56 @@
 package builder
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 
+	"github.com/dave/jennifer/jen"
 	"github.com/makerdao/vulcanizedb/pkg/config"
+	"github.com/makerdao/vulcanizedb/pkg/migrations"
 	"github.com/makerdao/vulcanizedb/pkg/plugin/helpers"
+	"github.com/makerdao/vulcanizedb/pkg/plugin/manager"
+	"github.com/makerdao/vulcanizedb/pkg/plugin/metadata"
 )
 
 // Interface for compile Go code written by the
 // PluginWriter into a shared object (.so file)
-// which can be used loaded as a plugin
+// which can be used loaded as a plugin, or in
+// static mode into a vulcanizedb binary
 type PluginBuilder interface {
 	BuildPlugin() error
 	CleanUp() error
 }
 
 type builder struct {
-	GenConfig    config.Plugin
-	dependencies []string
-	tmpVenDirs   []string // Keep track of temp vendor directories
-	goFile       string   // Keep track of goFile name
+	GenConfig config.Plugin
+	goFile    string // Keep track of goFile name
+	homePath  string // Directory of the vulcanizedb module the plugin is built against
+	homeMod   string // Module path of the vulcanizedb module the plugin is built against
+	tmpModDir string // Temporary module the plugin is built in
+}
+
+// Module in the output of `go list -m -json` and `go mod edit -json`
+type goModule struct {
+	Path    string
+	Version string
+	Main    bool
+	Dir     string
+	Replace *goModule
 }
 
 // Requires populated plugin config
 func NewPluginBuilder(gc config.Plugin) *builder {
 	return &builder{
-		GenConfig:  gc,
-		tmpVenDirs: make([]string, 0),
+		GenConfig: gc,
 	}
 }
 
@@ -59,60 +79,267 @@ func (b *builder) BuildPlugin() error {
 		return err
 	}
 
+	// A static binary doesn't share packages with another binary, so it needn't match vulcanizedb's build
+	if b.GenConfig.Static {
+		return b.buildStaticBinary()
+	}
+
+	goVersionErr := verifyGoVersion()
+	if goVersionErr != nil {
+		return goVersionErr
+	}
+
 	// setup env to build plugin
 	setupErr := b.setupBuildEnv()
 	if setupErr != nil {
 		return setupErr
 	}
 
+	verifyErr := b.verifyDependencies()
+	if verifyErr != nil {
+		return verifyErr
+	}
+
+	pluginMetadata, metadataErr := b.addMetadata()
+	if metadataErr != nil {
+		return metadataErr
+	}
+
 	// Build the .go file into a .so plugin
-	execErr := exec.Command("go", "build", "-mod=mod", "-buildmode=plugin", "-o", soFile, b.goFile).Run()
+	_, execErr := goCommand(b.tmpModDir, "build", "-buildmode=plugin", "-o", soFile, ".")
 	if execErr != nil {
-		return errors.New(fmt.Sprintf("unable to build .so file: %s", execErr.Error()))
+		return fmt.Errorf("unable to build .so file: %w", execErr)
+	}
+	// Also write the metadata next to the .so file, so it can be checked before linking the plugin
+	return metadata.Write(metadata.FilePath(soFile), pluginMetadata)
+}
+
+// Builds the generated main package into a vulcanizedb binary with the transformers compiled in
+func (b *builder) buildStaticBinary() error {
+	binaryFile, pathErr := b.GenConfig.GetBinaryPath()
+	if pathErr != nil {
+		return pathErr
+	}
+	setupErr := b.setupBuildEnv()
+	if setupErr != nil {
+		return setupErr
+	}
+	_, execErr := goCommand(b.tmpModDir, "build", "-o", binaryFile, ".")
+	if execErr != nil {
+		return fmt.Errorf("unable to build static binary: %w", execErr)
 	}
 	return nil
 }
 
-// Sets up temporary vendor libs needed for plugin build
-// This is to work around a conflict between plugins and vendoring (https://github.com/golang/go/issues/20481)
+// Sets up a temporary module to build the plugin in. It requires the transformer repositories at their configured
+// versions, resolved from the module cache, or else at the versions vulcanizedb's go.mod resolves them to, and replaces vulcanizedb with the home module so that the plugin is built
+// against the same packages as the binary loading it.
 func (b *builder) setupBuildEnv() error {
-	// TODO: Less hacky way of handling plugin build deps
-	vendorPath, err := helpers.CleanPath(filepath.Join("$GOPATH/src", b.GenConfig.Home, "vendor"))
-	if err != nil {
-		return err
+	repoVersions, versionErr := b.GenConfig.GetRepoVersions()
+	if versionErr != nil {
+		return versionErr
+	}
+	var homeErr error
+	b.homePath, homeErr = b.GenConfig.GetHomePath()
+	if homeErr != nil {
+		return homeErr
+	}
+	homeModFile, modFileErr := readModFile(b.homePath)
+	if modFileErr != nil {
+		return modFileErr
+	}
+	b.homeMod = homeModFile.Module.Path
+
+	var dirErr error
+	b.tmpModDir, dirErr = ioutil.TempDir("", "vulcanizedb_plugin")
+	if dirErr != nil {
+		return fmt.Errorf("unable to create temporary plugin module: %w", dirErr)
+	}
+	copyErr := helpers.CopyFile(b.goFile, filepath.Join(b.tmpModDir, filepath.Base(b.goFile)))
+	if copyErr != nil {
+		return fmt.Errorf("unable to copy %s to temporary plugin module: %w", b.goFile, copyErr)
 	}
 
-	repoPaths := b.GenConfig.GetRepoPaths()
+	name := strings.Split(b.GenConfig.FileName, ".")[0]
+	_, initErr := goCommand(b.tmpModDir, "mod", "init", "plugins/"+name)
+	if initErr != nil {
+		return fmt.Errorf("unable to initialize temporary plugin module: %w", initErr)
+	}
 
-	// Import transformer dependencies so that we can build our plugin
-	for importPath := range repoPaths {
-		dst := filepath.Join(vendorPath, importPath)
-		src, cleanErr := helpers.CleanPath(filepath.Join("$GOPATH/src", importPath))
-		if cleanErr != nil {
-			return cleanErr
+	editArgs := []string{"mod", "edit",
+		fmt.Sprintf("-require=%s@v0.0.0", homeModFile.Module.Path),
+		fmt.Sprintf("-replace=%s=%s", homeModFile.Module.Path, b.homePath),
+	}
+	// Repositories without a configured version are left to resolve to the version vulcanizedb requires
+	for repo, version := range repoVersions {
+		if version != "" {
+			editArgs = append(editArgs, fmt.Sprintf("-require=%s@%s", repo, version))
 		}
+	}
+	// Replace directives only apply in the main module, so vulcanizedb's have to be carried over
+	for _, replace := range homeModFile.Replace {
+		editArgs = append(editArgs, fmt.Sprintf("-replace=%s=%s", moduleString(replace.Old, ""),
+			moduleString(replace.New, b.homePath)))
+	}
+	_, editErr := goCommand(b.tmpModDir, editArgs...)
+	if editErr != nil {
+		return fmt.Errorf("unable to add requirements to temporary plugin module: %w", editErr)
+	}
+
+	migrationsErr := b.addMigrations()
+	if migrationsErr != nil {
+		return migrationsErr
+	}
+
+	_, tidyErr := goCommand(b.tmpModDir, "mod", "tidy")
+	if tidyErr != nil {
+		return fmt.Errorf("unable to resolve plugin dependencies: %w", tidyErr)
+	}
+	return nil
+}
+
+// Copies the transformers' migrations into the temporary module in rank order and embeds them in the plugin, or
+// registers them with the commands of a static binary, so that migrate can run them without the Go toolchain or the
+// transformer repositories
+func (b *builder) addMigrations() error {
+	paths, pathsErr := b.GenConfig.GetMigrationsPaths()
+	if pathsErr != nil {
+		return fmt.Errorf("unable to get plugin migration paths: %w", pathsErr)
+	}
+	migrationsDir := filepath.Join(b.tmpModDir, manager.PluginMigrationsDir)
+	mkdirErr := os.Mkdir(migrationsDir, 0755)
+	if mkdirErr != nil {
+		return fmt.Errorf("unable to create plugin migrations directory: %w", mkdirErr)
+	}
+	copyErr := migrations.CopyRankedMigrations(paths, migrationsDir)
+	if copyErr != nil {
+		return fmt.Errorf("unable to copy plugin migrations: %w", copyErr)
+	}
+	migrationFiles, globErr := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
+	if globErr != nil {
+		return fmt.Errorf("unable to list plugin migrations: %w", globErr)
+	}
+
+	f := jen.NewFile("main")
+	f.HeaderComment("These are the migrations of the plugin's transformers, copied when it was composed")
+	// go:embed fails on a pattern matching no files, so transformers without migrations embed an empty file system
+	if len(migrationFiles) > 0 {
+		f.Comment(fmt.Sprintf("//go:embed %s/*.sql", manager.PluginMigrationsDir)).Line().
+			Var().Id(manager.PluginMigrationsSymbol).Qual("embed", "FS")
+	} else {
+		f.Var().Id(manager.PluginMigrationsSymbol).Qual("embed", "FS")
+	}
+	if b.GenConfig.Static {
+		f.Func().Id("init").Params().Block(
+			jen.Qual("github.com/makerdao/vulcanizedb/cmd", "RegisterMigrations").Call(jen.Id(manager.PluginMigrationsSymbol)))
+	}
+	migrationsFile := filepath.Join(b.tmpModDir, "migrations.go")
+	saveErr := f.Save(migrationsFile)
+	if saveErr != nil {
+		return fmt.Errorf("unable to save plugin migrations to %s: %w", migrationsFile, saveErr)
+	}
+	return nil
+}
+
+// Plugins fail to load if they share a package with the binary loading them, but were built with a different version
+// of it. Checks that every module in the plugin's build list that vulcanizedb also depends on resolves to the same
+// version.
+func (b *builder) verifyDependencies() error {
+	homeModules, homeErr := listModules(b.homePath)
+	if homeErr != nil {
+		return homeErr
+	}
+	pluginModules, pluginErr := listModules(b.tmpModDir)
+	if pluginErr != nil {
+		return pluginErr
+	}
 
-		copyErr := helpers.CopyDir(src, dst, "vendor")
-		if copyErr != nil {
-			return errors.New(fmt.Sprintf("unable to copy transformer dependency from %s to %s: %v", src, dst, copyErr))
+	homeVersions := make(map[string]string)
+	for _, module := range homeModules {
+		if !module.Main {
+			homeVersions[module.Path] = resolvedVersion(module, b.homePath)
+		}
+	}
+	var mismatches []string
+	for _, module := range pluginModules {
+		homeVersion, ok := homeVersions[module.Path]
+		if !ok {
+			continue
+		}
+		pluginVersion := resolvedVersion(module, b.tmpModDir)
+		if pluginVersion != homeVersion {
+			mismatches = append(mismatches, fmt.Sprintf("%s (plugin: %s, vulcanizedb: %s)", module.Path, pluginVersion, homeVersion))
 		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("plugin dependencies are incompatible with vulcanizedb's go.mod: %s", strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+// Exports the vulcanizedb build, modules and transformers the plugin is built with from the temporary module, so that
+// it's only executed by a matching build. The modules are read from the module's build list after it's tidied, so they
+// include the versions of the transformer repositories and their dependencies.
+func (b *builder) addMetadata() (metadata.Metadata, error) {
+	repoVersions, versionErr := b.GenConfig.GetRepoVersions()
+	if versionErr != nil {
+		return metadata.Metadata{}, versionErr
+	}
+	modules, listErr := listModules(b.tmpModDir)
+	if listErr != nil {
+		return metadata.Metadata{}, listErr
+	}
 
-		// Have to clear out the copied over vendor lib or plugin won't build (see issue above)
-		removeErr := os.RemoveAll(filepath.Join(dst, "vendor"))
-		if removeErr != nil {
-			return removeErr
+	pluginMetadata := metadata.Metadata{
+		VulcanizeDBVersion: metadata.VulcanizeDBVersion,
+		GoVersion:          runtime.Version(),
+		Dependencies:       make(map[string]string),
+		TransformerModules: make(map[string]string),
+		Transformers:       b.GenConfig.GetTransformerNames(),
+	}
+	for _, module := range modules {
+		// vulcanizedb itself is identified by its version rather than as a module
+		if module.Main || module.Path == b.homeMod {
+			continue
+		}
+		version := module.Version
+		if module.Replace != nil {
+			version = metadata.ModuleVersion(module.Version, module.Replace.Path, module.Replace.Version)
 		}
-		// Keep track of this vendor directory to clear later
-		b.tmpVenDirs = append(b.tmpVenDirs, dst)
+		pluginMetadata.Dependencies[module.Path] = version
+		if _, ok := repoVersions[module.Path]; ok {
+			pluginMetadata.TransformerModules[module.Path] = version
+		}
+	}
+
+	f := jen.NewFile("main")
+	f.HeaderComment("This is the metadata of the plugin, generated once its modules have been resolved")
+	pluginMetadata.AddSymbols(f)
+	metadataFile := filepath.Join(b.tmpModDir, "metadata.go")
+	saveErr := f.Save(metadataFile)
+	if saveErr != nil {
+		return metadata.Metadata{}, fmt.Errorf("unable to save plugin metadata to %s: %w", metadataFile, saveErr)
 	}
+	return pluginMetadata, nil
+}
 
+// Plugins fail to load if they were built by a different Go toolchain than the binary loading them, and the composed
+// plugin records this binary's Go version as the one it requires
+func verifyGoVersion() error {
+	output, goEnvErr := goCommand("", "env", "GOVERSION")
+	if goEnvErr != nil {
+		return fmt.Errorf("unable to get go version: %w", goEnvErr)
+	}
+	goVersion := strings.TrimSpace(string(output))
+	if goVersion != runtime.Version() {
+		return fmt.Errorf("plugin would be built by %s, but vulcanizedb was built by %s", goVersion, runtime.Version())
+	}
 	return nil
 }
 
-// Used to clear all of the tmp vendor libs used to build the plugin
+// Used to clear the temporary module used to build the plugin
 // Also clears the go file if saving it has not been specified in the config
-// Do not call until after the MigrationManager has performed its operations
-// as it needs to pull the db migrations from the tmpVenDirs
 func (b *builder) CleanUp() error {
 	if !b.GenConfig.Save {
 		err := helpers.ClearFiles(b.goFile)
@@ -121,12 +348,86 @@ func (b *builder) CleanUp() error {
 		}
 	}
 
-	for _, venDir := range b.tmpVenDirs {
-		err := os.RemoveAll(venDir)
-		if err != nil {
-			return err
+	if b.tmpModDir != "" {
+		return os.RemoveAll(b.tmpModDir)
+	}
+	return nil
+}
+
+type modFile struct {
+	Module struct {
+		Path string
+	}
+	Replace []struct {
+		Old goModule
+		New goModule
+	}
+}
+
+func readModFile(dir string) (modFile, error) {
+	var file modFile
+	output, err := goCommand(dir, "mod", "edit", "-json")
+	if err != nil {
+		return file, fmt.Errorf("unable to read go.mod in %s: %w", dir, err)
+	}
+	jsonErr := json.Unmarshal(output, &file)
+	if jsonErr != nil {
+		return file, fmt.Errorf("unable to parse go.mod in %s: %w", dir, jsonErr)
+	}
+	return file, nil
+}
+
+func listModules(dir string) ([]goModule, error) {
+	output, err := goCommand(dir, "list", "-m", "-json", "all")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list modules in %s: %w", dir, err)
+	}
+	var modules []goModule
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var module goModule
+		decodeErr := decoder.Decode(&module)
+		if decodeErr == io.EOF {
+			return modules, nil
+		}
+		if decodeErr != nil {
+			return nil, fmt.Errorf("unable to parse modules in %s: %w", dir, decodeErr)
 		}
+		modules = append(modules, module)
 	}
+}
 
-	return nil
+// Returns the module version or replacement actually built, with replacement directories made absolute
+func resolvedVersion(module goModule, moduleDir string) string {
+	if module.Replace == nil {
+		return module.Version
+	}
+	return moduleString(*module.Replace, moduleDir)
+}
+
+// Formats a module as path@version, or as an absolute directory if it's a local replacement
+func moduleString(module goModule, moduleDir string) string {
+	if module.Version != "" {
+		return module.Path + "@" + module.Version
+	}
+	if moduleDir != "" && !filepath.IsAbs(module.Path) && isLocalPath(module.Path) {
+		return filepath.Join(moduleDir, module.Path)
+	}
+	return module.Path
+}
+
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || path == "." || path == ".."
+}
+
+func goCommand(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
 }
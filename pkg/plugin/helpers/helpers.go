@@ -17,9 +17,13 @@
 package helpers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"syscall"
@@ -41,6 +45,60 @@ func CleanPath(str string) (string, error) {
 	return path, nil
 }
 
+// ModuleDir returns the directory of a version of a module, downloading it into the Go module cache if it's missing
+func ModuleDir(modulePath, version string) (string, error) {
+	cmd := exec.Command("go", "mod", "download", "-json", modulePath+"@"+version)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	var module struct {
+		Dir   string
+		Error string
+	}
+	// go mod download -json reports errors in the Error field as well as its exit status
+	if jsonErr := json.Unmarshal(output, &module); jsonErr == nil && module.Error != "" {
+		return "", fmt.Errorf("could not download %s@%s: %s", modulePath, version, module.Error)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not download %s@%s: %w: %s", modulePath, version, err, strings.TrimSpace(stderr.String()))
+	}
+	if module.Dir == "" {
+		return "", fmt.Errorf("could not find the directory of %s@%s", modulePath, version)
+	}
+	return module.Dir, nil
+}
+
+// RequiredModuleDir returns the directory of the version of a module required by the module in moduleDir: its local
+// replacement or vendored copy if it has one, otherwise the version in the Go module cache
+func RequiredModuleDir(moduleDir, modulePath string) (string, error) {
+	cmd := exec.Command("go", "list", "-m", "-json", modulePath)
+	cmd.Dir = moduleDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not find %s in the requirements of %s: %w: %s", modulePath, moduleDir, err,
+			strings.TrimSpace(stderr.String()))
+	}
+	var module struct {
+		Version string
+		Dir     string
+	}
+	jsonErr := json.Unmarshal(output, &module)
+	if jsonErr != nil {
+		return "", fmt.Errorf("could not parse module %s required by %s: %w", modulePath, moduleDir, jsonErr)
+	}
+	if module.Dir != "" {
+		return module.Dir, nil
+	}
+	// Modules aren't given a directory in vendor mode, or if they haven't been downloaded
+	vendorDir := filepath.Join(moduleDir, "vendor", modulePath)
+	if _, statErr := os.Stat(vendorDir); statErr == nil {
+		return vendorDir, nil
+	}
+	return ModuleDir(modulePath, module.Version)
+}
+
 func ClearFiles(files ...string) error {
 	for _, file := range files {
 		if _, err := os.Stat(file); err == nil {
@@ -20,14 +20,21 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 
 	"github.com/lib/pq"
 	"github.com/makerdao/vulcanizedb/pkg/config"
-	"github.com/makerdao/vulcanizedb/pkg/plugin/helpers"
-	"github.com/pressly/goose"
+	"github.com/makerdao/vulcanizedb/pkg/migrations"
+)
+
+const (
+	// Directory of the plugin's module the builder copies the transformers' migrations into, to be embedded in the
+	// plugin or static binary
+	PluginMigrationsDir = "migrations"
+	// Name of the embed.FS holding the plugin's migrations, exported by plugins
+	PluginMigrationsSymbol = "Migrations"
 )
 
 // Interface for managing the db migrations for plugin transformers
@@ -66,11 +73,9 @@ func (m *manager) setDB() error {
 	return nil
 }
 
+// Runs the core migrations followed by the migrations of the plugin's transformers, read from their repositories. Used
+// when composing the plugin, which embeds the same migrations in it for later runs.
 func (m *manager) RunMigrations() error {
-	if len(m.GenConfig.Schema) <= 0 {
-		return errors.New("config is missing a schema, required for plugin migrations")
-	}
-
 	// Get paths to db migrations from the plugin config
 	paths, err := m.GenConfig.GetMigrationsPaths()
 	if err != nil {
@@ -80,21 +85,22 @@ func (m *manager) RunMigrations() error {
 		return nil
 	}
 
-	// Init directory for temporary copies of migrations
-	setupErr := m.setupMigrationEnv()
-	if setupErr != nil {
-		return fmt.Errorf("could not setup migration env %w", setupErr)
-	}
-	defer m.cleanUp()
-
 	// First run the public schema migrations
 	migrationErr := m.runPublicMigrations()
 	if migrationErr != nil {
 		return fmt.Errorf("could not run public migrations %w", migrationErr)
 	}
 
-	// Creates copies of migrations for all the plugin's transformers in a tmp dir
-	schemaMigrationErr := m.createMigrationCopies(paths)
+	pluginMigrator, pluginErr := m.newPluginMigrator(func() error {
+		// Creates copies of migrations for all the plugin's transformers in a tmp dir
+		return migrations.CopyRankedMigrations(paths, m.tmpMigDir)
+	})
+	if pluginErr != nil {
+		return pluginErr
+	}
+	defer m.CleanUp()
+
+	schemaMigrationErr := pluginMigrator.Up()
 	if schemaMigrationErr != nil {
 		return fmt.Errorf("could not run schema migrations %w", schemaMigrationErr)
 	}
@@ -102,89 +108,53 @@ func (m *manager) RunMigrations() error {
 	return nil
 }
 
-func (m *manager) runPublicMigrations() error {
+// PluginMigrator extracts the migrations embedded in a plugin or static binary when it was composed (in the
+// PluginMigrationsDir of pluginMigrations) into a temporary directory, and returns a migrator for them that records
+// applied migrations in the plugin's schema. Call CleanUp once done.
+func (m *manager) PluginMigrator(pluginMigrations fs.FS) (migrations.Migrator, error) {
+	return m.newPluginMigrator(func() error {
+		return migrations.ExtractMigrations(pluginMigrations, PluginMigrationsDir, m.tmpMigDir)
+	})
+}
+
+// Returns a migrator for the migrations populateMigrations puts in the temporary migration directory
+func (m *manager) newPluginMigrator(populateMigrations func() error) (migrations.Migrator, error) {
+	if len(m.GenConfig.Schema) <= 0 {
+		return migrations.Migrator{}, errors.New("config is missing a schema, required for plugin migrations")
+	}
+
 	// Setup DB if not set
 	if m.db == nil {
 		setErr := m.setDB()
 		if setErr != nil {
-			return fmt.Errorf("could not open db: %w", setErr)
+			return migrations.Migrator{}, fmt.Errorf("could not open db: %w", setErr)
 		}
 	}
 
-	goose.SetTableName("public.goose_db_version")
-
-	path, pathErr := helpers.CleanPath(filepath.Join("$GOPATH/src", m.GenConfig.Home, "db", "migrations"))
-
-	if pathErr != nil {
-		return fmt.Errorf("could not construct filepath %w", pathErr)
-	}
-
-	fixErr := goose.Fix(path)
-	if fixErr != nil {
-		return fmt.Errorf("version fixing for plugin migrations at %s failed: %w", path, fixErr)
+	// Init directory for temporary copies of migrations
+	setupErr := m.setupMigrationEnv()
+	if setupErr != nil {
+		return migrations.Migrator{}, fmt.Errorf("could not setup migration env %w", setupErr)
 	}
 
-	// Run the copied migrations with goose
-	upErr := goose.Up(m.db, path)
-	if upErr != nil {
-		return fmt.Errorf("db migrations for plugin transformers at %s failed: %w", path, upErr)
+	populateErr := populateMigrations()
+	if populateErr != nil {
+		m.CleanUp()
+		return migrations.Migrator{}, fmt.Errorf("could not copy schema migrations %w", populateErr)
 	}
-	return nil
 
-}
-
-// Setup a temporary directory to hold transformer db migrations
-func (m *manager) setupMigrationEnv() error {
-	var err error
-	m.tmpMigDir, err = helpers.CleanPath(filepath.Join("$GOPATH/src", m.GenConfig.Home, ".plugin_migrations"))
-	if err != nil {
-		return fmt.Errorf("unable to construct clean path %w", err)
-	}
-	removeErr := os.RemoveAll(m.tmpMigDir)
-	if removeErr != nil {
-		removeErrString := "unable to remove file found at %s where tmp directory needs to be written: %w"
-		return fmt.Errorf(removeErrString, m.tmpMigDir, removeErr)
-	}
-	mkdirErr := os.Mkdir(m.tmpMigDir, os.ModePerm)
-	if mkdirErr != nil {
-		mkdirErrString := "unable to create temporary migration directory %s: %w"
-		return fmt.Errorf(mkdirErrString, m.tmpMigDir, mkdirErr)
-	}
-
-	return nil
-}
-
-// Create copies of db migrations from vendored libs
-func (m *manager) createMigrationCopies(paths []string) error {
-	// Iterate through migration paths to find migration directory
-	for _, path := range paths {
-		dir, err := ioutil.ReadDir(path)
-		if err != nil {
-			return err
-		}
-		// For each file in the directory check if it is a migration
-		for _, file := range dir {
-			if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
-				continue
-			}
-			src := filepath.Join(path, file.Name())
-			dst := filepath.Join(m.tmpMigDir, file.Name())
-			//  and if it is make a copy of it to our tmp migration directory
-			err = helpers.CopyFile(src, dst)
-			if err != nil {
-				return err
-			}
-		}
-		err = m.fixAndRun(path)
-		if err != nil {
-			return err
-		}
+	_, execErr := m.db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", m.GenConfig.Schema))
+	if execErr != nil {
+		m.CleanUp()
+		return migrations.Migrator{}, fmt.Errorf("could not create schema %s, %w", m.GenConfig.Schema, execErr)
 	}
 
-	return nil
+	versionTable := fmt.Sprintf("%s.goose_db_version", m.GenConfig.Schema)
+	return migrations.NewMigrator(m.db, m.tmpMigDir, versionTable), nil
 }
 
-func (m *manager) fixAndRun(path string) error {
+// Runs the core migrations embedded in the binary
+func (m *manager) runPublicMigrations() error {
 	// Setup DB if not set
 	if m.db == nil {
 		setErr := m.setDB()
@@ -193,27 +163,27 @@ func (m *manager) fixAndRun(path string) error {
 		}
 	}
 
-	_, execErr := m.db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", m.GenConfig.Schema))
-	if execErr != nil {
-		return fmt.Errorf("could not create schema %s, %w", m.GenConfig.Schema, execErr)
+	coreMigrator, cleanUp, migratorErr := migrations.NewCoreMigrator(m.db)
+	if migratorErr != nil {
+		return migratorErr
 	}
+	defer cleanUp()
 
-	goose.SetTableName(fmt.Sprintf("%s.goose_db_version", m.GenConfig.Schema))
+	return coreMigrator.Up()
+}
 
-	// Fix the migrations
-	fixErr := goose.Fix(m.tmpMigDir)
-	if fixErr != nil {
-		return fmt.Errorf("version fixing for plugin migrations at %s failed: %w", path, fixErr)
+// Setup a temporary directory to hold transformer db migrations
+func (m *manager) setupMigrationEnv() error {
+	var err error
+	m.tmpMigDir, err = ioutil.TempDir("", "vulcanizedb_plugin_migrations")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary migration directory: %w", err)
 	}
 
-	// Run the copied migrations with goose
-	upErr := goose.Up(m.db, m.tmpMigDir)
-	if upErr != nil {
-		return fmt.Errorf("db migrations for plugin transformers at %s failed: %w", path, upErr)
-	}
 	return nil
 }
 
-func (m *manager) cleanUp() error {
+// Removes the temporary copies of the plugin's migrations
+func (m *manager) CleanUp() error {
 	return os.RemoveAll(m.tmpMigDir)
 }
@@ -0,0 +1,310 @@
+// VulcanizeDB
+// Copyright © 2019 Vulcanize
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"plugin"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// Version of vulcanizedb, set at build time with
+// -ldflags "-X github.com/makerdao/vulcanizedb/pkg/plugin/metadata.VulcanizeDBVersion=<version>". Otherwise it's read
+// from the build info: the vulcanizedb module's version, or the VCS revision it was built from.
+var VulcanizeDBVersion string
+
+const (
+	vulcanizeDBModule = "github.com/makerdao/vulcanizedb"
+	// Version of builds without a version set or recorded in their build info
+	devVersion = "dev"
+)
+
+func init() {
+	if VulcanizeDBVersion == "" {
+		VulcanizeDBVersion = buildInfoVersion()
+	}
+}
+
+func buildInfoVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return devVersion
+	}
+	module := &info.Main
+	if module.Path != vulcanizeDBModule {
+		module = nil
+		for _, dep := range info.Deps {
+			if dep.Path == vulcanizeDBModule {
+				module = dep
+				break
+			}
+		}
+	}
+	if module != nil && module.Replace != nil {
+		module = module.Replace
+	}
+	if module != nil && module.Version != "" && module.Version != "(devel)" {
+		return module.Version
+	}
+	// VCS settings describe the main module, so they're only used when building vulcanizedb itself
+	if info.Main.Path != vulcanizeDBModule {
+		return devVersion
+	}
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision == "" {
+		return devVersion
+	}
+	if modified == "true" {
+		return revision + "-dirty"
+	}
+	return revision
+}
+
+// Names of the symbols a composed plugin exports its metadata as
+const (
+	VersionSymbol            = "VulcanizeDBVersion"
+	GoVersionSymbol          = "GoVersion"
+	DependenciesSymbol       = "Dependencies"
+	TransformerModulesSymbol = "TransformerModules"
+	TransformersSymbol       = "Transformers"
+)
+
+// Version recorded for modules replaced by a local directory, which can't be told apart by version
+const LocalReplacement = "local replacement"
+
+var ErrIncompatiblePlugin = errors.New("plugin is incompatible with this vulcanizedb binary, recompose it with the same binary and config")
+
+// Metadata describes the vulcanizedb build and transformers a plugin was composed for
+type Metadata struct {
+	VulcanizeDBVersion string
+	GoVersion          string
+	Dependencies       map[string]string // Module path => version, of every module in the build
+	TransformerModules map[string]string // Transformer repository module path => version
+	Transformers       []string
+}
+
+// Returns the metadata expected of a plugin by the running binary, for the given transformers and versions of their
+// repositories, empty for those without a configured version
+func New(transformers []string, transformerModules map[string]string) Metadata {
+	sorted := append([]string{}, transformers...)
+	sort.Strings(sorted)
+	return Metadata{
+		VulcanizeDBVersion: VulcanizeDBVersion,
+		GoVersion:          runtime.Version(),
+		Dependencies:       dependencies(),
+		TransformerModules: transformerModules,
+		Transformers:       sorted,
+	}
+}
+
+// Formats the version of a module as it's built: its own version, or the path and version of its replacement
+func ModuleVersion(version, replacePath, replaceVersion string) string {
+	if replacePath == "" {
+		return version
+	}
+	if replaceVersion == "" {
+		return LocalReplacement
+	}
+	return replacePath + "@" + replaceVersion
+}
+
+// Returns the path of the metadata file written next to a plugin's .so file
+func FilePath(soFile string) string {
+	return strings.TrimSuffix(soFile, ".so") + ".json"
+}
+
+// Writes the metadata file for a plugin, so it can be checked without linking the plugin
+func Write(path string, m Metadata) error {
+	data, marshalErr := json.MarshalIndent(m, "", "  ")
+	if marshalErr != nil {
+		return fmt.Errorf("failed to encode plugin metadata: %w", marshalErr)
+	}
+	writeErr := ioutil.WriteFile(path, data, 0644)
+	if writeErr != nil {
+		return fmt.Errorf("failed to write plugin metadata file %s: %w", path, writeErr)
+	}
+	return nil
+}
+
+// Adds the metadata to a plugin's generated code as the exported symbols read by Lookup
+func (m Metadata) AddSymbols(f *jen.File) {
+	f.Var().Id(VersionSymbol).Op("=").Lit(m.VulcanizeDBVersion)
+	f.Var().Id(GoVersionSymbol).Op("=").Lit(m.GoVersion)
+	f.Var().Id(DependenciesSymbol).Op("=").Map(jen.String()).String().Values(stringMap(m.Dependencies))
+	f.Var().Id(TransformerModulesSymbol).Op("=").Map(jen.String()).String().Values(stringMap(m.TransformerModules))
+	f.Var().Id(TransformersSymbol).Op("=").Index().String().ValuesFunc(func(g *jen.Group) {
+		for _, name := range m.Transformers {
+			g.Lit(name)
+		}
+	})
+}
+
+func stringMap(values map[string]string) jen.Code {
+	return jen.DictFunc(func(d jen.Dict) {
+		for key, value := range values {
+			d[jen.Lit(key)] = jen.Lit(value)
+		}
+	})
+}
+
+// Reads a plugin's metadata file
+func Read(path string) (Metadata, error) {
+	var m Metadata
+	data, readErr := ioutil.ReadFile(path)
+	if readErr != nil {
+		return m, fmt.Errorf("failed to read plugin metadata file %s: %w", path, readErr)
+	}
+	unmarshalErr := json.Unmarshal(data, &m)
+	if unmarshalErr != nil {
+		return m, fmt.Errorf("failed to decode plugin metadata file %s: %w", path, unmarshalErr)
+	}
+	return m, nil
+}
+
+// Reads the metadata symbols exported by a linked plugin
+func Lookup(plug *plugin.Plugin) (Metadata, error) {
+	var m Metadata
+	version, versionErr := lookupSymbol(plug, VersionSymbol)
+	if versionErr != nil {
+		return m, versionErr
+	}
+	goVersion, goVersionErr := lookupSymbol(plug, GoVersionSymbol)
+	if goVersionErr != nil {
+		return m, goVersionErr
+	}
+	deps, depsErr := lookupSymbol(plug, DependenciesSymbol)
+	if depsErr != nil {
+		return m, depsErr
+	}
+	transformerModules, transformerModulesErr := lookupSymbol(plug, TransformerModulesSymbol)
+	if transformerModulesErr != nil {
+		return m, transformerModulesErr
+	}
+	transformers, transformersErr := lookupSymbol(plug, TransformersSymbol)
+	if transformersErr != nil {
+		return m, transformersErr
+	}
+
+	versionPtr, versionOk := version.(*string)
+	goVersionPtr, goVersionOk := goVersion.(*string)
+	depsPtr, depsOk := deps.(*map[string]string)
+	transformerModulesPtr, transformerModulesOk := transformerModules.(*map[string]string)
+	transformersPtr, transformersOk := transformers.(*[]string)
+	if !versionOk || !goVersionOk || !depsOk || !transformerModulesOk || !transformersOk {
+		return m, errors.New("plugin metadata symbols have unexpected types")
+	}
+	m.VulcanizeDBVersion = *versionPtr
+	m.GoVersion = *goVersionPtr
+	m.Dependencies = *depsPtr
+	m.TransformerModules = *transformerModulesPtr
+	m.Transformers = *transformersPtr
+	return m, nil
+}
+
+// Returns ErrIncompatiblePlugin describing every difference between the plugin's metadata and the expected metadata.
+// Dependencies are only compared for the modules both were built with, since those are the packages they share.
+func (m Metadata) Compare(expected Metadata) error {
+	var mismatches []string
+	if m.VulcanizeDBVersion != expected.VulcanizeDBVersion {
+		mismatches = append(mismatches, mismatch("vulcanizedb version", m.VulcanizeDBVersion, expected.VulcanizeDBVersion))
+	}
+	if m.GoVersion != expected.GoVersion {
+		mismatches = append(mismatches, mismatch("go version", m.GoVersion, expected.GoVersion))
+	}
+	var depMismatches []string
+	for path, version := range expected.Dependencies {
+		pluginVersion, ok := m.Dependencies[path]
+		if ok && pluginVersion != version {
+			depMismatches = append(depMismatches, mismatch("dependency "+path, pluginVersion, version))
+		}
+	}
+	for path, version := range expected.TransformerModules {
+		// Repositories without a configured version are built at whichever version vulcanizedb's go.mod resolves
+		pluginVersion, ok := m.TransformerModules[path]
+		if !ok || (version != "" && pluginVersion != version) {
+			depMismatches = append(depMismatches, mismatch("transformer module "+path, pluginVersion, version))
+		}
+	}
+	for path, version := range m.TransformerModules {
+		if _, ok := expected.TransformerModules[path]; !ok {
+			depMismatches = append(depMismatches, mismatch("transformer module "+path, version, ""))
+		}
+	}
+	sort.Strings(depMismatches)
+	mismatches = append(mismatches, depMismatches...)
+	pluginTransformers := strings.Join(m.Transformers, ", ")
+	expectedTransformers := strings.Join(expected.Transformers, ", ")
+	if pluginTransformers != expectedTransformers {
+		mismatches = append(mismatches, mismatch("transformers", pluginTransformers, expectedTransformers))
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %s", ErrIncompatiblePlugin, strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+func lookupSymbol(plug *plugin.Plugin, name string) (plugin.Symbol, error) {
+	symbol, err := plug.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin has no %s symbol, it was composed by an older version of vulcanizedb: %w", name, err)
+	}
+	return symbol, nil
+}
+
+func mismatch(field, pluginValue, expectedValue string) string {
+	if pluginValue == "" {
+		pluginValue = "none"
+	}
+	if expectedValue == "" {
+		expectedValue = "none"
+	}
+	return fmt.Sprintf("%s (plugin: %s, expected: %s)", field, pluginValue, expectedValue)
+}
+
+// Returns the version of each module the running binary was built with
+func dependencies() map[string]string {
+	deps := make(map[string]string)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return deps
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			deps[dep.Path] = ModuleVersion(dep.Version, dep.Replace.Path, dep.Replace.Version)
+		} else {
+			deps[dep.Path] = ModuleVersion(dep.Version, "", "")
+		}
+	}
+	return deps
+}
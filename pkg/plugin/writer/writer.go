@@ -24,11 +24,14 @@ import (
 
 	"github.com/makerdao/vulcanizedb/pkg/config"
 	"github.com/makerdao/vulcanizedb/pkg/plugin/helpers"
+	"github.com/makerdao/vulcanizedb/pkg/plugin/metadata"
 )
 
 // Interface for writing a .go file for a simple
 // plugin that exports the set of transformer
-// initializers specified in the config
+// initializers specified in the config, or in
+// static mode a vulcanizedb main package with
+// them compiled in
 type PluginWriter interface {
 	WritePlugin() error
 }
@@ -54,7 +57,11 @@ func (w *writer) WritePlugin() error {
 
 	// Begin code generation
 	f := NewFile("main")
-	f.HeaderComment("This is a plugin generated to export the configured transformer initializers")
+	if w.GenConfig.Static {
+		f.HeaderComment("This is a vulcanizedb binary generated with the configured transformer initializers compiled in")
+	} else {
+		f.HeaderComment("This is a plugin generated to export the configured transformer initializers")
+	}
 
 	// Import pkgs for generic TransformerInitializer interface and specific TransformerInitializers specified in config
 	f.ImportAlias("github.com/makerdao/vulcanizedb/libraries/shared/transformer", "interface")
@@ -88,8 +95,18 @@ func (w *writer) WritePlugin() error {
 			"github.com/makerdao/vulcanizedb/libraries/shared/transformer",
 			"ContractTransformerInitializer").Values(code[config.EthContract]...))) // Exports the collected event and storage transformer initializers
 
-	// Write code to destination file
-	err = f.Save(goFile)
+	// A static binary registers the Exporter with vulcanizedb's commands instead of exporting it as a plugin symbol
+	if w.GenConfig.Static {
+		f.Func().Id("init").Params().Block(Qual("github.com/makerdao/vulcanizedb/cmd", "RegisterExporter").Call(Id("Exporter")))
+		f.Func().Id("main").Params().Block(Qual("github.com/makerdao/vulcanizedb/cmd", "Main").Call())
+	}
+
+	// A plugin's metadata is exported by the builder, once the modules it's built with have been resolved
+	return saveFile(f, goFile)
+}
+
+func saveFile(f *File, goFile string) error {
+	err := f.Save(goFile)
 	if err != nil {
 		return errors.New(fmt.Sprintf("failed to save generated .go file: %s\r\n%s", goFile, err.Error()))
 	}
@@ -116,12 +133,16 @@ func (w *writer) collectTransformers() (map[config.TransformerType][]Code, error
 	return code, nil
 }
 
-// Setup the .go, clear old ones if present
+// Setup the .go file, clear old outputs if present
 func (w *writer) setupFilePath() (string, error) {
 	goFile, soFile, err := w.GenConfig.GetPluginPaths()
 	if err != nil {
 		return "", err
 	}
-	// Clear .go and .so files of the same name if they exist
-	return goFile, helpers.ClearFiles(goFile, soFile)
+	binaryFile, err := w.GenConfig.GetBinaryPath()
+	if err != nil {
+		return "", err
+	}
+	// Clear .go, .so, metadata and binary files of the same name if they exist
+	return goFile, helpers.ClearFiles(goFile, soFile, metadata.FilePath(soFile), binaryFile)
 }
@@ -71,13 +71,22 @@ func NewTestDB(node core.Node) *postgres.DB {
 }
 
 func CleanTestDB(db *postgres.DB) {
+	db.MustExec("DELETE FROM public.abis")
 	db.MustExec("DELETE FROM public.addresses")
 	db.MustExec("DELETE FROM public.checked_headers")
+	db.MustExec("DELETE FROM public.checked_logs")
 	// can't delete from eth_nodes since this function is called after the required eth_node is persisted
 	db.MustExec("DELETE FROM public.goose_db_version")
+	db.MustExec("DELETE FROM public.transform_errors")
 	db.MustExec("DELETE FROM public.event_logs")
+	db.MustExec("DELETE FROM public.legacy_seeded_transformers")
+	db.MustExec("DELETE FROM public.legacy_transformed_logs")
 	db.MustExec("DELETE FROM public.receipts")
+	db.MustExec("DELETE FROM public.registered_addresses")
+	db.MustExec("DELETE FROM public.reorg_notifications")
+	db.MustExec("DELETE FROM public.reorgs")
 	db.MustExec("DELETE FROM public.transactions")
+	db.MustExec("DELETE FROM public.transformed_logs")
 	db.MustExec("DELETE FROM public.headers")
 	db.MustExec("DELETE FROM public.storage_diff")
 	db.MustExec("DELETE FROM public.watched_logs")